@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	acv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -64,6 +65,12 @@ type CNAutoscalerSpec struct {
 	// +optional
 	ScalePeriodSeconds *ScalePeriodSeconds `json:"scalePeriodSeconds,omitempty"`
 
+	// StabilizationWindowSeconds indicates the length of time in the past that the k8s HPA takes the
+	// recommendations into account, which prevents the replicas from flapping when the metrics fluctuate.
+	// Ref: https://kubernetes.io/docs/tasks/run-application/horizontal-pod-autoscale/#stabilization-window
+	// +optional
+	StabilizationWindowSeconds *ScalePeriodSeconds `json:"stabilizationWindowSeconds,omitempty"`
+
 	// Whether to disable scale down
 	// Default to false
 	// +optional
@@ -79,6 +86,31 @@ type CNAutoscalerRules struct {
 	// Rules for scaling based on memory usage percentage of CN pods
 	// +optional
 	Memory *UtilizationThresholdRange `json:"memory,omitempty"`
+
+	// Rules for scaling based on the metrics served by the k8s external metrics API,
+	// e.g. the running query count of Doris FE that served by prometheus-adapter.
+	// +optional
+	External []ExternalMetricRule `json:"external,omitempty"`
+}
+
+// ExternalMetricRule is the scaling rule based on a metric of the k8s external metrics API,
+// the metric value is averaged over the CN pods before being compared with the thresholds.
+type ExternalMetricRule struct {
+	// Name of the metric in the external metrics API
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Selector of the metric labels, e.g. the name of Doris cluster.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// CN replicas is scaled up when the average metric value is greater than max.
+	// +optional
+	Max *resource.Quantity `json:"max,omitempty"`
+
+	// CN replicas is scaled down when the average metric value is less than min.
+	// +optional
+	Min *resource.Quantity `json:"min,omitempty"`
 }
 
 type ReplicasRange struct {
@@ -22,12 +22,19 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DorisCluster is the Schema for the doris clusters API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=dc
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="FE-Ready",type=integer,JSONPath=`.status.fe.readyReplicas`
+// +kubebuilder:printcolumn:name="FE-Desired",type=integer,JSONPath=`.status.fe.replicas`,priority=1
+// +kubebuilder:printcolumn:name="BE-Ready",type=integer,JSONPath=`.status.be.readyReplicas`
+// +kubebuilder:printcolumn:name="BE-Desired",type=integer,JSONPath=`.status.be.replicas`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 type DorisCluster struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -62,13 +69,102 @@ type DorisClusterSpec struct {
 	// +optional
 	BusyBoxImage *string `json:"busyBoxImage,omitempty"`
 
+	// HelperImage is the image of the operator-injected init containers running bash scripts,
+	// e.g. the wait-for-fe init container of BE and CN, which must provide bash.
+	// Defaults to the image of the component.
+	// +optional
+	HelperImage *string `json:"helperImage,omitempty"`
+
+	// Whether to pause the reconciliation of the Doris cluster, the existing resources
+	// would be left untouched when it is paused.
+	// Default to false
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Whether to generate the Prometheus Operator ServiceMonitors of FE, BE and CN,
+	// it would be skipped when the ServiceMonitor CRD is not installed.
+	// Default to false
+	// +optional
+	EnableServiceMonitor bool `json:"enableServiceMonitor,omitempty"`
+
+	// Monitoring configures the Prometheus scraping annotations of Doris pods.
+	// +optional
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
+	// Whether BE and CN pods wait for the FE query port to be serving before starting.
+	// Default to true
+	// +optional
+	WaitForFE *bool `json:"waitForFE,omitempty"`
+
+	// ConfigKeyValidation controls how the keys of FE, BE and CN configs are checked against
+	// the known Doris config keys of the major version on admission, the check is skipped when
+	// the known keys of the version are not bundled with operator.
+	// "Warning" warns about the unknown keys, "Strict" rejects them, and "Disabled" skips the check.
+	// Default to Warning
+	// +optional
+	ConfigKeyValidation ConfigKeyValidationMode `json:"configKeyValidation,omitempty"`
+
 	// Doris cluster image version
 	Version string `json:"version"`
 
-	// ImagePullPolicy of Doris cluster Pods
+	// Domain of the kubernetes cluster, which is used to compose the FQDN of Doris pods
+	// like <pod>.<peer-service>.<namespace>.svc.<clusterDomain>.
+	// Default to cluster.local
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Namespace of the sub resources of Doris cluster, the referenced ConfigMaps and Secrets
+	// are resolved in this namespace too. Default to the namespace of DorisCluster.
+	// Owner references can not cross namespaces, when it differs from the namespace of
+	// DorisCluster, the sub resources are labeled with the owner instead and are garbage
+	// collected by operator on the deletion of DorisCluster. Note that the cascading deletion
+	// of kubernetes no longer applies, the sub resources would be left behind when the
+	// DorisCluster is deleted while operator is not running.
+	// It is immutable, and two DorisClusters with the same name can not share it.
+	// +optional
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+
+	// Reference to an existing secret in the same namespace that contains the "user" and
+	// "password" of the Doris SQL account used by operator, the account would be created
+	// when the FE cluster is bootstrapped.
+	// Defaults to a secret generated by operator with a random password.
+	// +optional
+	OprSqlAccountSecretRef *corev1.LocalObjectReference `json:"oprSqlAccountSecretRef,omitempty"`
+
+	// OprSqlAccount customizes the Doris SQL account generated by operator, it can not be used
+	// together with the spec.oprSqlAccountSecretRef.
+	// +optional
+	OprSqlAccount *OprSqlAccountSpec `json:"oprSqlAccount,omitempty"`
+
+	// InitSQL is the SQL script executed against FE via the operator SQL account once the
+	// Doris cluster is ready for the first time, e.g. creating databases, users and grants.
+	// The script is re-executed from the beginning on failure, so the statements should be idempotent.
+	// +optional
+	InitSQL *InitSQLSpec `json:"initSQL,omitempty"`
+
+	// RestoreFrom restores the databases of the Doris cluster from the snapshots in a backup
+	// repository once the cluster is ready for the first time, before the spec.initSQL is executed.
+	// It only takes effect when the DorisCluster is created with it, and can not be changed after that.
+	// +optional
+	RestoreFrom *RestoreFromSpec `json:"restoreFrom,omitempty"`
+
+	// Backup periodically backs up the databases of the Doris cluster to a backup repository via the
+	// Doris BACKUP statement, which includes both the metadata and the data of the tables.
+	// +optional
+	Backup *BackupSpec `json:"backup,omitempty"`
+
+	// ImagePullPolicy of Doris cluster Pods.
+	// When unset, the FE, BE, CN and Broker containers use Always for the "latest"
+	// or missing image tag, and IfNotPresent for a pinned tag or digest.
 	// +optional
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 
+	// ImageRegistry is the private registry host prefixed to the FE, BE, CN, Broker, busybox and helper
+	// images that do not specify a registry host, e.g. "registry.example.com" or "registry.example.com/mirror",
+	// the bare library images like "busybox" become "registry.example.com/library/busybox".
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling
 	// any of the images.
 	// +optional
@@ -82,7 +178,8 @@ type DorisClusterSpec struct {
 	// +optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
-	// NodeSelector of the Doris cluster.
+	// NodeSelector of the Doris cluster pods, it is merged with the NodeSelector of
+	// each component.
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
@@ -94,13 +191,81 @@ type DorisClusterSpec struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// DefaultResources are the resource requests and limits of the FE, BE, CN and Broker
+	// containers that do not specify their own. The requests and limits are inherited
+	// respectively as a whole, e.g. a component specifying the cpu request only does not
+	// inherit the memory request, while it still inherits the limits when it specifies none.
+	// The storage is never inherited since it is the request of the component PVC.
+	// +optional
+	DefaultResources *corev1.ResourceRequirements `json:"defaultResources,omitempty"`
+
+	// TopologySpreadConstraints describes how pods ought to spread across topology domains.
+	// Defaults to spread pods of the component across nodes when replicas > 1.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// Specify pod priorities of pods in Doris cluster, default to empty.
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
 
+	// PreemptionPolicy of Doris cluster pods, e.g. "Never" to prevent the pods from preempting
+	// the lower priority pods, default to the preemption policy of the PriorityClass.
+	// +optional
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// DNSConfig of Doris cluster pods, e.g. tuning the "ndots" option to speed up the
+	// resolution of external hostnames such as HDFS or S3 endpoints, default to empty.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// DNSPolicy of Doris cluster pods, default to "ClusterFirst",
+	// or "ClusterFirstWithHostNet" for the BE pods in the host network.
+	// +optional
+	DNSPolicy *corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// RuntimeClassName of Doris cluster pods, e.g. running the pods in the gVisor or Kata
+	// sandbox, default to the default container runtime of the node.
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
 	// Update strategy of Doris cluster StatefulSet.
+	// With the OnDelete strategy, the operator recreates the outdated FE and BE pods one
+	// by one, the next pod is deleted only after the replacement is ready and all the nodes
+	// are alive in the Doris cluster, and for BE, all the tablets are healthy.
 	// +optional
 	StatefulSetUpdateStrategy *appv1.StatefulSetUpdateStrategyType `json:"statefulSetUpdateStrategy,omitempty"`
+
+	// PodSecurityContext of Doris cluster pods, default to empty.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// FSGroup of Doris cluster pods, kubernetes changes the group ownership of the mounted
+	// volumes to it so that the PVC data is writable by the Doris process running as a non-root
+	// user. Defaults to the non-root user that the container runs as via runAsUser of the
+	// security context, it should be set explicitly when the non-root user is built into the
+	// image. The fsGroup of podSecurityContext takes precedence when it is set.
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// FSGroupChangePolicy of Doris cluster pods, defaults to OnRootMismatch when the fsGroup
+	// is applied. Always changes the ownership and permissions of all the files in the volumes
+	// on every mount, which would delay the startup of BE with large volumes for a long time,
+	// OnRootMismatch only changes them when the root of the volume does not match.
+	// +kubebuilder:validation:Enum=OnRootMismatch;Always
+	// +optional
+	FSGroupChangePolicy *corev1.PodFSGroupChangePolicy `json:"fsGroupChangePolicy,omitempty"`
+
+	// ContainerSecurityContext of the FE, BE, CN and Broker containers.
+	// Defaults to drop the capabilities that are not required by Doris.
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// Whether to add the SYS_NICE and IPC_LOCK capabilities, which are used by Doris to
+	// adjust the thread priorities and lock the memory, to the default container security context.
+	// It takes no effect when the ContainerSecurityContext is specified.
+	// Default to false
+	// +optional
+	AddDorisCapabilities bool `json:"addDorisCapabilities,omitempty"`
 }
 
 // FESpec contains details of FE members.
@@ -115,8 +280,185 @@ type FESpec struct {
 
 	// Service defines a Kubernetes service of FE
 	Service *FeServiceSpec `json:"service,omitempty"`
+
+	// PodService generates a Kubernetes service for each FE pod named after the pod, so that
+	// each FE is individually addressable from outside, e.g. connecting to the FE master.
+	// The services are created and deleted along with the FE replicas.
+	// +optional
+	PodService *FePodServiceSpec `json:"podService,omitempty"`
+
+	// Ingress generates a Kubernetes Ingress that routes to the http port of the FE service,
+	// which exposes the FE web UI. It would be skipped when the Ingress API is not available.
+	// +optional
+	Ingress *FeIngressSpec `json:"ingress,omitempty"`
+
+	// The number of FE followers (voting members) among the FE replicas, the FE pods
+	// with ordinal greater than or equal to it join the Doris cluster as observers
+	// (non-voting members). Default to the FE replicas, which means all FE nodes are followers.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Followers *int32 `json:"followers,omitempty"`
+
+	// PodDisruptionBudget overrides the default PodDisruptionBudget of FE, which keeps
+	// the majority of FE followers available, or allows at most one FE pod to be
+	// unavailable when there are observers.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// JvmHeap configures the JVM heap of FE.
+	// +optional
+	JvmHeap *JvmHeapSpec `json:"jvmHeap,omitempty"`
+
+	// TLS enables the SSL of the FE MySQL protocol, which requires Doris 2.0 or later.
+	// +optional
+	TLS *FeTLSSpec `json:"tls,omitempty"`
+
+	// LeaderAwareRollout makes the operator restart the outdated FE pods one by one
+	// instead of the statefulset rolling update, the FE master is restarted last and
+	// each pod is restarted only after the previous one has rejoined the Doris cluster.
+	// +optional
+	LeaderAwareRollout bool `json:"leaderAwareRollout,omitempty"`
+
+	// MetaMountPath is the mount path of the FE metadata volume for the custom image layout,
+	// the "meta_dir" config of FE is set to it when it differs from the default.
+	// Default to /opt/apache-doris/fe/doris-meta
+	// +optional
+	MetaMountPath string `json:"metaMountPath,omitempty"`
+
+	// LogMountPath is the mount path of the FE log volume for the custom image layout,
+	// the "LOG_DIR" config of FE is set to it when it differs from the default.
+	// Default to /opt/apache-doris/fe/log
+	// +optional
+	LogMountPath string `json:"logMountPath,omitempty"`
+
+	// DefaultAntiAffinity is the pod anti-affinity across nodes applied to the FE pods when
+	// neither the FE nor the cluster affinity is set and there are more than one FE replicas,
+	// so that a single node failure does not lose the FE quorum. Preferred spreads the FE pods
+	// to different nodes as much as possible, Required never schedules two FE pods on the same
+	// node, None disables the default anti-affinity.
+	// Default to Preferred
+	// +optional
+	DefaultAntiAffinity AntiAffinityMode `json:"defaultAntiAffinity,omitempty"`
+
+	// ConfigRollback enables the automatic rollback of the FE config, when any FE pod restarted
+	// with a changed config stays unready longer than the timeout, the configFileContent, configMapRef
+	// and config of FE are reverted to the last ones that all the FE pods have been ready with,
+	// while the other changes of spec, e.g. the image, are kept.
+	// +optional
+	ConfigRollback *FEConfigRollbackSpec `json:"configRollback,omitempty"`
+}
+
+// FEConfigRollbackSpec configures the automatic rollback of the FE config. Each changed config
+// is rolled back at most once, and it would not be applied again until the config is changed.
+type FEConfigRollbackSpec struct {
+	// ReadyTimeoutSeconds is how long each FE pod restarted with the changed config is
+	// allowed to be unready before the config is rolled back.
+	// Default to 600
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	ReadyTimeoutSeconds *int32 `json:"readyTimeoutSeconds,omitempty"`
 }
 
+// AntiAffinityMode describes how the default pod anti-affinity is enforced.
+// +kubebuilder:validation:Enum=Preferred;Required;None
+type AntiAffinityMode string
+
+const (
+	AntiAffinityPreferred AntiAffinityMode = "Preferred"
+	AntiAffinityRequired  AntiAffinityMode = "Required"
+	AntiAffinityNone      AntiAffinityMode = "None"
+)
+
+// BackupRepositorySpec defines the location of Doris backup repository where the snapshots are stored.
+// +k8s:openapi-gen=true
+type BackupRepositorySpec struct {
+	// Location of the backup repository on S3 or HDFS,
+	// e.g. "s3://bucket/doris/backup" or "hdfs://namenode:8020/doris/backup".
+	// +kubebuilder:validation:Pattern=`^(s3|hdfs)://.+`
+	Location string `json:"location"`
+
+	// Properties of the backup repository, e.g. "s3.endpoint" and "s3.region" for S3, or
+	// "fs.defaultFS" and "hadoop.username" for HDFS. The Kerberos keytab mounted from the
+	// spec.hadoopConf.credentialSecretRef can be referenced by "hadoop.kerberos.keytab".
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+
+	// CredentialSecretRef refers to a Secret in the namespace of the DorisCluster resources,
+	// the keys and values of which are appended to the properties of the backup repository,
+	// e.g. "s3.access_key" and "s3.secret_key".
+	// +optional
+	CredentialSecretRef *corev1.LocalObjectReference `json:"credentialSecretRef,omitempty"`
+}
+
+// BackupSpec defines the schedule and the backup repository of the periodic backups.
+// +k8s:openapi-gen=true
+type BackupSpec struct {
+	BackupRepositorySpec `json:",inline"`
+
+	// Schedule of the backups in the cron format like "0 2 * * *" in the timezone of operator,
+	// the macros like "@daily" are supported too.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Databases to back up.
+	// +kubebuilder:validation:MinItems=1
+	Databases []string `json:"databases"`
+
+	// Retention is the number of the latest successful backups kept in status.backup.history.
+	// Doris is not able to delete the snapshots from the backup repository, the expired snapshots
+	// are reported by the "BackupExpired" events and should be removed by the lifecycle rules
+	// of the storage. Default to 7
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// Suspend the scheduling of backups, the running backup is not affected.
+	// Default to false
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// RestoreFromSpec defines the backup repository and the snapshots to restore the Doris cluster from.
+// +k8s:openapi-gen=true
+type RestoreFromSpec struct {
+	BackupRepositorySpec `json:",inline"`
+
+	// Snapshots to restore in order.
+	// +kubebuilder:validation:MinItems=1
+	Snapshots []RestoreSnapshotSpec `json:"snapshots"`
+}
+
+// RestoreSnapshotSpec defines a snapshot of database in the backup repository.
+// +k8s:openapi-gen=true
+type RestoreSnapshotSpec struct {
+	// Database to restore the snapshot into, it would be created when it does not exist.
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+
+	// Snapshot name in the backup repository.
+	// +kubebuilder:validation:MinLength=1
+	Snapshot string `json:"snapshot"`
+
+	// BackupTimestamp of the snapshot like "2024-01-01-12-00-00", as shown by
+	// "SHOW SNAPSHOT ON <repository>".
+	// +kubebuilder:validation:MinLength=1
+	BackupTimestamp string `json:"backupTimestamp"`
+
+	// Properties of the restore job, e.g. "replication_num".
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ConfigKeyValidationMode describes how the unknown config keys of Doris components are handled.
+// +kubebuilder:validation:Enum=Warning;Strict;Disabled
+type ConfigKeyValidationMode string
+
+const (
+	ConfigKeyValidationWarning  ConfigKeyValidationMode = "Warning"
+	ConfigKeyValidationStrict   ConfigKeyValidationMode = "Strict"
+	ConfigKeyValidationDisabled ConfigKeyValidationMode = "Disabled"
+)
+
 // BESpec contains details of BE members.
 // +k8s:openapi-gen=true
 type BESpec struct {
@@ -131,12 +473,94 @@ type BESpec struct {
 	// +optional
 	Storage []BEStorage `json:"storage,omitempty"`
 
+	// PodDisruptionBudget overrides the default PodDisruptionBudget of BE,
+	// which allows at most one BE pod to be unavailable.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// Disable the tablet balancing of Doris cluster via the FE config "disable_balance"
+	// during the rolling update of BE, and re-enable it after the rollout.
+	// The balancing that has been disabled intentionally would not be touched.
+	// +optional
+	DisableBalanceDuringRollout *bool `json:"disableBalanceDuringRollout,omitempty"`
+
 	// Whether to retain the default data storage mount for BE which is located at be/storage,
 	// Default to false
 	// +optional
 	RetainDefaultStorage bool `json:"retainDefaultStorage,omitempty"`
+
+	// DecommissionTimeout is the upper bound of the decommission of the BE nodes removed by
+	// scaling down, the DecommissionTimeoutPolicy is applied when it is exceeded.
+	// No timeout by default.
+	// +optional
+	DecommissionTimeout *metav1.Duration `json:"decommissionTimeout,omitempty"`
+
+	// DecommissionTimeoutPolicy is the action taken when the decommission exceeds the
+	// DecommissionTimeout. "Abort" cancels the decommission and holds the scaling down
+	// until the replicas are restored or the timeout is extended, "Force" drops the BE
+	// nodes from the Doris cluster regardless of the remaining tablets, which may lose
+	// the data of the tablets without other replicas.
+	// Default to Abort
+	// +kubebuilder:validation:Enum=Abort;Force
+	// +optional
+	DecommissionTimeoutPolicy DecommissionTimeoutPolicy `json:"decommissionTimeoutPolicy,omitempty"`
+
+	// Whether to decommission the BE from the Doris cluster in the preStop hook of BE container
+	// when the BE pod is removed by scaling down, the BE is stopped directly on the other
+	// terminations such as the rolling restarts and evictions. The decommission is retried
+	// until it succeeds, the terminationGracePeriodSeconds should be long enough for the
+	// tablets migration.
+	// Default to false
+	// +optional
+	PreStopDecommission bool `json:"preStopDecommission,omitempty"`
+
+	// StorageMountPath is the mount path of the default BE data storage for the custom image
+	// layout, the "storage_root_path" config of BE is set to it when it differs from the default,
+	// and the "storage_root_path" defined by user must match the mount paths of BE data storage.
+	// Default to /opt/apache-doris/be/storage
+	// +optional
+	StorageMountPath string `json:"storageMountPath,omitempty"`
+
+	// LogMountPath is the mount path of the BE log volume for the custom image layout,
+	// the "LOG_DIR" config of BE is set to it when it differs from the default.
+	// Default to /opt/apache-doris/be/log
+	// +optional
+	LogMountPath string `json:"logMountPath,omitempty"`
+
+	// HostNetwork makes the BE pods use the host network with the "ClusterFirstWithHostNet" DNS policy
+	// unless the dnsPolicy is specified, the BE ports are exposed as the host ports, so that at most one BE pod can be run on each node.
+	// Default to false
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+
+	// PVCReclaimPolicy determines whether the PVCs of the BE pods removed by scaling down are kept,
+	// when it is Delete, the PVCs are deleted once the BE nodes have been decommissioned and
+	// dropped from the Doris cluster and their pods have been terminated.
+	// Default to Retain
+	// +optional
+	PVCReclaimPolicy PVCReclaimPolicy `json:"pvcReclaimPolicy,omitempty"`
+
+	// CordonedOrdinals are the ordinals of the BE pods to be taken out of service for maintenance
+	// without changing the replicas, e.g. 2 for the pod "<cluster>-be-2". The backends of them are
+	// decommissioned and dropped from the Doris cluster, then the pods are restarted and held in
+	// the "cordon-gate" init container, so that they keep their PVCs and ordinals but do not run BE.
+	// Removing an ordinal from the list releases the pod and re-adds its backend to the Doris cluster.
+	// The ordinals must be less than the replicas. Note that the cordoned pods are unavailable, so the
+	// statefulset rolling update is held at the highest cordoned ordinal until it is uncordoned, and
+	// the BE PodDisruptionBudget may block the eviction of other BE pods.
+	// +optional
+	CordonedOrdinals []int32 `json:"cordonedOrdinals,omitempty"`
 }
 
+// PVCReclaimPolicy describes what happens to the PVCs of the pods removed by scaling down.
+// +kubebuilder:validation:Enum=Retain;Delete
+type PVCReclaimPolicy string
+
+const (
+	PVCReclaimPolicyRetain PVCReclaimPolicy = "Retain"
+	PVCReclaimPolicyDelete PVCReclaimPolicy = "Delete"
+)
+
 // BEStorage defines the custom storage of BE
 type BEStorage struct {
 	// Name of the storage
@@ -149,11 +573,57 @@ type BEStorage struct {
 	// Storage size requirements, e.g: "500Gi"
 	Request *resource.Quantity `json:"request"`
 
-	// K8s storage-class-name of the BE storage
+	// K8s storage-class-name of the BE storage, it only takes effect when the BE statefulset is created.
 	StorageClassName *string `json:"storageClassName"`
 }
 
+// StorageVolumeType is the type of the storage volume.
+// +kubebuilder:validation:Enum=Persistent;Ephemeral
+type StorageVolumeType string
+
+const (
+	// StorageVolumePersistent is backed by the PVC that is retained across the pod restarts.
+	StorageVolumePersistent StorageVolumeType = "Persistent"
+	// StorageVolumeEphemeral is backed by the emptyDir that is deleted with the pod,
+	// which fits the spill or cache directories.
+	StorageVolumeEphemeral StorageVolumeType = "Ephemeral"
+)
+
+// StorageVolume defines a volume that mounted into the component pod, a volumeClaimTemplate
+// would be generated for each persistent StorageVolume, and an emptyDir volume for each
+// ephemeral one.
+type StorageVolume struct {
+	// Name of the storage volume, it should be unique in the component, and must not be the
+	// name of the built-in volumes of component, e.g. "conf", "be-log".
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Mount path of the storage volume in the component container, which must not overlap with
+	// the other storage volumes, the built-in volume with the same mount path would be replaced,
+	// e.g. log directory.
+	// +kubebuilder:validation:Required
+	MountPath string `json:"mountPath"`
+
+	// Type of the storage volume, the optional values include "Persistent" and "Ephemeral",
+	// it can not be changed after the storage volume is created.
+	// Default to Persistent
+	// +optional
+	Type StorageVolumeType `json:"type,omitempty"`
+
+	// Storage size requirements, e.g: "100Gi", it is required by the persistent storage volume,
+	// and is the size limit of the ephemeral storage volume.
+	// +optional
+	Request *resource.Quantity `json:"request,omitempty"`
+
+	// K8s storage-class-name of the storage volume.
+	// Defaults to the storageClassName of component.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
 // CNSpec contains details of CN members.
+// When a DorisAutoscaler refers to the DorisCluster, the CN replicas are scaled by its HPAs
+// within the replicas range of DorisAutoscaler, and the replicas here are ignored.
 // +k8s:openapi-gen=true
 type CNSpec struct {
 	DorisComponentSpec `json:",inline"`
@@ -163,6 +633,155 @@ type CNSpec struct {
 // +k8s:openapi-gen=true
 type BrokerSpec struct {
 	DorisComponentSpec `json:",inline"`
+
+	// Service defines the ClusterIP service exposing the Broker ipc port, which is
+	// a stable endpoint to reach brokers for load operations.
+	// The service would not be created when it is omitted.
+	// +optional
+	Service *BrokerServiceSpec `json:"service,omitempty"`
+
+	// JvmHeap configures the JVM heap of Broker.
+	// +optional
+	JvmHeap *JvmHeapSpec `json:"jvmHeap,omitempty"`
+
+	// Kerberos configures the Kerberos authentication of Broker to access the Kerberized HDFS.
+	// +optional
+	Kerberos *BrokerKerberosSpec `json:"kerberos,omitempty"`
+}
+
+// BrokerKerberosSpec defines `.broker.kerberos` field of `DorisCluster.spec`.
+// The keys of the Secret are mounted as files under /etc/apache-doris/broker-kerberos/, the broker
+// load refers to the keytab by the literal path in the "kerberos_keytab" property, e.g.
+// "kerberos_keytab" = "/etc/apache-doris/broker-kerberos/keytab", along with the principal in the
+// "kerberos_principal" property.
+type BrokerKerberosSpec struct {
+	// Name of the Secret in the namespace of the DorisCluster resources that holds the keytab
+	// and optionally the krb5.conf, the changes of the Secret trigger a rolling restart of Broker.
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+	// Principal of the keytab, e.g. doris@EXAMPLE.COM.
+	// Deprecated: it is not consumed by Broker, the principal should be set in the
+	// "kerberos_principal" property of the broker load.
+	// +optional
+	Principal string `json:"principal,omitempty"`
+	// KeytabKey is the key of the Secret that holds the keytab.
+	// Default to "keytab"
+	// +optional
+	KeytabKey string `json:"keytabKey,omitempty"`
+	// Krb5ConfKey is the key of the Secret that holds the krb5.conf, which is passed to the JVM of
+	// Broker via -Djava.security.krb5.conf. The krb5.conf of spec.hadoopConf.credentialSecretRef
+	// is used when it is omitted.
+	// +optional
+	Krb5ConfKey string `json:"krb5ConfKey,omitempty"`
+}
+
+// FeTLSSpec defines `.fe.tls` field of `DorisCluster.spec`.
+type FeTLSSpec struct {
+	// Name of the secret in the same namespace that contains the PKCS#12 keystores of
+	// the CA certificate and the server certificate in "ca.p12" and "server.p12" keys.
+	// The passwords of the keystores can be provided in "ca.password" and "server.password"
+	// keys, which default to "doris".
+	// Changing the content of the secret triggers a rolling restart of FE.
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+
+	// Whether to require the client certificate when the client connects with SSL.
+	// Default to false
+	// +optional
+	ForceClientAuth bool `json:"forceClientAuth,omitempty"`
+}
+
+// BrokerServiceSpec defines `.broker.service` field of `DorisCluster.spec`.
+// +k8s:openapi-gen=true
+type BrokerServiceSpec struct {
+	// Annotations of the service.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// JvmHeapSpec configures the JVM heap of the Java based components (FE, Broker).
+type JvmHeapSpec struct {
+	// Whether to disable the auto-tuning of JVM heap, the JAVA_OPTS in configs would be
+	// retained as is when it is disabled, which is useful to set the heap manually.
+	// Default to false
+	// +optional
+	DisableAutoTuning bool `json:"disableAutoTuning,omitempty"`
+
+	// Percentage of the container memory limit, or the memory request when the limit is
+	// not set, to be used as the JVM max heap (-Xmx). When neither of them is set, it is
+	// applied as the -XX:MaxRAMPercentage of the JVM.
+	// Default to 75
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percentage *int32 `json:"percentage,omitempty"`
+}
+
+// MonitoringSpec defines the Prometheus scraping annotations of Doris pods.
+// +k8s:openapi-gen=true
+type MonitoringSpec struct {
+	// Whether to annotate the pods with "prometheus.io/scrape", "prometheus.io/path" and
+	// "prometheus.io/port", it is recommended to disable them when the ServiceMonitors are
+	// enabled or the scraping is managed centrally, to avoid scraping the same pods twice.
+	// Default to true
+	// +optional
+	PrometheusAnnotations *bool `json:"prometheusAnnotations,omitempty"`
+
+	// MetricsPath is the path of the metrics endpoint of Doris components,
+	// which also applies to the ServiceMonitors.
+	// Default to /metrics
+	// +optional
+	MetricsPath string `json:"metricsPath,omitempty"`
+
+	// MetricsPorts overrides the metrics ports of Doris components in the annotations.
+	// +optional
+	MetricsPorts *MetricsPortsSpec `json:"metricsPorts,omitempty"`
+}
+
+// MetricsPortsSpec defines the metrics ports of Doris components.
+type MetricsPortsSpec struct {
+	// Default to the http port of FE
+	// +optional
+	FE *int32 `json:"fe,omitempty"`
+
+	// Default to the webserver port of BE
+	// +optional
+	BE *int32 `json:"be,omitempty"`
+
+	// Default to the webserver port of CN
+	// +optional
+	CN *int32 `json:"cn,omitempty"`
+
+	// The Broker does not expose the metrics endpoint, and its pods are annotated
+	// only when the port is specified.
+	// +optional
+	Broker *int32 `json:"broker,omitempty"`
+}
+
+// OprSqlAccountSpec defines the Doris SQL account generated by operator.
+// +k8s:openapi-gen=true
+type OprSqlAccountSpec struct {
+	// Username of the account, changing it creates the account of the new username in Doris
+	// via the current account and switches the secret to it, while the previous account is
+	// retained since the running pods still use it until they are restarted.
+	// Default to k8sopr
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`
+	// +optional
+	Username string `json:"username,omitempty"`
+}
+
+// InitSQLSpec defines the SQL script executed on the first boot of Doris cluster,
+// either inline or referenced from a ConfigMap.
+// +k8s:openapi-gen=true
+type InitSQLSpec struct {
+	// Script is the inline SQL script, the statements are separated by semicolons.
+	// +optional
+	Script string `json:"script,omitempty"`
+
+	// ConfigMapRef references the key of a ConfigMap that contains the SQL script,
+	// the ConfigMap lives along with the sub resources of DorisCluster.
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
 }
 
 // HadoopConfSpec contains the configuration needed for doris to connect to the Hadoop cluster.
@@ -172,6 +791,29 @@ type HadoopConfSpec struct {
 	Hosts []HostnameIpItem `json:"hostAliases"`
 	// Hadoop configuration files.
 	Config map[string]string `json:"config,omitempty"`
+	// CredentialSecretRef refers to the Secret of the credentials for accessing HDFS/S3, which
+	// is injected into the FE, BE, CN and Broker containers, the changes of the Secret trigger
+	// a rolling restart of them.
+	// +optional
+	CredentialSecretRef *HadoopCredentialSecretRef `json:"credentialSecretRef,omitempty"`
+}
+
+// HadoopCredentialSecretRef refers to a Secret in the namespace of the DorisCluster resources,
+// all the keys of the Secret are mounted as files under /etc/apache-doris/hadoop-credentials/,
+// e.g. the Kerberos keytab files referenced by the catalog properties.
+// +k8s:openapi-gen=true
+type HadoopCredentialSecretRef struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// EnvKeys are the keys of the Secret injected as the environment variables of the same
+	// names, e.g. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// +optional
+	EnvKeys []string `json:"envKeys,omitempty"`
+	// Krb5ConfKey is the key of the Secret that holds the krb5.conf of Kerberos,
+	// which is mounted to /etc/krb5.conf.
+	// +optional
+	Krb5ConfKey string `json:"krb5ConfKey,omitempty"`
 }
 
 // HostnameIpItem define Hostname-IP kv item
@@ -183,13 +825,42 @@ type HostnameIpItem struct {
 	Name string `json:"name"`
 }
 
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget of the component,
+// only one of MinAvailable and MaxUnavailable can be set.
+// +k8s:openapi-gen=true
+type PodDisruptionBudgetSpec struct {
+	// The minimum number or percentage of pods that must be available after the eviction.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// The maximum number or percentage of pods that can be unavailable after the eviction.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // FeServiceSpec defines `.fe.service` field of `DorisCluster.spec`.
 // +k8s:openapi-gen=true
 type FeServiceSpec struct {
 	// Type of the real kubernetes service
-	// Only ClusterIP and NodePort support is available.
+	// Only ClusterIP, NodePort and LoadBalancer support is available.
 	Type corev1.ServiceType `json:"type,omitempty"`
 
+	// Annotations of the service, e.g. the provider-specific annotations of
+	// the cloud load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerClass of the service, only takes effect when the service type is LoadBalancer.
+	// Optional: Defaults to omitted
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs that can access the load balancer,
+	// only takes effect when the service type is LoadBalancer.
+	// Optional: Defaults to omitted
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
 	// Expose the FE query port
 	// Optional: Defaults to 0
 	// +optional
@@ -200,18 +871,85 @@ type FeServiceSpec struct {
 	// +optional
 	HttpPort *int32 `json:"httpPort,omitempty"`
 
+	// Expose the FE edit log port, only takes effect when the service type is NodePort.
+	// Optional: Defaults to omitted
+	// +optional
+	EditLogPort *int32 `json:"editLogPort,omitempty"`
+
+	// Expose the FE rpc port, only takes effect when the service type is NodePort.
+	// Optional: Defaults to omitted
+	// +optional
+	RpcPort *int32 `json:"rpcPort,omitempty"`
+
+	// Publish the FE edit log port and rpc port on the service for any service type, e.g. for the
+	// client tooling or the cross-cluster replication, they are published on the NodePort service
+	// only when the editLogPort or rpcPort is specified otherwise.
+	// Default to false
+	// +optional
+	ExposeInternalPorts bool `json:"exposeInternalPorts,omitempty"`
+
 	// ExternalTrafficPolicy of the service
 	// Optional: Defaults to omitted
 	// +optional
 	ExternalTrafficPolicy *corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
 }
 
+// FePodServiceSpec defines `.fe.podService` field of `DorisCluster.spec`.
+// +k8s:openapi-gen=true
+type FePodServiceSpec struct {
+	// Type of the services of FE pods, the NodePort is assigned randomly for NodePort services.
+	// Only ClusterIP, NodePort and LoadBalancer support is available.
+	// Default to ClusterIP
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations of the services, e.g. the provider-specific annotations of the cloud load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerClass of the services, only takes effect when the service type is LoadBalancer.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs that can access the load balancers,
+	// only takes effect when the service type is LoadBalancer.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy of the services.
+	// +optional
+	ExternalTrafficPolicy *corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+}
+
+// FeIngressSpec defines `.fe.ingress` field of `DorisCluster.spec`.
+// +k8s:openapi-gen=true
+type FeIngressSpec struct {
+	// Host of the ingress rule, the rule applies to all inbound HTTP traffic when it is empty.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// IngressClassName of the ingress, defaults to the default IngressClass of the cluster.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// TLSSecretName is the name of the secret that contains the TLS certificate of the host,
+	// the TLS termination is enabled when it is specified.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations of the ingress, e.g. the controller-specific annotations.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 // DorisComponentSpec is the base component spec.
 // +k8s:openapi-gen=true
 type DorisComponentSpec struct {
-	//Base image of the component
-	// +kubebuilder:validation:Required
-	BaseImage string `json:"baseImage"`
+	// Base image of the component.
+	// Default to the operator-wide default image of the component
+	// +optional
+	BaseImage string `json:"baseImage,omitempty"`
 
 	// Type of the real kubernetes service
 	// +optional
@@ -222,6 +960,8 @@ type DorisComponentSpec struct {
 	Replicas int32 `json:"replicas"`
 
 	// Defines the specification of resource cpu, mem, storage.
+	// The cpu, memory and ephemeral-storage are applied to the container and their limits
+	// must not be less than the requests, the storage is only used as the request of the PVC.
 	corev1.ResourceRequirements `json:",inline"`
 
 	// Additional Doris component configuration
@@ -231,6 +971,21 @@ type DorisComponentSpec struct {
 	// +optional
 	Configs map[string]string `json:"config,omitempty"`
 
+	// Raw content of the component config file (fe.conf, be.conf or apache_hdfs_broker.conf),
+	// which is used verbatim as the head of the generated config file when set.
+	// The configs from ConfigMapRef and Configs are appended after it and take precedence
+	// on key conflict.
+	// +optional
+	ConfigFileContent string `json:"configFileContent,omitempty"`
+
+	// Reference to an existing ConfigMap in the same namespace that contains the
+	// config file of the component (fe.conf, be.conf or apache_hdfs_broker.conf).
+	// The configs in the referenced file are merged with Configs, and Configs
+	// takes precedence on key conflict.
+	// The ports of the component are still resolved from Configs and ConfigFileContent.
+	// +optional
+	ConfigMapRef *corev1.LocalObjectReference `json:"configMapRef,omitempty"`
+
 	// HostAliases is an optional list of hosts and IPs that will be injected into the pod's hosts
 	// file if specified.
 	// +optional
@@ -240,7 +995,8 @@ type DorisComponentSpec struct {
 	// +optional
 	ServiceAccount string `json:"serviceAccount,omitempty"`
 
-	// NodeSelector of the component.
+	// NodeSelector of the component pods, it is merged with the cluster NodeSelector
+	// and takes precedence on the same key.
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
@@ -248,6 +1004,44 @@ type DorisComponentSpec struct {
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 
+	// Additional labels of the component pods, the labels managed by operator take precedence on conflict.
+	// +optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// Additional annotations of the component pods.
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// Additional labels of the component Services, the labels managed by operator take precedence on conflict.
+	// +optional
+	ServiceLabels map[string]string `json:"serviceLabels,omitempty"`
+
+	// Additional annotations of the component Services, the annotations of the dedicated
+	// service spec (e.g. spec.fe.service.annotations) take precedence on conflict.
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// Additional labels of the component ConfigMap, the labels managed by operator take precedence on conflict.
+	// +optional
+	ConfigMapLabels map[string]string `json:"configMapLabels,omitempty"`
+
+	// Additional annotations of the component ConfigMap.
+	// +optional
+	ConfigMapAnnotations map[string]string `json:"configMapAnnotations,omitempty"`
+
+	// Additional named ports of the component container, which are also published on
+	// the component service, e.g. the ports of the sidecar processes in the container.
+	// The names and numbers must not conflict with the ports managed by the operator.
+	// +optional
+	ExtraPorts []corev1.ContainerPort `json:"extraPorts,omitempty"`
+
+	// Additional annotations of the volumeClaimTemplates of the component, e.g. the
+	// annotations required by CSI drivers. They are immutable after creation since the
+	// volumeClaimTemplates of statefulset can not be updated, and changes on them would
+	// not be applied to the existing PVCs anyway.
+	// +optional
+	StorageAnnotations map[string]string `json:"storageAnnotations,omitempty"`
+
 	// Affinity for pod scheduling of Doris cluster.
 	// +optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
@@ -256,22 +1050,96 @@ type DorisComponentSpec struct {
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// TopologySpreadConstraints describes how pods ought to spread across topology domains.
+	// Defaults to spread pods of the component across nodes when replicas > 1.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// Specify pod priorities of pods in Doris cluster, default to empty.
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
 
+	// PreemptionPolicy of the component pods, which takes precedence over the cluster one.
+	// +optional
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// DNSConfig of the component pods, which takes precedence over the cluster one.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// DNSPolicy of the component pods, which takes precedence over the cluster one.
+	// +optional
+	DNSPolicy *corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// RuntimeClassName of the component pods, which takes precedence over the cluster one.
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
 	// Update strategy of Doris cluster StatefulSet.
+	// With the OnDelete strategy, the operator recreates the outdated FE and BE pods one
+	// by one, the next pod is deleted only after the replacement is ready and all the nodes
+	// are alive in the Doris cluster, and for BE, all the tablets are healthy.
 	// +optional
 	StatefulSetUpdateStrategy *appv1.StatefulSetUpdateStrategyType `json:"statefulSetUpdateStrategy,omitempty"`
 
+	// PodSecurityContext of the component pods, which takes precedence over the cluster one.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// FSGroup of the component pods, which takes precedence over the cluster one.
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// FSGroupChangePolicy of the component pods, which takes precedence over the cluster one.
+	// +kubebuilder:validation:Enum=OnRootMismatch;Always
+	// +optional
+	FSGroupChangePolicy *corev1.PodFSGroupChangePolicy `json:"fsGroupChangePolicy,omitempty"`
+
+	// ContainerSecurityContext of the component container, which takes precedence over the cluster one.
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// Partition of the RollingUpdate strategy of the component StatefulSet, which
+	// allows a canary rollout by only updating the pods with an ordinal greater than
+	// or equal to the partition. It takes no effect when the update strategy is OnDelete.
+	// Note that a change of configs also rolls out through the config-hash annotation
+	// of the pod template, so it is held back by the partition as well.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
+
+	// Command overrides the entrypoint of the main container, the image default
+	// entrypoint is used when it is not specified.
+	// Note that overriding the command bypasses the startup behavior injected by the
+	// operator, e.g. the registration of the node into the Doris cluster.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args overrides the arguments of the main container entrypoint.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
 	// Additional environment variables to set in the container
 	// +optional
 	AdditionalEnvs []corev1.EnvVar `json:"additionalEnv,omitempty"`
 
+	// Additional sources to populate environment variables in the container, e.g. all the
+	// keys of a ConfigMap or Secret. The variables in AdditionalEnvs and the ones injected
+	// by the operator take precedence on the same name.
+	// +optional
+	AdditionalEnvFrom []corev1.EnvFromSource `json:"additionalEnvFrom,omitempty"`
+
 	// Additional containers of the component.
 	// +optional
 	AdditionalContainers []corev1.Container `json:"additionalContainers,omitempty"`
 
+	// Whether to mount the log volume of the component into the AdditionalContainers
+	// as read-only at the same path of the main container, e.g. /opt/apache-doris/fe/log,
+	// so that a log shipping sidecar like filebeat or fluent-bit can read the logs.
+	// Default to false
+	// +optional
+	ShareLogVolume bool `json:"shareLogVolume,omitempty"`
+
 	// Additional volumes of component pod.
 	// +optional
 	AdditionalVolumes []corev1.Volume `json:"additionalVolumes,omitempty"`
@@ -279,8 +1147,60 @@ type DorisComponentSpec struct {
 	// Additional volume mounts of component pod.
 	// +optional
 	AdditionalVolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
+
+	// Duration in seconds the pod needs to terminate gracefully.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// Minimum number of seconds for which a newly created pod should be ready without any of
+	// its container crashing to be considered available, which delays the rolling update of
+	// the next pod. Default to 15 for BE, and 0 for the other components.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// Additional storage volumes of the component pod, e.g. the log directory, or the
+	// ephemeral spill and cache directories of BE.
+	// +optional
+	StorageVolumes []StorageVolume `json:"storageVolumes,omitempty"`
+
+	// LivenessProbe overrides the default liveness probe of the component main container.
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// StartupProbe overrides the default startup probe of the component main container,
+	// the liveness and readiness probes are suspended until the startup probe succeeds.
+	// Default to a TCP check that allows up to 10 minutes for the startup
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+
+	// ReadinessProbeType is the check of the default readiness probe of the component main container.
+	// "TCP" checks that the port is listening, which is the edit log port of FE, the heartbeat port
+	// of BE and CN, and the IPC port of Broker.
+	// "HTTP" requests the "/api/health" of the http port of FE or the webserver port of BE and CN,
+	// which is only served by FE once its metadata is ready, it requires Doris 1.2 or later.
+	// "Exec" queries "SHOW FRONTENDS" via the local FE query port with the mysql client of the image,
+	// which confirms that FE is a functioning member of the cluster with an elected master, it is
+	// only supported by FE.
+	// With HTTP and Exec, the FE peer service publishes the addresses of the unready FE pods,
+	// so that the joining FE can be resolved by the others before it is ready.
+	// Only TCP is supported by Broker.
+	// Default to TCP
+	// +kubebuilder:validation:Enum=TCP;HTTP;Exec
+	// +optional
+	ReadinessProbeType ProbeType `json:"readinessProbeType,omitempty"`
 }
 
+// ProbeType describes how a probe checks the container.
+type ProbeType string
+
+const (
+	ProbeTCP  ProbeType = "TCP"
+	ProbeHTTP ProbeType = "HTTP"
+	ProbeExec ProbeType = "Exec"
+)
+
 // ########################################
 //   		DorisClusterStatus
 // ########################################
@@ -288,11 +1208,221 @@ type DorisComponentSpec struct {
 // DorisClusterStatus defines the observed state of DorisCluster
 // +k8s:openapi-gen=true
 type DorisClusterStatus struct {
-	LastApplySpecHash      *string `json:"lastApplySpecHash,omitempty"`
-	DorisClusterRecStatus  `json:",inline"`
+	LastApplySpecHash     *string `json:"lastApplySpecHash,omitempty"`
+	DorisClusterRecStatus `json:",inline"`
+
+	// The value of the password rotation annotation of the operator SQL account
+	// that has been applied.
+	// +optional
+	LastOprAccountRotation string `json:"lastOprAccountRotation,omitempty"`
+
+	// The namespace where the sub resources of DorisCluster have been placed.
+	// +optional
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+
+	// The value of the restart request annotation that has been rolled out.
+	// +optional
+	LastRestartRequest string `json:"lastRestartRequest,omitempty"`
+
+	// Fingerprint of the ConfigMaps referenced by the configMapRef of components
+	// that has been rolled out.
+	// +optional
+	RefConfigMapFingerprint string `json:"refConfigMapFingerprint,omitempty"`
+
+	// Fingerprint of the FE TLS secret that has been applied.
+	// +optional
+	FeTlsFingerprint string `json:"feTlsFingerprint,omitempty"`
+
+	// Fingerprint of the Hadoop credential secret that has been applied.
+	// +optional
+	HadoopCredentialFingerprint string `json:"hadoopCredentialFingerprint,omitempty"`
+
+	// Fingerprint of the Kerberos secret of Broker that has been applied.
+	// +optional
+	BrokerKerberosFingerprint string `json:"brokerKerberosFingerprint,omitempty"`
+
+	// InitSQLApplied indicates that the spec.initSQL has been executed successfully,
+	// which would not be executed again.
+	// +optional
+	InitSQLApplied bool `json:"initSQLApplied,omitempty"`
+
+	// Restore is the progress of restoring the Doris cluster from the spec.restoreFrom,
+	// which is only tracked when the DorisCluster is created with it.
+	// +optional
+	Restore *RestoreStatus `json:"restore,omitempty"`
+
+	// Backup is the status of the periodic backups of spec.backup.
+	// +optional
+	Backup *BackupStatus `json:"backup,omitempty"`
+
 	DorisClusterSyncStatus `json:",inline"`
+
+	// Phase is the high-level summary of the DorisCluster state.
+	// +optional
+	Phase DorisClusterPhase `json:"phase,omitempty"`
+
+	// Conditions of the DorisCluster.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Plan of the changes that the operator would apply to the sub resources, it is only
+	// computed when the DorisCluster is annotated with dry-run.
+	// +optional
+	Plan *DorisClusterPlan `json:"plan,omitempty"`
+}
+
+// RestorePhase is the phase of restoring the Doris cluster from the backup repository.
+type RestorePhase string
+
+const (
+	RestorePending   RestorePhase = "Pending"
+	RestoreRunning   RestorePhase = "Running"
+	RestoreCompleted RestorePhase = "Completed"
+	RestoreFailed    RestorePhase = "Failed"
+)
+
+// RestoreStatus is the progress of restoring the Doris cluster from the backup repository.
+type RestoreStatus struct {
+	Phase RestorePhase `json:"phase"`
+
+	// Snapshots are the restore jobs of the snapshots in order.
+	// +optional
+	Snapshots []RestoreSnapshotStatus `json:"snapshots,omitempty"`
+
+	// Message describes the failure of restoring.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreSnapshotStatus is the restore job of the snapshot.
+type RestoreSnapshotStatus struct {
+	Database string `json:"database"`
+	Snapshot string `json:"snapshot"`
+	// JobId of the restore job in Doris.
+	// +optional
+	JobId string `json:"jobId,omitempty"`
+	// State of the restore job like "DOWNLOADING" and "FINISHED".
+	// +optional
+	State string `json:"state,omitempty"`
+}
+
+// BackupStatus is the status of the periodic backups.
+type BackupStatus struct {
+	// LastScheduleTime is the time when the latest backup was scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessTime is the time when the latest successful backup was completed.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// RunningSnapshot is the snapshot name of the backup in progress.
+	// +optional
+	RunningSnapshot string `json:"runningSnapshot,omitempty"`
+
+	// History of the successful backups within the retention, the newest first.
+	// +optional
+	History []BackupRecord `json:"history,omitempty"`
+}
+
+// BackupRecord is a successful backup.
+type BackupRecord struct {
+	Snapshot       string      `json:"snapshot"`
+	Databases      []string    `json:"databases"`
+	CompletionTime metav1.Time `json:"completionTime"`
+}
+
+// DorisClusterPlan is the plan of changes to the sub resources of DorisCluster
+// computed in dry-run mode without mutating the cluster.
+type DorisClusterPlan struct {
+	// Hash of the spec that the plan is computed from.
+	SpecHash string `json:"specHash,omitempty"`
+
+	// Stages of the plan in reconciling order.
+	// +optional
+	Stages []PlanStage `json:"stages,omitempty"`
 }
 
+// PlanStage is the planned changes of a reconciling stage.
+type PlanStage struct {
+	Stage  DorisClusterOprStage `json:"stage"`
+	Status OprStageStatus       `json:"status,omitempty"`
+	// Message of the stage, e.g. the reason why the stage is skipped or failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	Actions []PlanAction `json:"actions,omitempty"`
+}
+
+// PlanAction is the planned change of a kubernetes object.
+type PlanAction struct {
+	Action PlanActionType `json:"action"`
+	Kind   string         `json:"kind"`
+	Name   string         `json:"name"`
+	// Diff of the fields between the existing object and the desired one in
+	// "path: existing -> desired" format, the values of Secret are redacted.
+	// +optional
+	Diff []string `json:"diff,omitempty"`
+}
+
+// PlanActionType is the type of the planned change.
+type PlanActionType string
+
+const (
+	PlanActionCreate  PlanActionType = "create"
+	PlanActionUpdate  PlanActionType = "update"
+	PlanActionDelete  PlanActionType = "delete"
+	PlanActionReplace PlanActionType = "replace"
+)
+
+// DorisClusterPhase represents the high-level state of DorisCluster
+// +kubebuilder:validation:Enum=Creating;Updating;Scaling;Ready;Failed
+type DorisClusterPhase string
+
+const (
+	DorisClusterPhaseCreating DorisClusterPhase = "Creating"
+	DorisClusterPhaseUpdating DorisClusterPhase = "Updating"
+	DorisClusterPhaseScaling  DorisClusterPhase = "Scaling"
+	DorisClusterPhaseReady    DorisClusterPhase = "Ready"
+	DorisClusterPhaseFailed   DorisClusterPhase = "Failed"
+)
+
+// DorisCluster condition types
+const (
+	// DorisClusterPaused represents the reconciliation of DorisCluster is paused.
+	DorisClusterPaused = "Paused"
+	// DorisClusterReconciled represents the last reconciliation of DorisCluster has been completed.
+	DorisClusterReconciled = "Reconciled"
+	// DorisClusterReady represents the statefulsets of all enabled components have their desired replicas available.
+	DorisClusterReady = "Ready"
+	// FEReady represents the FE statefulset has its desired replicas available.
+	FEReady = "FEReady"
+	// BEReady represents the BE statefulset has its desired replicas available.
+	BEReady = "BEReady"
+	// CNReady represents the CN statefulset has its desired replicas available.
+	CNReady = "CNReady"
+	// BrokerReady represents the Broker statefulset has its desired replicas available.
+	BrokerReady = "BrokerReady"
+	// FEMetaStorageResizing represents the PVCs of FE metadata storage are being resized,
+	// it is false with reason ResizeUnsupported when their StorageClass does not allow volume expansion,
+	// or with reason ShrinkRejected when the storage request is decreased.
+	FEMetaStorageResizing = "FEMetaStorageResizing"
+	// InitSQLApplied represents the spec.initSQL has been executed against FE.
+	InitSQLApplied = "InitSQLApplied"
+	// Restored represents the Doris cluster has been restored from the spec.restoreFrom.
+	Restored = "Restored"
+	// BackupSucceeded represents whether the latest backup of spec.backup has succeeded.
+	BackupSucceeded = "BackupSucceeded"
+	// BEDecommissionTimedOut represents the decommission of BE nodes removed by scaling down has
+	// exceeded spec.be.decommissionTimeout, the reason is the applied DecommissionTimeoutPolicy.
+	BEDecommissionTimedOut = "BEDecommissionTimedOut"
+	// RollbackPerformed represents the changed FE config has been rolled back to the last-known-good
+	// config since the FE pods were not ready within the timeout, it is false once a config is ready.
+	RollbackPerformed = "RollbackPerformed"
+)
+
 type DorisClusterRecStatus struct {
 	Stage       DorisClusterOprStage `json:"stage,omitempty"`
 	StageAction OprStageAction       `json:"stageAction,omitempty"`
@@ -308,21 +1438,60 @@ type DorisClusterSyncStatus struct {
 
 	// AllReady represents all components(FE, BE, CN, Broker) of DorisCluster are ready.
 	AllReady bool `json:"allReady"`
+
+	// GeneratedResources lists the kubernetes objects generated by the operator for
+	// each component, the objects that no longer exist are pruned.
+	// +optional
+	GeneratedResources GeneratedResources `json:"generatedResources,omitempty"`
+}
+
+// GeneratedResources represents the kubernetes objects generated by the operator
+// for each component of DorisCluster.
+type GeneratedResources struct {
+	FE     []GeneratedResource `json:"fe,omitempty"`
+	BE     []GeneratedResource `json:"be,omitempty"`
+	CN     []GeneratedResource `json:"cn,omitempty"`
+	Broker []GeneratedResource `json:"broker,omitempty"`
+}
+
+// GeneratedResource is the reference to a kubernetes object generated by the operator.
+type GeneratedResource struct {
+	Kind           string `json:"kind"`
+	NamespacedName `json:",inline"`
 }
 
 // DorisClusterOprStage represents DorisCluster operator stage
 type DorisClusterOprStage string
 
 const (
+	StageSpecValidation    DorisClusterOprStage = "SpecValidation"
+	StageResourceNamespace DorisClusterOprStage = "ResourceNamespace"
 	StageSqlAccountSecret  DorisClusterOprStage = "operator-sql-account/Secret"
+	StageSqlAccountRotate  DorisClusterOprStage = "operator-sql-account/Rotation"
+	StageSqlAccountRename  DorisClusterOprStage = "operator-sql-account/Rename"
+	StagePriorityClass     DorisClusterOprStage = "PriorityClass"
 	StageFe                DorisClusterOprStage = "fe"
 	StageFeConfigmap       DorisClusterOprStage = "fe/Configmap"
 	StageFeService         DorisClusterOprStage = "fe/Service"
 	StageFeStatefulSet     DorisClusterOprStage = "fe/Statefulset"
+	StageFePvcResize       DorisClusterOprStage = "fe/PvcResize"
+	StageFeRollout         DorisClusterOprStage = "fe/Rollout"
+	StageFeRecreate        DorisClusterOprStage = "fe/Recreate"
+	StageFePdb             DorisClusterOprStage = "fe/PodDisruptionBudget"
+	StageFeIngress         DorisClusterOprStage = "fe/Ingress"
+	StageFePodService      DorisClusterOprStage = "fe/PodService"
+	StageFeConfigRollback  DorisClusterOprStage = "fe/ConfigRollback"
 	StageBe                DorisClusterOprStage = "be"
 	StageBeConfigmap       DorisClusterOprStage = "be/Configmap"
 	StageBeService         DorisClusterOprStage = "be/Service"
 	StageBeStatefulSet     DorisClusterOprStage = "be/Statefulset"
+	StageBeDecommission    DorisClusterOprStage = "be/Decommission"
+	StageBeRollout         DorisClusterOprStage = "be/Rollout"
+	StageBeRecreate        DorisClusterOprStage = "be/Recreate"
+	StageBeBalanceRestore  DorisClusterOprStage = "be/BalanceRestore"
+	StageBePvcReclaim      DorisClusterOprStage = "be/PvcReclaim"
+	StageBePdb             DorisClusterOprStage = "be/PodDisruptionBudget"
+	StageBeCordon          DorisClusterOprStage = "be/Cordon"
 	StageCn                DorisClusterOprStage = "cn"
 	StageCnConfigmap       DorisClusterOprStage = "cn/ConfigMap"
 	StageCnService         DorisClusterOprStage = "cn/Service"
@@ -331,6 +1500,10 @@ const (
 	StageBrokerConfigmap   DorisClusterOprStage = "broker/ConfigMap"
 	StageBrokerService     DorisClusterOprStage = "broker/Service"
 	StageBrokerStatefulSet DorisClusterOprStage = "broker/Statefulset"
+	StageServiceMonitor    DorisClusterOprStage = "ServiceMonitor"
+	StageGarbageCollect    DorisClusterOprStage = "GarbageCollect"
+	StageRestore           DorisClusterOprStage = "Restore"
+	StageInitSQL           DorisClusterOprStage = "InitSQL"
 
 	StageComplete DorisClusterOprStage = "complete"
 )
@@ -339,16 +1512,181 @@ const (
 type FEStatus struct {
 	ServiceRef           NamespacedName `json:"serviceName,omitempty"`
 	DorisComponentStatus `json:",inline"`
+
+	// AliveMembers is the number of alive FE nodes that have joined the Doris cluster.
+	AliveMembers int32 `json:"aliveMembers,omitempty"`
+
+	// Followers are the FE pods that have joined the Doris cluster as followers.
+	Followers []string `json:"followers,omitempty"`
+
+	// Observers are the FE pods that have joined the Doris cluster as observers.
+	Observers []string `json:"observers,omitempty"`
+
+	// Rollout is the progress of the leader-aware rolling restart of FE pods.
+	// +optional
+	Rollout *FERolloutStatus `json:"rollout,omitempty"`
+
+	// Recreate is the progress of the operator-driven recreation of FE pods with the
+	// OnDelete update strategy.
+	// +optional
+	Recreate *RecreateRolloutStatus `json:"recreate,omitempty"`
+
+	// MetadataRecovery is the progress and records of the automatic FE metadata recovery.
+	// +optional
+	MetadataRecovery *FEMetadataRecoveryStatus `json:"metadataRecovery,omitempty"`
+
+	// AppliedHotConfigs are the hot-reloadable FE configs that have been applied to the
+	// running FE nodes at runtime without restarting them.
+	// +optional
+	AppliedHotConfigs map[string]string `json:"appliedHotConfigs,omitempty"`
+
+	// ConfigHistory is the recent FE configs tracked by the automatic config rollback,
+	// the newest first, only the latest ones are kept.
+	// +optional
+	ConfigHistory []FEConfigRevision `json:"configHistory,omitempty"`
+}
+
+// FEConfigRevision is a FE config applied to the FE statefulset.
+type FEConfigRevision struct {
+	// Hash of the config annotated on the pod template of FE statefulset.
+	Hash string `json:"hash"`
+	// AppliedTime is when the config was applied.
+	AppliedTime metav1.Time `json:"appliedTime"`
+	// Ready indicates that all the FE pods have been ready with the config.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+	// RolledBack indicates that the config has been rolled back to the last-known-good one.
+	// +optional
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// FEMetadataRecoveryStatus represents the automatic recovery of the FE pods that are
+// crash-looping due to the metadata failure.
+type FEMetadataRecoveryStatus struct {
+	// RecoveringMember is the FE pod that is started in the metadata failure recovery mode.
+	RecoveringMember string `json:"recoveringMember,omitempty"`
+	// Records of the recovery actions, only the latest ones are kept.
+	Records []FEMetadataRecoveryRecord `json:"records,omitempty"`
+}
+
+// FEMetadataRecoveryRecord is a recovery action taken on a FE pod.
+type FEMetadataRecoveryRecord struct {
+	Member string                   `json:"member"`
+	Action FEMetadataRecoveryAction `json:"action"`
+	// RestartCount of the FE container when the action is taken.
+	RestartCount int32       `json:"restartCount"`
+	Time         metav1.Time `json:"time"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FEMetadataRecoveryAction represents the action of the FE metadata recovery
+type FEMetadataRecoveryAction string
+
+const (
+	FEMetadataRecoveryStarted  FEMetadataRecoveryAction = "Started"
+	FEMetadataRecoveryReverted FEMetadataRecoveryAction = "Reverted"
+)
+
+// FERolloutStatus represents the progress of the leader-aware rolling restart of FE pods.
+type FERolloutStatus struct {
+	// UpdateRevision is the statefulset revision that the FE pods are rolled to.
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// UpdatedMembers are the FE pods that are running with the update revision.
+	UpdatedMembers []string `json:"updatedMembers,omitempty"`
+	// PendingMembers are the FE pods waiting to be restarted in order, the FE master is the last one.
+	PendingMembers []string `json:"pendingMembers,omitempty"`
+	// RestartingMember is the FE pod that is being restarted.
+	RestartingMember string `json:"restartingMember,omitempty"`
+}
+
+// RecreateRolloutStatus represents the progress of the operator-driven recreation of the
+// component pods with the OnDelete update strategy.
+type RecreateRolloutStatus struct {
+	// UpdateRevision is the statefulset revision that the pods are recreated with.
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// UpdatedMembers are the pods that are running with the update revision.
+	UpdatedMembers []string `json:"updatedMembers,omitempty"`
+	// PendingMembers are the pods waiting to be recreated in order.
+	PendingMembers []string `json:"pendingMembers,omitempty"`
+	// RecreatingMember is the pod that is being recreated.
+	RecreatingMember string `json:"recreatingMember,omitempty"`
 }
 
 // BEStatus represents the current state of Doris BE
 type BEStatus struct {
 	DorisComponentStatus `json:",inline"`
+
+	// DecommissioningMembers are the BE pods that are being decommissioned before scaling down.
+	DecommissioningMembers []string `json:"decommissioningMembers,omitempty"`
+
+	// Decommissioning is the progress of the BE nodes that are being decommissioned,
+	// the entries are cleared once the decommission has completed and the pods are removed.
+	// +optional
+	Decommissioning []BEDecommissionStatus `json:"decommissioning,omitempty"`
+
+	// Cordoning is the decommission progress of the BE nodes cordoned by spec.be.cordonedOrdinals.
+	// +optional
+	Cordoning []BEDecommissionStatus `json:"cordoning,omitempty"`
+
+	// CordonedMembers are the BE pods whose backends have been dropped from the Doris cluster
+	// and are held out of service by spec.be.cordonedOrdinals.
+	// +optional
+	CordonedMembers []string `json:"cordonedMembers,omitempty"`
+
+	// OriginalDisableBalance records the original value of the FE config "disable_balance"
+	// when the tablet balancing is disabled by the operator during the BE rollout,
+	// it is nil when the balancing is not disabled by the operator.
+	OriginalDisableBalance *string `json:"originalDisableBalance,omitempty"`
+
+	// Recreate is the progress of the operator-driven recreation of BE pods with the
+	// OnDelete update strategy.
+	// +optional
+	Recreate *RecreateRolloutStatus `json:"recreate,omitempty"`
+
+	// AppliedHotConfigs are the hot-reloadable BE configs that have been applied to the
+	// running BE nodes at runtime without restarting them.
+	// +optional
+	AppliedHotConfigs map[string]string `json:"appliedHotConfigs,omitempty"`
+
+	// UnregisteredMembers are the ready BE pods whose backends were found missing from the
+	// Doris cluster at the last verification, they have been added back by the operator.
+	// +optional
+	UnregisteredMembers []string `json:"unregisteredMembers,omitempty"`
+}
+
+// DecommissionTimeoutPolicy is the action taken when the BE decommission has timed out.
+type DecommissionTimeoutPolicy string
+
+const (
+	DecommissionTimeoutAbort DecommissionTimeoutPolicy = "Abort"
+	DecommissionTimeoutForce DecommissionTimeoutPolicy = "Force"
+)
+
+// BEDecommissionStatus represents the decommission progress of a BE node.
+type BEDecommissionStatus struct {
+	Member    string `json:"member"`
+	BackendId string `json:"backendId,omitempty"`
+	// TabletNum is the number of tablets remaining on the BE node.
+	TabletNum int64 `json:"tabletNum"`
+	// StartTime is the time when the decommission of the BE node is observed by operator.
+	StartTime metav1.Time `json:"startTime"`
+	// Elapsed is the time elapsed since the StartTime when it is last observed.
+	// +optional
+	Elapsed *metav1.Duration `json:"elapsed,omitempty"`
+	// Aborted indicates that the decommission has been cancelled since it has timed out.
+	// +optional
+	Aborted bool `json:"aborted,omitempty"`
 }
 
 // CNStatus represents the current state of Doris CN
 type CNStatus struct {
 	DorisComponentStatus `json:",inline"`
+
+	// UnregisteredMembers are the ready CN pods whose compute nodes were found missing from the
+	// Doris cluster at the last verification, they have been added back by the operator.
+	// +optional
+	UnregisteredMembers []string `json:"unregisteredMembers,omitempty"`
 }
 
 // BrokerStatus represents the current state of Doris Broker
@@ -363,6 +1701,34 @@ type DorisComponentStatus struct {
 	Members        []string                     `json:"members,omitempty"`
 	ReadyMembers   []string                     `json:"readyMembers,omitempty"`
 	Conditions     []appv1.StatefulSetCondition `json:"conditions,omitempty"`
+
+	// Replicas is the desired replicas of the component statefulset.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// CurrentReplicas is the number of pods of the component statefulset at the current revision.
+	// +optional
+	CurrentReplicas int32 `json:"currentReplicas,omitempty"`
+	// ReadyReplicas is the number of ready pods of the component statefulset.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// UpdatedReplicas is the number of pods of the component statefulset at the update revision,
+	// which reaches Replicas when the rollout is completed.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// LastAppliedConfigHash is the config hash annotated on the pod template of the component
+	// statefulset by the operator, of which the change triggers a rolling restart of the component.
+	// +optional
+	LastAppliedConfigHash string `json:"lastAppliedConfigHash,omitempty"`
+
+	// LastAppliedConfig is a preview of the rendered config file last applied by the operator,
+	// e.g. fe.conf, which is truncated to 4KiB.
+	// +optional
+	LastAppliedConfig string `json:"lastAppliedConfig,omitempty"`
+
+	// LastConfigChangeTime is the time when the LastAppliedConfigHash was changed.
+	// +optional
+	LastConfigChangeTime *metav1.Time `json:"lastConfigChangeTime,omitempty"`
 }
 
 func init() {
@@ -81,6 +81,63 @@ type DorisMonitorSpec struct {
 	// NodeSelector of the Doris monitor components.
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Dashboards provisions the Doris Grafana dashboards as a ConfigMap labeled for the dashboard
+	// sidecar of Grafana, e.g. the Grafana of kube-prometheus-stack.
+	// The dashboards are not provisioned when it is not set.
+	// +optional
+	Dashboards *MonitorDashboardsSpec `json:"dashboards,omitempty"`
+
+	// AlertRules deploys the Prometheus alert rules of the FE/BE health, tablet imbalance and
+	// compaction backlog of the Doris cluster, which are loaded by the Prometheus of DorisMonitor.
+	// +optional
+	AlertRules *MonitorAlertRulesSpec `json:"alertRules,omitempty"`
+}
+
+// MonitorDashboardsSpec defines the ConfigMap of the Doris Grafana dashboards.
+// +k8s:openapi-gen=true
+type MonitorDashboardsSpec struct {
+	// Labels of the dashboards ConfigMap, which should match the label selector of the Grafana
+	// dashboard sidecar.
+	// Default to grafana_dashboard: "1"
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations of the dashboards ConfigMap, e.g. the folder annotation of the Grafana dashboard sidecar.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// DatasourceUID is the uid of the Prometheus datasource in Grafana that the dashboards query.
+	// Default to the datasource of the Grafana of DorisMonitor.
+	// +optional
+	DatasourceUID string `json:"datasourceUID,omitempty"`
+}
+
+// MonitorAlertRulesSpec defines the Prometheus alert rules of the Doris cluster.
+// +k8s:openapi-gen=true
+type MonitorAlertRulesSpec struct {
+	// PrometheusRule creates the alert rules as a PrometheusRule of Prometheus Operator as well,
+	// it would be skipped when the PrometheusRule CRD is not installed.
+	// Default to false
+	// +optional
+	PrometheusRule bool `json:"prometheusRule,omitempty"`
+
+	// Labels of the PrometheusRule, which should match the rule selector of Prometheus.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// TabletImbalancePercent is the threshold of the difference between the max and min tablet
+	// numbers of BE nodes in percentage of the average.
+	// Default to 20
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TabletImbalancePercent *int32 `json:"tabletImbalancePercent,omitempty"`
+
+	// CompactionScoreThreshold is the threshold of the max cumulative compaction score of BE nodes.
+	// Default to 100
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CompactionScoreThreshold *int32 `json:"compactionScoreThreshold,omitempty"`
 }
 
 // PrometheusSpec defines the desired state of Prometheus
@@ -230,6 +287,9 @@ const (
 	MnrOprStageLokiService     DorisMonitorOprStage = "loki/Service"
 	MnrOprStageLokiStatefulset DorisMonitorOprStage = "loki/Statefulset"
 
+	MnrOprStageDashboards DorisMonitorOprStage = "grafana/Dashboards"
+	MnrOprStageAlertRules DorisMonitorOprStage = "prometheus/AlertRules"
+
 	MnrOprStageCompleted DorisMonitorOprStage = "completed"
 )
 
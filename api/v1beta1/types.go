@@ -46,6 +46,7 @@ type OprStageStatus string
 const (
 	StageResultSucceeded OprStageStatus = "succeeded"
 	StageResultFailed    OprStageStatus = "failed"
+	StageResultWaiting   OprStageStatus = "waiting"
 )
 
 func (e *DorisCluster) ObjKey() types.NamespacedName {
@@ -58,6 +59,15 @@ func (e *DorisCluster) ObjKey() types.NamespacedName {
 	}
 }
 
+// ResourceKey returns the key that the sub resources of DorisCluster are derived from,
+// which is placed in spec.resourceNamespace when it is specified.
+func (e *DorisCluster) ResourceKey() types.NamespacedName {
+	if e.Spec.ResourceNamespace != "" {
+		return types.NamespacedName{Namespace: e.Spec.ResourceNamespace, Name: e.Name}
+	}
+	return e.ObjKey()
+}
+
 func (e *DorisAutoscaler) ObjKey() types.NamespacedName {
 	if e.objKey == nil {
 		key := types.NamespacedName{Namespace: e.Namespace, Name: e.Name}
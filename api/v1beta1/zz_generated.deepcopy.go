@@ -24,9 +24,11 @@ package v1beta1
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/autoscaling/v2"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -61,6 +63,27 @@ func (in *AutoscalerRecStatus) DeepCopy() *AutoscalerRecStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BEDecommissionStatus) DeepCopyInto(out *BEDecommissionStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.Elapsed != nil {
+		in, out := &in.Elapsed, &out.Elapsed
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BEDecommissionStatus.
+func (in *BEDecommissionStatus) DeepCopy() *BEDecommissionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BEDecommissionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BESpec) DeepCopyInto(out *BESpec) {
 	*out = *in
@@ -77,6 +100,26 @@ func (in *BESpec) DeepCopyInto(out *BESpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisableBalanceDuringRollout != nil {
+		in, out := &in.DisableBalanceDuringRollout, &out.DisableBalanceDuringRollout
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DecommissionTimeout != nil {
+		in, out := &in.DecommissionTimeout, &out.DecommissionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CordonedOrdinals != nil {
+		in, out := &in.CordonedOrdinals, &out.CordonedOrdinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BESpec.
@@ -93,6 +136,52 @@ func (in *BESpec) DeepCopy() *BESpec {
 func (in *BEStatus) DeepCopyInto(out *BEStatus) {
 	*out = *in
 	in.DorisComponentStatus.DeepCopyInto(&out.DorisComponentStatus)
+	if in.DecommissioningMembers != nil {
+		in, out := &in.DecommissioningMembers, &out.DecommissioningMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Decommissioning != nil {
+		in, out := &in.Decommissioning, &out.Decommissioning
+		*out = make([]BEDecommissionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cordoning != nil {
+		in, out := &in.Cordoning, &out.Cordoning
+		*out = make([]BEDecommissionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CordonedMembers != nil {
+		in, out := &in.CordonedMembers, &out.CordonedMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OriginalDisableBalance != nil {
+		in, out := &in.OriginalDisableBalance, &out.OriginalDisableBalance
+		*out = new(string)
+		**out = **in
+	}
+	if in.Recreate != nil {
+		in, out := &in.Recreate, &out.Recreate
+		*out = new(RecreateRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppliedHotConfigs != nil {
+		in, out := &in.AppliedHotConfigs, &out.AppliedHotConfigs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UnregisteredMembers != nil {
+		in, out := &in.UnregisteredMembers, &out.UnregisteredMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BEStatus.
@@ -130,10 +219,166 @@ func (in *BEStorage) DeepCopy() *BEStorage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRecord) DeepCopyInto(out *BackupRecord) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRecord.
+func (in *BackupRecord) DeepCopy() *BackupRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRepositorySpec) DeepCopyInto(out *BackupRepositorySpec) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CredentialSecretRef != nil {
+		in, out := &in.CredentialSecretRef, &out.CredentialSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRepositorySpec.
+func (in *BackupRepositorySpec) DeepCopy() *BackupRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+	in.BackupRepositorySpec.DeepCopyInto(&out.BackupRepositorySpec)
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSpec.
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]BackupRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
+func (in *BackupStatus) DeepCopy() *BackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerKerberosSpec) DeepCopyInto(out *BrokerKerberosSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerKerberosSpec.
+func (in *BrokerKerberosSpec) DeepCopy() *BrokerKerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerKerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerServiceSpec) DeepCopyInto(out *BrokerServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerServiceSpec.
+func (in *BrokerServiceSpec) DeepCopy() *BrokerServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerSpec) DeepCopyInto(out *BrokerSpec) {
 	*out = *in
 	in.DorisComponentSpec.DeepCopyInto(&out.DorisComponentSpec)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(BrokerServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JvmHeap != nil {
+		in, out := &in.JvmHeap, &out.JvmHeap
+		*out = new(JvmHeapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(BrokerKerberosSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerSpec.
@@ -175,6 +420,13 @@ func (in *CNAutoscalerRules) DeepCopyInto(out *CNAutoscalerRules) {
 		*out = new(UtilizationThresholdRange)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = make([]ExternalMetricRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNAutoscalerRules.
@@ -197,6 +449,11 @@ func (in *CNAutoscalerSpec) DeepCopyInto(out *CNAutoscalerSpec) {
 		*out = new(ScalePeriodSeconds)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StabilizationWindowSeconds != nil {
+		in, out := &in.StabilizationWindowSeconds, &out.StabilizationWindowSeconds
+		*out = new(ScalePeriodSeconds)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNAutoscalerSpec.
@@ -281,6 +538,11 @@ func (in *CNSpec) DeepCopy() *CNSpec {
 func (in *CNStatus) DeepCopyInto(out *CNStatus) {
 	*out = *in
 	in.DorisComponentStatus.DeepCopyInto(&out.DorisComponentStatus)
+	if in.UnregisteredMembers != nil {
+		in, out := &in.UnregisteredMembers, &out.UnregisteredMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNStatus.
@@ -463,6 +725,28 @@ func (in *DorisClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DorisClusterPlan) DeepCopyInto(out *DorisClusterPlan) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]PlanStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisClusterPlan.
+func (in *DorisClusterPlan) DeepCopy() *DorisClusterPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(DorisClusterPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DorisClusterRecStatus) DeepCopyInto(out *DorisClusterRecStatus) {
 	*out = *in
@@ -511,14 +795,54 @@ func (in *DorisClusterSpec) DeepCopyInto(out *DorisClusterSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.HelperImage != nil {
+		in, out := &in.HelperImage, &out.HelperImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WaitForFE != nil {
+		in, out := &in.WaitForFE, &out.WaitForFE
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OprSqlAccountSecretRef != nil {
+		in, out := &in.OprSqlAccountSecretRef, &out.OprSqlAccountSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.OprSqlAccount != nil {
+		in, out := &in.OprSqlAccount, &out.OprSqlAccount
+		*out = new(OprSqlAccountSpec)
+		**out = **in
+	}
+	if in.InitSQL != nil {
+		in, out := &in.InitSQL, &out.InitSQL
+		*out = new(InitSQLSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(RestoreFromSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.NodeSelector != nil {
@@ -537,16 +861,68 @@ func (in *DorisClusterSpec) DeepCopyInto(out *DorisClusterSpec) {
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultResources != nil {
+		in, out := &in.DefaultResources, &out.DefaultResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(corev1.PreemptionPolicy)
+		**out = **in
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSPolicy != nil {
+		in, out := &in.DNSPolicy, &out.DNSPolicy
+		*out = new(corev1.DNSPolicy)
+		**out = **in
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.StatefulSetUpdateStrategy != nil {
 		in, out := &in.StatefulSetUpdateStrategy, &out.StatefulSetUpdateStrategy
 		*out = new(appsv1.StatefulSetUpdateStrategyType)
 		**out = **in
 	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroupChangePolicy != nil {
+		in, out := &in.FSGroupChangePolicy, &out.FSGroupChangePolicy
+		*out = new(corev1.PodFSGroupChangePolicy)
+		**out = **in
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisClusterSpec.
@@ -568,7 +944,29 @@ func (in *DorisClusterStatus) DeepCopyInto(out *DorisClusterStatus) {
 		**out = **in
 	}
 	out.DorisClusterRecStatus = in.DorisClusterRecStatus
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DorisClusterSyncStatus.DeepCopyInto(&out.DorisClusterSyncStatus)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(DorisClusterPlan)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisClusterStatus.
@@ -588,6 +986,7 @@ func (in *DorisClusterSyncStatus) DeepCopyInto(out *DorisClusterSyncStatus) {
 	in.BE.DeepCopyInto(&out.BE)
 	in.CN.DeepCopyInto(&out.CN)
 	in.Broker.DeepCopyInto(&out.Broker)
+	in.GeneratedResources.DeepCopyInto(&out.GeneratedResources)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisClusterSyncStatus.
@@ -611,9 +1010,14 @@ func (in *DorisComponentSpec) DeepCopyInto(out *DorisComponentSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	if in.HostAliases != nil {
 		in, out := &in.HostAliases, &out.HostAliases
-		*out = make([]v1.HostAlias, len(*in))
+		*out = make([]corev1.HostAlias, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -632,51 +1036,201 @@ func (in *DorisComponentSpec) DeepCopyInto(out *DorisComponentSpec) {
 			(*out)[key] = val
 		}
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.StatefulSetUpdateStrategy != nil {
-		in, out := &in.StatefulSetUpdateStrategy, &out.StatefulSetUpdateStrategy
-		*out = new(appsv1.StatefulSetUpdateStrategyType)
-		**out = **in
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.AdditionalEnvs != nil {
-		in, out := &in.AdditionalEnvs, &out.AdditionalEnvs
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigMapLabels != nil {
+		in, out := &in.ConfigMapLabels, &out.ConfigMapLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigMapAnnotations != nil {
+		in, out := &in.ConfigMapAnnotations, &out.ConfigMapAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]corev1.ContainerPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageAnnotations != nil {
+		in, out := &in.StorageAnnotations, &out.StorageAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(corev1.PreemptionPolicy)
+		**out = **in
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSPolicy != nil {
+		in, out := &in.DNSPolicy, &out.DNSPolicy
+		*out = new(corev1.DNSPolicy)
+		**out = **in
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.StatefulSetUpdateStrategy != nil {
+		in, out := &in.StatefulSetUpdateStrategy, &out.StatefulSetUpdateStrategy
+		*out = new(appsv1.StatefulSetUpdateStrategyType)
+		**out = **in
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroupChangePolicy != nil {
+		in, out := &in.FSGroupChangePolicy, &out.FSGroupChangePolicy
+		*out = new(corev1.PodFSGroupChangePolicy)
+		**out = **in
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalEnvs != nil {
+		in, out := &in.AdditionalEnvs, &out.AdditionalEnvs
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalEnvFrom != nil {
+		in, out := &in.AdditionalEnvFrom, &out.AdditionalEnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.AdditionalContainers != nil {
 		in, out := &in.AdditionalContainers, &out.AdditionalContainers
-		*out = make([]v1.Container, len(*in))
+		*out = make([]corev1.Container, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.AdditionalVolumes != nil {
 		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
-		*out = make([]v1.Volume, len(*in))
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.AdditionalVolumeMounts != nil {
 		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinReadySeconds != nil {
+		in, out := &in.MinReadySeconds, &out.MinReadySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StorageVolumes != nil {
+		in, out := &in.StorageVolumes, &out.StorageVolumes
+		*out = make([]StorageVolume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisComponentSpec.
@@ -710,6 +1264,10 @@ func (in *DorisComponentStatus) DeepCopyInto(out *DorisComponentStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastConfigChangeTime != nil {
+		in, out := &in.LastConfigChangeTime, &out.LastConfigChangeTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisComponentStatus.
@@ -806,7 +1364,7 @@ func (in *DorisInitializerSpec) DeepCopyInto(out *DorisInitializerSpec) {
 	*out = *in
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.MaxRetry != nil {
@@ -817,7 +1375,7 @@ func (in *DorisInitializerSpec) DeepCopyInto(out *DorisInitializerSpec) {
 	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1008,7 +1566,7 @@ func (in *DorisMonitorSpec) DeepCopyInto(out *DorisMonitorSpec) {
 	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.StorageClassName != nil {
@@ -1023,6 +1581,16 @@ func (in *DorisMonitorSpec) DeepCopyInto(out *DorisMonitorSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Dashboards != nil {
+		in, out := &in.Dashboards, &out.Dashboards
+		*out = new(MonitorDashboardsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertRules != nil {
+		in, out := &in.AlertRules, &out.AlertRules
+		*out = new(MonitorAlertRulesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DorisMonitorSpec.
@@ -1077,204 +1645,629 @@ func (in *DorisMonitorSyncStatus) DeepCopy() *DorisMonitorSyncStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FESpec) DeepCopyInto(out *FESpec) {
+func (in *ExternalMetricRule) DeepCopyInto(out *ExternalMetricRule) {
 	*out = *in
-	in.DorisComponentSpec.DeepCopyInto(&out.DorisComponentSpec)
-	if in.StorageClassName != nil {
-		in, out := &in.StorageClassName, &out.StorageClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(FeServiceSpec)
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FESpec.
-func (in *FESpec) DeepCopy() *FESpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalMetricRule.
+func (in *ExternalMetricRule) DeepCopy() *ExternalMetricRule {
 	if in == nil {
 		return nil
 	}
-	out := new(FESpec)
+	out := new(ExternalMetricRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FEStatus) DeepCopyInto(out *FEStatus) {
+func (in *FEConfigRevision) DeepCopyInto(out *FEConfigRevision) {
 	*out = *in
-	out.ServiceRef = in.ServiceRef
-	in.DorisComponentStatus.DeepCopyInto(&out.DorisComponentStatus)
+	in.AppliedTime.DeepCopyInto(&out.AppliedTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEStatus.
-func (in *FEStatus) DeepCopy() *FEStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEConfigRevision.
+func (in *FEConfigRevision) DeepCopy() *FEConfigRevision {
 	if in == nil {
 		return nil
 	}
-	out := new(FEStatus)
+	out := new(FEConfigRevision)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FeServiceSpec) DeepCopyInto(out *FeServiceSpec) {
+func (in *FEConfigRollbackSpec) DeepCopyInto(out *FEConfigRollbackSpec) {
 	*out = *in
-	if in.QueryPort != nil {
-		in, out := &in.QueryPort, &out.QueryPort
-		*out = new(int32)
-		**out = **in
-	}
-	if in.HttpPort != nil {
-		in, out := &in.HttpPort, &out.HttpPort
+	if in.ReadyTimeoutSeconds != nil {
+		in, out := &in.ReadyTimeoutSeconds, &out.ReadyTimeoutSeconds
 		*out = new(int32)
 		**out = **in
 	}
-	if in.ExternalTrafficPolicy != nil {
-		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
-		*out = new(v1.ServiceExternalTrafficPolicy)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeServiceSpec.
-func (in *FeServiceSpec) DeepCopy() *FeServiceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEConfigRollbackSpec.
+func (in *FEConfigRollbackSpec) DeepCopy() *FEConfigRollbackSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FeServiceSpec)
+	out := new(FEConfigRollbackSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
+func (in *FEMetadataRecoveryRecord) DeepCopyInto(out *FEMetadataRecoveryRecord) {
 	*out = *in
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(MonitorServiceSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
-	if in.StorageClassName != nil {
-		in, out := &in.StorageClassName, &out.StorageClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	in.Time.DeepCopyInto(&out.Time)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSpec.
-func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEMetadataRecoveryRecord.
+func (in *FEMetadataRecoveryRecord) DeepCopy() *FEMetadataRecoveryRecord {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaSpec)
+	out := new(FEMetadataRecoveryRecord)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
+func (in *FEMetadataRecoveryStatus) DeepCopyInto(out *FEMetadataRecoveryStatus) {
 	*out = *in
-	in.DorisMonitorComponentStatus.DeepCopyInto(&out.DorisMonitorComponentStatus)
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make([]FEMetadataRecoveryRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatus.
-func (in *GrafanaStatus) DeepCopy() *GrafanaStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEMetadataRecoveryStatus.
+func (in *FEMetadataRecoveryStatus) DeepCopy() *FEMetadataRecoveryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaStatus)
+	out := new(FEMetadataRecoveryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HadoopConfSpec) DeepCopyInto(out *HadoopConfSpec) {
+func (in *FERolloutStatus) DeepCopyInto(out *FERolloutStatus) {
 	*out = *in
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = make([]HostnameIpItem, len(*in))
+	if in.UpdatedMembers != nil {
+		in, out := &in.UpdatedMembers, &out.UpdatedMembers
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.PendingMembers != nil {
+		in, out := &in.PendingMembers, &out.PendingMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopConfSpec.
-func (in *HadoopConfSpec) DeepCopy() *HadoopConfSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FERolloutStatus.
+func (in *FERolloutStatus) DeepCopy() *FERolloutStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HadoopConfSpec)
+	out := new(FERolloutStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HostnameIpItem) DeepCopyInto(out *HostnameIpItem) {
+func (in *FESpec) DeepCopyInto(out *FESpec) {
 	*out = *in
+	in.DorisComponentSpec.DeepCopyInto(&out.DorisComponentSpec)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(FeServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodService != nil {
+		in, out := &in.PodService, &out.PodService
+		*out = new(FePodServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(FeIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Followers != nil {
+		in, out := &in.Followers, &out.Followers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JvmHeap != nil {
+		in, out := &in.JvmHeap, &out.JvmHeap
+		*out = new(JvmHeapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(FeTLSSpec)
+		**out = **in
+	}
+	if in.ConfigRollback != nil {
+		in, out := &in.ConfigRollback, &out.ConfigRollback
+		*out = new(FEConfigRollbackSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameIpItem.
-func (in *HostnameIpItem) DeepCopy() *HostnameIpItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FESpec.
+func (in *FESpec) DeepCopy() *FESpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HostnameIpItem)
+	out := new(FESpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LokiSpec) DeepCopyInto(out *LokiSpec) {
+func (in *FEStatus) DeepCopyInto(out *FEStatus) {
 	*out = *in
-	if in.RetentionTime != nil {
-		in, out := &in.RetentionTime, &out.RetentionTime
-		*out = new(string)
-		**out = **in
+	out.ServiceRef = in.ServiceRef
+	in.DorisComponentStatus.DeepCopyInto(&out.DorisComponentStatus)
+	if in.Followers != nil {
+		in, out := &in.Followers, &out.Followers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
-	if in.StorageClassName != nil {
-		in, out := &in.StorageClassName, &out.StorageClassName
-		*out = new(string)
-		**out = **in
+	if in.Observers != nil {
+		in, out := &in.Observers, &out.Observers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(FERolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recreate != nil {
+		in, out := &in.Recreate, &out.Recreate
+		*out = new(RecreateRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetadataRecovery != nil {
+		in, out := &in.MetadataRecovery, &out.MetadataRecovery
+		*out = new(FEMetadataRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppliedHotConfigs != nil {
+		in, out := &in.AppliedHotConfigs, &out.AppliedHotConfigs
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.ConfigHistory != nil {
+		in, out := &in.ConfigHistory, &out.ConfigHistory
+		*out = make([]FEConfigRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiSpec.
-func (in *LokiSpec) DeepCopy() *LokiSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FEStatus.
+func (in *FEStatus) DeepCopy() *FEStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LokiSpec)
+	out := new(FEStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LokiStatus) DeepCopyInto(out *LokiStatus) {
+func (in *FeIngressSpec) DeepCopyInto(out *FeIngressSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeIngressSpec.
+func (in *FeIngressSpec) DeepCopy() *FeIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FePodServiceSpec) DeepCopyInto(out *FePodServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalTrafficPolicy != nil {
+		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
+		*out = new(corev1.ServiceExternalTrafficPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FePodServiceSpec.
+func (in *FePodServiceSpec) DeepCopy() *FePodServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FePodServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeServiceSpec) DeepCopyInto(out *FeServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.QueryPort != nil {
+		in, out := &in.QueryPort, &out.QueryPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HttpPort != nil {
+		in, out := &in.HttpPort, &out.HttpPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EditLogPort != nil {
+		in, out := &in.EditLogPort, &out.EditLogPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RpcPort != nil {
+		in, out := &in.RpcPort, &out.RpcPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExternalTrafficPolicy != nil {
+		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
+		*out = new(corev1.ServiceExternalTrafficPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeServiceSpec.
+func (in *FeServiceSpec) DeepCopy() *FeServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeTLSSpec) DeepCopyInto(out *FeTLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeTLSSpec.
+func (in *FeTLSSpec) DeepCopy() *FeTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedResource) DeepCopyInto(out *GeneratedResource) {
+	*out = *in
+	out.NamespacedName = in.NamespacedName
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedResource.
+func (in *GeneratedResource) DeepCopy() *GeneratedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedResources) DeepCopyInto(out *GeneratedResources) {
+	*out = *in
+	if in.FE != nil {
+		in, out := &in.FE, &out.FE
+		*out = make([]GeneratedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.BE != nil {
+		in, out := &in.BE, &out.BE
+		*out = make([]GeneratedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.CN != nil {
+		in, out := &in.CN, &out.CN
+		*out = make([]GeneratedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Broker != nil {
+		in, out := &in.Broker, &out.Broker
+		*out = make([]GeneratedResource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedResources.
+func (in *GeneratedResources) DeepCopy() *GeneratedResources {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(MonitorServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSpec.
+func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
+	*out = *in
+	in.DorisMonitorComponentStatus.DeepCopyInto(&out.DorisMonitorComponentStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatus.
+func (in *GrafanaStatus) DeepCopy() *GrafanaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopConfSpec) DeepCopyInto(out *HadoopConfSpec) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]HostnameIpItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CredentialSecretRef != nil {
+		in, out := &in.CredentialSecretRef, &out.CredentialSecretRef
+		*out = new(HadoopCredentialSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopConfSpec.
+func (in *HadoopConfSpec) DeepCopy() *HadoopConfSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopConfSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopCredentialSecretRef) DeepCopyInto(out *HadoopCredentialSecretRef) {
+	*out = *in
+	if in.EnvKeys != nil {
+		in, out := &in.EnvKeys, &out.EnvKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopCredentialSecretRef.
+func (in *HadoopCredentialSecretRef) DeepCopy() *HadoopCredentialSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopCredentialSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameIpItem) DeepCopyInto(out *HostnameIpItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameIpItem.
+func (in *HostnameIpItem) DeepCopy() *HostnameIpItem {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameIpItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitSQLSpec) DeepCopyInto(out *InitSQLSpec) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitSQLSpec.
+func (in *InitSQLSpec) DeepCopy() *InitSQLSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InitSQLSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JvmHeapSpec) DeepCopyInto(out *JvmHeapSpec) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JvmHeapSpec.
+func (in *JvmHeapSpec) DeepCopy() *JvmHeapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JvmHeapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiSpec) DeepCopyInto(out *LokiSpec) {
+	*out = *in
+	if in.RetentionTime != nil {
+		in, out := &in.RetentionTime, &out.RetentionTime
+		*out = new(string)
+		**out = **in
+	}
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiSpec.
+func (in *LokiSpec) DeepCopy() *LokiSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiStatus) DeepCopyInto(out *LokiStatus) {
 	*out = *in
 	in.DorisMonitorComponentStatus.DeepCopyInto(&out.DorisMonitorComponentStatus)
 }
@@ -1289,6 +2282,102 @@ func (in *LokiStatus) DeepCopy() *LokiStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsPortsSpec) DeepCopyInto(out *MetricsPortsSpec) {
+	*out = *in
+	if in.FE != nil {
+		in, out := &in.FE, &out.FE
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BE != nil {
+		in, out := &in.BE, &out.BE
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CN != nil {
+		in, out := &in.CN, &out.CN
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Broker != nil {
+		in, out := &in.Broker, &out.Broker
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsPortsSpec.
+func (in *MetricsPortsSpec) DeepCopy() *MetricsPortsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsPortsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorAlertRulesSpec) DeepCopyInto(out *MonitorAlertRulesSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TabletImbalancePercent != nil {
+		in, out := &in.TabletImbalancePercent, &out.TabletImbalancePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CompactionScoreThreshold != nil {
+		in, out := &in.CompactionScoreThreshold, &out.CompactionScoreThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorAlertRulesSpec.
+func (in *MonitorAlertRulesSpec) DeepCopy() *MonitorAlertRulesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorAlertRulesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorDashboardsSpec) DeepCopyInto(out *MonitorDashboardsSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorDashboardsSpec.
+func (in *MonitorDashboardsSpec) DeepCopy() *MonitorDashboardsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorDashboardsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitorServiceSpec) DeepCopyInto(out *MonitorServiceSpec) {
 	*out = *in
@@ -1299,7 +2388,7 @@ func (in *MonitorServiceSpec) DeepCopyInto(out *MonitorServiceSpec) {
 	}
 	if in.ExternalTrafficPolicy != nil {
 		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
-		*out = new(v1.ServiceExternalTrafficPolicy)
+		*out = new(corev1.ServiceExternalTrafficPolicy)
 		**out = **in
 	}
 }
@@ -1314,6 +2403,31 @@ func (in *MonitorServiceSpec) DeepCopy() *MonitorServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.PrometheusAnnotations != nil {
+		in, out := &in.PrometheusAnnotations, &out.PrometheusAnnotations
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MetricsPorts != nil {
+		in, out := &in.MetricsPorts, &out.MetricsPorts
+		*out = new(MetricsPortsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
 	*out = *in
@@ -1329,6 +2443,88 @@ func (in *NamespacedName) DeepCopy() *NamespacedName {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OprSqlAccountSpec) DeepCopyInto(out *OprSqlAccountSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OprSqlAccountSpec.
+func (in *OprSqlAccountSpec) DeepCopy() *OprSqlAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OprSqlAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanAction) DeepCopyInto(out *PlanAction) {
+	*out = *in
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanAction.
+func (in *PlanAction) DeepCopy() *PlanAction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanStage) DeepCopyInto(out *PlanStage) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]PlanAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanStage.
+func (in *PlanStage) DeepCopy() *PlanStage {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
 	*out = *in
@@ -1422,6 +2618,31 @@ func (in *PromtailStatus) DeepCopy() *PromtailStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecreateRolloutStatus) DeepCopyInto(out *RecreateRolloutStatus) {
+	*out = *in
+	if in.UpdatedMembers != nil {
+		in, out := &in.UpdatedMembers, &out.UpdatedMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingMembers != nil {
+		in, out := &in.PendingMembers, &out.PendingMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecreateRolloutStatus.
+func (in *RecreateRolloutStatus) DeepCopy() *RecreateRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecreateRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicasRange) DeepCopyInto(out *ReplicasRange) {
 	*out = *in
@@ -1442,6 +2663,86 @@ func (in *ReplicasRange) DeepCopy() *ReplicasRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreFromSpec) DeepCopyInto(out *RestoreFromSpec) {
+	*out = *in
+	in.BackupRepositorySpec.DeepCopyInto(&out.BackupRepositorySpec)
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]RestoreSnapshotSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreFromSpec.
+func (in *RestoreFromSpec) DeepCopy() *RestoreFromSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreFromSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSnapshotSpec) DeepCopyInto(out *RestoreSnapshotSpec) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSnapshotSpec.
+func (in *RestoreSnapshotSpec) DeepCopy() *RestoreSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSnapshotStatus) DeepCopyInto(out *RestoreSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSnapshotStatus.
+func (in *RestoreSnapshotStatus) DeepCopy() *RestoreSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
+	*out = *in
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]RestoreSnapshotStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStatus.
+func (in *RestoreStatus) DeepCopy() *RestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalePeriodSeconds) DeepCopyInto(out *ScalePeriodSeconds) {
 	*out = *in
@@ -1467,6 +2768,31 @@ func (in *ScalePeriodSeconds) DeepCopy() *ScalePeriodSeconds {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageVolume) DeepCopyInto(out *StorageVolume) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageVolume.
+func (in *StorageVolume) DeepCopy() *StorageVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UtilizationThresholdRange) DeepCopyInto(out *UtilizationThresholdRange) {
 	*out = *in
@@ -0,0 +1,96 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"k8s.io/client-go/tools/leaderelection"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+// The default leader election parameters are more tolerant of a slow or flaky API server
+// than the controller-runtime defaults (15s, 10s, 2s), a leader that fails to renew its lease
+// within a few retries would not give up the reconciliation, at the cost of a longer failover.
+const (
+	defaultLeaseDuration          = 60 * time.Second
+	defaultRenewDeadline          = 40 * time.Second
+	defaultRetryPeriod            = 10 * time.Second
+	defaultLeaderElectionResource = "0a2dfd6b.al-assad.github.io"
+)
+
+// leaderElectionConfig holds the leader election parameters of the controller manager,
+// only one operator replica that holds the lease reconciles the Doris resources.
+type leaderElectionConfig struct {
+	enabled           bool
+	leaseDuration     time.Duration
+	renewDeadline     time.Duration
+	retryPeriod       time.Duration
+	resourceName      string
+	resourceNamespace string
+}
+
+func (c *leaderElectionConfig) bindFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.enabled, "leader-elect", false,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	fs.DurationVar(&c.leaseDuration, "leader-elect-lease-duration", defaultLeaseDuration,
+		"The duration that non-leader candidates will wait to force acquire the leadership.")
+	fs.DurationVar(&c.renewDeadline, "leader-elect-renew-deadline", defaultRenewDeadline,
+		"The duration that the acting leader will retry refreshing the leadership before giving up.")
+	fs.DurationVar(&c.retryPeriod, "leader-elect-retry-period", defaultRetryPeriod,
+		"The duration the clients should wait between tries of the leader election actions.")
+	fs.StringVar(&c.resourceName, "leader-elect-resource-name", defaultLeaderElectionResource,
+		"The name of the Lease resource that is used for the leader election.")
+	fs.StringVar(&c.resourceNamespace, "leader-elect-resource-namespace", "",
+		"The namespace of the Lease resource, default to the namespace that the operator is running in.")
+}
+
+// validate checks the leader election parameters in the same way as the leader elector of client-go,
+// so that the misconfiguration is reported on startup rather than after the manager has started.
+func (c *leaderElectionConfig) validate() error {
+	if !c.enabled {
+		return nil
+	}
+	if c.resourceName == "" {
+		return errors.New("leader-elect-resource-name must not be empty")
+	}
+	if c.retryPeriod <= 0 {
+		return fmt.Errorf("leader-elect-retry-period %s must be positive", c.retryPeriod)
+	}
+	if c.renewDeadline <= time.Duration(leaderelection.JitterFactor*float64(c.retryPeriod)) {
+		return fmt.Errorf("leader-elect-renew-deadline %s must be greater than %v times leader-elect-retry-period %s",
+			c.renewDeadline, leaderelection.JitterFactor, c.retryPeriod)
+	}
+	if c.leaseDuration <= c.renewDeadline {
+		return fmt.Errorf("leader-elect-lease-duration %s must be greater than leader-elect-renew-deadline %s",
+			c.leaseDuration, c.renewDeadline)
+	}
+	return nil
+}
+
+// apply sets the leader election parameters to the options of controller manager.
+func (c *leaderElectionConfig) apply(opts *ctrl.Options) {
+	opts.LeaderElection = c.enabled
+	opts.LeaderElectionID = c.resourceName
+	opts.LeaderElectionNamespace = c.resourceNamespace
+	opts.LeaseDuration = &c.leaseDuration
+	opts.RenewDeadline = &c.renewDeadline
+	opts.RetryPeriod = &c.retryPeriod
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+	"time"
+)
+
+func TestLeaderElectionConfig(t *testing.T) {
+	parse := func(args ...string) *leaderElectionConfig {
+		conf := &leaderElectionConfig{}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		conf.bindFlags(fs)
+		assert.NoError(t, fs.Parse(args))
+		return conf
+	}
+
+	// defaults
+	conf := parse("--leader-elect")
+	assert.NoError(t, conf.validate())
+	opts := ctrl.Options{}
+	conf.apply(&opts)
+	assert.True(t, opts.LeaderElection)
+	assert.Equal(t, defaultLeaderElectionResource, opts.LeaderElectionID)
+	assert.Empty(t, opts.LeaderElectionNamespace)
+	assert.Equal(t, defaultLeaseDuration, *opts.LeaseDuration)
+	assert.Equal(t, defaultRenewDeadline, *opts.RenewDeadline)
+	assert.Equal(t, defaultRetryPeriod, *opts.RetryPeriod)
+
+	// custom parameters
+	conf = parse("--leader-elect", "--leader-elect-lease-duration=30s", "--leader-elect-renew-deadline=20s",
+		"--leader-elect-retry-period=5s", "--leader-elect-resource-name=doris-operator",
+		"--leader-elect-resource-namespace=doris-system")
+	assert.NoError(t, conf.validate())
+	conf.apply(&opts)
+	assert.Equal(t, "doris-operator", opts.LeaderElectionID)
+	assert.Equal(t, "doris-system", opts.LeaderElectionNamespace)
+	assert.Equal(t, 30*time.Second, *opts.LeaseDuration)
+	assert.Equal(t, 20*time.Second, *opts.RenewDeadline)
+	assert.Equal(t, 5*time.Second, *opts.RetryPeriod)
+
+	// invalid parameters
+	assert.ErrorContains(t, parse("--leader-elect", "--leader-elect-lease-duration=40s").validate(),
+		"leader-elect-lease-duration 40s must be greater than leader-elect-renew-deadline 40s")
+	assert.ErrorContains(t, parse("--leader-elect", "--leader-elect-retry-period=35s").validate(),
+		"leader-elect-renew-deadline 40s must be greater than 1.2 times leader-elect-retry-period 35s")
+	assert.ErrorContains(t, parse("--leader-elect", "--leader-elect-resource-name=").validate(),
+		"leader-elect-resource-name must not be empty")
+	// not checked when the leader election is disabled
+	assert.NoError(t, parse("--leader-elect-lease-duration=1s").validate())
+}
@@ -40,6 +40,8 @@ import (
 
 	alassadgithubiov1beta1 "github.com/al-assad/doris-operator/api/v1beta1"
 	"github.com/al-assad/doris-operator/internal/controller"
+	"github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -56,13 +58,33 @@ func init() {
 
 func main() {
 	var metricsAddr string
-	var enableLeaderElection bool
+	var leaderElection leaderElectionConfig
 	var probeAddr string
+	var enableWebhook bool
+	var serverSideApply bool
+	var fieldManager string
+	var nodePortRange string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
-		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
+	leaderElection.bindFlags(flag.CommandLine)
+	flag.BoolVar(&enableWebhook, "enable-webhook", false,
+		"Enable the validating and mutating admission webhooks, which require the webhook server certificates.")
+	flag.BoolVar(&serverSideApply, "server-side-apply", false,
+		"Apply the sub resources with the server-side apply, so that the operator only owns the fields it sets.")
+	flag.StringVar(&fieldManager, "field-manager", "doris-operator",
+		"The field manager name of the server-side apply.")
+	flag.StringVar(&transformer.DefaultFeBaseImage, "default-fe-image", transformer.DefaultFeBaseImage,
+		"The default base image of FE when it is not specified in DorisCluster.")
+	flag.StringVar(&transformer.DefaultBeBaseImage, "default-be-image", transformer.DefaultBeBaseImage,
+		"The default base image of BE when it is not specified in DorisCluster.")
+	flag.StringVar(&transformer.DefaultCnBaseImage, "default-cn-image", transformer.DefaultCnBaseImage,
+		"The default base image of CN when it is not specified in DorisCluster.")
+	flag.StringVar(&transformer.DefaultBrokerBaseImage, "default-broker-image", transformer.DefaultBrokerBaseImage,
+		"The default base image of Broker when it is not specified in DorisCluster.")
+	flag.BoolVar(&transformer.EnableDefaultPriorityClasses, "enable-default-priority-classes", transformer.EnableDefaultPriorityClasses,
+		"Create the recommended PriorityClasses of FE and BE, and use them when the priorityClassName is not specified in DorisCluster.")
+	flag.StringVar(&nodePortRange, "service-node-port-range", "30000-32767",
+		"The NodePort range of kube-apiserver, which the NodePorts of FE service are validated against.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -70,20 +92,33 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	if !serverSideApply {
+		fieldManager = ""
+	}
+	minNodePort, maxNodePort, err := transformer.ParseNodePortRange(nodePortRange)
+	if err != nil {
+		setupLog.Error(err, "invalid service-node-port-range")
+		os.Exit(1)
+	}
+	transformer.NodePortRangeMin, transformer.NodePortRangeMax = minNodePort, maxNodePort
+	if err := leaderElection.validate(); err != nil {
+		setupLog.Error(err, "invalid leader election configuration")
+		os.Exit(1)
+	}
 
 	// Obtain kubernetes server version
 	serverVersion := obtainK8sServerVersion()
 	setupLog.Info(fmt.Sprintf("Kubernetes version: %s, platform: %s", serverVersion, serverVersion.Platform))
 
 	// Setup manager
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgrOpts := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "0a2dfd6b.al-assad.github.io",
-	})
+	}
+	leaderElection.apply(&mgrOpts)
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), mgrOpts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -92,8 +127,10 @@ func main() {
 	// Setup controllers
 	setupLog.Info("set up DorisCluster controller")
 	if err = (&controller.DorisClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("doriscluster-controller"),
+		FieldManager: fieldManager,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DorisCluster")
 		os.Exit(1)
@@ -101,8 +138,9 @@ func main() {
 
 	setupLog.Info("set up DorisInitializer controller")
 	if err = (&controller.DorisInitializerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DorisInitializer")
 		os.Exit(1)
@@ -110,8 +148,9 @@ func main() {
 
 	setupLog.Info("set up DorisMonitor controller")
 	if err = (&controller.DorisMonitorReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DorisMonitor")
 		os.Exit(1)
@@ -121,8 +160,9 @@ func main() {
 	if serverVersion != nil && serverVersion.Major >= "1" && serverVersion.Minor >= "22" {
 		setupLog.Info("set up DorisAutoscaler controller")
 		if err = (&controller.DorisAutoscalerReconciler{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			FieldManager: fieldManager,
 		}).SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "DorisAutoscaler")
 			os.Exit(1)
@@ -130,6 +170,19 @@ func main() {
 	} else {
 		setupLog.Info("do not set up DorisAutoscaler controller because Kubernetes version < 1.22")
 	}
+
+	// Setup webhooks
+	if enableWebhook {
+		setupLog.Info("set up DorisCluster webhook")
+		if err = (&webhook.DorisClusterValidator{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DorisCluster")
+			os.Exit(1)
+		}
+		if err = (&webhook.DorisClusterDefaulter{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DorisCluster")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	// Manager health & ready check
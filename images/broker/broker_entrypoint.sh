@@ -1,7 +1,7 @@
 #!/bin/bash
 
 # Extra environment variables:
-#  FE_SVC: FE service name, required.
+#  FE_SVC: FE service DNS name, required.
 #  FE_QUERY_PORT: FE service query port, optional, default: 9030
 #  ACC_USER: account name to execute sql, optional, default: k8sopr
 #  ACC_PWD: account password to execute sql, optional.
@@ -26,7 +26,7 @@ PROBE_TIMEOUT=${PROBE_TIMEOUT:-60}
 
 # collect env info from container
 collect_env() {
-  SELF_HOST=$(hostname -f)
+  SELF_HOST=$(myself_host)
   BROKER_IPC_PORT=$(get_value_from_conf_file "$BROKER_CONF_FILE" 'broker_ipc_port' 8000)
   if [[ -z $FE_QUERY_PORT ]]; then
     FE_QUERY_PORT=9030
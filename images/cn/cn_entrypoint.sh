@@ -1,7 +1,7 @@
 #!/bin/bash
 
 # Extra environment variables:
-#  FE_SVC: FE service name, required.
+#  FE_SVC: FE service DNS name, required.
 #  FE_QUERY_PORT: FE service query port, optional, default: 9030
 #  ACC_USER: account name to execute sql, optional, default: k8sopr
 #  ACC_PWD: account password to execute sql, optional.
@@ -42,8 +42,13 @@ doris_error() {
   exit 1
 }
 
-# Get the FQDN DNS of the current container.
+# Get the FQDN DNS of the current container, which prefers the POD_FQDN
+# composed by operator with the cluster domain.
 myself_host() {
+  if [[ -n $POD_FQDN ]]; then
+    echo "$POD_FQDN"
+    return
+  fi
   hostname -f
 #  if [[ -n $POD_NAME ]]; then
 #    if [[ -n $POD_NAMESPACE ]]; then
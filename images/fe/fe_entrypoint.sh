@@ -1,15 +1,22 @@
 #!/bin/bash
 
 # Extra environment variables:
-#  FE_SVC: FE service name, required.
+#  FE_SVC: FE service DNS name, required.
 #  ACC_USER: account name to execute sql, optional.
 #  ACC_PWD: account password to execute sql, optional.
+#  FE_FOLLOWER_NUM: number of FE followers, the FE with pod index >= FE_FOLLOWER_NUM joins as OBSERVER, optional.
+#  FE_SSL_CA_CERT_PASSWORD: password of the SSL CA certificate keystore, optional.
+#  FE_SSL_SERVER_CERT_PASSWORD: password of the SSL server certificate keystore, optional.
 
 source entrypoint_helper.sh
 
 FE_MIRROR_CONF_DIR=/etc/apache-doris/fe/
 FE_CONF_DIR=${DORIS_HOME}/fe/conf/
 FE_CONF_FILE=${DORIS_HOME}/fe/conf/fe.conf
+# written by the operator via downward API to start FE in the metadata failure recovery mode
+FE_RECOVERY_FLAG_FILE=/etc/apache-doris/fe-recovery/metadata-failure-recovery
+# mount path of the FE metadata volume, which is consistent with the "meta_dir" of fe.conf
+FE_META_DIR=${FE_META_DIR:-${DORIS_HOME}/fe/doris-meta}
 
 # self fqdn host
 declare SELF_HOST
@@ -33,6 +40,22 @@ override_fe_conf() {
   fi
   # force fqdn mode on
   inject_item_into_conf_file "$FE_CONF_FILE" 'enable_fqdn_mode' 'true'
+  # inject the passwords of SSL keystores without printing them
+  if [[ -n "$FE_SSL_CA_CERT_PASSWORD" ]]; then
+    printf '\nmysql_ssl_default_ca_certificate_password=%s\n' "$FE_SSL_CA_CERT_PASSWORD" >>"$FE_CONF_FILE"
+  fi
+  if [[ -n "$FE_SSL_SERVER_CERT_PASSWORD" ]]; then
+    printf '\nmysql_ssl_default_server_certificate_password=%s\n' "$FE_SSL_SERVER_CERT_PASSWORD" >>"$FE_CONF_FILE"
+  fi
+}
+
+# report the journal id of the latest metadata image and the role of FE via the termination message,
+# which is used by the operator to pick the FE with the latest metadata for the metadata failure recovery.
+report_meta_journal() {
+  local journal_id role
+  journal_id=$(ls "${FE_META_DIR}/image" 2>/dev/null | sed -n 's/^image\.\([0-9][0-9]*\)$/\1/p' | sort -n | tail -1)
+  role=$(sed -n 's/^role=//p' "${FE_META_DIR}/image/ROLE" 2>/dev/null)
+  echo "journal_id=${journal_id:-0} role=${role}" >/dev/termination-log 2>/dev/null || true
 }
 
 show_frontends() {
@@ -124,23 +147,34 @@ probe_leader_for_podx() {
   done
 }
 
-# add self to fe leader as follower
+# the FE role of myself, FOLLOWER or OBSERVER
+self_role() {
+  if [[ -n $FE_FOLLOWER_NUM && $POD_INDEX -ge $FE_FOLLOWER_NUM ]]; then
+    echo "OBSERVER"
+  else
+    echo "FOLLOWER"
+  fi
+}
+
+# add self to fe leader as follower or observer
 add_self() {
   set +e
   local start
   local expire
   local now
+  local role
   start=$(date +%s)
   expire=$((start + FE_PROBE_TIMEOUT))
+  role=$(self_role)
 
   while true; do
-    doris_note "Try to add myself($SELF_HOST:$EDIT_LOG_PORT) to Doris Cluster as FOLLOWER via $FE_SVC:$EDIT_LOG_PORT..."
+    doris_note "Try to add myself($SELF_HOST:$EDIT_LOG_PORT) to Doris Cluster as $role via $FE_SVC:$EDIT_LOG_PORT..."
     # check if it has been added to the cluster
     if show_frontends | grep -q -w "$SELF_HOST" &>/dev/null; then
       doris_note "Myself($SELF_HOST:$EDIT_LOG_PORT) already exists in cluster."
       break
     fi
-    timeout 15 mysql --connect-timeout 2 -h "$FE_SVC" -P "$QUERY_PORT" -u"$ACC_USER" -p"$ACC_PWD" --skip-column-names --batch -e "ALTER SYSTEM ADD FOLLOWER \"$SELF_HOST:$EDIT_LOG_PORT\";"
+    timeout 15 mysql --connect-timeout 2 -h "$FE_SVC" -P "$QUERY_PORT" -u"$ACC_USER" -p"$ACC_PWD" --skip-column-names --batch -e "ALTER SYSTEM ADD $role \"$SELF_HOST:$EDIT_LOG_PORT\";"
 
     # check if it was added successfully
     if show_frontends | grep -q -w "$SELF_HOST" &>/dev/null; then
@@ -198,10 +232,15 @@ if [[ -z $FE_SVC ]]; then
 fi
 
 opts="--console "
-if [[ -f ${DORIS_HOME}/fe/doris-meta/image/ROLE ]]; then
+if [[ -f ${FE_META_DIR}/image/ROLE ]]; then
   # start fe with meta role exist.
   doris_note "Start FE with role meta exits."
   override_fe_conf
+  report_meta_journal
+  if [[ "$(cat "$FE_RECOVERY_FLAG_FILE" 2>/dev/null)" == "true" ]]; then
+    doris_warn "Start FE in metadata failure recovery mode."
+    opts+="--metadata_failure_recovery "
+  fi
   doris_note "Ready to start FE!"
   start_fe.sh $opts
 else
@@ -32,6 +32,8 @@ import (
 type DorisAutoscalerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// FieldManager enables the server-side apply with the field manager when it is set.
+	FieldManager string
 }
 
 //+kubebuilder:rbac:groups=al-assad.github.io,resources=dorisautoscalers,verbs=get;list;watch;create;update;patch;delete
@@ -41,6 +43,7 @@ type DorisAutoscalerReconciler struct {
 
 func (r *DorisAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	recCtx := reconciler.NewReconcileContext(r.Client, r.Scheme, ctx)
+	recCtx.FieldManager = r.FieldManager
 
 	// obtain CR
 	cr := &dapi.DorisAutoscaler{}
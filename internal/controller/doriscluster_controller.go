@@ -21,17 +21,31 @@ import (
 	"fmt"
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
 	"github.com/al-assad/doris-operator/internal/reconciler"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
 	"github.com/al-assad/doris-operator/internal/util"
 	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
 )
 
 // DorisClusterReconciler reconciles a DorisCluster object
 type DorisClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// FieldManager enables the server-side apply with the field manager when it is set.
+	FieldManager string
 }
 
 //+kubebuilder:rbac:groups=al-assad.github.io,resources=dorisclusters,verbs=get;list;watch;create;update;patch;delete
@@ -42,10 +56,24 @@ type DorisClusterReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create
 
 func (r *DorisClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	recCtx := reconciler.NewReconcileContext(r.Client, r.Scheme, ctx)
+	recCtx.Recorder = r.Recorder
+	recCtx.FieldManager = r.FieldManager
+	// refresh the metrics of managed clusters once the phase may have been changed
+	defer func() {
+		if err := reconciler.RecordManagedClusters(ctx, r.Client); err != nil {
+			recCtx.Log.Error(err, "failed to record the metrics of managed DorisClusters")
+		}
+	}()
 	// obtain CR
 	cr := &dapi.DorisCluster{}
 	exist, err := recCtx.Exist(req.NamespacedName, cr)
@@ -58,14 +86,38 @@ func (r *DorisClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 	rec := reconciler.DorisClusterReconciler{ReconcileContext: recCtx, CR: cr}
+	// tear down the sub resources in order when it is being deleted, even if it has been paused
+	if !cr.DeletionTimestamp.IsZero() {
+		return r.teardown(ctx, rec)
+	}
+	// skip reconciling process when it has been paused
+	if paused, updateErr := r.checkPaused(ctx, cr); paused || updateErr != nil {
+		errSet := StCtrlErrSet{Update: updateErr}
+		return errSet.AsResult()
+	}
+	// add the finalizer for the ordered teardown
+	if controllerutil.AddFinalizer(cr, reconciler.DorisClusterFinalizer) {
+		if err := r.Update(ctx, cr); err != nil {
+			errSet := StCtrlErrSet{Update: err}
+			return errSet.AsResult()
+		}
+	}
 
 	curSpecHash := util.Md5HashOr(cr.Spec, "")
 	isFirstCreated := cr.Status.LastApplySpecHash == nil
 	specHasChanged := isFirstCreated || *cr.Status.LastApplySpecHash != curSpecHash
 	preRecCompleted := cr.Status.Stage == dapi.StageComplete
+	rotationRequested := reconciler.IsOprAccountRotationRequested(cr)
+	restartRequested := reconciler.IsRestartRequested(cr)
+	tlsRotated := rec.IsFeTlsRotated()
+	credentialRotated := rec.IsHadoopCredentialRotated()
+	kerberosRotated := rec.IsBrokerKerberosRotated()
+	refConfigMapChanged := rec.IsRefConfigMapChanged()
 
 	if isFirstCreated && cr.Status.Stage == "" {
 		recCtx.Log.Info(fmt.Sprintf("DorisCluster(%s) is created for the first time", util.K8sObjKeyStr(req.NamespacedName)))
+		// the restoring from snapshots only applies to the newly created cluster
+		reconciler.InitRestoreStatus(cr)
 	}
 	if specHasChanged {
 		recCtx.Log.Info(fmt.Sprintf("DorisCluster(%s) spec has been updated", util.K8sObjKeyStr(req.NamespacedName)))
@@ -73,34 +125,211 @@ func (r *DorisClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// reconcile the sub resource of DorisCluster
 	var recErr error
-	if specHasChanged || !preRecCompleted {
-		recRs := rec.Reconcile()
-		recErr = recRs.Err
-		cr.Status.DorisClusterRecStatus = recRs.AsDorisClusterRecStatus()
-		// when reconcile process competed success, update the last apply spec hash
-		if recRs.Stage == dapi.StageComplete {
-			cr.Status.LastApplySpecHash = &curSpecHash
+	var recPermanent bool
+	if reconciler.IsDryRun(cr) {
+		// compute the plan of changes instead of applying them
+		plan := rec.Plan()
+		plan.SpecHash = curSpecHash
+		cr.Status.Plan = &plan
+	} else {
+		cr.Status.Plan = nil
+		if specHasChanged || !preRecCompleted || rotationRequested || restartRequested ||
+			tlsRotated || credentialRotated || kerberosRotated || refConfigMapChanged {
+			recRs := rec.Reconcile()
+			recErr = recRs.Err
+			recPermanent = recRs.Permanent
+			cr.Status.DorisClusterRecStatus = recRs.AsDorisClusterRecStatus()
+			// when reconcile process competed success, update the last apply spec hash
+			if recRs.Stage == dapi.StageComplete {
+				cr.Status.LastApplySpecHash = &curSpecHash
+			}
 		}
 	}
+	// recover the FE pods that keep crash-looping due to the metadata failure
+	var recoveryErr error
+	if !reconciler.IsDryRun(cr) {
+		recoveryErr = rec.RecFeMetadataRecovery()
+	}
+	// add back the be and cn nodes that are not registered in the doris cluster
+	var registrationErr error
+	if !reconciler.IsDryRun(cr) && cr.Status.Stage == dapi.StageComplete {
+		registrationErr = rec.RecNodeRegistration()
+	}
+	// schedule the periodic backups and track the running one
+	var backupErr error
+	var backupRequeueAfter time.Duration
+	if !reconciler.IsDryRun(cr) {
+		backupRequeueAfter, backupErr = rec.RecBackup()
+	}
 	// sync the status of CR
 	syncRs, syncErr := rec.Sync()
 	cr.Status.DorisClusterSyncStatus = syncRs
+	if recoveryErr != nil {
+		syncErr = util.MergeErrors(syncErr, recoveryErr)
+	}
+	if backupErr != nil {
+		syncErr = util.MergeErrors(syncErr, backupErr)
+	}
+	if registrationErr != nil {
+		syncErr = util.MergeErrors(syncErr, registrationErr)
+	}
+	// sync the phase and conditions of CR
+	if phaseErr := rec.SyncPhase(); phaseErr != nil {
+		syncErr = util.MergeErrors(syncErr, phaseErr)
+	}
 	// update status
 	updateErr := r.Status().Update(ctx, cr)
 
 	// merge error at different reconcile phases
 	errSet := StCtrlErrSet{
-		Rec:    recErr,
-		Sync:   syncErr,
-		Update: updateErr,
+		Rec:          recErr,
+		Sync:         syncErr,
+		Update:       updateErr,
+		RecPermanent: recPermanent,
+		// the restarts of FE pods, the backup schedule and the registration of nodes in Doris
+		// do not trigger the reconciliation, check them periodically
+		RequeueAfter: minRequeueAfter(
+			util.Elvis(reconciler.IsFeMetadataRecoveryEnabled(cr), reconciler.FeMetadataRecoveryCheckInterval, 0),
+			backupRequeueAfter,
+			util.Elvis(cr.Spec.BE != nil || cr.Spec.CN != nil, reconciler.NodeRegistrationCheckInterval, 0)),
 	}
 	return errSet.AsResult()
 }
 
+// returns the shortest positive duration, or zero when none of them is positive.
+func minRequeueAfter(durations ...time.Duration) time.Duration {
+	var result time.Duration
+	for _, d := range durations {
+		if d > 0 && (result == 0 || d < result) {
+			result = d
+		}
+	}
+	return result
+}
+
+// Tear down the deleting DorisCluster, and remove the finalizer once the teardown has completed.
+func (r *DorisClusterReconciler) teardown(ctx context.Context, rec reconciler.DorisClusterReconciler) (ctrl.Result, error) {
+	cr := rec.CR
+	if !controllerutil.ContainsFinalizer(cr, reconciler.DorisClusterFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	recRs := rec.Teardown()
+	if recRs.Stage == dapi.StageComplete {
+		controllerutil.RemoveFinalizer(cr, reconciler.DorisClusterFinalizer)
+		errSet := StCtrlErrSet{Update: r.Update(ctx, cr)}
+		return errSet.AsResult()
+	}
+	cr.Status.DorisClusterRecStatus = recRs.AsDorisClusterRecStatus()
+	errSet := StCtrlErrSet{
+		Rec:    recRs.Err,
+		Update: r.Status().Update(ctx, cr),
+	}
+	return errSet.AsResult()
+}
+
+// Check whether the DorisCluster is paused, and record the Paused condition and event
+// when it transitions into or out of paused state.
+func (r *DorisClusterReconciler) checkPaused(ctx context.Context, cr *dapi.DorisCluster) (bool, error) {
+	wasPaused := meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.DorisClusterPaused)
+	if cr.Spec.Paused == wasPaused {
+		return cr.Spec.Paused, nil
+	}
+	if !cr.Spec.Paused {
+		// the status would be updated at the end of the reconciling process
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    dapi.DorisClusterPaused,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Resumed",
+			Message: "reconciliation of DorisCluster is resumed",
+		})
+		r.Recorder.Event(cr, corev1.EventTypeNormal, "Resumed", "Reconciliation of DorisCluster is resumed")
+		return false, nil
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:    dapi.DorisClusterPaused,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Paused",
+		Message: "reconciliation of DorisCluster is paused",
+	})
+	r.Recorder.Event(cr, corev1.EventTypeNormal, "Paused", "Reconciliation of DorisCluster is paused")
+	return true, r.Status().Update(ctx, cr)
+}
+
+// find the DorisCluster recorded in the owner labels of the sub resource placed in another namespace,
+// which can not be enqueued via owner references.
+func findClusterByOwnerLabels(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	namespace, name := labels[tran.ClusterOwnerNamespaceLabelKey], labels[tran.ClusterOwnerNameLabelKey]
+	if namespace == "" || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// find the DorisClusters in the same namespace that reference the secret as the FE TLS secret,
+// the Hadoop credential secret or the Kerberos secret of Broker.
+func (r *DorisClusterReconciler) findClustersByRefSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	crList := &dapi.DorisClusterList{}
+	if err := r.List(ctx, crList, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, item := range crList.Items {
+		if isFeTlsSecret(&item, secret.GetName()) || isHadoopCredentialSecret(&item, secret.GetName()) ||
+			isBrokerKerberosSecret(&item, secret.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+		}
+	}
+	return requests
+}
+
+func isFeTlsSecret(cr *dapi.DorisCluster, name string) bool {
+	return cr.Spec.FE != nil && cr.Spec.FE.TLS != nil && cr.Spec.FE.TLS.SecretName == name
+}
+
+func isHadoopCredentialSecret(cr *dapi.DorisCluster, name string) bool {
+	return cr.Spec.HadoopConf != nil && cr.Spec.HadoopConf.CredentialSecretRef != nil &&
+		cr.Spec.HadoopConf.CredentialSecretRef.Name == name
+}
+
+func isBrokerKerberosSecret(cr *dapi.DorisCluster, name string) bool {
+	return cr.Spec.Broker != nil && cr.Spec.Broker.Kerberos != nil && cr.Spec.Broker.Kerberos.SecretName == name
+}
+
+// find the DorisClusters that reference the ConfigMap via the configMapRef of components,
+// the referenced ConfigMap lives along with the sub resources of DorisCluster.
+func (r *DorisClusterReconciler) findClustersByRefConfigMap(ctx context.Context, configMap client.Object) []reconcile.Request {
+	crList := &dapi.DorisClusterList{}
+	if err := r.List(ctx, crList); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, item := range crList.Items {
+		if item.ResourceKey().Namespace == configMap.GetNamespace() && isRefConfigMap(&item, configMap.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+		}
+	}
+	return requests
+}
+
+func isRefConfigMap(cr *dapi.DorisCluster, name string) bool {
+	isRef := func(ref *corev1.LocalObjectReference) bool {
+		return ref != nil && ref.Name == name
+	}
+	return (cr.Spec.FE != nil && isRef(cr.Spec.FE.ConfigMapRef)) ||
+		(cr.Spec.BE != nil && isRef(cr.Spec.BE.ConfigMapRef)) ||
+		(cr.Spec.CN != nil && isRef(cr.Spec.CN.ConfigMapRef)) ||
+		(cr.Spec.Broker != nil && isRef(cr.Spec.Broker.ConfigMapRef))
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DorisClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dapi.DorisCluster{}).
 		Owns(&appv1.StatefulSet{}).
+		Watches(&appv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(findClusterByOwnerLabels)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findClustersByRefSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findClustersByRefConfigMap)).
+		WithOptions(controller.Options{RateLimiter: NewRequeueRateLimiter()}).
 		Complete(r)
 }
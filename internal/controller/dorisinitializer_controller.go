@@ -32,6 +32,8 @@ import (
 type DorisInitializerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// FieldManager enables the server-side apply with the field manager when it is set.
+	FieldManager string
 }
 
 //+kubebuilder:rbac:groups=al-assad.github.io,resources=dorisinitializers,verbs=get;list;watch;create;update;patch;delete
@@ -43,6 +45,7 @@ type DorisInitializerReconciler struct {
 
 func (r *DorisInitializerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	recCtx := reconciler.NewReconcileContext(r.Client, r.Scheme, ctx)
+	recCtx.FieldManager = r.FieldManager
 
 	// obtain DorisInitializerReconciler CR and skip reconciling process when it has been deleted
 	cr := &dapi.DorisInitializer{}
@@ -32,6 +32,8 @@ import (
 type DorisMonitorReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// FieldManager enables the server-side apply with the field manager when it is set.
+	FieldManager string
 }
 
 //+kubebuilder:rbac:groups=al-assad.github.io,resources=dorismonitors,verbs=get;list;watch;create;update;patch;delete
@@ -45,9 +47,11 @@ type DorisMonitorReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;bind;escalate
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 func (r *DorisMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	recCtx := reconciler.NewReconcileContext(r.Client, r.Scheme, ctx)
+	recCtx.FieldManager = r.FieldManager
 
 	// obtain CR
 	cr := &dapi.DorisMonitor{}
@@ -21,17 +21,43 @@ package controller
 import (
 	"github.com/al-assad/doris-operator/internal/util"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
 )
 
+// The backoff of requeuing the resource on transient errors, e.g. the API conflict or
+// the components that are not ready yet. The requeue delay starts from RequeueBaseDelay
+// and doubles on each consecutive failure of the same resource up to RequeueMaxDelay,
+// it is reset once the resource has been reconciled without error.
+const (
+	RequeueBaseDelay = 1 * time.Second
+	RequeueMaxDelay  = 5 * time.Minute
+)
+
+// NewRequeueRateLimiter creates the rate limiter of requeuing resource on transient errors.
+func NewRequeueRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(RequeueBaseDelay, RequeueMaxDelay)
+}
+
 // StCtrlErrSet is the standard controller error container
 type StCtrlErrSet struct {
 	Rec    error
 	Sync   error
 	Update error
+	// RecPermanent marks that the Rec error can not be recovered by retrying, which
+	// has been recorded in the status and would not be requeued.
+	RecPermanent bool
+	// RequeueAfter requeues the resource after the duration when there is no error,
+	// it is ignored when it is zero.
+	RequeueAfter time.Duration
 }
 
 func (r *StCtrlErrSet) AsResult() (ctrl.Result, error) {
+	// Skip requeuing the permanent reconcile error, it would be retried once the spec has been changed
+	if r.RecPermanent {
+		r.Rec = nil
+	}
 	// Silent update conflict error
 	updateConflict := false
 	if r.Update != nil && errors.IsConflict(r.Update) {
@@ -53,7 +79,7 @@ func (r *StCtrlErrSet) AsResult() (ctrl.Result, error) {
 		if updateConflict {
 			return ctrl.Result{Requeue: true}, nil
 		} else {
-			return ctrl.Result{}, nil
+			return ctrl.Result{RequeueAfter: r.RequeueAfter}, nil
 		}
 	} else {
 		return ctrl.Result{Requeue: true}, mergedErr
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 @ Linying Assad <linying@apache.org>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+	"time"
+)
+
+func TestStCtrlErrSetAsResult(t *testing.T) {
+	// transient error is requeued
+	errSet := StCtrlErrSet{Rec: errors.New("fe is not ready")}
+	rs, err := errSet.AsResult()
+	assert.Error(t, err)
+	assert.True(t, rs.Requeue)
+
+	// permanent error is not requeued
+	errSet = StCtrlErrSet{Rec: errors.New("invalid spec"), RecPermanent: true}
+	rs, err = errSet.AsResult()
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, rs)
+
+	// other errors are still requeued along with the permanent error
+	errSet = StCtrlErrSet{Rec: errors.New("invalid spec"), RecPermanent: true, Sync: errors.New("boom")}
+	rs, err = errSet.AsResult()
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "invalid spec")
+	assert.True(t, rs.Requeue)
+
+	// update conflict is requeued silently
+	errSet = StCtrlErrSet{Update: apierrors.NewConflict(schema.GroupResource{}, "test", errors.New("conflict"))}
+	rs, err = errSet.AsResult()
+	assert.NoError(t, err)
+	assert.True(t, rs.Requeue)
+
+	// periodical requeue without error
+	errSet = StCtrlErrSet{RequeueAfter: 30 * time.Second}
+	rs, err = errSet.AsResult()
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Second}, rs)
+}
+
+func TestNewRequeueRateLimiter(t *testing.T) {
+	limiter := NewRequeueRateLimiter()
+	assert.Equal(t, RequeueBaseDelay, limiter.When("test"))
+	assert.Equal(t, 2*RequeueBaseDelay, limiter.When("test"))
+	for i := 0; i < 20; i++ {
+		limiter.When("test")
+	}
+	assert.Equal(t, RequeueMaxDelay, limiter.When("test"))
+	limiter.Forget("test")
+	assert.Equal(t, RequeueBaseDelay, limiter.When("test"))
+}
@@ -167,9 +167,7 @@ func (r *DorisDiscovery) recDorisBroker() *RecErr {
 	addBkNames := u.Difference(expectBkNames, actualBkNames)
 	addBkNameHosts := make(map[string]string)
 	for _, name := range addBkNames {
-		addBkNameHosts[name] = fmt.Sprintf(
-			"%s.%s.%s.svc.cluster.local",
-			GetBrokerPodNameByName(name), tran.GetBrokerPeerServiceKey(r.CR.ObjKey()), r.CR.Namespace)
+		addBkNameHosts[name] = tran.GetBrokerPodFQDN(r.CR, GetBrokerPodNameByName(name))
 	}
 	evictBkNames := u.Difference(actualBkNames, expectBkNames)
 
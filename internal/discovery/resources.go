@@ -20,7 +20,6 @@ package discovery
 
 import (
 	"errors"
-	"fmt"
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
 	tran "github.com/al-assad/doris-operator/internal/transformer"
 	u "github.com/rjNemo/underscore"
@@ -53,7 +52,7 @@ func (r *DorisDiscovery) createSqlConnConf() (*DorisSqlConnConf, *RecErr) {
 	}
 	// create sql connection
 	sqlConnConf := DorisSqlConnConf{
-		Host:     tran.GetFeServiceDNS(r.CR.ObjKey()),
+		Host:     tran.GetFeServiceDNS(r.CR),
 		Port:     tran.GetFeQueryPort(r.CR),
 		User:     sqlAcc.User,
 		Password: sqlAcc.Password,
@@ -62,7 +61,7 @@ func (r *DorisDiscovery) createSqlConnConf() (*DorisSqlConnConf, *RecErr) {
 }
 
 func (r *DorisDiscovery) getOprSqlAccount() (SqlAccount, error) {
-	secretRef := tran.GetOprSqlAccountSecretKey(r.CR.ObjKey())
+	secretRef := tran.GetOprSqlAccountSecretRef(r.CR)
 	secret := &corev1.Secret{}
 	exist, err := r.Exist(secretRef, secret)
 	if err != nil {
@@ -72,17 +71,16 @@ func (r *DorisDiscovery) getOprSqlAccount() (SqlAccount, error) {
 		return SqlAccount{}, nil
 	}
 	sqlAccount := SqlAccount{
-		User:     string(secret.Data["user"]),
-		Password: string(secret.Data["password"]),
+		User:     string(secret.Data[tran.OprSqlAccountUserKey]),
+		Password: string(secret.Data[tran.OprSqlAccountPasswordKey]),
 	}
 	return sqlAccount, nil
 }
 
 func GetFeExpectedHosts(cr *dapi.DorisCluster) []string {
-	podNames := tran.GetFeExpectPodNames(cr.ObjKey(), cr.Spec.FE.Replicas)
-	peerSvcName := tran.GetFePeerServiceKey(cr.ObjKey()).Name
+	podNames := tran.GetFeExpectPodNames(cr.ResourceKey(), cr.Spec.FE.Replicas)
 	res := u.Map(podNames, func(podName string) string {
-		return fmt.Sprintf("%s.%s.%s.svc.cluster.local", podName, peerSvcName, cr.Namespace)
+		return tran.GetFePodFQDN(cr, podName)
 	})
 	return res
 }
@@ -91,10 +89,9 @@ func GetBeExpectedHosts(cr *dapi.DorisCluster) []string {
 	if cr.Spec.BE == nil {
 		return []string{}
 	}
-	podNames := tran.GetBeExpectPodNames(cr.ObjKey(), cr.Spec.BE.Replicas)
-	peerSvcName := tran.GetBePeerServiceKey(cr.ObjKey()).Name
+	podNames := tran.GetBeExpectPodNames(cr.ResourceKey(), cr.Spec.BE.Replicas)
 	res := u.Map(podNames, func(podName string) string {
-		return fmt.Sprintf("%s.%s.%s.svc.cluster.local", podName, peerSvcName, cr.Namespace)
+		return tran.GetBePodFQDN(cr, podName)
 	})
 	return res
 }
@@ -103,10 +100,9 @@ func GetCnExpectedHosts(cr *dapi.DorisCluster) []string {
 	if cr.Spec.CN == nil {
 		return []string{}
 	}
-	podNames := tran.GetCnExpectPodNames(cr.ObjKey(), cr.Spec.CN.Replicas)
-	peerSvcName := tran.GetCnPeerServiceKey(cr.ObjKey()).Name
+	podNames := tran.GetCnExpectPodNames(cr.ResourceKey(), cr.Spec.CN.Replicas)
 	res := u.Map(podNames, func(podName string) string {
-		return fmt.Sprintf("%s.%s.%s.svc.cluster.local", podName, peerSvcName, cr.Namespace)
+		return tran.GetCnPodFQDN(cr, podName)
 	})
 	return res
 }
@@ -115,10 +111,9 @@ func GetBrokerExpectedHosts(cr *dapi.DorisCluster) []string {
 	if cr.Spec.Broker == nil {
 		return []string{}
 	}
-	podNames := tran.GetBrokerExpectPodNames(cr.ObjKey(), cr.Spec.Broker.Replicas)
-	peerSvcName := tran.GetBrokerPeerServiceKey(cr.ObjKey()).Name
+	podNames := tran.GetBrokerExpectPodNames(cr.ResourceKey(), cr.Spec.Broker.Replicas)
 	res := u.Map(podNames, func(podName string) string {
-		return fmt.Sprintf("%s.%s.%s.svc.cluster.local", podName, peerSvcName, cr.Namespace)
+		return tran.GetBrokerPodFQDN(cr, podName)
 	})
 	return res
 }
@@ -127,7 +122,7 @@ func GetBrokerExpectedNames(cr *dapi.DorisCluster) []string {
 	if cr.Spec.Broker == nil {
 		return []string{}
 	}
-	podNames := tran.GetBrokerExpectPodNames(cr.ObjKey(), cr.Spec.Broker.Replicas)
+	podNames := tran.GetBrokerExpectPodNames(cr.ResourceKey(), cr.Spec.Broker.Replicas)
 	res := u.Map(podNames, func(podName string) string {
 		return GetBrokerNameByPodName(podName)
 	})
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// BackendHttpConf is the connection configuration of Doris BE webserver port.
+type BackendHttpConf struct {
+	Host     string
+	Port     int32
+	User     string
+	Password string
+}
+
+// UpdateBackendConfig updates the value of the BE config item at runtime via the
+// "/api/update_config" http api of BE, the value is not persisted into be_custom.conf
+// so that be.conf still takes effect after the restart of BE.
+func UpdateBackendConfig(conf BackendHttpConf, key string, value string) error {
+	query := url.Values{}
+	query.Set(key, value)
+	apiUrl := fmt.Sprintf("http://%s:%d/api/update_config?%s", conf.Host, conf.Port, query.Encode())
+	req, err := http.NewRequest(http.MethodPost, apiUrl, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(conf.User, conf.Password)
+	client := &http.Client{Timeout: DefaultConnTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update config %s of BE %s: %w", key, conf.Host, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update config %s of BE %s: %s %s", key, conf.Host, resp.Status, string(body))
+	}
+	// the api responds with the status of each config item, e.g. [{"config_name":"k","status":"OK","msg":""}]
+	var results []struct {
+		Status string `json:"status"`
+		Msg    string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &results); err == nil {
+		for _, result := range results {
+			if result.Status != "OK" {
+				return fmt.Errorf("failed to update config %s of BE %s: %s", key, conf.Host, result.Msg)
+			}
+		}
+	}
+	return nil
+}
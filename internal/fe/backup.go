@@ -0,0 +1,74 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"database/sql"
+	"fmt"
+	ut "github.com/al-assad/doris-operator/internal/util"
+)
+
+// States of the backup job from "show backup"
+const (
+	BackupJobFinished  = "FINISHED"
+	BackupJobCancelled = "CANCELLED"
+)
+
+// BackupJob is the backup job info from "show backup".
+type BackupJob struct {
+	JobId        string
+	SnapshotName string
+	State        string
+	// Status is the error message of the job when it is cancelled.
+	Status string
+}
+
+// BackupSnapshot submits the job backing up all tables of the database to the repository as the snapshot.
+func BackupSnapshot(db *sql.DB, database string, snapshot string, repo string) error {
+	execSql := fmt.Sprintf("backup snapshot `%s`.`%s` to `%s`", database, snapshot, repo)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// ShowBackup returns the backup jobs of the database.
+func ShowBackup(db *sql.DB, database string) ([]BackupJob, error) {
+	execSql := fmt.Sprintf("show backup from `%s`", database)
+	rows, err := db.Query(execSql)
+	if err != nil {
+		return nil, ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	defer rows.Close()
+
+	var jobs []BackupJob
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rowMaps {
+		jobs = append(jobs, BackupJob{
+			JobId:        row["JobId"],
+			SnapshotName: row["SnapshotName"],
+			State:        row["State"],
+			Status:       row["Status"],
+		})
+	}
+	return jobs, nil
+}
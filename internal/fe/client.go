@@ -0,0 +1,294 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	ut "github.com/al-assad/doris-operator/internal/util"
+	_ "github.com/go-sql-driver/mysql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultConnTimeout = 5 * time.Second
+
+// ConnConf is the connection configuration of Doris FE query port.
+type ConnConf struct {
+	Host     string
+	Port     int32
+	User     string
+	Password string
+}
+
+func (e *ConnConf) HostPort() string {
+	return fmt.Sprintf("%s:%d", e.Host, e.Port)
+}
+
+func (e *ConnConf) Connect() (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/?timeout=%s&readTimeout=%s&writeTimeout=%s",
+		e.User, e.Password, e.Host, e.Port, DefaultConnTimeout, DefaultConnTimeout, DefaultConnTimeout)
+	return sql.Open("mysql", dsn)
+}
+
+// FE roles of the frontend in Doris cluster
+const (
+	FrontendRoleFollower = "FOLLOWER"
+	FrontendRoleObserver = "OBSERVER"
+)
+
+// Frontend is the FE node info from "show frontends".
+type Frontend struct {
+	Name     string
+	Host     string
+	Role     string
+	IsMaster bool
+	Alive    bool
+}
+
+// ShowFrontends returns the FE nodes of the Doris cluster.
+func ShowFrontends(db *sql.DB) ([]Frontend, error) {
+	rows, err := db.Query("show frontends")
+	if err != nil {
+		return nil, ut.MergeErrors(errors.New("failed to execute sql 'show frontends'"), err)
+	}
+	defer rows.Close()
+
+	var frontends []Frontend
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rowMaps {
+		frontends = append(frontends, Frontend{
+			Name:     row["Name"],
+			Host:     row["Host"],
+			Role:     row["Role"],
+			IsMaster: isTrue(row["IsMaster"]),
+			Alive:    isTrue(row["Alive"]),
+		})
+	}
+	return frontends, nil
+}
+
+func isTrue(value string) bool {
+	return strings.EqualFold(value, "true")
+}
+
+// reads all rows from sql.Rows and returns a slice of column-value map
+func readAllRowsAsString(rows *sql.Rows) ([]map[string]string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, ut.MergeErrors(errors.New("failed to read the columns of sql result"), err)
+	}
+	var rowMaps []map[string]string
+
+	for rows.Next() {
+		columns := make([]sql.RawBytes, len(cols))
+		columnPointers := make([]any, len(cols))
+		for i := range columns {
+			columnPointers[i] = &columns[i]
+		}
+		if err := rows.Scan(columnPointers...); err != nil {
+			return nil, ut.MergeErrors(errors.New("failed to scan the row of sql result"), err)
+		}
+		m := make(map[string]string)
+		for i, colName := range cols {
+			m[colName] = string(columns[i])
+		}
+		rowMaps = append(rowMaps, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ut.MergeErrors(errors.New("failed to read the rows of sql result"), err)
+	}
+	return rowMaps, nil
+}
+
+// Backend is the BE node info from "show backends".
+type Backend struct {
+	BackendId            string
+	Host                 string
+	HeartbeatPort        string
+	Alive                bool
+	SystemDecommissioned bool
+	TabletNum            int64
+}
+
+// ShowBackends returns the BE nodes of the Doris cluster.
+func ShowBackends(db *sql.DB) ([]Backend, error) {
+	rows, err := db.Query("show backends")
+	if err != nil {
+		return nil, ut.MergeErrors(errors.New("failed to execute sql 'show backends'"), err)
+	}
+	defer rows.Close()
+
+	var backends []Backend
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rowMaps {
+		tabletNum, _ := strconv.ParseInt(row["TabletNum"], 10, 64)
+		backends = append(backends, Backend{
+			BackendId:            row["BackendId"],
+			Host:                 row["Host"],
+			HeartbeatPort:        row["HeartbeatPort"],
+			Alive:                isTrue(row["Alive"]),
+			SystemDecommissioned: isTrue(row["SystemDecommissioned"]),
+			TabletNum:            tabletNum,
+		})
+	}
+	return backends, nil
+}
+
+// ShowUnhealthyTabletNum returns the number of tablets that are not healthy in the Doris
+// cluster, e.g. the tablets with missing replicas or incomplete versions.
+func ShowUnhealthyTabletNum(db *sql.DB) (int64, error) {
+	rows, err := db.Query("show proc '/cluster_health/tablet_health'")
+	if err != nil {
+		return 0, ut.MergeErrors(errors.New("failed to execute sql \"show proc '/cluster_health/tablet_health'\""), err)
+	}
+	defer rows.Close()
+
+	var total, dbSum int64
+	hasTotal := false
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rowMaps {
+		tabletNum, _ := strconv.ParseInt(row["TabletNum"], 10, 64)
+		healthyNum, _ := strconv.ParseInt(row["HealthyNum"], 10, 64)
+		// the summary row of all databases
+		if strings.EqualFold(row["DbId"], "Total") {
+			total = tabletNum - healthyNum
+			hasTotal = true
+			continue
+		}
+		dbSum += tabletNum - healthyNum
+	}
+	if hasTotal {
+		return total, nil
+	}
+	return dbSum, nil
+}
+
+// DecommissionBackend decommissions the BE node, Doris would drop the BE node
+// after all tablets on it have been migrated.
+func DecommissionBackend(db *sql.DB, beHostPort string) error {
+	execSql := fmt.Sprintf(`alter system decommission backend "%s"`, beHostPort)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// CancelDecommissionBackend cancels the decommission of the BE node that has not been dropped yet.
+func CancelDecommissionBackend(db *sql.DB, beHostPort string) error {
+	execSql := fmt.Sprintf(`cancel decommission backend "%s"`, beHostPort)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// ForceDropBackend drops the BE node from the Doris cluster immediately without migrating
+// the tablets on it, the tablets without other replicas would be lost.
+func ForceDropBackend(db *sql.DB, beHostPort string) error {
+	execSql := fmt.Sprintf(`alter system dropp backend "%s"`, beHostPort)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// AddBackend adds the BE node to the Doris cluster.
+func AddBackend(db *sql.DB, beHostPort string) error {
+	execSql := fmt.Sprintf(`alter system add backend "%s"`, beHostPort)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// SetPassword sets the password of the Doris user, the password would not be
+// exposed in the error message.
+func SetPassword(db *sql.DB, user string, password string) error {
+	execSql := fmt.Sprintf(`set password for '%s' = password('%s')`, user, password)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to set password for user '%s'", user), err)
+	}
+	return nil
+}
+
+// CreateAdminUser creates the user with the NODE_PRIV and ADMIN_PRIV privileges like the operator
+// SQL account, the password of the existing user is reset. The password would not be exposed in
+// the error message.
+func CreateAdminUser(db *sql.DB, user string, password string) error {
+	for _, execSql := range []string{
+		fmt.Sprintf(`create user if not exists '%s' identified by '%s'`, user, password),
+		fmt.Sprintf(`set password for '%s' = password('%s')`, user, password),
+		fmt.Sprintf(`grant node_priv, admin_priv on *.*.* to '%s'`, user),
+	} {
+		if _, err := db.Exec(execSql); err != nil {
+			return ut.MergeErrors(fmt.Errorf("failed to create admin user '%s'", user), err)
+		}
+	}
+	return nil
+}
+
+// ShowFrontendConfig returns the value of the FE config item.
+func ShowFrontendConfig(db *sql.DB, key string) (string, error) {
+	execSql := fmt.Sprintf(`admin show frontend config like "%s"`, key)
+	rows, err := db.Query(execSql)
+	if err != nil {
+		return "", ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	defer rows.Close()
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return "", err
+	}
+	for _, row := range rowMaps {
+		if row["Key"] == key {
+			return row["Value"], nil
+		}
+	}
+	return "", fmt.Errorf("FE config %s not found", key)
+}
+
+// SetFrontendConfig sets the value of the FE config item.
+func SetFrontendConfig(db *sql.DB, key string, value string) error {
+	execSql := fmt.Sprintf(`admin set frontend config (%s = %s)`, quoteSqlString(key), quoteSqlString(value))
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// SetAllFrontendsConfig sets the value of the FE config item on all FE nodes.
+func SetAllFrontendsConfig(db *sql.DB, key string, value string) error {
+	execSql := fmt.Sprintf(`admin set all frontends config (%s = %s)`, quoteSqlString(key), quoteSqlString(value))
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
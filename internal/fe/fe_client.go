@@ -0,0 +1,215 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// The connection pool settings of the FE client, the connections are reused by the
+// statements issued through the same client and recycled periodically, so that the
+// client survives the restart of the FE behind the service.
+const (
+	DefaultMaxOpenConns    = 4
+	DefaultMaxIdleConns    = 2
+	DefaultConnMaxLifetime = 5 * time.Minute
+)
+
+// Client is the typed access to Doris FE via the MySQL protocol.
+type Client interface {
+	// Ping verifies the connection and the credentials of the client.
+	Ping() error
+	ShowFrontends() ([]Frontend, error)
+	ShowBackends() ([]Backend, error)
+	// ShowUnhealthyTabletNum returns the number of unhealthy tablets in the Doris cluster.
+	ShowUnhealthyTabletNum() (int64, error)
+	DecommissionBackend(beHostPort string) error
+	CancelDecommissionBackend(beHostPort string) error
+	// ForceDropBackend drops the BE node without migrating the tablets on it.
+	ForceDropBackend(beHostPort string) error
+	AddBackend(beHostPort string) error
+	// ShowConfig returns the value of the config item of the FE that the client connects to.
+	ShowConfig(key string) (string, error)
+	// SetConfig sets the config item of the FE that the client connects to.
+	SetConfig(key string, value string) error
+	// SetAllConfig sets the config item of all FE nodes.
+	SetAllConfig(key string, value string) error
+	SetPassword(user string, password string) error
+	// CreateAdminUser creates the user with the privileges of the operator SQL account.
+	CreateAdminUser(user string, password string) error
+	ExecScript(script string) error
+	ShowRepositories() ([]string, error)
+	CreateRepository(repo Repository) error
+	DropRepository(name string) error
+	// RestoreSnapshot submits the job restoring the snapshot of the database from the repository.
+	RestoreSnapshot(database string, snapshot string, repo string, properties map[string]string) error
+	ShowRestore(database string) ([]RestoreJob, error)
+	// BackupSnapshot submits the job backing up the database to the repository as the snapshot.
+	BackupSnapshot(database string, snapshot string, repo string) error
+	ShowBackup(database string) ([]BackupJob, error)
+	// Close releases the client, the connection pool shared with the other clients
+	// of the same FE endpoint is kept open.
+	Close() error
+}
+
+// ClientFactory creates the FE client with the connection configuration,
+// which allows replacing the client with a test double.
+type ClientFactory func(conf ConnConf) (Client, error)
+
+// NewClient creates the FE client backed by the connection pool of the FE endpoint,
+// the connection is established lazily on the first statement.
+func NewClient(conf ConnConf) (Client, error) {
+	db, err := connPools.get(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlClient{db: db}, nil
+}
+
+// the connection pools shared by the FE clients of the same endpoint.
+var connPools = &connPoolCache{pools: make(map[string]*connPool)}
+
+type connPool struct {
+	conf ConnConf
+	db   *sql.DB
+}
+
+// connPoolCache caches one connection pool per FE endpoint, the pool is replaced
+// once the credentials of the endpoint have been changed.
+type connPoolCache struct {
+	mu    sync.Mutex
+	pools map[string]*connPool
+}
+
+func (c *connPoolCache) get(conf ConnConf) (*sql.DB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	endpoint := conf.HostPort()
+	if pool, ok := c.pools[endpoint]; ok {
+		if pool.conf == conf {
+			return pool.db, nil
+		}
+		_ = pool.db.Close()
+		delete(c.pools, endpoint)
+	}
+	db, err := conf.Connect()
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(DefaultMaxOpenConns)
+	db.SetMaxIdleConns(DefaultMaxIdleConns)
+	db.SetConnMaxLifetime(DefaultConnMaxLifetime)
+	c.pools[endpoint] = &connPool{conf: conf, db: db}
+	return db, nil
+}
+
+type sqlClient struct {
+	db *sql.DB
+}
+
+func (c *sqlClient) Ping() error {
+	return c.db.Ping()
+}
+
+func (c *sqlClient) ShowFrontends() ([]Frontend, error) {
+	return ShowFrontends(c.db)
+}
+
+func (c *sqlClient) ShowBackends() ([]Backend, error) {
+	return ShowBackends(c.db)
+}
+
+func (c *sqlClient) ShowUnhealthyTabletNum() (int64, error) {
+	return ShowUnhealthyTabletNum(c.db)
+}
+
+func (c *sqlClient) DecommissionBackend(beHostPort string) error {
+	return DecommissionBackend(c.db, beHostPort)
+}
+
+func (c *sqlClient) CancelDecommissionBackend(beHostPort string) error {
+	return CancelDecommissionBackend(c.db, beHostPort)
+}
+
+func (c *sqlClient) ForceDropBackend(beHostPort string) error {
+	return ForceDropBackend(c.db, beHostPort)
+}
+
+func (c *sqlClient) AddBackend(beHostPort string) error {
+	return AddBackend(c.db, beHostPort)
+}
+
+func (c *sqlClient) ShowConfig(key string) (string, error) {
+	return ShowFrontendConfig(c.db, key)
+}
+
+func (c *sqlClient) SetConfig(key string, value string) error {
+	return SetFrontendConfig(c.db, key, value)
+}
+
+func (c *sqlClient) SetAllConfig(key string, value string) error {
+	return SetAllFrontendsConfig(c.db, key, value)
+}
+
+func (c *sqlClient) SetPassword(user string, password string) error {
+	return SetPassword(c.db, user, password)
+}
+
+func (c *sqlClient) CreateAdminUser(user string, password string) error {
+	return CreateAdminUser(c.db, user, password)
+}
+
+func (c *sqlClient) ExecScript(script string) error {
+	return ExecSqlScript(c.db, script)
+}
+
+func (c *sqlClient) ShowRepositories() ([]string, error) {
+	return ShowRepositories(c.db)
+}
+
+func (c *sqlClient) CreateRepository(repo Repository) error {
+	return CreateRepository(c.db, repo)
+}
+
+func (c *sqlClient) DropRepository(name string) error {
+	return DropRepository(c.db, name)
+}
+
+func (c *sqlClient) RestoreSnapshot(database string, snapshot string, repo string, properties map[string]string) error {
+	return RestoreSnapshot(c.db, database, snapshot, repo, properties)
+}
+
+func (c *sqlClient) ShowRestore(database string) ([]RestoreJob, error) {
+	return ShowRestore(c.db, database)
+}
+
+func (c *sqlClient) BackupSnapshot(database string, snapshot string, repo string) error {
+	return BackupSnapshot(c.db, database, snapshot, repo)
+}
+
+func (c *sqlClient) ShowBackup(database string) ([]BackupJob, error) {
+	return ShowBackup(c.db, database)
+}
+
+// Close leaves the connection pool to the later clients of the same FE endpoint.
+func (c *sqlClient) Close() error {
+	return nil
+}
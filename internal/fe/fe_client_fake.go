@@ -0,0 +1,313 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeClient is an in-memory test double of Client, which serves the frontends, backends
+// and configs it holds and records the write operations, so that the code talking to FE
+// can be unit-tested without a live Doris cluster.
+type FakeClient struct {
+	mu        sync.Mutex
+	Frontends []Frontend
+	Backends  []Backend
+	Configs   map[string]string
+	// UnhealthyTablets is the number of unhealthy tablets served by ShowUnhealthyTabletNum.
+	UnhealthyTablets int64
+	// Passwords are the passwords set by SetPassword, keyed by user.
+	Passwords map[string]string
+	// Decommissioned are the "host:heartbeat_port" of the decommissioned backends.
+	Decommissioned []string
+	// Dropped are the "host:heartbeat_port" of the force dropped backends.
+	Dropped []string
+	// Added are the "host:heartbeat_port" of the added backends.
+	Added []string
+	// Scripts are the executed SQL scripts.
+	Scripts []string
+	// Repositories are the backup repositories, keyed by name.
+	Repositories map[string]Repository
+	// RestoreJobs are the submitted restore jobs, keyed by database.
+	RestoreJobs map[string][]RestoreJob
+	// BackupJobs are the submitted backup jobs, keyed by database.
+	BackupJobs map[string][]BackupJob
+	// Err is returned by all the operations when it is set.
+	Err    error
+	Closed bool
+}
+
+var _ Client = &FakeClient{}
+
+// Factory returns the ClientFactory that always serves the FakeClient.
+func (c *FakeClient) Factory() ClientFactory {
+	return func(_ ConnConf) (Client, error) {
+		return c, nil
+	}
+}
+
+func (c *FakeClient) Ping() error {
+	return c.Err
+}
+
+func (c *FakeClient) ShowFrontends() ([]Frontend, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Frontend(nil), c.Frontends...), c.Err
+}
+
+func (c *FakeClient) ShowBackends() ([]Backend, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Backend(nil), c.Backends...), c.Err
+}
+
+func (c *FakeClient) ShowUnhealthyTabletNum() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.UnhealthyTablets, c.Err
+}
+
+// DecommissionBackend marks the matched backend as decommissioned.
+func (c *FakeClient) DecommissionBackend(beHostPort string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	for i := range c.Backends {
+		if c.Backends[i].Host == hostOf(beHostPort) {
+			c.Backends[i].SystemDecommissioned = true
+		}
+	}
+	c.Decommissioned = append(c.Decommissioned, beHostPort)
+	return nil
+}
+
+// CancelDecommissionBackend clears the decommissioned mark of the matched backend.
+func (c *FakeClient) CancelDecommissionBackend(beHostPort string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	for i := range c.Backends {
+		if c.Backends[i].Host == hostOf(beHostPort) {
+			c.Backends[i].SystemDecommissioned = false
+		}
+	}
+	return nil
+}
+
+// ForceDropBackend removes the matched backend.
+func (c *FakeClient) ForceDropBackend(beHostPort string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	var backends []Backend
+	for _, backend := range c.Backends {
+		if backend.Host != hostOf(beHostPort) {
+			backends = append(backends, backend)
+		}
+	}
+	c.Backends = backends
+	c.Dropped = append(c.Dropped, beHostPort)
+	return nil
+}
+
+// AddBackend appends an alive backend of the host.
+func (c *FakeClient) AddBackend(beHostPort string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	c.Backends = append(c.Backends, Backend{Host: hostOf(beHostPort), Alive: true})
+	c.Added = append(c.Added, beHostPort)
+	return nil
+}
+
+func (c *FakeClient) ShowConfig(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return "", c.Err
+	}
+	value, found := c.Configs[key]
+	if !found {
+		return "", fmt.Errorf("FE config %s not found", key)
+	}
+	return value, nil
+}
+
+func (c *FakeClient) SetConfig(key string, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	if c.Configs == nil {
+		c.Configs = make(map[string]string)
+	}
+	c.Configs[key] = value
+	return nil
+}
+
+func (c *FakeClient) SetAllConfig(key string, value string) error {
+	return c.SetConfig(key, value)
+}
+
+func (c *FakeClient) SetPassword(user string, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	if c.Passwords == nil {
+		c.Passwords = make(map[string]string)
+	}
+	c.Passwords[user] = password
+	return nil
+}
+
+// CreateAdminUser records the password of the user in Passwords.
+func (c *FakeClient) CreateAdminUser(user string, password string) error {
+	return c.SetPassword(user, password)
+}
+
+func (c *FakeClient) ExecScript(script string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	c.Scripts = append(c.Scripts, script)
+	return nil
+}
+
+func (c *FakeClient) ShowRepositories() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	var names []string
+	for name := range c.Repositories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *FakeClient) CreateRepository(repo Repository) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	if _, exist := c.Repositories[repo.Name]; exist {
+		return fmt.Errorf("repository %s already exists", repo.Name)
+	}
+	if c.Repositories == nil {
+		c.Repositories = make(map[string]Repository)
+	}
+	c.Repositories[repo.Name] = repo
+	return nil
+}
+
+func (c *FakeClient) DropRepository(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	delete(c.Repositories, name)
+	return nil
+}
+
+// RestoreSnapshot appends a pending restore job of the snapshot to the database,
+// the state of which can be advanced by modifying RestoreJobs.
+func (c *FakeClient) RestoreSnapshot(database string, snapshot string, repo string, _ map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	if _, exist := c.Repositories[repo]; !exist {
+		return fmt.Errorf("repository %s does not exist", repo)
+	}
+	if c.RestoreJobs == nil {
+		c.RestoreJobs = make(map[string][]RestoreJob)
+	}
+	jobId := strconv.Itoa(10000 + len(c.RestoreJobs[database]))
+	c.RestoreJobs[database] = append(c.RestoreJobs[database], RestoreJob{JobId: jobId, Label: snapshot, State: "PENDING"})
+	return nil
+}
+
+func (c *FakeClient) ShowRestore(database string) ([]RestoreJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]RestoreJob(nil), c.RestoreJobs[database]...), c.Err
+}
+
+// BackupSnapshot appends a pending backup job of the snapshot to the database,
+// the state of which can be advanced by modifying BackupJobs.
+func (c *FakeClient) BackupSnapshot(database string, snapshot string, repo string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Err != nil {
+		return c.Err
+	}
+	if _, exist := c.Repositories[repo]; !exist {
+		return fmt.Errorf("repository %s does not exist", repo)
+	}
+	if c.BackupJobs == nil {
+		c.BackupJobs = make(map[string][]BackupJob)
+	}
+	jobId := strconv.Itoa(20000 + len(c.BackupJobs[database]))
+	c.BackupJobs[database] = append(c.BackupJobs[database], BackupJob{JobId: jobId, SnapshotName: snapshot, State: "PENDING"})
+	return nil
+}
+
+func (c *FakeClient) ShowBackup(database string) ([]BackupJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]BackupJob(nil), c.BackupJobs[database]...), c.Err
+}
+
+func (c *FakeClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Closed = true
+	return nil
+}
+
+// get the host of the "host:port" address.
+func hostOf(hostPort string) string {
+	if idx := strings.LastIndex(hostPort, ":"); idx >= 0 {
+		return hostPort[:idx]
+	}
+	return hostPort
+}
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewClient(t *testing.T) {
+	cli, err := NewClient(ConnConf{Host: "127.0.0.1", Port: 9030, User: "root"})
+	assert.NoError(t, err)
+	defer cli.Close()
+	assert.Equal(t, DefaultMaxOpenConns, cli.(*sqlClient).db.Stats().MaxOpenConnections)
+
+	// the connection pool is reused by the clients of the same endpoint and credentials
+	assert.NoError(t, cli.Close())
+	reused, err := NewClient(ConnConf{Host: "127.0.0.1", Port: 9030, User: "root"})
+	assert.NoError(t, err)
+	assert.Same(t, cli.(*sqlClient).db, reused.(*sqlClient).db)
+
+	// and replaced once the credentials have been changed
+	replaced, err := NewClient(ConnConf{Host: "127.0.0.1", Port: 9030, User: "root", Password: "pwd"})
+	assert.NoError(t, err)
+	assert.NotSame(t, cli.(*sqlClient).db, replaced.(*sqlClient).db)
+	assert.ErrorContains(t, cli.Ping(), "sql: database is closed")
+}
+
+func TestFakeClient(t *testing.T) {
+	fake := &FakeClient{
+		Backends: []Backend{{BackendId: "10001", Host: "test-be-0.test-be-peer"}, {BackendId: "10002", Host: "test-be-1.test-be-peer"}},
+		Configs:  map[string]string{"disable_balance": "false"},
+	}
+	cli, err := fake.Factory()(ConnConf{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, cli.DecommissionBackend("test-be-1.test-be-peer:9050"))
+	backends, err := cli.ShowBackends()
+	assert.NoError(t, err)
+	assert.False(t, backends[0].SystemDecommissioned)
+	assert.True(t, backends[1].SystemDecommissioned)
+	assert.Equal(t, []string{"test-be-1.test-be-peer:9050"}, fake.Decommissioned)
+	assert.NoError(t, cli.CancelDecommissionBackend("test-be-1.test-be-peer:9050"))
+	assert.False(t, fake.Backends[1].SystemDecommissioned)
+	assert.NoError(t, cli.AddBackend("test-be-2.test-be-peer:9050"))
+	assert.Equal(t, "test-be-2.test-be-peer", fake.Backends[2].Host)
+	assert.Equal(t, []string{"test-be-2.test-be-peer:9050"}, fake.Added)
+
+	assert.NoError(t, cli.SetConfig("disable_balance", "true"))
+	value, err := cli.ShowConfig("disable_balance")
+	assert.NoError(t, err)
+	assert.Equal(t, "true", value)
+	_, err = cli.ShowConfig("unknown")
+	assert.ErrorContains(t, err, "FE config unknown not found")
+
+	assert.NoError(t, cli.ExecScript("create database demo;"))
+	assert.Equal(t, []string{"create database demo;"}, fake.Scripts)
+	assert.NoError(t, cli.Close())
+	assert.True(t, fake.Closed)
+
+	// all operations fail with the injected error
+	fake.Err = errors.New("connection refused")
+	assert.Error(t, cli.Ping())
+	_, err = cli.ShowFrontends()
+	assert.Error(t, err)
+	assert.Error(t, cli.SetPassword("root", "pwd"))
+}
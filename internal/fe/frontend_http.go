@@ -0,0 +1,90 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// FrontendHttpConf is the connection configuration of Doris FE http port.
+type FrontendHttpConf struct {
+	Host     string
+	Port     int32
+	User     string
+	Password string
+}
+
+// ShowFrontendsViaHttp returns the FE nodes of the Doris cluster via the "/api/show_proc" http
+// api of FE, which does not occupy the connections of the FE query port.
+func ShowFrontendsViaHttp(conf FrontendHttpConf) ([]Frontend, error) {
+	query := url.Values{}
+	query.Set("path", "/frontends")
+	apiUrl := fmt.Sprintf("http://%s:%d/api/show_proc?%s", conf.Host, conf.Port, query.Encode())
+	req, err := http.NewRequest(http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(conf.User, conf.Password)
+	client := &http.Client{Timeout: DefaultConnTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to show frontends via FE %s: %w", conf.Host, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to show frontends via FE %s: %w", conf.Host, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to show frontends via FE %s: %s %s", conf.Host, resp.Status, string(body))
+	}
+	// the api responds with the rows of proc, e.g. {"msg":"success","code":0,"data":[{"Name":"fe1","Host":"h1",...}]}
+	var result struct {
+		Msg  string           `json:"msg"`
+		Code int              `json:"code"`
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse the frontends from FE %s: %w", conf.Host, err)
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("failed to show frontends via FE %s: %s", conf.Host, result.Msg)
+	}
+	var frontends []Frontend
+	for _, row := range result.Data {
+		value := func(key string) string {
+			if v, ok := row[key]; ok && v != nil {
+				return fmt.Sprint(v)
+			}
+			return ""
+		}
+		frontends = append(frontends, Frontend{
+			Name:     value("Name"),
+			Host:     value("Host"),
+			Role:     value("Role"),
+			IsMaster: isTrue(value("IsMaster")),
+			Alive:    isTrue(value("Alive")),
+		})
+	}
+	return frontends, nil
+}
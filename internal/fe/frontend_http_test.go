@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestShowFrontendsViaHttp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if user != "root" || password != "pwd" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "/api/show_proc", r.URL.Path)
+		assert.Equal(t, "/frontends", r.URL.Query().Get("path"))
+		_, _ = w.Write([]byte(`{"msg":"success","code":0,"data":[
+			{"Name":"fe1","Host":"fe-0.fe-peer","Role":"FOLLOWER","IsMaster":"true","Alive":"true"},
+			{"Name":"fe2","Host":"fe-1.fe-peer","Role":"OBSERVER","IsMaster":"false","Alive":"false"}]}`))
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	conf := FrontendHttpConf{Host: host, Port: int32(port), User: "root", Password: "pwd"}
+
+	frontends, err := ShowFrontendsViaHttp(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, []Frontend{
+		{Name: "fe1", Host: "fe-0.fe-peer", Role: FrontendRoleFollower, IsMaster: true, Alive: true},
+		{Name: "fe2", Host: "fe-1.fe-peer", Role: FrontendRoleObserver},
+	}, frontends)
+
+	conf.Password = "wrong"
+	_, err = ShowFrontendsViaHttp(conf)
+	assert.ErrorContains(t, err, "401")
+}
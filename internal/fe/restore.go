@@ -0,0 +1,155 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	ut "github.com/al-assad/doris-operator/internal/util"
+	"sort"
+	"strings"
+)
+
+// Storage types of the Doris backup repository
+const (
+	RepositoryTypeS3   = "S3"
+	RepositoryTypeHdfs = "HDFS"
+)
+
+// Repository is the Doris backup repository where the snapshots are stored.
+type Repository struct {
+	Name       string
+	Type       string
+	Location   string
+	Properties map[string]string
+	// ReadOnly repository only allows restoring from it.
+	ReadOnly bool
+}
+
+// States of the restore job from "show restore"
+const (
+	RestoreJobFinished  = "FINISHED"
+	RestoreJobCancelled = "CANCELLED"
+)
+
+// RestoreJob is the restore job info from "show restore".
+type RestoreJob struct {
+	JobId string
+	// Label is the name of the snapshot being restored.
+	Label string
+	State string
+	// Status is the error message of the job when it is cancelled.
+	Status string
+}
+
+// ShowRepositories returns the names of the backup repositories.
+func ShowRepositories(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("show repositories")
+	if err != nil {
+		return nil, ut.MergeErrors(errors.New("failed to execute sql 'show repositories'"), err)
+	}
+	defer rows.Close()
+
+	var names []string
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rowMaps {
+		names = append(names, row["RepoName"])
+	}
+	return names, nil
+}
+
+// CreateRepository creates the backup repository, the properties of the repository would
+// not be exposed in the error message since they usually contain the credentials.
+func CreateRepository(db *sql.DB, repo Repository) error {
+	execSql := fmt.Sprintf("create %srepository `%s` with %s on location %s properties (%s)",
+		ut.Elvis(repo.ReadOnly, "read only ", ""), repo.Name, strings.ToLower(repo.Type),
+		quoteSqlString(repo.Location), formatSqlProperties(repo.Properties))
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to create repository '%s'", repo.Name), err)
+	}
+	return nil
+}
+
+// DropRepository drops the backup repository, the snapshots in the repository are retained.
+func DropRepository(db *sql.DB, name string) error {
+	execSql := fmt.Sprintf("drop repository `%s`", name)
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// RestoreSnapshot submits the job restoring the snapshot of the database from the repository,
+// the properties must contain the "backup_timestamp" of the snapshot.
+func RestoreSnapshot(db *sql.DB, database string, snapshot string, repo string, properties map[string]string) error {
+	execSql := fmt.Sprintf("restore snapshot `%s`.`%s` from `%s` properties (%s)",
+		database, snapshot, repo, formatSqlProperties(properties))
+	if _, err := db.Exec(execSql); err != nil {
+		return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	return nil
+}
+
+// ShowRestore returns the restore jobs of the database.
+func ShowRestore(db *sql.DB, database string) ([]RestoreJob, error) {
+	execSql := fmt.Sprintf("show restore from `%s`", database)
+	rows, err := db.Query(execSql)
+	if err != nil {
+		return nil, ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", execSql), err)
+	}
+	defer rows.Close()
+
+	var jobs []RestoreJob
+	rowMaps, err := readAllRowsAsString(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rowMaps {
+		jobs = append(jobs, RestoreJob{
+			JobId:  row["JobId"],
+			Label:  row["Label"],
+			State:  row["State"],
+			Status: row["Status"],
+		})
+	}
+	return jobs, nil
+}
+
+// format the properties like `"k1" = "v1", "k2" = "v2"` in the order of keys.
+func formatSqlProperties(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("%s = %s", quoteSqlString(key), quoteSqlString(properties[key])))
+	}
+	return strings.Join(items, ", ")
+}
+
+// quote the string literal with double quotes, escaping the backslashes and double quotes.
+func quoteSqlString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
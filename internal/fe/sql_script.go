@@ -0,0 +1,92 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"database/sql"
+	"fmt"
+	ut "github.com/al-assad/doris-operator/internal/util"
+	"strings"
+	"unicode"
+)
+
+// SplitSqlStatements splits the SQL script into statements by the semicolons, the semicolons
+// within the quoted strings or identifiers are retained, and the comments are removed.
+func SplitSqlStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	runes := []rune(script)
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if quote != 0 {
+			current.WriteRune(c)
+			if c == '\\' && quote != '`' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteRune(c)
+		case c == '#' || isDoubleDashComment(runes, i):
+			// skip the line comment
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case c == ';':
+			flush()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// the "--" starts a comment only when it is followed by a whitespace or the end of
+// script, e.g. "1--1" is an arithmetic expression rather than a comment.
+func isDoubleDashComment(runes []rune, i int) bool {
+	if runes[i] != '-' || i+1 >= len(runes) || runes[i+1] != '-' {
+		return false
+	}
+	return i+2 >= len(runes) || unicode.IsSpace(runes[i+2])
+}
+
+// ExecSqlScript executes the statements of the SQL script in order,
+// and stops at the first failed statement.
+func ExecSqlScript(db *sql.DB, script string) error {
+	for _, stmt := range SplitSqlStatements(script) {
+		if _, err := db.Exec(stmt); err != nil {
+			return ut.MergeErrors(fmt.Errorf("failed to execute sql '%s'", stmt), err)
+		}
+	}
+	return nil
+}
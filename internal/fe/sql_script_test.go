@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package fe
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitSqlStatements(t *testing.T) {
+	script := `
+-- create the demo database
+create database if not exists demo;
+create user if not exists 'app'@'%' identified by 'pa;ss\'wd'; # inline comment
+grant select_priv on demo.* to 'app'@'%';
+create table demo.t (` + "`a;b`" + ` int) distributed by hash(` + "`a;b`" + `) properties ("replication_num" = "1")
+`
+	assert.Equal(t, []string{
+		"create database if not exists demo",
+		`create user if not exists 'app'@'%' identified by 'pa;ss\'wd'`,
+		"grant select_priv on demo.* to 'app'@'%'",
+		"create table demo.t (`a;b` int) distributed by hash(`a;b`) properties (\"replication_num\" = \"1\")",
+	}, SplitSqlStatements(script))
+	assert.Empty(t, SplitSqlStatements(" ;\n-- comment only\n;"))
+	// "--" without the following whitespace is not a comment
+	assert.Equal(t, []string{"select 1--1", "select 2"}, SplitSqlStatements("select 1--1;\nselect 2;--\n--\tcomment"))
+}
+
+func TestQuoteSqlString(t *testing.T) {
+	assert.Equal(t, `"3000"`, quoteSqlString("3000"))
+	assert.Equal(t, `"a\"); drop user \\x"`, quoteSqlString(`a"); drop user \x`))
+}
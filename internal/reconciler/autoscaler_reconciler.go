@@ -78,6 +78,9 @@ func (r *DorisAutoScalerReconciler) Reconcile() (dapi.AutoscalerRecStatus, error
 				fmt.Sprintf("target DorisCluster already bound another DorisAutoscaler[name=%s][namespace=%s]",
 					bound.Name, bound.Name))
 		}
+		if err := tran.ValidateDorisAutoscaler(r.CR); err != nil {
+			return err
+		}
 		// apply hpa resources
 		if cnUpHpa := tran.MakeCnScaleUpHpa(r.CR, r.Schema); cnUpHpa != nil {
 			if err := r.CreateOrUpdate(cnUpHpa, &acv2.HorizontalPodAutoscaler{}); err != nil {
@@ -0,0 +1,212 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"time"
+)
+
+const (
+	// BackupCheckInterval is the interval of checking the progress of the running backup.
+	BackupCheckInterval = 30 * time.Second
+	// DefaultBackupRetention is the default number of the successful backups kept in status.
+	DefaultBackupRetention = 7
+)
+
+// RecBackup schedules the backups of spec.backup and tracks the running one, returns the duration
+// after which the DorisCluster should be reconciled again to check the backup, or zero when there
+// is no backup to wait for. The failure of backup is reported by the events and the BackupSucceeded
+// condition instead of the error, which would not be retried until the next schedule.
+func (r *DorisClusterReconciler) RecBackup() (time.Duration, error) {
+	return r.recBackupAt(time.Now())
+}
+
+func (r *DorisClusterReconciler) recBackupAt(now time.Time) (time.Duration, error) {
+	spec := r.CR.Spec.Backup
+	if spec == nil {
+		return 0, nil
+	}
+	status := util.PointerDeRefer(r.CR.Status.Backup.DeepCopy(), dapi.BackupStatus{})
+	defer func() {
+		r.CR.Status.Backup = &status
+	}()
+
+	if status.RunningSnapshot == "" {
+		if spec.Suspend {
+			return 0, nil
+		}
+		schedule, err := util.ParseCronSchedule(spec.Schedule)
+		if err != nil {
+			return 0, err
+		}
+		lastTime := r.CR.CreationTimestamp.Time
+		if status.LastScheduleTime != nil {
+			lastTime = status.LastScheduleTime.Time
+		}
+		next := schedule.Next(lastTime)
+		if next.IsZero() {
+			return 0, fmt.Errorf("spec.backup.schedule %q would never be triggered", spec.Schedule)
+		}
+		if now.Before(next) {
+			return next.Sub(now), nil
+		}
+		if !r.CR.Status.AllReady {
+			return BackupCheckInterval, nil
+		}
+		status.RunningSnapshot = tran.GetBackupSnapshotName(now)
+		status.LastScheduleTime = &metav1.Time{Time: now}
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "BackupStarted",
+			fmt.Sprintf("Backing up databases %v as snapshot %s", spec.Databases, status.RunningSnapshot))
+	}
+
+	finished, err := r.progressBackup(status.RunningSnapshot)
+	if err != nil {
+		msg := fmt.Sprintf("backup %s failed: %s", status.RunningSnapshot, err.Error())
+		status.RunningSnapshot = ""
+		r.setBackupCondition(metav1.ConditionFalse, "Failed", msg)
+		r.RecordEvent(r.CR, corev1.EventTypeWarning, "BackupFailed", msg)
+		return BackupCheckInterval, nil
+	}
+	if !finished {
+		return BackupCheckInterval, nil
+	}
+
+	status.History = append([]dapi.BackupRecord{{
+		Snapshot:       status.RunningSnapshot,
+		Databases:      spec.Databases,
+		CompletionTime: metav1.Time{Time: now},
+	}}, status.History...)
+	if retention := int(util.PointerDeRefer(spec.Retention, DefaultBackupRetention)); len(status.History) > retention {
+		for _, expired := range status.History[retention:] {
+			r.RecordEvent(r.CR, corev1.EventTypeNormal, "BackupExpired", fmt.Sprintf(
+				"Snapshot %s is beyond the retention, it can be removed from %s", expired.Snapshot, spec.Location))
+		}
+		status.History = status.History[:retention]
+	}
+	status.LastSuccessTime = &metav1.Time{Time: now}
+	r.setBackupCondition(metav1.ConditionTrue, "Succeeded", fmt.Sprintf("snapshot %s has been backed up", status.RunningSnapshot))
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "BackupSucceeded",
+		fmt.Sprintf("Snapshot %s has been backed up", status.RunningSnapshot))
+	status.RunningSnapshot = ""
+	return 0, nil
+}
+
+// submit the backup jobs of the snapshot for the databases that have not been submitted yet,
+// and check whether all of them have finished. The error is returned when any of them is cancelled.
+func (r *DorisClusterReconciler) progressBackup(snapshot string) (bool, error) {
+	feCli, err := r.connectFe()
+	if err != nil {
+		return false, err
+	}
+	defer feCli.Close()
+	repoName := tran.GetBackupRepositoryName(r.CR)
+	if err := r.createBackupRepository(feCli, repoName, r.CR.Spec.Backup.BackupRepositorySpec, false); err != nil {
+		return false, err
+	}
+	finished := true
+	for _, database := range r.CR.Spec.Backup.Databases {
+		jobs, err := feCli.ShowBackup(database)
+		if err != nil {
+			return false, err
+		}
+		var job *fe.BackupJob
+		for i := len(jobs) - 1; i >= 0; i-- {
+			if jobs[i].SnapshotName == snapshot {
+				job = &jobs[i]
+				break
+			}
+		}
+		switch {
+		case job == nil:
+			if err := feCli.BackupSnapshot(database, snapshot, repoName); err != nil {
+				return false, err
+			}
+			finished = false
+		case job.State == fe.BackupJobCancelled:
+			return false, fmt.Errorf("backup job %s of database %s has been cancelled: %s", job.JobId, database, job.Status)
+		case job.State != fe.BackupJobFinished:
+			finished = false
+		}
+	}
+	return finished, nil
+}
+
+// create the backup repository when it does not exist.
+func (r *DorisClusterReconciler) createBackupRepository(feCli fe.Client, name string,
+	spec dapi.BackupRepositorySpec, readOnly bool) error {
+	repos, err := feCli.ShowRepositories()
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if repo == name {
+			return nil
+		}
+	}
+	properties, err := r.getBackupRepositoryCredential(spec.CredentialSecretRef)
+	if err != nil {
+		return err
+	}
+	return feCli.CreateRepository(fe.Repository{
+		Name:       name,
+		Type:       tran.GetBackupRepositoryType(spec.Location),
+		Location:   spec.Location,
+		Properties: util.MergeMaps(spec.Properties, properties),
+		ReadOnly:   readOnly,
+	})
+}
+
+// get the repository properties from the credential secret of the backup repository.
+func (r *DorisClusterReconciler) getBackupRepositoryCredential(ref *corev1.LocalObjectReference) (map[string]string, error) {
+	if ref == nil || ref.Name == "" {
+		return nil, nil
+	}
+	secretRef := types.NamespacedName{Namespace: r.CR.ResourceKey().Namespace, Name: ref.Name}
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(secretRef, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("backup repository credential secret %s not found", util.K8sObjKeyStr(secretRef))
+	}
+	properties := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		properties[key] = string(value)
+	}
+	return properties, nil
+}
+
+func (r *DorisClusterReconciler) setBackupCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.BackupSucceeded,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,128 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestRecBackup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", CreationTimestamp: metav1.Time{Time: created}},
+		Spec: dapi.DorisClusterSpec{Backup: &dapi.BackupSpec{
+			BackupRepositorySpec: dapi.BackupRepositorySpec{Location: "hdfs://namenode:8020/backup"},
+			Schedule:             "0 2 * * *",
+			Databases:            []string{"db1", "db2"},
+			Retention:            util.Pointer(int32(1)),
+		}},
+		Status: dapi.DorisClusterStatus{DorisClusterSyncStatus: dapi.DorisClusterSyncStatus{AllReady: true}},
+	}
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+	).Build()
+	feCli := &fe.FakeClient{}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// wait for the next schedule
+	requeueAfter, err := rec.recBackupAt(created.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Hour, requeueAfter)
+	assert.Empty(t, feCli.BackupJobs)
+
+	// submit the backup jobs of all databases once scheduled
+	firstTime := time.Date(2024, 1, 2, 2, 0, 10, 0, time.UTC)
+	requeueAfter, err = rec.recBackupAt(firstTime)
+	assert.NoError(t, err)
+	assert.Equal(t, BackupCheckInterval, requeueAfter)
+	assert.Equal(t, "backup_20240102020010", cr.Status.Backup.RunningSnapshot)
+	assert.Equal(t, fe.RepositoryTypeHdfs, feCli.Repositories["test_cluster_backup"].Type)
+	assert.False(t, feCli.Repositories["test_cluster_backup"].ReadOnly)
+	assert.Len(t, feCli.BackupJobs["db1"], 1)
+	assert.Len(t, feCli.BackupJobs["db2"], 1)
+
+	// wait for all jobs to finish
+	feCli.BackupJobs["db1"][0].State = fe.BackupJobFinished
+	requeueAfter, err = rec.recBackupAt(firstTime.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, BackupCheckInterval, requeueAfter)
+	assert.Len(t, feCli.BackupJobs["db2"], 1)
+
+	feCli.BackupJobs["db2"][0].State = fe.BackupJobFinished
+	requeueAfter, err = rec.recBackupAt(firstTime.Add(2 * time.Minute))
+	assert.NoError(t, err)
+	assert.Zero(t, requeueAfter)
+	assert.Empty(t, cr.Status.Backup.RunningSnapshot)
+	assert.Equal(t, firstTime.Add(2*time.Minute), cr.Status.Backup.LastSuccessTime.Time)
+	assert.Equal(t, []string{"backup_20240102020010"}, []string{cr.Status.Backup.History[0].Snapshot})
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.BackupSucceeded))
+
+	// the cancelled job fails the backup until the next schedule
+	secondTime := time.Date(2024, 1, 3, 2, 0, 0, 0, time.UTC)
+	_, err = rec.recBackupAt(secondTime)
+	assert.NoError(t, err)
+	feCli.BackupJobs["db1"][1].State = fe.BackupJobCancelled
+	feCli.BackupJobs["db1"][1].Status = "no space left"
+	_, err = rec.recBackupAt(secondTime.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, cr.Status.Backup.RunningSnapshot)
+	cond := meta.FindStatusCondition(cr.Status.Conditions, dapi.BackupSucceeded)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Contains(t, cond.Message, "no space left")
+	assert.Equal(t, firstTime.Add(2*time.Minute), cr.Status.Backup.LastSuccessTime.Time)
+
+	// only the latest backups within the retention are kept
+	thirdTime := time.Date(2024, 1, 4, 2, 0, 0, 0, time.UTC)
+	_, _ = rec.recBackupAt(thirdTime)
+	feCli.BackupJobs["db1"][2].State = fe.BackupJobFinished
+	feCli.BackupJobs["db2"][2].State = fe.BackupJobFinished
+	_, _ = rec.recBackupAt(thirdTime.Add(time.Minute))
+	assert.Len(t, cr.Status.Backup.History, 1)
+	assert.Equal(t, "backup_20240104020000", cr.Status.Backup.History[0].Snapshot)
+
+	// no more backups when suspended
+	cr.Spec.Backup.Suspend = true
+	requeueAfter, err = rec.recBackupAt(time.Date(2024, 1, 5, 2, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Zero(t, requeueAfter)
+	assert.Len(t, feCli.BackupJobs["db1"], 3)
+}
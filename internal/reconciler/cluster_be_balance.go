@@ -0,0 +1,121 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	appv1 "k8s.io/api/apps/v1"
+	"strings"
+)
+
+const feDisableBalanceConfKey = "disable_balance"
+
+// Disable the tablet balancing of Doris cluster during the BE rolling update when
+// spec.be.disableBalanceDuringRollout is enabled, the stage would be blocked until
+// the rollout has finished, and then the balancing is restored by recBeBalanceRestore.
+// Returns nil when there is no BE rollout in progress.
+func (r *DorisClusterReconciler) recBeBalancePause() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageBeRollout, action, err)
+		return &res
+	}
+	wait := func(msg string) *ClusterStageRecResult {
+		res := clusterStageWait(dapi.StageBeRollout, action, errors.New(msg))
+		return &res
+	}
+	if r.CR.Spec.BE.DisableBalanceDuringRollout == nil || !*r.CR.Spec.BE.DisableBalanceDuringRollout {
+		return nil
+	}
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetBeStatefulSetKey(r.CR.ResourceKey()), sts)
+	if err != nil {
+		return fail(err)
+	}
+	if !exist || sts.Spec.UpdateStrategy.Type == appv1.OnDeleteStatefulSetStrategyType {
+		return nil
+	}
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return wait("waiting for BE statefulset to observe the latest spec")
+	}
+	if !isStatefulSetRolling(sts) {
+		return nil
+	}
+	// disable the balancing when it has not been disabled by operator
+	if r.CR.Status.BE.OriginalDisableBalance == nil {
+		feCli, err := r.connectFe()
+		if err != nil {
+			return fail(err)
+		}
+		defer feCli.Close()
+		origin, err := feCli.ShowConfig(feDisableBalanceConfKey)
+		if err != nil {
+			return fail(err)
+		}
+		// keep the balancing that has been disabled intentionally untouched
+		if !isTrueConf(origin) {
+			if err := feCli.SetConfig(feDisableBalanceConfKey, "true"); err != nil {
+				return fail(err)
+			}
+			r.CR.Status.BE.OriginalDisableBalance = &origin
+			r.Log.Info("disable tablet balancing during BE rollout")
+		}
+	}
+	return wait("waiting for the rolling update of BE to finish")
+}
+
+// Restore the tablet balancing of Doris cluster that has been disabled by recBeBalancePause.
+// Returns nil when there is nothing to restore.
+func (r *DorisClusterReconciler) recBeBalanceRestore() *ClusterStageRecResult {
+	origin := r.CR.Status.BE.OriginalDisableBalance
+	if origin == nil {
+		return nil
+	}
+	action := dapi.StageActionApply
+	feCli, err := r.connectFe()
+	if err != nil {
+		res := clusterStageFail(dapi.StageBeBalanceRestore, action, err)
+		return &res
+	}
+	defer feCli.Close()
+	if err := feCli.SetConfig(feDisableBalanceConfKey, *origin); err != nil {
+		res := clusterStageFail(dapi.StageBeBalanceRestore, action, err)
+		return &res
+	}
+	r.CR.Status.BE.OriginalDisableBalance = nil
+	r.Log.Info("restore tablet balancing after BE rollout")
+	return nil
+}
+
+// check whether the statefulset is rolling updating its pods, the pods held back
+// by the partition of RollingUpdate strategy are not regarded as rolling.
+func isStatefulSetRolling(sts *appv1.StatefulSet) bool {
+	rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate != nil && rollingUpdate.Partition != nil {
+		return sts.Status.UpdatedReplicas < sts.Status.Replicas-*rollingUpdate.Partition
+	}
+	return sts.Status.UpdateRevision != sts.Status.CurrentRevision ||
+		sts.Status.UpdatedReplicas < sts.Status.Replicas
+}
+
+func isTrueConf(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), "true")
+}
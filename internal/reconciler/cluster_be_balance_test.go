@@ -0,0 +1,52 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	"testing"
+)
+
+func TestIsStatefulSetRolling(t *testing.T) {
+	sts := &appv1.StatefulSet{}
+	sts.Status.Replicas = 3
+	sts.Status.UpdatedReplicas = 3
+	sts.Status.CurrentRevision = "rev-1"
+	sts.Status.UpdateRevision = "rev-1"
+	assert.False(t, isStatefulSetRolling(sts))
+
+	sts.Status.UpdateRevision = "rev-2"
+	sts.Status.UpdatedReplicas = 1
+	assert.True(t, isStatefulSetRolling(sts))
+
+	// the rest pods are held back by partition
+	sts.Spec.UpdateStrategy.RollingUpdate = &appv1.RollingUpdateStatefulSetStrategy{Partition: util.Pointer(int32(2))}
+	assert.False(t, isStatefulSetRolling(sts))
+	sts.Spec.UpdateStrategy.RollingUpdate.Partition = util.Pointer(int32(1))
+	assert.True(t, isStatefulSetRolling(sts))
+}
+
+func TestIsTrueConf(t *testing.T) {
+	assert.True(t, isTrueConf("true"))
+	assert.True(t, isTrueConf(" TRUE "))
+	assert.False(t, isTrueConf("false"))
+	assert.False(t, isTrueConf(""))
+}
@@ -0,0 +1,177 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Cordon the BE pods listed in spec.be.cordonedOrdinals for maintenance, the backends of them
+// are decommissioned and dropped from the Doris cluster, then the pods are restarted to be held
+// in the cordon gate init container. The backends of the uncordoned pods are added back.
+// Returns nil when all the cordoned BE pods have been held out of service.
+func (r *DorisClusterReconciler) recBeCordon() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageBeCordon, action, err)
+		return &res
+	}
+	cordoned := tran.GetBeCordonedPodNames(r.CR)
+	uncordoned := r.getUncordonedBePods(cordoned)
+	if len(cordoned) == 0 && len(uncordoned) == 0 {
+		r.CR.Status.BE.Cordoning = nil
+		r.CR.Status.BE.CordonedMembers = nil
+		return nil
+	}
+	feCli, err := r.connectFe()
+	if err != nil {
+		return fail(err)
+	}
+	defer feCli.Close()
+
+	// add back the backends of the uncordoned pods
+	if err := r.uncordonBackends(feCli, uncordoned); err != nil {
+		return fail(err)
+	}
+	// decommission the backends of the cordoned pods
+	statuses, err := r.decommissionBackends(feCli, cordoned, r.CR.Status.BE.Cordoning)
+	if err != nil {
+		return fail(err)
+	}
+	r.CR.Status.BE.Cordoning = statuses
+	decommissioning := make(map[string]bool)
+	for _, status := range statuses {
+		decommissioning[status.Member] = true
+	}
+	// hold the pods whose backends have been dropped in the cordon gate
+	var dropped []string
+	for _, pod := range cordoned {
+		if !decommissioning[pod] {
+			dropped = append(dropped, pod)
+		}
+	}
+	held, err := r.holdCordonedBePods(dropped)
+	if err != nil {
+		return fail(err)
+	}
+	r.CR.Status.BE.CordonedMembers = held
+	if len(held) < len(cordoned) {
+		res := clusterStageWait(dapi.StageBeCordon, action,
+			fmt.Errorf("waiting for the cordon of BE: %v", cordoned))
+		return &res
+	}
+	return nil
+}
+
+// get the BE pods that have been cordoned or being cordoned but are no longer listed in
+// spec.be.cordonedOrdinals, the pods removed by scaling down are excluded.
+func (r *DorisClusterReconciler) getUncordonedBePods(cordoned []string) []string {
+	excluded := make(map[string]bool)
+	for _, pod := range cordoned {
+		excluded[pod] = true
+	}
+	previous := append([]string(nil), r.CR.Status.BE.CordonedMembers...)
+	for _, status := range r.CR.Status.BE.Cordoning {
+		previous = append(previous, status.Member)
+	}
+	var uncordoned []string
+	for _, pod := range previous {
+		if excluded[pod] || getPodOrdinal(pod) >= int(r.CR.Spec.BE.Replicas) {
+			continue
+		}
+		excluded[pod] = true
+		uncordoned = append(uncordoned, pod)
+	}
+	return uncordoned
+}
+
+// Add the backends of the uncordoned BE pods back to the Doris cluster, the decommission
+// of the backends that have not been dropped yet is cancelled instead.
+func (r *DorisClusterReconciler) uncordonBackends(feCli fe.Client, pods []string) error {
+	if len(pods) == 0 {
+		return nil
+	}
+	backends, err := feCli.ShowBackends()
+	if err != nil {
+		return err
+	}
+	backendMap := make(map[string]fe.Backend)
+	for _, be := range backends {
+		backendMap[be.Host] = be
+	}
+	for _, pod := range pods {
+		host := tran.GetBePodFQDN(r.CR, pod)
+		hostPort := fmt.Sprintf("%s:%d", host, tran.GetBeHeartbeatServicePort(r.CR))
+		be, found := backendMap[host]
+		switch {
+		case !found:
+			if err := feCli.AddBackend(hostPort); err != nil {
+				return err
+			}
+			r.Log.Info(fmt.Sprintf("add uncordoned backend: %s", hostPort))
+		case be.SystemDecommissioned:
+			if err := feCli.CancelDecommissionBackend(hostPort); err != nil {
+				return err
+			}
+			r.Log.Info(fmt.Sprintf("cancel decommission of uncordoned backend: %s", hostPort))
+		}
+	}
+	return nil
+}
+
+// Restart the cordoned BE pods that are still running BE, so that they are held in the cordon
+// gate init container once recreated by the statefulset. Returns the pods that have been held.
+func (r *DorisClusterReconciler) holdCordonedBePods(pods []string) ([]string, error) {
+	var held []string
+	for _, podName := range pods {
+		pod := &corev1.Pod{}
+		exist, err := r.Exist(types.NamespacedName{Namespace: r.CR.ResourceKey().Namespace, Name: podName}, pod)
+		if err != nil {
+			return nil, err
+		}
+		if !exist || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if isBePodHeldByCordonGate(pod) {
+			held = append(held, podName)
+			continue
+		}
+		if err := r.Delete(r.Ctx, pod); err != nil {
+			return nil, err
+		}
+		r.Log.Info(fmt.Sprintf("restart cordoned BE pod: %s", podName))
+	}
+	return held, nil
+}
+
+// check whether the BE pod is held in the cordon gate init container, which means that
+// BE is not running in it.
+func isBePodHeldByCordonGate(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == tran.BeCordonGateContainerName {
+			return status.State.Terminated == nil
+		}
+	}
+	return len(pod.Status.ContainerStatuses) == 0
+}
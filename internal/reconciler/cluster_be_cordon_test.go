@@ -0,0 +1,139 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestIsBePodHeldByCordonGate(t *testing.T) {
+	pod := &corev1.Pod{}
+	assert.True(t, isBePodHeldByCordonGate(pod))
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "be"}}
+	assert.False(t, isBePodHeldByCordonGate(pod))
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name: tran.BeCordonGateContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+	assert.True(t, isBePodHeldByCordonGate(pod))
+	pod.Status.InitContainerStatuses[0].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	assert.False(t, isBePodHeldByCordonGate(pod))
+}
+
+func TestRecBeCordon(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}}},
+	}
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 3)
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: pods[1], Namespace: "default"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: tran.BeCordonGateContainerName, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "be"}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		runningPod,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+	).Build()
+	feCli := &fe.FakeClient{Backends: []fe.Backend{
+		{BackendId: "10001", Host: tran.GetBePodFQDN(cr, pods[0])},
+		{BackendId: "10002", Host: tran.GetBePodFQDN(cr, pods[1]), TabletNum: 100},
+		{BackendId: "10003", Host: tran.GetBePodFQDN(cr, pods[2])},
+	}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// nothing to do without cordoned pods
+	assert.Nil(t, rec.recBeCordon())
+	assert.Empty(t, feCli.Decommissioned)
+
+	// decommission the backend of the cordoned pod
+	cr.Spec.BE.CordonedOrdinals = []int32{1}
+	res := rec.recBeCordon()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, dapi.StageBeCordon, res.Stage)
+	assert.Equal(t, "10002", cr.Status.BE.Cordoning[0].BackendId)
+	assert.Len(t, feCli.Decommissioned, 1)
+	assert.Contains(t, feCli.Decommissioned[0], tran.GetBePodFQDN(cr, pods[1]))
+
+	// restart the pod once the backend has been dropped
+	feCli.Backends = []fe.Backend{feCli.Backends[0], feCli.Backends[2]}
+	assert.NotNil(t, rec.recBeCordon())
+	assert.Empty(t, cr.Status.BE.Cordoning)
+	assert.Empty(t, cr.Status.BE.CordonedMembers)
+	exist, err := rec.Exist(types.NamespacedName{Namespace: "default", Name: pods[1]}, &corev1.Pod{})
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	// cordoned once the recreated pod is held by the cordon gate
+	heldPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pods[1], Namespace: "default"}}
+	assert.Nil(t, cli.Create(context.Background(), heldPod))
+	assert.Nil(t, rec.recBeCordon())
+	assert.Equal(t, []string{pods[1]}, cr.Status.BE.CordonedMembers)
+
+	// add back the backend of the uncordoned pod
+	cr.Spec.BE.CordonedOrdinals = nil
+	assert.Nil(t, rec.recBeCordon())
+	assert.Len(t, feCli.Added, 1)
+	assert.Contains(t, feCli.Added[0], tran.GetBePodFQDN(cr, pods[1]))
+	assert.Empty(t, cr.Status.BE.CordonedMembers)
+	assert.Nil(t, rec.recBeCordon())
+	assert.Len(t, feCli.Added, 1)
+}
+
+func TestUncordonBackendsCancelDecommission(t *testing.T) {
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}}},
+	}
+	cr.Status.BE.Cordoning = []dapi.BEDecommissionStatus{{Member: "test-be-2"}, {Member: "test-be-1"}}
+	rec := DorisClusterReconciler{CR: cr}
+	// the pods still being cordoned are not uncordoned
+	uncordoned := rec.getUncordonedBePods([]string{"test-be-1"})
+	assert.Equal(t, []string{"test-be-2"}, uncordoned)
+
+	feCli := &fe.FakeClient{Backends: []fe.Backend{{Host: tran.GetBePodFQDN(cr, "test-be-2"), SystemDecommissioned: true}}}
+	assert.Nil(t, rec.uncordonBackends(feCli, uncordoned))
+	assert.False(t, feCli.Backends[0].SystemDecommissioned)
+	assert.Empty(t, feCli.Added)
+}
@@ -0,0 +1,295 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"time"
+)
+
+// Decommission the BE nodes that would be removed when the BE replicas is scaled down,
+// the scaling down would be blocked until Doris has migrated all tablets on those nodes
+// and dropped them.
+// Returns nil when there is no BE node waiting for decommissioning.
+func (r *DorisClusterReconciler) recBeDecommission() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageBeDecommission, action, err)
+		return &res
+	}
+	// find the BE pods that would be removed
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetBeStatefulSetKey(r.CR.ResourceKey()), sts)
+	if err != nil {
+		return fail(err)
+	}
+	curReplicas := int32(0)
+	if exist && sts.Spec.Replicas != nil {
+		curReplicas = *sts.Spec.Replicas
+	}
+	if curReplicas <= r.CR.Spec.BE.Replicas {
+		r.setBeDecommissioning(nil)
+		r.resolveBeDecommissionTimedOut()
+		return nil
+	}
+	curPods := tran.GetBeExpectPodNames(r.CR.ResourceKey(), curReplicas)
+	removedPods := curPods[r.CR.Spec.BE.Replicas:]
+
+	// resume the aborted decommission once the timeout has been extended or the policy has been changed
+	timeout := r.CR.Spec.BE.DecommissionTimeout
+	prev := make([]dapi.BEDecommissionStatus, 0, len(r.CR.Status.BE.Decommissioning))
+	for _, status := range r.CR.Status.BE.Decommissioning {
+		if status.Aborted && (getBeDecommissionTimeoutPolicy(r.CR) != dapi.DecommissionTimeoutAbort ||
+			!isBeDecommissionTimedOut(status.StartTime, timeout)) {
+			status.Aborted = false
+		}
+		prev = append(prev, status)
+	}
+
+	// decommission backends that still exist in Doris cluster
+	feCli, err := r.connectFe()
+	if err != nil {
+		return fail(err)
+	}
+	defer feCli.Close()
+	statuses, err := r.decommissionBackends(feCli, removedPods, prev)
+	if err != nil {
+		return fail(err)
+	}
+	statuses, err = r.applyBeDecommissionTimeout(feCli, statuses)
+	if err != nil {
+		return fail(err)
+	}
+	decommissioning := r.setBeDecommissioning(statuses)
+	var aborted []string
+	for _, status := range statuses {
+		if status.Aborted {
+			aborted = append(aborted, status.Member)
+		}
+	}
+	if len(aborted) > 0 {
+		return fail(fmt.Errorf("the decommission of BE %v has been aborted since it exceeded the timeout %s, "+
+			"restore spec.be.replicas or extend spec.be.decommissionTimeout to resume it", aborted, timeout.Duration))
+	}
+	if len(decommissioning) > 0 {
+		res := clusterStageWait(dapi.StageBeDecommission, action,
+			fmt.Errorf("waiting for the decommission of BE: %v", decommissioning))
+		return &res
+	}
+	r.resolveBeDecommissionTimedOut()
+	return nil
+}
+
+// Apply the spec.be.decommissionTimeoutPolicy to the BE nodes whose decommission has exceeded
+// the spec.be.decommissionTimeout, the timed out backends are either cancelled and marked as
+// aborted, or force dropped from the Doris cluster.
+// Returns the progress of the BE nodes that have not been dropped.
+func (r *DorisClusterReconciler) applyBeDecommissionTimeout(
+	feCli fe.Client, statuses []dapi.BEDecommissionStatus) ([]dapi.BEDecommissionStatus, error) {
+	timeout := r.CR.Spec.BE.DecommissionTimeout
+	policy := getBeDecommissionTimeoutPolicy(r.CR)
+	var remaining []dapi.BEDecommissionStatus
+	for _, status := range statuses {
+		if status.Aborted || !isBeDecommissionTimedOut(status.StartTime, timeout) {
+			remaining = append(remaining, status)
+			continue
+		}
+		hostPort := fmt.Sprintf("%s:%d", tran.GetBePodFQDN(r.CR, status.Member), tran.GetBeHeartbeatServicePort(r.CR))
+		msg := fmt.Sprintf("the decommission of BE %s has exceeded the timeout %s with %d tablets remaining",
+			status.Member, timeout.Duration, status.TabletNum)
+		if policy == dapi.DecommissionTimeoutForce {
+			if err := feCli.ForceDropBackend(hostPort); err != nil {
+				return nil, err
+			}
+			r.Log.Info(fmt.Sprintf("force drop backend: %s", hostPort))
+			r.RecordEvent(r.CR, corev1.EventTypeWarning, "DecommissionForced", msg+", the backend has been force dropped")
+			r.setBeDecommissionTimedOut(metav1.ConditionTrue, "ForceDropped", msg)
+			continue
+		}
+		if err := feCli.CancelDecommissionBackend(hostPort); err != nil {
+			return nil, err
+		}
+		r.Log.Info(fmt.Sprintf("abort the decommission of backend: %s", hostPort))
+		r.RecordEvent(r.CR, corev1.EventTypeWarning, "DecommissionAborted", msg+", the decommission has been cancelled")
+		r.setBeDecommissionTimedOut(metav1.ConditionTrue, "Aborted", msg)
+		status.Aborted = true
+		remaining = append(remaining, status)
+	}
+	return remaining, nil
+}
+
+func getBeDecommissionTimeoutPolicy(cr *dapi.DorisCluster) dapi.DecommissionTimeoutPolicy {
+	return util.Coalesce(cr.Spec.BE.DecommissionTimeoutPolicy, dapi.DecommissionTimeoutAbort)
+}
+
+// check whether the decommission started at the time has exceeded the timeout, which never
+// times out when the timeout is not set.
+func isBeDecommissionTimedOut(startTime metav1.Time, timeout *metav1.Duration) bool {
+	return timeout != nil && timeout.Duration > 0 && time.Since(startTime.Time) > timeout.Duration
+}
+
+func (r *DorisClusterReconciler) setBeDecommissionTimedOut(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.BEDecommissionTimedOut,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// the aborted condition is resolved once there is no BE node being decommissioned,
+// while the force dropped one is retained as the record of the potential data loss.
+func (r *DorisClusterReconciler) resolveBeDecommissionTimedOut() {
+	cond := meta.FindStatusCondition(r.CR.Status.Conditions, dapi.BEDecommissionTimedOut)
+	if cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == "Aborted" {
+		r.setBeDecommissionTimedOut(metav1.ConditionFalse, "Resolved", "no BE decommission is in progress")
+	}
+}
+
+// Decommission the backends of the given BE pods that still exist in Doris cluster,
+// returns the decommission progress of the pods whose backends are still being decommissioned,
+// the start times and the aborted marks are taken from the previous progress.
+func (r *DorisClusterReconciler) decommissionBackends(
+	feCli fe.Client, pods []string, prev []dapi.BEDecommissionStatus) ([]dapi.BEDecommissionStatus, error) {
+	backends, err := feCli.ShowBackends()
+	if err != nil {
+		return nil, err
+	}
+	backendMap := make(map[string]fe.Backend)
+	for _, be := range backends {
+		backendMap[be.Host] = be
+	}
+	startTimes := make(map[string]metav1.Time)
+	aborted := make(map[string]bool)
+	for _, status := range prev {
+		startTimes[status.Member] = status.StartTime
+		aborted[status.Member] = status.Aborted
+	}
+	var decommissioning []dapi.BEDecommissionStatus
+	for _, pod := range pods {
+		be, found := backendMap[tran.GetBePodFQDN(r.CR, pod)]
+		if !found {
+			continue
+		}
+		startTime, started := startTimes[pod]
+		if !started {
+			startTime = metav1.Now()
+		}
+		decommissioning = append(decommissioning, dapi.BEDecommissionStatus{
+			Member:    pod,
+			BackendId: be.BackendId,
+			TabletNum: be.TabletNum,
+			StartTime: startTime,
+			Elapsed:   &metav1.Duration{Duration: time.Since(startTime.Time).Round(time.Second)},
+			Aborted:   aborted[pod],
+		})
+		// the aborted decommission is not issued again
+		if be.SystemDecommissioned || aborted[pod] {
+			continue
+		}
+		hostPort := fmt.Sprintf("%s:%d", be.Host, tran.GetBeHeartbeatServicePort(r.CR))
+		if err := feCli.DecommissionBackend(hostPort); err != nil {
+			return nil, err
+		}
+		r.Log.Info(fmt.Sprintf("decommission backend: %s", hostPort))
+	}
+	return decommissioning, nil
+}
+
+// Update the decommission progress of BE in status, returns the decommissioning pods.
+func (r *DorisClusterReconciler) setBeDecommissioning(statuses []dapi.BEDecommissionStatus) []string {
+	var members []string
+	for _, status := range statuses {
+		members = append(members, status.Member)
+	}
+	r.CR.Status.BE.Decommissioning = statuses
+	r.CR.Status.BE.DecommissioningMembers = members
+	return members
+}
+
+// BeTargetReplicasRefreshPeriod is the period to wait for a changed annotation of BE pod to be
+// refreshed into the pod via downward API, the kubelet refreshes the downward API volumes on its
+// periodic sync of the pod, which takes up to about one minute by default.
+var BeTargetReplicasRefreshPeriod = 90 * time.Second
+
+// Record the target replicas of BE on the annotation of BE pods when the preStop decommission is
+// enabled, which tells the preStop hook whether the BE pod is removed by scaling down. The time of
+// changing the annotation is recorded along with it, the BE reconciliation waits until the refresh
+// period has passed since then, so that the preStop hook never reads a stale target replicas.
+// The pods that have not been annotated yet are not waited for, whose preStop hook stops the BE
+// directly without the target replicas.
+// Returns nil when the annotations are up-to-date and have been refreshed into the pods.
+func (r *DorisClusterReconciler) annotateBeTargetReplicas() *ClusterStageRecResult {
+	if !r.CR.Spec.BE.PreStopDecommission || r.DryRun != nil {
+		return nil
+	}
+	action := dapi.StageActionApply
+	podList := &corev1.PodList{}
+	if err := r.List(r.Ctx, podList, client.InNamespace(r.CR.ResourceKey().Namespace),
+		client.MatchingLabels(tran.GetBeComponentLabels(r.CR.ResourceKey()))); err != nil {
+		res := clusterStageFail(dapi.StageBeDecommission, action, err)
+		return &res
+	}
+	target := strconv.Itoa(int(r.CR.Spec.BE.Replicas))
+	var refreshing []string
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		cur, annotated := pod.Annotations[tran.BeTargetReplicasPodAnnoKey]
+		if cur == target {
+			if isBeTargetReplicasRefreshing(pod) {
+				refreshing = append(refreshing, pod.Name)
+			}
+			continue
+		}
+		annotations := map[string]string{tran.BeTargetReplicasPodAnnoKey: target}
+		if annotated && cur != "" {
+			annotations[tran.BeTargetReplicasUpdatedAtPodAnnoKey] = time.Now().UTC().Format(time.RFC3339)
+			refreshing = append(refreshing, pod.Name)
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		pod.Annotations = util.MergeMaps(pod.Annotations, annotations)
+		if err := client.IgnoreNotFound(r.Patch(r.Ctx, pod, patch)); err != nil {
+			res := clusterStageFail(dapi.StageBeDecommission, action, err)
+			return &res
+		}
+	}
+	if len(refreshing) > 0 {
+		res := clusterStageWait(dapi.StageBeDecommission, action,
+			fmt.Errorf("waiting for the target replicas %s to be refreshed into the BE pods %v", target, refreshing))
+		return &res
+	}
+	return nil
+}
+
+// check whether the changed target replicas annotation of the BE pod may not have been
+// refreshed into the pod yet.
+func isBeTargetReplicasRefreshing(pod *corev1.Pod) bool {
+	updatedAt, err := time.Parse(time.RFC3339, pod.Annotations[tran.BeTargetReplicasUpdatedAtPodAnnoKey])
+	return err == nil && time.Since(updatedAt) < BeTargetReplicasRefreshPeriod
+}
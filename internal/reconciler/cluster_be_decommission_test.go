@@ -0,0 +1,229 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestSetBeDecommissioning(t *testing.T) {
+	rec := DorisClusterReconciler{CR: &dapi.DorisCluster{}}
+	statuses := []dapi.BEDecommissionStatus{
+		{Member: "test-be-3", BackendId: "10003", TabletNum: 120, StartTime: metav1.Now()},
+		{Member: "test-be-4", BackendId: "10004", TabletNum: 0, StartTime: metav1.Now()},
+	}
+	assert.Equal(t, []string{"test-be-3", "test-be-4"}, rec.setBeDecommissioning(statuses))
+	assert.Equal(t, statuses, rec.CR.Status.BE.Decommissioning)
+	assert.Equal(t, []string{"test-be-3", "test-be-4"}, rec.CR.Status.BE.DecommissioningMembers)
+
+	// the entries are cleared once the decommission has completed
+	assert.Empty(t, rec.setBeDecommissioning(nil))
+	assert.Nil(t, rec.CR.Status.BE.Decommissioning)
+	assert.Nil(t, rec.CR.Status.BE.DecommissioningMembers)
+}
+
+func TestRecBeDecommission(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 2}}},
+	}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(3))},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+	).Build()
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 3)
+	feCli := &fe.FakeClient{Backends: []fe.Backend{
+		{BackendId: "10001", Host: tran.GetBePodFQDN(cr, pods[1]), TabletNum: 100},
+		{BackendId: "10002", Host: tran.GetBePodFQDN(cr, pods[2]), TabletNum: 120},
+	}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// decommission the backend of the removed pod
+	res := rec.recBeDecommission()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, []string{pods[2]}, cr.Status.BE.DecommissioningMembers)
+	assert.Equal(t, "10002", cr.Status.BE.Decommissioning[0].BackendId)
+	assert.Len(t, feCli.Decommissioned, 1)
+	assert.Contains(t, feCli.Decommissioned[0], tran.GetBePodFQDN(cr, pods[2]))
+	assert.True(t, feCli.Closed)
+
+	// the backend that is being decommissioned is not decommissioned again
+	assert.NotNil(t, rec.recBeDecommission())
+	assert.Len(t, feCli.Decommissioned, 1)
+
+	// completed once the backend has been dropped by Doris
+	feCli.Backends = feCli.Backends[:1]
+	assert.Nil(t, rec.recBeDecommission())
+	assert.Empty(t, cr.Status.BE.DecommissioningMembers)
+}
+
+func TestRecBeDecommissionTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{BE: &dapi.BESpec{
+			DorisComponentSpec:  dapi.DorisComponentSpec{Replicas: 2},
+			DecommissionTimeout: &metav1.Duration{Duration: time.Hour},
+		}},
+	}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(3))},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+	).Build()
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 3)
+	feCli := &fe.FakeClient{Backends: []fe.Backend{
+		{BackendId: "10002", Host: tran.GetBePodFQDN(cr, pods[2]), TabletNum: 120, SystemDecommissioned: true},
+	}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+	startTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	cr.Status.BE.Decommissioning = []dapi.BEDecommissionStatus{{Member: pods[2], BackendId: "10002", StartTime: startTime}}
+
+	// abort the decommission that exceeds the timeout
+	res := rec.recBeDecommission()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	assert.ErrorContains(t, res.Err, "has been aborted")
+	assert.False(t, feCli.Backends[0].SystemDecommissioned)
+	assert.True(t, cr.Status.BE.Decommissioning[0].Aborted)
+	assert.GreaterOrEqual(t, cr.Status.BE.Decommissioning[0].Elapsed.Duration, 2*time.Hour)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.BEDecommissionTimedOut))
+
+	// the aborted decommission is not issued again
+	assert.NotNil(t, rec.recBeDecommission())
+	assert.Empty(t, feCli.Decommissioned)
+	assert.Equal(t, startTime, cr.Status.BE.Decommissioning[0].StartTime)
+
+	// resumed once the timeout is extended
+	cr.Spec.BE.DecommissionTimeout = &metav1.Duration{Duration: 3 * time.Hour}
+	res = rec.recBeDecommission()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.False(t, cr.Status.BE.Decommissioning[0].Aborted)
+	assert.Len(t, feCli.Decommissioned, 1)
+
+	// the aborted condition is resolved once the scaling down is reverted
+	cr.Spec.BE.Replicas = 3
+	assert.Nil(t, rec.recBeDecommission())
+	assert.Equal(t, "Resolved", meta.FindStatusCondition(cr.Status.Conditions, dapi.BEDecommissionTimedOut).Reason)
+	cr.Spec.BE.Replicas = 2
+	cr.Status.BE.Decommissioning = []dapi.BEDecommissionStatus{{Member: pods[2], BackendId: "10002", StartTime: startTime}}
+
+	// force drop the backend that exceeds the timeout
+	cr.Spec.BE.DecommissionTimeout = &metav1.Duration{Duration: time.Hour}
+	cr.Spec.BE.DecommissionTimeoutPolicy = dapi.DecommissionTimeoutForce
+	assert.Nil(t, rec.recBeDecommission())
+	assert.Len(t, feCli.Dropped, 1)
+	assert.Contains(t, feCli.Dropped[0], tran.GetBePodFQDN(cr, pods[2]))
+	assert.Empty(t, feCli.Backends)
+	assert.Empty(t, cr.Status.BE.Decommissioning)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.BEDecommissionTimedOut))
+	assert.Equal(t, "ForceDropped", meta.FindStatusCondition(cr.Status.Conditions, dapi.BEDecommissionTimedOut).Reason)
+}
+
+func TestAnnotateBeTargetReplicas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{BE: &dapi.BESpec{
+			DorisComponentSpec:  dapi.DorisComponentSpec{Replicas: 3},
+			PreStopDecommission: true,
+		}},
+	}
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 3)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newRecreateTestPod(cr, pods[0], "rev-1", true),
+		newRecreateTestPod(cr, pods[1], "rev-1", true),
+		newRecreateTestPod(cr, pods[2], "rev-1", true),
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	getTarget := func(podName string) string {
+		pod := &corev1.Pod{}
+		assert.NoError(t, cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: podName}, pod))
+		return pod.Annotations[tran.BeTargetReplicasPodAnnoKey]
+	}
+
+	// the pods that have not been annotated are annotated without waiting
+	assert.Nil(t, rec.annotateBeTargetReplicas())
+	assert.Equal(t, "3", getTarget(pods[2]))
+
+	// the scaling down waits until the changed annotation has been refreshed into the pods
+	cr.Spec.BE.Replicas = 2
+	res := rec.annotateBeTargetReplicas()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, "2", getTarget(pods[0]))
+	assert.Equal(t, "2", getTarget(pods[2]))
+	res = rec.annotateBeTargetReplicas()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Contains(t, res.Err.Error(), pods[0])
+
+	defer func(period time.Duration) { BeTargetReplicasRefreshPeriod = period }(BeTargetReplicasRefreshPeriod)
+	BeTargetReplicasRefreshPeriod = 0
+	assert.Nil(t, rec.annotateBeTargetReplicas())
+
+	// nothing to do when the preStop decommission is disabled
+	cr.Spec.BE.PreStopDecommission = false
+	cr.Spec.BE.Replicas = 1
+	assert.Nil(t, rec.annotateBeTargetReplicas())
+	assert.Equal(t, "2", getTarget(pods[0]))
+}
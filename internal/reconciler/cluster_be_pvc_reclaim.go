@@ -0,0 +1,154 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+)
+
+// Delete the PVCs of the BE pods that have been removed by scaling down when the
+// spec.be.pvcReclaimPolicy is Delete. To avoid losing the data that has not been migrated,
+// the PVCs of a removed pod are only deleted when its BE node is not decommissioning,
+// its pod has been terminated and its backend has been dropped from the Doris cluster.
+// Returns nil when there is nothing to reclaim.
+func (r *DorisClusterReconciler) recBePvcReclaim() *ClusterStageRecResult {
+	if r.CR.Spec.BE.PVCReclaimPolicy != dapi.PVCReclaimPolicyDelete {
+		return nil
+	}
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageBePvcReclaim, action, err)
+		return &res
+	}
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetBeStatefulSetKey(r.CR.ResourceKey()), sts)
+	if err != nil {
+		return fail(err)
+	}
+	if !exist || sts.Spec.Replicas == nil {
+		return nil
+	}
+	orphanPvcs, err := r.findOrphanPvcs(sts)
+	if err != nil {
+		return fail(err)
+	}
+	reclaimable, err := r.filterTerminatedPods(sts.Namespace, orphanPvcs)
+	if err != nil {
+		return fail(err)
+	}
+	if len(reclaimable) == 0 {
+		return nil
+	}
+	// the backends of removed pods must have been dropped by the decommission
+	feCli, err := r.connectFe()
+	if err != nil {
+		return fail(err)
+	}
+	defer feCli.Close()
+	backends, err := feCli.ShowBackends()
+	if err != nil {
+		return fail(err)
+	}
+	for _, be := range backends {
+		for pod := range reclaimable {
+			if tran.GetBePodFQDN(r.CR, pod) == be.Host {
+				r.Log.Info(fmt.Sprintf("skip reclaiming the pvcs of %s since its backend still exists", pod))
+				delete(reclaimable, pod)
+			}
+		}
+	}
+	if err := r.deleteOrphanPvcs(reclaimable); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// Find the PVCs created by the volumeClaimTemplates of statefulset whose ordinals are beyond
+// the replicas of statefulset, returns the PVCs grouped by the name of their pods.
+func (r *DorisClusterReconciler) findOrphanPvcs(sts *appv1.StatefulSet) (map[string][]corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(r.Ctx, pvcList,
+		client.InNamespace(sts.Namespace), client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+	orphans := make(map[string][]corev1.PersistentVolumeClaim)
+	for _, pvc := range pvcList.Items {
+		for _, tpl := range sts.Spec.VolumeClaimTemplates {
+			pvcPrefix := fmt.Sprintf("%s-%s-", tpl.Name, sts.Name)
+			if !strings.HasPrefix(pvc.Name, pvcPrefix) {
+				continue
+			}
+			ordinal, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, pvcPrefix))
+			if err == nil && int32(ordinal) >= *sts.Spec.Replicas {
+				pod := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+				orphans[pod] = append(orphans[pod], pvc)
+			}
+			break
+		}
+	}
+	return orphans, nil
+}
+
+// Filter out the PVCs of the pods that are still running or being decommissioned.
+func (r *DorisClusterReconciler) filterTerminatedPods(
+	namespace string, podPvcs map[string][]corev1.PersistentVolumeClaim) (map[string][]corev1.PersistentVolumeClaim, error) {
+	decommissioning := make(map[string]bool)
+	for _, member := range r.CR.Status.BE.DecommissioningMembers {
+		decommissioning[member] = true
+	}
+	terminated := make(map[string][]corev1.PersistentVolumeClaim)
+	for pod, pvcs := range podPvcs {
+		if decommissioning[pod] {
+			continue
+		}
+		exist, err := r.Exist(types.NamespacedName{Namespace: namespace, Name: pod}, &corev1.Pod{})
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			terminated[pod] = pvcs
+		}
+	}
+	return terminated, nil
+}
+
+// Delete the PVCs of the removed pods and record an event for each of them.
+func (r *DorisClusterReconciler) deleteOrphanPvcs(podPvcs map[string][]corev1.PersistentVolumeClaim) error {
+	for _, pod := range util.MapSortedKeys(podPvcs) {
+		for _, pvc := range podPvcs[pod] {
+			if err := r.DeleteWhenExist(client.ObjectKeyFromObject(&pvc), &corev1.PersistentVolumeClaim{}); err != nil {
+				return err
+			}
+			if r.DryRun == nil {
+				r.RecordEvent(r.CR, corev1.EventTypeNormal, "PvcReclaimed",
+					fmt.Sprintf("deleted pvc %s of the removed BE pod %s", pvc.Name, pod))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestBePvcReclaim(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}}},
+	}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	labels := map[string]string{"app": "be"}
+	sts := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+		Spec: appv1.StatefulSetSpec{
+			Replicas: util.Pointer(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "be-storage"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "be-log"}},
+			},
+		},
+	}
+	newPvc := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		sts,
+		newPvc("be-storage-"+stsKey.Name+"-0"),
+		newPvc("be-storage-"+stsKey.Name+"-1"),
+		newPvc("be-log-"+stsKey.Name+"-1"),
+		newPvc("be-storage-"+stsKey.Name+"-2"),
+		newPvc("be-storage-other-3"),
+		// the pod of ordinal 2 is still terminating
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name + "-2", Namespace: "default"}},
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	recorder := record.NewFakeRecorder(10)
+	rec.Recorder = recorder
+
+	// retain the pvcs by default
+	assert.Nil(t, rec.recBePvcReclaim())
+
+	// the pvcs beyond the replicas are grouped by pod
+	orphans, err := rec.findOrphanPvcs(sts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{stsKey.Name + "-1", stsKey.Name + "-2"}, util.MapSortedKeys(orphans))
+	assert.Len(t, orphans[stsKey.Name+"-1"], 2)
+
+	// the pvcs of running or decommissioning pods are kept
+	terminated, err := rec.filterTerminatedPods("default", orphans)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{stsKey.Name + "-1"}, util.MapSortedKeys(terminated))
+	cr.Status.BE.DecommissioningMembers = []string{stsKey.Name + "-1"}
+	terminated, err = rec.filterTerminatedPods("default", orphans)
+	assert.NoError(t, err)
+	assert.Empty(t, terminated)
+	cr.Status.BE.DecommissioningMembers = nil
+
+	// delete the pvcs with events
+	terminated, _ = rec.filterTerminatedPods("default", orphans)
+	assert.NoError(t, rec.deleteOrphanPvcs(terminated))
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	assert.NoError(t, cli.List(context.Background(), pvcList, client.InNamespace("default")))
+	var remaining []string
+	for _, pvc := range pvcList.Items {
+		remaining = append(remaining, pvc.Name)
+	}
+	assert.ElementsMatch(t, []string{"be-storage-" + stsKey.Name + "-0", "be-storage-" + stsKey.Name + "-2", "be-storage-other-3"}, remaining)
+	assert.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, "PvcReclaimed")
+}
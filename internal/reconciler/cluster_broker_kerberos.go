@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the key of the Broker Kerberos fingerprint folded into the Broker config hash
+const brokerKerberosFingerprintHashKey = "broker-kerberos-fingerprint"
+
+// IsBrokerKerberosRotated checks whether the content of the Kerberos secret of Broker has been
+// changed since it was applied, the error of reading the secret is only logged, since it would
+// not be resolved by reconciling.
+func (r *DorisClusterReconciler) IsBrokerKerberosRotated() bool {
+	fingerprint, err := r.getBrokerKerberosFingerprint()
+	if err != nil {
+		r.Log.Error(err, "failed to check the rotation of broker kerberos secret")
+		return false
+	}
+	return fingerprint != r.CR.Status.BrokerKerberosFingerprint
+}
+
+// get the fingerprint of the Kerberos secret of Broker, which is folded into the config hash of
+// Broker so that the rotation of the keytab triggers a rolling restart of it, since the Broker
+// only logins with the keytab on startup. Returns empty string when Kerberos is not enabled.
+func (r *DorisClusterReconciler) getBrokerKerberosFingerprint() (string, error) {
+	if r.CR.Spec.Broker == nil || r.CR.Spec.Broker.Kerberos == nil {
+		return "", nil
+	}
+	spec := r.CR.Spec.Broker.Kerberos
+	secretRef := types.NamespacedName{Namespace: r.CR.ResourceKey().Namespace, Name: spec.SecretName}
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(secretRef, secret)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return "", fmt.Errorf("broker kerberos secret %s not found", util.K8sObjKeyStr(secretRef))
+	}
+	requiredKeys := []string{tran.GetBrokerKeytabKey(spec)}
+	if spec.Krb5ConfKey != "" {
+		requiredKeys = append(requiredKeys, spec.Krb5ConfKey)
+	}
+	for _, key := range requiredKeys {
+		if _, found := secret.Data[key]; !found {
+			return "", fmt.Errorf("key %s not found in broker kerberos secret %s", key, util.K8sObjKeyStr(secretRef))
+		}
+	}
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	return util.ConfigHash(data), nil
+}
+
+// fold the fingerprint of the Kerberos secret of Broker into the config hash data of Broker,
+// and record it as applied.
+func (r *DorisClusterReconciler) withBrokerKerberosFingerprint(confHashData map[string]string) (map[string]string, error) {
+	fingerprint, err := r.getBrokerKerberosFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	r.CR.Status.BrokerKerberosFingerprint = fingerprint
+	if fingerprint == "" {
+		return confHashData, nil
+	}
+	return util.MergeMaps(confHashData, map[string]string{brokerKerberosFingerprintHashKey: fingerprint}), nil
+}
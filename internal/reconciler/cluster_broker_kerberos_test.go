@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestWithBrokerKerberosFingerprint(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker-krb", Namespace: "default"},
+		Data:       map[string][]byte{"keytab": []byte("kt"), "krb5.conf": []byte("[libdefaults]")},
+	}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{Broker: &dapi.BrokerSpec{}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// the config hash data is untouched without kerberos
+	data, err := rec.withBrokerKerberosFingerprint(map[string]string{"apache_hdfs_broker.conf": "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"apache_hdfs_broker.conf": "a"}, data)
+	assert.False(t, rec.IsBrokerKerberosRotated())
+
+	cr.Spec.Broker.Kerberos = &dapi.BrokerKerberosSpec{SecretName: "broker-krb"}
+	assert.True(t, rec.IsBrokerKerberosRotated())
+	data, err = rec.withBrokerKerberosFingerprint(map[string]string{"apache_hdfs_broker.conf": "a"})
+	assert.Nil(t, err)
+	fingerprint := cr.Status.BrokerKerberosFingerprint
+	assert.NotEmpty(t, fingerprint)
+	assert.Equal(t, fingerprint, data[brokerKerberosFingerprintHashKey])
+	assert.False(t, rec.IsBrokerKerberosRotated())
+
+	// the fingerprint changes with the keytab
+	secret.Data["keytab"] = []byte("kt-rotated")
+	assert.Nil(t, cli.Update(context.Background(), secret))
+	assert.True(t, rec.IsBrokerKerberosRotated())
+	_, err = rec.withBrokerKerberosFingerprint(map[string]string{"apache_hdfs_broker.conf": "a"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, fingerprint, cr.Status.BrokerKerberosFingerprint)
+
+	// the referenced keys must exist in the secret
+	cr.Spec.Broker.Kerberos.KeytabKey = "doris.keytab"
+	_, err = rec.withBrokerKerberosFingerprint(map[string]string{"apache_hdfs_broker.conf": "a"})
+	assert.ErrorContains(t, err, "key doris.keytab not found in broker kerberos secret broker-krb.default")
+
+	// the secret must exist
+	cr.Spec.Broker.Kerberos.SecretName = "absent"
+	_, err = rec.withBrokerKerberosFingerprint(map[string]string{"apache_hdfs_broker.conf": "a"})
+	assert.ErrorContains(t, err, "broker kerberos secret absent.default not found")
+	// which is not regarded as rotated
+	assert.False(t, rec.IsBrokerKerberosRotated())
+}
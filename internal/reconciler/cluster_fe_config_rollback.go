@@ -0,0 +1,246 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	// DefaultFeConfigRollbackReadyTimeout is the default duration that the FE pods are allowed
+	// to be unready after the FE config is changed before it is rolled back.
+	DefaultFeConfigRollbackReadyTimeout = 10 * time.Minute
+	// the max number of FE config revisions kept in status
+	maxFeConfigHistory = 5
+)
+
+// check whether the automatic rollback of FE config is opted in.
+func isFeConfigRollbackEnabled(cr *dapi.DorisCluster) bool {
+	return cr.Spec.FE != nil && cr.Spec.FE.ConfigRollback != nil
+}
+
+func getFeConfigRollbackReadyTimeout(cr *dapi.DorisCluster) time.Duration {
+	seconds := util.PointerDeRefer(cr.Spec.FE.ConfigRollback.ReadyTimeoutSeconds, 0)
+	if seconds <= 0 {
+		return DefaultFeConfigRollbackReadyTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Resolve the FE config to be applied, the desired config is recorded into the config history,
+// and the user configs of configMap data are replaced with the last-known-good ones when the
+// desired config has been rolled back, so that a rolled back config is never applied again
+// until it is changed.
+// Returns the hash of the config to be applied.
+func (r *DorisClusterReconciler) resolveFeConfig(configMap *corev1.ConfigMap, desiredHash string) (string, *ClusterStageRecResult) {
+	if !isFeConfigRollbackEnabled(r.CR) || r.DryRun != nil {
+		return desiredHash, nil
+	}
+	history := r.CR.Status.FE.ConfigHistory
+	if len(history) == 0 || history[0].Hash != desiredHash {
+		history = append([]dapi.FEConfigRevision{{Hash: desiredHash, AppliedTime: metav1.Now()}}, history...)
+		if len(history) > maxFeConfigHistory {
+			history = history[:maxFeConfigHistory]
+		}
+		r.CR.Status.FE.ConfigHistory = history
+	}
+	if !history[0].RolledBack {
+		return desiredHash, nil
+	}
+	lastGood, err := r.getFeLastGoodConfigMap()
+	if err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, dapi.StageActionApply, err)
+		return "", &res
+	}
+	configMap.Data = tran.MakeFeRolledBackConfigMap(r.CR, r.Schema, lastGood).Data
+	return lastGood.Annotations[tran.FeConfigHashAnnoKey], nil
+}
+
+// Roll back the FE config when any FE pod restarted with the changed config stays unready longer
+// than the timeout, the user configs of FE are reverted to the last-known-good ones while the other
+// changes of spec are kept, and the unready FE pods of the failed revision are deleted so that they
+// can be recreated with the reverted config, since the rolling update of statefulset would be stuck
+// on them. The timeout is measured per pod, so a slow but healthy rolling update is not rolled back.
+// Returns nil when there is nothing to roll back.
+func (r *DorisClusterReconciler) recFeConfigRollback(
+	configMap *corev1.ConfigMap, statefulSet *appv1.StatefulSet, confHash string) *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	if !isFeConfigRollbackEnabled(r.CR) || r.DryRun != nil {
+		return nil
+	}
+	history := r.CR.Status.FE.ConfigHistory
+	if len(history) == 0 || history[0].Hash != confHash || history[0].Ready || history[0].RolledBack {
+		return nil
+	}
+	// roll back only to a config that has been ready
+	var lastGoodHash string
+	for _, revision := range history[1:] {
+		if revision.Ready {
+			lastGoodHash = revision.Hash
+			break
+		}
+	}
+	if lastGoodHash == "" {
+		return nil
+	}
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetFeStatefulSetKey(r.CR.ResourceKey()), curSts)
+	if err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+		return &res
+	}
+	if !exist || getStatefulSetRolloutPending(curSts, 0) == "" {
+		return nil
+	}
+	failedRevision := curSts.Status.UpdateRevision
+	timeout := getFeConfigRollbackReadyTimeout(r.CR)
+	failedPods, err := r.listFePodsOfRevision(failedRevision)
+	if err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+		return &res
+	}
+	timedOut := false
+	for _, pod := range failedPods {
+		if !util.IsPodReady(pod) && time.Since(getPodUnreadySince(pod)) >= timeout {
+			timedOut = true
+			break
+		}
+	}
+	if !timedOut {
+		return nil
+	}
+	lastGood, err := r.getFeLastGoodConfigMap()
+	if err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+		return &res
+	}
+	if goodHash := lastGood.Annotations[tran.FeConfigHashAnnoKey]; goodHash != lastGoodHash {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action,
+			fmt.Errorf("the last-known-good FE config %s is not found, got %s", lastGoodHash, goodHash))
+		return &res
+	}
+	// revert the configmap and statefulset
+	configMap.Data = tran.MakeFeRolledBackConfigMap(r.CR, r.Schema, lastGood).Data
+	if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+		return &res
+	}
+	annotatePodTemplate(statefulSet, FeConfHashAnnotationKey, lastGoodHash)
+	if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+		return &res
+	}
+	history[0].RolledBack = true
+	r.recordAppliedConfig(&r.CR.Status.FE.DorisComponentStatus, lastGoodHash, configMap.Data[tran.FeConfFileKey])
+	message := fmt.Sprintf("FE config %s is rolled back to %s since the FE pods were not ready with it within %s",
+		confHash, lastGoodHash, timeout)
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.RollbackPerformed,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReadyTimeout",
+		Message: message,
+	})
+	r.RecordEvent(r.CR, corev1.EventTypeWarning, "RollbackPerformed", message)
+	// recreate the unready pods of the failed revision
+	for i := range failedPods {
+		if util.IsPodReady(failedPods[i]) {
+			continue
+		}
+		if err := client.IgnoreNotFound(r.Delete(r.Ctx, &failedPods[i])); err != nil {
+			res := clusterStageFail(dapi.StageFeConfigRollback, action, err)
+			return &res
+		}
+		r.Log.Info("delete the unready FE pod of the rolled back config: " + failedPods[i].Name)
+	}
+	res := clusterStageWait(dapi.StageFeConfigRollback, action, fmt.Errorf("%s", message))
+	return &res
+}
+
+// Mark the applied FE config as ready once all the FE pods have been ready with it,
+// and save the user configs of it as the last-known-good ones.
+func (r *DorisClusterReconciler) markFeConfigReady(refConfigs map[string]string, confHash string) *ClusterStageRecResult {
+	if !isFeConfigRollbackEnabled(r.CR) || r.DryRun != nil {
+		return nil
+	}
+	history := r.CR.Status.FE.ConfigHistory
+	// the FE pods are running with the last-known-good config after the rollback
+	if len(history) == 0 || history[0].Hash != confHash || history[0].Ready {
+		return nil
+	}
+	lastGood := tran.MakeFeLastGoodConfigMap(r.CR, r.Schema, refConfigs, confHash)
+	if err := r.CreateOrUpdate(lastGood, &corev1.ConfigMap{}); err != nil {
+		res := clusterStageFail(dapi.StageFeConfigRollback, dapi.StageActionApply, err)
+		return &res
+	}
+	history[0].Ready = true
+	if meta.IsStatusConditionTrue(r.CR.Status.Conditions, dapi.RollbackPerformed) {
+		meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+			Type:    dapi.RollbackPerformed,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ConfigReady",
+			Message: fmt.Sprintf("FE pods are ready with the config %s", confHash),
+		})
+	}
+	return nil
+}
+
+// get the snapshot of the last-known-good FE ConfigMap.
+func (r *DorisClusterReconciler) getFeLastGoodConfigMap() (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	key := tran.GetFeLastGoodConfigMapKey(r.CR.ResourceKey())
+	exist, err := r.Exist(key, configMap)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("the last-known-good FE ConfigMap %s is not found", util.K8sObjKeyStr(key))
+	}
+	return configMap, nil
+}
+
+// list the FE pods of the statefulset revision.
+func (r *DorisClusterReconciler) listFePodsOfRevision(revision string) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	labels := util.MergeMaps(tran.GetFeComponentLabels(r.CR.ResourceKey()),
+		map[string]string{appv1.ControllerRevisionHashLabelKey: revision})
+	if err := r.List(r.Ctx, podList, client.InNamespace(r.CR.ResourceKey().Namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+// get the time since when the pod has been unready, the crash-looping pod keeps
+// unready since it is created.
+func getPodUnreadySince(pod corev1.Pod) time.Time {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && !cond.LastTransitionTime.IsZero() {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
@@ -0,0 +1,200 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func newFeConfigRollbackTestCluster() *dapi.DorisCluster {
+	return &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{FE: &dapi.FESpec{
+			DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 2, BaseImage: "doris-fe"},
+			ConfigRollback:     &dapi.FEConfigRollbackSpec{},
+		}},
+	}
+}
+
+// make the last-known-good FE ConfigMap with the given user configs.
+func newTestFeLastGoodConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, configs map[string]string) *corev1.ConfigMap {
+	goodCr := cr.DeepCopy()
+	goodCr.Spec.FE.Configs = configs
+	return tran.MakeFeLastGoodConfigMap(goodCr, scheme, nil, "hash-good")
+}
+
+func TestResolveFeConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := newFeConfigRollbackTestCluster()
+	lastGood := newTestFeLastGoodConfigMap(cr, scheme, map[string]string{"qe_max_connection": "1024"})
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lastGood).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// the desired config is recorded as the newest revision
+	configMap := &corev1.ConfigMap{Data: map[string]string{tran.FeConfFileKey: "bad"}}
+	confHash, res := rec.resolveFeConfig(configMap, "hash-bad")
+	assert.Nil(t, res)
+	assert.Equal(t, "hash-bad", confHash)
+	assert.Equal(t, "bad", configMap.Data[tran.FeConfFileKey])
+	assert.Len(t, cr.Status.FE.ConfigHistory, 1)
+	assert.Equal(t, "hash-bad", cr.Status.FE.ConfigHistory[0].Hash)
+
+	// the last-known-good config is kept once the desired one has been rolled back
+	cr.Status.FE.ConfigHistory[0].RolledBack = true
+	confHash, res = rec.resolveFeConfig(configMap, "hash-bad")
+	assert.Nil(t, res)
+	assert.Equal(t, "hash-good", confHash)
+	assert.Contains(t, configMap.Data[tran.FeConfFileKey], "qe_max_connection=1024")
+	assert.Len(t, cr.Status.FE.ConfigHistory, 1)
+
+	// the changed config is applied again
+	configMap.Data = map[string]string{tran.FeConfFileKey: "fixed"}
+	confHash, res = rec.resolveFeConfig(configMap, "hash-fixed")
+	assert.Nil(t, res)
+	assert.Equal(t, "hash-fixed", confHash)
+	assert.Equal(t, "fixed", configMap.Data[tran.FeConfFileKey])
+	assert.Equal(t, []string{"hash-fixed", "hash-bad"}, []string{cr.Status.FE.ConfigHistory[0].Hash, cr.Status.FE.ConfigHistory[1].Hash})
+
+	// the history is bounded
+	for i := 0; i < 10; i++ {
+		_, _ = rec.resolveFeConfig(configMap, string(rune('a'+i)))
+	}
+	assert.Len(t, cr.Status.FE.ConfigHistory, maxFeConfigHistory)
+}
+
+func TestRecFeConfigRollback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := newFeConfigRollbackTestCluster()
+	cr.Spec.FE.Configs = map[string]string{"qe_max_connection": "bad"}
+	cr.Status.FE.ConfigHistory = []dapi.FEConfigRevision{
+		{Hash: "hash-bad", AppliedTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+		{Hash: "hash-good", Ready: true},
+	}
+	stsKey := tran.GetFeStatefulSetKey(cr.ResourceKey())
+	feLabels := tran.GetFeComponentLabels(cr.ResourceKey())
+	curSts := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+		Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(2))},
+		Status: appv1.StatefulSetStatus{
+			Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 1,
+			CurrentRevision: "rev-good", UpdateRevision: "rev-bad",
+		},
+	}
+	// the bad pod has been unready for 5 minutes
+	unreadySince := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	newPod := func(name, revision string, ready bool) *corev1.Pod {
+		status := util.Elvis(ready, corev1.ConditionTrue, corev1.ConditionFalse)
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+				Labels: util.MergeMaps(tran.GetFeComponentLabels(cr.ResourceKey()),
+					map[string]string{appv1.ControllerRevisionHashLabelKey: revision})},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status, LastTransitionTime: unreadySince},
+			}},
+		}
+	}
+	lastGood := newTestFeLastGoodConfigMap(cr, scheme, map[string]string{"qe_max_connection": "1024"})
+	badPod := newPod(stsKey.Name+"-1", "rev-bad", false)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		curSts, lastGood,
+		newPod(stsKey.Name+"-0", "rev-good", true),
+		badPod,
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	rec.Recorder = record.NewFakeRecorder(10)
+
+	// the image is bumped along with the bad config
+	cr.Spec.FE.BaseImage = "doris-fe-new"
+	configMap := tran.MakeFeConfigMap(cr, scheme, nil)
+	statefulSet := tran.MakeFeStatefulSet(cr, scheme)
+	annotatePodTemplate(statefulSet, FeConfHashAnnotationKey, "hash-bad")
+
+	// each fe pod is allowed to be unready within the timeout, even if the whole
+	// rollout has taken longer than it
+	assert.Nil(t, rec.recFeConfigRollback(configMap, statefulSet, "hash-bad"))
+
+	badPod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	assert.NoError(t, cli.Status().Update(context.Background(), badPod))
+	res := rec.recFeConfigRollback(configMap, statefulSet, "hash-bad")
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageFeConfigRollback, res.Stage)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.True(t, cr.Status.FE.ConfigHistory[0].RolledBack)
+	assert.Equal(t, "hash-good", cr.Status.FE.LastAppliedConfigHash)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.RollbackPerformed))
+
+	// only the user configs are reverted, the image bump is kept
+	appliedCm := &corev1.ConfigMap{}
+	assert.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(configMap), appliedCm))
+	assert.Contains(t, appliedCm.Data[tran.FeConfFileKey], "qe_max_connection=1024")
+	assert.Contains(t, appliedCm.Data[tran.FeConfFileKey], "enable_fqdn_mode=true")
+	appliedSts := &appv1.StatefulSet{}
+	assert.NoError(t, cli.Get(context.Background(), stsKey, appliedSts))
+	assert.Equal(t, "hash-good", appliedSts.Spec.Template.Annotations[FeConfHashAnnotationKey])
+	assert.Contains(t, appliedSts.Spec.Template.Spec.Containers[0].Image, "doris-fe-new")
+
+	// only the unready pod of the failed revision is deleted
+	pods := &corev1.PodList{}
+	assert.NoError(t, cli.List(context.Background(), pods, client.MatchingLabels(feLabels)))
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, stsKey.Name+"-0", pods.Items[0].Name)
+
+	// the config is rolled back only once
+	assert.Nil(t, rec.recFeConfigRollback(configMap, statefulSet, "hash-bad"))
+}
+
+func TestMarkFeConfigReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := newFeConfigRollbackTestCluster()
+	cr.Status.FE.ConfigHistory = []dapi.FEConfigRevision{{Hash: "hash-fixed"}, {Hash: "hash-bad", RolledBack: true}}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type: dapi.RollbackPerformed, Status: metav1.ConditionTrue, Reason: "ReadyTimeout",
+	})
+	cr.Spec.FE.ConfigFileContent = "sys_log_level = INFO"
+	cr.Spec.FE.Configs = map[string]string{"qe_max_connection": "2048"}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	assert.Nil(t, rec.markFeConfigReady(map[string]string{"qe_max_connection": "1024", "max_conn": "10"}, "hash-fixed"))
+	assert.True(t, cr.Status.FE.ConfigHistory[0].Ready)
+	assert.True(t, meta.IsStatusConditionFalse(cr.Status.Conditions, dapi.RollbackPerformed))
+	lastGood, err := rec.getFeLastGoodConfigMap()
+	assert.NoError(t, err)
+	assert.Equal(t, "sys_log_level = INFO", lastGood.Data[tran.FeLastGoodRawConfKey])
+	assert.Equal(t, "max_conn=10\nqe_max_connection=2048", lastGood.Data[tran.FeLastGoodConfKey])
+	assert.Equal(t, "hash-fixed", lastGood.Annotations[tran.FeConfigHashAnnoKey])
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// reconcile the Ingress of FE web UI, it would be skipped with a warning event
+// when the networking.k8s.io/v1 Ingress API is not available.
+func (r *DorisClusterReconciler) recFeIngress() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	available, err := r.isIngressApiAvailable()
+	if err != nil {
+		res := clusterStageFail(dapi.StageFeIngress, action, err)
+		return &res
+	}
+	ingress := tran.MakeFeIngress(r.CR, r.Schema)
+	if !available {
+		if ingress != nil {
+			r.Log.Info("skip creating FE Ingress since the networking.k8s.io/v1 Ingress API is not available")
+			r.RecordEvent(r.CR, corev1.EventTypeWarning, "IngressUnavailable",
+				"Skip creating FE Ingress since the networking.k8s.io/v1 Ingress API is not available")
+		}
+		return nil
+	}
+	if ingress == nil {
+		if err := r.DeleteWhenExist(tran.GetFeIngressKey(r.CR.ResourceKey()), &networkingv1.Ingress{}); err != nil {
+			res := clusterStageFail(dapi.StageFeIngress, dapi.StageActionDelete, err)
+			return &res
+		}
+		return nil
+	}
+	if err := r.CreateOrUpdate(ingress, &networkingv1.Ingress{}); err != nil {
+		res := clusterStageFail(dapi.StageFeIngress, action, err)
+		return &res
+	}
+	return nil
+}
+
+// check whether the networking.k8s.io/v1 Ingress API is available.
+func (r *DorisClusterReconciler) isIngressApiAvailable() (bool, error) {
+	gvk := networkingv1.SchemeGroupVersion.WithKind("Ingress")
+	if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcile the services of each FE pod, the services of the ordinals out of the replicas
+// are deleted, and all of them are deleted when the podService is disabled or FE is removed.
+func (r *DorisClusterReconciler) recFePodServices() *ClusterStageRecResult {
+	services := tran.MakeFePodServices(r.CR, r.Schema)
+	desired := make(map[string]bool, len(services))
+	for _, service := range services {
+		if err := r.CreateOrUpdate(service, &corev1.Service{}); err != nil {
+			res := clusterStageFail(dapi.StageFePodService, dapi.StageActionApply, err)
+			return &res
+		}
+		desired[service.Name] = true
+	}
+	// prune the stale services
+	ordinalExists, err := labels.NewRequirement(tran.FePodServiceOrdinalLabelKey, selection.Exists, nil)
+	if err != nil {
+		res := clusterStageFail(dapi.StageFePodService, dapi.StageActionDelete, err)
+		return &res
+	}
+	selector := labels.SelectorFromSet(tran.GetFeComponentLabels(r.CR.ResourceKey())).Add(*ordinalExists)
+	serviceList := &corev1.ServiceList{}
+	if err := r.List(r.Ctx, serviceList, client.InNamespace(r.CR.ResourceKey().Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		res := clusterStageFail(dapi.StageFePodService, dapi.StageActionDelete, err)
+		return &res
+	}
+	for _, service := range serviceList.Items {
+		if desired[service.Name] {
+			continue
+		}
+		if err := r.DeleteWhenExist(client.ObjectKeyFromObject(&service), &corev1.Service{}); err != nil {
+			res := clusterStageFail(dapi.StageFePodService, dapi.StageActionDelete, err)
+			return &res
+		}
+	}
+	return nil
+}
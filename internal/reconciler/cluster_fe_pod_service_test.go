@@ -0,0 +1,82 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecFePodServices(t *testing.T) {
+	// the sub resources are placed in the namespace of DorisCluster or the resource namespace
+	for _, resourceNamespace := range []string{"", "doris-res"} {
+		t.Run("resourceNamespace="+resourceNamespace, func(t *testing.T) {
+			testRecFePodServices(t, resourceNamespace)
+		})
+	}
+}
+
+func testRecFePodServices(t *testing.T, resourceNamespace string) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			ResourceNamespace: resourceNamespace,
+			FE: &dapi.FESpec{
+				DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3},
+				PodService:         &dapi.FePodServiceSpec{},
+			},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	listServices := func() []string {
+		services := &corev1.ServiceList{}
+		assert.NoError(t, cli.List(context.Background(), services, client.InNamespace(cr.ResourceKey().Namespace)))
+		var names []string
+		for _, svc := range services.Items {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+
+	assert.Nil(t, rec.recFePodServices())
+	assert.ElementsMatch(t, []string{"test-fe-0", "test-fe-1", "test-fe-2"}, listServices())
+
+	// the services of the removed ordinals are deleted on scale-in
+	cr.Spec.FE.Replicas = 1
+	assert.Nil(t, rec.recFePodServices())
+	assert.ElementsMatch(t, []string{"test-fe-0"}, listServices())
+
+	// the other FE services are left untouched when the pod services are disabled
+	assert.NoError(t, cli.Create(context.Background(), tran.MakeFeService(cr, scheme)))
+	cr.Spec.FE.PodService = nil
+	assert.Nil(t, rec.recFePodServices())
+	assert.ElementsMatch(t, []string{"test-fe"}, listServices())
+}
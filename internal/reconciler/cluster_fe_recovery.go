@@ -0,0 +1,265 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFeMetadataRecoveryRestarts is the default number of consecutive restarts of
+	// the FE pod that triggers the metadata recovery.
+	DefaultFeMetadataRecoveryRestarts = 5
+	// FeMetadataRecoveryCheckInterval is the interval to check the FE pods when the metadata
+	// recovery is enabled, since the restarts of pods do not trigger the reconciliation.
+	FeMetadataRecoveryCheckInterval = 30 * time.Second
+	// the max number of FE metadata recovery records kept in status
+	maxFeMetadataRecoveryRecords = 10
+)
+
+// IsFeMetadataRecoveryEnabled checks whether the automatic FE metadata recovery is opted in
+// via the annotation of DorisCluster.
+func IsFeMetadataRecoveryEnabled(cr *dapi.DorisCluster) bool {
+	return cr.Spec.FE != nil && cr.Annotations[tran.FeMetadataRecoveryAnnoKey] == "true"
+}
+
+// get the number of consecutive restarts of the FE pod that triggers the metadata recovery.
+func getFeMetadataRecoveryRestarts(cr *dapi.DorisCluster) int32 {
+	value, err := strconv.Atoi(cr.Annotations[tran.FeMetadataRecoveryRestartsAnnoKey])
+	if err != nil || value < 1 {
+		return DefaultFeMetadataRecoveryRestarts
+	}
+	return int32(value)
+}
+
+// RecFeMetadataRecovery starts the FE follower with the latest metadata in the metadata failure
+// recovery mode when it is opted in and it keeps crash-looping, and reverts the pod as soon as it
+// has rejoined the Doris cluster or the recovery is disabled. The recovery is only applied when
+// neither the FE master nor the quorum of followers is reachable, otherwise it would cause the
+// split-brain of FE. Only a single FE pod is recovered at a time to avoid the data loss.
+func (r *DorisClusterReconciler) RecFeMetadataRecovery() error {
+	if r.CR.Spec.FE == nil {
+		return nil
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(r.Ctx, podList, client.InNamespace(r.CR.ResourceKey().Namespace),
+		client.MatchingLabels(tran.GetFeComponentLabels(r.CR.ResourceKey()))); err != nil {
+		return err
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return getPodOrdinal(pods[i].Name) < getPodOrdinal(pods[j].Name)
+	})
+	status := util.PointerDeRefer(r.CR.Status.FE.MetadataRecovery.DeepCopy(), dapi.FEMetadataRecoveryStatus{})
+	defer func() {
+		if status.RecoveringMember != "" || len(status.Records) > 0 {
+			r.CR.Status.FE.MetadataRecovery = &status
+		}
+	}()
+	enabled := IsFeMetadataRecoveryEnabled(r.CR)
+	frontends, quorumReachable := r.getReachableFrontends()
+	aliveHosts := make(map[string]bool)
+	for _, frontend := range frontends {
+		if frontend.Alive {
+			aliveHosts[frontend.Host] = true
+		}
+	}
+
+	// revert the recovering pod once it has rejoined the Doris cluster or the recovery is disabled
+	status.RecoveringMember = ""
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Annotations[tran.FeMetadataFailureRecoveryPodAnnoKey] != "true" {
+			continue
+		}
+		rejoined := util.IsPodReady(*pod) || aliveHosts[tran.GetFePodFQDN(r.CR, pod.Name)]
+		if enabled && !rejoined {
+			status.RecoveringMember = pod.Name
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		delete(pod.Annotations, tran.FeMetadataFailureRecoveryPodAnnoKey)
+		if err := r.Patch(r.Ctx, pod, patch); err != nil {
+			return err
+		}
+		message := util.Elvis(rejoined,
+			"FE pod started in metadata failure recovery mode has rejoined the Doris cluster",
+			"FE metadata recovery is disabled")
+		r.recordFeMetadataRecovery(&status, pod, dapi.FEMetadataRecoveryReverted, message)
+	}
+	if !enabled || status.RecoveringMember != "" || quorumReachable {
+		return nil
+	}
+
+	// start the follower with the latest metadata in the metadata failure recovery mode
+	// once it keeps crash-looping
+	pod := pickFeMetadataRecoveryCandidate(pods)
+	if pod == nil {
+		return nil
+	}
+	restarts, crashLooping := getFeContainerCrashLoop(pod)
+	if !crashLooping || restarts-getLastFeMetadataRecoveryRestarts(&status, pod.Name, restarts) < getFeMetadataRecoveryRestarts(r.CR) {
+		return nil
+	}
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[tran.FeMetadataFailureRecoveryPodAnnoKey] = "true"
+	if err := r.Patch(r.Ctx, pod, patch); err != nil {
+		return err
+	}
+	status.RecoveringMember = pod.Name
+	journalId, _ := getFeMetaJournal(pod)
+	r.recordFeMetadataRecovery(&status, pod, dapi.FEMetadataRecoveryStarted,
+		fmt.Sprintf("FE pod with the latest metadata journal %d restarted %d times consecutively while no FE master "+
+			"or quorum is reachable, start it in metadata failure recovery mode", journalId, restarts))
+	return nil
+}
+
+// get the frontends of the Doris cluster and whether the FE master or the quorum of followers
+// is reachable, the frontends are empty when FE is not reachable at all.
+func (r *DorisClusterReconciler) getReachableFrontends() ([]fe.Frontend, bool) {
+	feCli, err := r.connectFe()
+	if err != nil {
+		return nil, false
+	}
+	defer feCli.Close()
+	frontends, err := feCli.ShowFrontends()
+	if err != nil {
+		return nil, false
+	}
+	followers, aliveFollowers := 0, 0
+	for _, frontend := range frontends {
+		if frontend.IsMaster && frontend.Alive {
+			return frontends, true
+		}
+		if strings.EqualFold(frontend.Role, "FOLLOWER") {
+			followers++
+			if frontend.Alive {
+				aliveFollowers++
+			}
+		}
+	}
+	return frontends, followers > 0 && aliveFollowers >= followers/2+1
+}
+
+// pick the FE follower pod with the latest metadata, which reports the highest journal id of
+// its metadata image via the termination message, the lowest ordinal wins the tie.
+// The pods are expected to be sorted by ordinal.
+func pickFeMetadataRecoveryCandidate(pods []corev1.Pod) *corev1.Pod {
+	var candidate *corev1.Pod
+	var maxJournalId int64 = -1
+	for i := range pods {
+		pod := &pods[i]
+		journalId, role := getFeMetaJournal(pod)
+		if pod.DeletionTimestamp != nil || strings.EqualFold(role, "OBSERVER") {
+			continue
+		}
+		if journalId > maxJournalId {
+			candidate, maxJournalId = pod, journalId
+		}
+	}
+	return candidate
+}
+
+// parse the journal id of the latest metadata image and the role of FE reported by the FE
+// container via the termination message of its last run, e.g. "journal_id=1024 role=FOLLOWER".
+func getFeMetaJournal(pod *corev1.Pod) (int64, string) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != "fe" {
+			continue
+		}
+		terminated := util.Coalesce(status.State.Terminated, status.LastTerminationState.Terminated)
+		if terminated == nil {
+			return 0, ""
+		}
+		var journalId int64
+		var role string
+		for _, field := range strings.Fields(terminated.Message) {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "journal_id":
+				journalId, _ = strconv.ParseInt(value, 10, 64)
+			case "role":
+				role = value
+			}
+		}
+		return journalId, role
+	}
+	return 0, ""
+}
+
+// record the FE metadata recovery action in the status and events.
+func (r *DorisClusterReconciler) recordFeMetadataRecovery(status *dapi.FEMetadataRecoveryStatus,
+	pod *corev1.Pod, action dapi.FEMetadataRecoveryAction, message string) {
+	restarts, _ := getFeContainerCrashLoop(pod)
+	status.Records = append(status.Records, dapi.FEMetadataRecoveryRecord{
+		Member:       pod.Name,
+		Action:       action,
+		RestartCount: restarts,
+		Time:         metav1.Now(),
+		Message:      message,
+	})
+	if len(status.Records) > maxFeMetadataRecoveryRecords {
+		status.Records = status.Records[len(status.Records)-maxFeMetadataRecoveryRecords:]
+	}
+	eventType := util.Elvis(action == dapi.FEMetadataRecoveryStarted, corev1.EventTypeWarning, corev1.EventTypeNormal)
+	r.RecordEvent(r.CR, eventType, "FeMetadataRecovery"+string(action), fmt.Sprintf("%s: %s", pod.Name, message))
+	r.Log.Info(fmt.Sprintf("FE metadata recovery %s on pod %s: %s", action, pod.Name, message))
+}
+
+// get the restart count of the FE container and whether it is in crash loop back-off.
+func getFeContainerCrashLoop(pod *corev1.Pod) (int32, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != "fe" {
+			continue
+		}
+		crashLooping := status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff"
+		return status.RestartCount, crashLooping
+	}
+	return 0, false
+}
+
+// get the restart count of the FE pod when the last recovery action is taken, the restarts
+// are counted from it, and it is 0 when the pod has been recreated since then.
+func getLastFeMetadataRecoveryRestarts(status *dapi.FEMetadataRecoveryStatus, podName string, restarts int32) int32 {
+	for i := len(status.Records) - 1; i >= 0; i-- {
+		record := status.Records[i]
+		if record.Member != podName {
+			continue
+		}
+		if record.RestartCount > restarts {
+			return 0
+		}
+		return record.RestartCount
+	}
+	return 0
+}
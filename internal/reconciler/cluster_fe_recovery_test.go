@@ -0,0 +1,161 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func newTestFePod(name string, restarts int32, crashLooping bool, journal string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: tran.GetFeComponentLabels(
+			client.ObjectKey{Name: "test", Namespace: "default"})},
+	}
+	status := corev1.ContainerStatus{Name: "fe", RestartCount: restarts}
+	if crashLooping {
+		status.State.Waiting = &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}
+	}
+	if journal != "" {
+		status.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Message: journal}
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+	return pod
+}
+
+func TestRecFeMetadataRecovery(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.FE = &dapi.FESpec{}
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+		newTestFePod("test-fe-0", 1, false, "journal_id=100 role=FOLLOWER"),
+		newTestFePod("test-fe-1", 6, true, "journal_id=300 role=FOLLOWER"),
+		newTestFePod("test-fe-2", 8, true, "journal_id=200 role=FOLLOWER"),
+		newTestFePod("test-fe-3", 8, true, "journal_id=400 role=OBSERVER"),
+	).Build()
+	feCli := &fe.FakeClient{Frontends: []fe.Frontend{
+		{Host: tran.GetFePodFQDN(cr, "test-fe-0"), Role: "FOLLOWER", IsMaster: true, Alive: true},
+		{Host: tran.GetFePodFQDN(cr, "test-fe-1"), Role: "FOLLOWER"},
+		{Host: tran.GetFePodFQDN(cr, "test-fe-2"), Role: "FOLLOWER"},
+	}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+	isRecovering := func(podName string) bool {
+		pod := &corev1.Pod{}
+		assert.NoError(t, cli.Get(context.Background(), client.ObjectKey{Name: podName, Namespace: "default"}, pod))
+		return pod.Annotations[tran.FeMetadataFailureRecoveryPodAnnoKey] == "true"
+	}
+
+	// nothing happens without opt-in
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-1"))
+	assert.Nil(t, cr.Status.FE.MetadataRecovery)
+
+	// never recover while the FE master is reachable
+	cr.Annotations = map[string]string{tran.FeMetadataRecoveryAnnoKey: "true"}
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-1"))
+	assert.Nil(t, cr.Status.FE.MetadataRecovery)
+
+	// never recover while the quorum of followers is reachable
+	feCli.Frontends[0].IsMaster = false
+	feCli.Frontends[1].Alive = true
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-1"))
+	assert.Nil(t, cr.Status.FE.MetadataRecovery)
+
+	// only the follower with the latest metadata is recovered when FE is unreachable
+	feCli.Err = errors.New("connection refused")
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-0"))
+	assert.True(t, isRecovering("test-fe-1"))
+	assert.False(t, isRecovering("test-fe-2"))
+	assert.False(t, isRecovering("test-fe-3"))
+	assert.Equal(t, "test-fe-1", cr.Status.FE.MetadataRecovery.RecoveringMember)
+	assert.Len(t, cr.Status.FE.MetadataRecovery.Records, 1)
+	assert.Equal(t, dapi.FEMetadataRecoveryStarted, cr.Status.FE.MetadataRecovery.Records[0].Action)
+
+	// keep waiting while the recovering pod has not rejoined
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.True(t, isRecovering("test-fe-1"))
+	assert.False(t, isRecovering("test-fe-2"))
+	assert.Len(t, cr.Status.FE.MetadataRecovery.Records, 1)
+
+	// revert as soon as the recovering pod has rejoined
+	feCli.Err = nil
+	feCli.Frontends = []fe.Frontend{{Host: tran.GetFePodFQDN(cr, "test-fe-1"), Role: "FOLLOWER", IsMaster: true, Alive: true}}
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-1"))
+	assert.Empty(t, cr.Status.FE.MetadataRecovery.RecoveringMember)
+	assert.Len(t, cr.Status.FE.MetadataRecovery.Records, 2)
+	assert.Equal(t, dapi.FEMetadataRecoveryReverted, cr.Status.FE.MetadataRecovery.Records[1].Action)
+
+	// the restarts are counted from the last recovery action
+	feCli.Err = errors.New("connection refused")
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-1"))
+	assert.False(t, isRecovering("test-fe-2"))
+
+	// revert once the recovery is disabled
+	pod := newTestFePod("test-fe-2", 8, true, "")
+	pod.Annotations = map[string]string{tran.FeMetadataFailureRecoveryPodAnnoKey: "true"}
+	assert.NoError(t, cli.Update(context.Background(), pod))
+	cr.Annotations = nil
+	assert.NoError(t, rec.RecFeMetadataRecovery())
+	assert.False(t, isRecovering("test-fe-2"))
+	assert.Len(t, cr.Status.FE.MetadataRecovery.Records, 3)
+}
+
+func TestGetFeMetaJournal(t *testing.T) {
+	journalId, role := getFeMetaJournal(newTestFePod("test-fe-0", 1, true, "journal_id=1024 role=FOLLOWER"))
+	assert.Equal(t, int64(1024), journalId)
+	assert.Equal(t, "FOLLOWER", role)
+	journalId, role = getFeMetaJournal(newTestFePod("test-fe-0", 1, true, ""))
+	assert.Equal(t, int64(0), journalId)
+	assert.Empty(t, role)
+}
+
+func TestGetFeMetadataRecoveryRestarts(t *testing.T) {
+	cr := &dapi.DorisCluster{}
+	assert.Equal(t, int32(DefaultFeMetadataRecoveryRestarts), getFeMetadataRecoveryRestarts(cr))
+	cr.Annotations = map[string]string{tran.FeMetadataRecoveryRestartsAnnoKey: "10"}
+	assert.Equal(t, int32(10), getFeMetadataRecoveryRestarts(cr))
+	cr.Annotations = map[string]string{tran.FeMetadataRecoveryRestartsAnnoKey: "-1"}
+	assert.Equal(t, int32(DefaultFeMetadataRecoveryRestarts), getFeMetadataRecoveryRestarts(cr))
+}
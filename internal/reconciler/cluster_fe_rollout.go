@@ -0,0 +1,171 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Restart the outdated FE pods one by one when the leader-aware rollout is enabled,
+// the FE master is restarted last to minimize the leader elections, and each pod
+// is restarted only after all FE nodes have rejoined the Doris cluster.
+// Returns nil when all FE pods are running with the update revision.
+func (r *DorisClusterReconciler) recFeLeaderAwareRollout() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageFeRollout, action, err)
+		return &res
+	}
+	wait := func(format string, args ...any) *ClusterStageRecResult {
+		res := clusterStageWait(dapi.StageFeRollout, action, fmt.Errorf(format, args...))
+		return &res
+	}
+	prevRollout := r.CR.Status.FE.Rollout
+	r.CR.Status.FE.Rollout = nil
+	if !r.CR.Spec.FE.LeaderAwareRollout {
+		return nil
+	}
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetFeStatefulSetKey(r.CR.ResourceKey()), sts)
+	if err != nil {
+		return fail(err)
+	}
+	if !exist {
+		return nil
+	}
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdateRevision == "" {
+		return wait("waiting for FE statefulset to observe the latest spec")
+	}
+
+	// find the FE pods that are not running with the update revision
+	podList := &corev1.PodList{}
+	if err := r.List(r.Ctx, podList, client.InNamespace(r.CR.ResourceKey().Namespace),
+		client.MatchingLabels(tran.GetFeComponentLabels(r.CR.ResourceKey()))); err != nil {
+		return fail(err)
+	}
+	var updated, outdated []string
+	allReady := true
+	for _, pod := range podList.Items {
+		if pod.Labels[appv1.StatefulSetRevisionLabel] == sts.Status.UpdateRevision {
+			updated = append(updated, pod.Name)
+		} else {
+			outdated = append(outdated, pod.Name)
+		}
+		if pod.DeletionTimestamp != nil || !util.IsPodReady(pod) {
+			allReady = false
+		}
+	}
+	if len(outdated) == 0 {
+		return nil
+	}
+	sort.Strings(updated)
+	rollout := &dapi.FERolloutStatus{
+		UpdateRevision: sts.Status.UpdateRevision,
+		UpdatedMembers: updated,
+		PendingMembers: orderFeRestartMembers(outdated, ""),
+	}
+	if prevRollout != nil {
+		rollout.RestartingMember = prevRollout.RestartingMember
+	}
+	r.CR.Status.FE.Rollout = rollout
+
+	// wait for the previous restarted FE pod to rejoin
+	replicas := util.PointerDeRefer(sts.Spec.Replicas, 1)
+	if !allReady || len(podList.Items) < int(replicas) {
+		return wait("waiting for all FE pods to be ready before restarting the next one")
+	}
+	feCli, err := r.connectFe()
+	if err != nil {
+		return fail(err)
+	}
+	defer feCli.Close()
+	frontends, err := feCli.ShowFrontends()
+	if err != nil {
+		return fail(err)
+	}
+	var aliveCount int32
+	var masterHost string
+	for _, frontend := range frontends {
+		if frontend.Alive {
+			aliveCount++
+		}
+		if frontend.IsMaster {
+			masterHost = frontend.Host
+		}
+	}
+	if aliveCount < replicas {
+		return wait("waiting for all FE nodes to rejoin the Doris cluster before restarting the next one")
+	}
+
+	// restart the next FE pod, the master is the last one
+	masterPod := ""
+	for _, podName := range outdated {
+		if tran.GetFePodFQDN(r.CR, podName) == masterHost {
+			masterPod = podName
+		}
+	}
+	pending := orderFeRestartMembers(outdated, masterPod)
+	target := &corev1.Pod{}
+	target.Name = pending[0]
+	target.Namespace = r.CR.ResourceKey().Namespace
+	if err := client.IgnoreNotFound(r.Delete(r.Ctx, target)); err != nil {
+		return fail(err)
+	}
+	r.Log.Info(fmt.Sprintf("restart FE pod: %s", target.Name))
+	rollout.RestartingMember = target.Name
+	rollout.PendingMembers = pending[1:]
+	return wait("restarting FE pod %s", target.Name)
+}
+
+// Order the FE pods to be restarted by ordinal in descending order like the
+// statefulset rolling update, and move the master pod to the last.
+func orderFeRestartMembers(podNames []string, masterPod string) []string {
+	ordered := make([]string, 0, len(podNames))
+	for _, podName := range podNames {
+		if podName != masterPod {
+			ordered = append(ordered, podName)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return getPodOrdinal(ordered[i]) > getPodOrdinal(ordered[j])
+	})
+	if masterPod != "" && len(ordered) < len(podNames) {
+		ordered = append(ordered, masterPod)
+	}
+	return ordered
+}
+
+// get the ordinal of the statefulset pod from its name.
+func getPodOrdinal(podName string) int {
+	idx := strings.LastIndex(podName, "-")
+	ordinal, err := strconv.Atoi(podName[idx+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
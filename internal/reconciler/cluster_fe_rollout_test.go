@@ -0,0 +1,32 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrderFeRestartMembers(t *testing.T) {
+	pods := []string{"test-fe-0", "test-fe-2", "test-fe-10", "test-fe-1"}
+	assert.Equal(t, []string{"test-fe-10", "test-fe-2", "test-fe-0", "test-fe-1"}, orderFeRestartMembers(pods, "test-fe-1"))
+	assert.Equal(t, []string{"test-fe-10", "test-fe-2", "test-fe-1", "test-fe-0"}, orderFeRestartMembers(pods, ""))
+	// the master has been restarted
+	assert.Equal(t, []string{"test-fe-2", "test-fe-0"}, orderFeRestartMembers([]string{"test-fe-0", "test-fe-2"}, "test-fe-1"))
+}
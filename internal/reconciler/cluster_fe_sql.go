@@ -0,0 +1,112 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	corev1 "k8s.io/api/core/v1"
+	"sort"
+	"strings"
+)
+
+// connect to the Doris FE query port via the operator sql account.
+func (r *DorisClusterReconciler) connectFe() (fe.Client, error) {
+	if r.DryRun != nil {
+		return nil, errDryRunSkipped
+	}
+	user, password, err := r.getOprSqlAccount()
+	if err != nil {
+		return nil, err
+	}
+	connConf := fe.ConnConf{
+		Host:     tran.GetFeServiceDNS(r.CR),
+		Port:     tran.GetFeQueryPort(r.CR),
+		User:     user,
+		Password: password,
+	}
+	return r.newFeClient(connConf)
+}
+
+// create the FE client with the NewFeClient factory, which defaults to fe.NewClient.
+func (r *DorisClusterReconciler) newFeClient(conf fe.ConnConf) (fe.Client, error) {
+	if r.NewFeClient != nil {
+		return r.NewFeClient(conf)
+	}
+	return fe.NewClient(conf)
+}
+
+// get the user and password of the operator sql account from its secret.
+func (r *DorisClusterReconciler) getOprSqlAccount() (string, string, error) {
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(tran.GetOprSqlAccountSecretRef(r.CR), secret)
+	if err != nil {
+		return "", "", err
+	}
+	if !exist {
+		return "", "", errors.New("operator sql account secret has not been created yet")
+	}
+	return string(secret.Data[tran.OprSqlAccountUserKey]), string(secret.Data[tran.OprSqlAccountPasswordKey]), nil
+}
+
+// fill the alive FE count and the follower/observer membership from the "/api/show_proc"
+// http api of FE, which does not occupy the connections of the FE query port.
+func (r *DorisClusterReconciler) fillFrontendMembers(feStatus *dapi.FEStatus) error {
+	if r.DryRun != nil {
+		return errDryRunSkipped
+	}
+	user, password, err := r.getOprSqlAccount()
+	if err != nil {
+		return err
+	}
+	showFrontends := fe.ShowFrontendsViaHttp
+	if r.ShowFrontendsViaHttp != nil {
+		showFrontends = r.ShowFrontendsViaHttp
+	}
+	frontends, err := showFrontends(fe.FrontendHttpConf{
+		Host:     tran.GetFeServiceDNS(r.CR),
+		Port:     tran.GetFeHttpPort(r.CR),
+		User:     user,
+		Password: password,
+	})
+	if err != nil {
+		return err
+	}
+	feStatus.AliveMembers = 0
+	feStatus.Followers = nil
+	feStatus.Observers = nil
+	for _, frontend := range frontends {
+		if frontend.Alive {
+			feStatus.AliveMembers++
+		}
+		// the host of frontend is the FQDN of FE pod
+		podName := strings.SplitN(frontend.Host, ".", 2)[0]
+		switch frontend.Role {
+		case fe.FrontendRoleFollower:
+			feStatus.Followers = append(feStatus.Followers, podName)
+		case fe.FrontendRoleObserver:
+			feStatus.Observers = append(feStatus.Observers, podName)
+		}
+	}
+	sort.Strings(feStatus.Followers)
+	sort.Strings(feStatus.Observers)
+	return nil
+}
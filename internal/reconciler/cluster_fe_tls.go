@@ -0,0 +1,79 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the key of the FE TLS fingerprint folded into the FE config hash
+const feTlsFingerprintHashKey = "tls-fingerprint"
+
+// IsFeTlsRotated checks whether the content of the FE TLS secret has been changed since
+// it was applied, the error of reading the secret is only logged, since it would not be
+// resolved by reconciling.
+func (r *DorisClusterReconciler) IsFeTlsRotated() bool {
+	if r.CR.Spec.FE == nil {
+		return false
+	}
+	fingerprint, err := r.getFeTlsFingerprint()
+	if err != nil {
+		r.Log.Error(err, "failed to check the rotation of FE TLS secret")
+		return false
+	}
+	return fingerprint != r.CR.Status.FeTlsFingerprint
+}
+
+// get the fingerprint of the FE TLS secret, which is folded into the FE config hash so
+// that rotating the keystores triggers a rolling restart of FE.
+// Returns empty string when the TLS of FE is not enabled.
+func (r *DorisClusterReconciler) getFeTlsFingerprint() (string, error) {
+	tls := r.CR.Spec.FE.TLS
+	if tls == nil {
+		return "", nil
+	}
+	secretRef := types.NamespacedName{Namespace: r.CR.ResourceKey().Namespace, Name: tls.SecretName}
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(secretRef, secret)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return "", fmt.Errorf("fe tls secret %s not found", util.K8sObjKeyStr(secretRef))
+	}
+	for _, key := range tran.GetFeTlsRequiredSecretKeys() {
+		if len(secret.Data[key]) == 0 {
+			return "", fmt.Errorf("key %s not found in fe tls secret %s", key, util.K8sObjKeyStr(secretRef))
+		}
+	}
+	return feTlsFingerprint(secret), nil
+}
+
+// compute the fingerprint of the keystores and the passwords of them in the FE TLS secret.
+func feTlsFingerprint(secret *corev1.Secret) string {
+	data := make(map[string]string)
+	for _, key := range []string{tran.FeTlsCaCertKey, tran.FeTlsServerCertKey, tran.FeTlsCaPasswordKey, tran.FeTlsServerPasswordKey} {
+		data[key] = string(secret.Data[key])
+	}
+	return util.ConfigHash(data)
+}
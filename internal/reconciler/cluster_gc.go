@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Garbage collect the sub resources of the deleting DorisCluster that are placed in another
+// namespace, which are found by the owner labels since the cascading deletion does not apply.
+func (r *DorisClusterReconciler) gcCrossNamespaceResources() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	if !tran.IsCrossNamespace(r.CR) {
+		return clusterStageSucc(dapi.StageGarbageCollect, action)
+	}
+	lists := []client.ObjectList{
+		&appv1.StatefulSetList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.SecretList{},
+		&policyv1.PodDisruptionBudgetList{},
+	}
+	ingressAvailable, err := r.isIngressApiAvailable()
+	if err != nil {
+		return clusterStageFail(dapi.StageGarbageCollect, action, err)
+	}
+	if ingressAvailable {
+		lists = append(lists, &networkingv1.IngressList{})
+	}
+	installed, err := r.isServiceMonitorCrdInstalled()
+	if err != nil {
+		return clusterStageFail(dapi.StageGarbageCollect, action, err)
+	}
+	if installed {
+		monitorList := &unstructured.UnstructuredList{}
+		monitorList.SetGroupVersionKind(tran.ServiceMonitorGVK.GroupVersion().WithKind(tran.ServiceMonitorGVK.Kind + "List"))
+		lists = append(lists, monitorList)
+	}
+	for _, list := range lists {
+		if err := r.deleteByClusterOwnerLabels(list); err != nil {
+			return clusterStageFail(dapi.StageGarbageCollect, action, err)
+		}
+	}
+	return clusterStageSucc(dapi.StageGarbageCollect, action)
+}
+
+// delete the objects in the resource namespace that labeled with the owner DorisCluster.
+func (r *DorisClusterReconciler) deleteByClusterOwnerLabels(list client.ObjectList) error {
+	if err := r.List(r.Ctx, list, client.InNamespace(r.CR.ResourceKey().Namespace),
+		client.MatchingLabels(tran.GetClusterOwnerLabels(r.CR))); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		if err := client.IgnoreNotFound(r.Delete(r.Ctx, obj)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
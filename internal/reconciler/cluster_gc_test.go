@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestGcCrossNamespaceResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.ResourceNamespace = "doris"
+	ownerLabels := tran.GetClusterOwnerLabels(cr)
+	objMeta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "doris", Labels: labels}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{ObjectMeta: objMeta("test-fe-config", ownerLabels)},
+		&corev1.Service{ObjectMeta: objMeta("test-fe", ownerLabels)},
+		&appv1.StatefulSet{ObjectMeta: objMeta("test-fe", ownerLabels)},
+		&corev1.Secret{ObjectMeta: objMeta("test-opr-account", ownerLabels)},
+		// the objects not owned by the DorisCluster are left untouched
+		&corev1.ConfigMap{ObjectMeta: objMeta("other-config", nil)},
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	res := rec.gcCrossNamespaceResources()
+	assert.NoError(t, res.Err)
+	assert.Equal(t, dapi.StageGarbageCollect, res.Stage)
+
+	exist := func(key client.ObjectKey, obj client.Object) bool {
+		ok, err := rec.Exist(key, obj)
+		assert.NoError(t, err)
+		return ok
+	}
+	assert.False(t, exist(client.ObjectKey{Namespace: "doris", Name: "test-fe-config"}, &corev1.ConfigMap{}))
+	assert.False(t, exist(client.ObjectKey{Namespace: "doris", Name: "test-fe"}, &corev1.Service{}))
+	assert.False(t, exist(client.ObjectKey{Namespace: "doris", Name: "test-fe"}, &appv1.StatefulSet{}))
+	assert.False(t, exist(client.ObjectKey{Namespace: "doris", Name: "test-opr-account"}, &corev1.Secret{}))
+	assert.True(t, exist(client.ObjectKey{Namespace: "doris", Name: "other-config"}, &corev1.ConfigMap{}))
+}
@@ -0,0 +1,129 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type generatedResourceRef struct {
+	kind    string
+	key     types.NamespacedName
+	objType func() client.Object
+}
+
+// sync the kubernetes objects generated by the operator for each component,
+// only the objects that exist are listed.
+func (r *DorisClusterReconciler) syncGeneratedResources() (dapi.GeneratedResources, error) {
+	res := dapi.GeneratedResources{}
+	crKey := r.CR.ResourceKey()
+	monitorInstalled, err := r.isServiceMonitorCrdInstalled()
+	if err != nil {
+		return res, err
+	}
+	ingressAvailable, err := r.isIngressApiAvailable()
+	if err != nil {
+		return res, err
+	}
+	configMap := func() client.Object { return &corev1.ConfigMap{} }
+	service := func() client.Object { return &corev1.Service{} }
+	statefulSet := func() client.Object { return &appv1.StatefulSet{} }
+	pdb := func() client.Object { return &policyv1.PodDisruptionBudget{} }
+	serviceMonitor := func() client.Object { return tran.NewServiceMonitorObject() }
+	ingress := func() client.Object { return &networkingv1.Ingress{} }
+
+	components := []struct {
+		refs   []generatedResourceRef
+		target *[]dapi.GeneratedResource
+	}{
+		{
+			refs: []generatedResourceRef{
+				{"ConfigMap", tran.GetFeConfigMapKey(crKey), configMap},
+				{"ConfigMap", tran.GetFeLastGoodConfigMapKey(crKey), configMap},
+				{"Service", tran.GetFeServiceKey(crKey), service},
+				{"Service", tran.GetFePeerServiceKey(crKey), service},
+				{"StatefulSet", tran.GetFeStatefulSetKey(crKey), statefulSet},
+				{"PodDisruptionBudget", tran.GetFePodDisruptionBudgetKey(crKey), pdb},
+				{"Ingress", tran.GetFeIngressKey(crKey), ingress},
+				{"ServiceMonitor", tran.GetFeServiceMonitorKey(crKey), serviceMonitor},
+			},
+			target: &res.FE,
+		}, {
+			refs: []generatedResourceRef{
+				{"ConfigMap", tran.GetBeConfigMapKey(crKey), configMap},
+				{"ConfigMap", tran.GetBeCordonConfigMapKey(crKey), configMap},
+				{"Service", tran.GetBeServiceKey(crKey), service},
+				{"Service", tran.GetBePeerServiceKey(crKey), service},
+				{"StatefulSet", tran.GetBeStatefulSetKey(crKey), statefulSet},
+				{"PodDisruptionBudget", tran.GetBePodDisruptionBudgetKey(crKey), pdb},
+				{"ServiceMonitor", tran.GetBeServiceMonitorKey(crKey), serviceMonitor},
+			},
+			target: &res.BE,
+		}, {
+			refs: []generatedResourceRef{
+				{"ConfigMap", tran.GetCnConfigMapKey(crKey), configMap},
+				{"Service", tran.GetCnServiceKey(crKey), service},
+				{"Service", tran.GetCnPeerServiceKey(crKey), service},
+				{"StatefulSet", tran.GetCnStatefulSetKey(crKey), statefulSet},
+				{"ServiceMonitor", tran.GetCnServiceMonitorKey(crKey), serviceMonitor},
+			},
+			target: &res.CN,
+		}, {
+			refs: []generatedResourceRef{
+				{"ConfigMap", tran.GetBrokerConfigMapKey(crKey), configMap},
+				{"Service", tran.GetBrokerServiceKey(crKey), service},
+				{"Service", tran.GetBrokerPeerServiceKey(crKey), service},
+				{"StatefulSet", tran.GetBrokerStatefulSetKey(crKey), statefulSet},
+			},
+			target: &res.Broker,
+		},
+	}
+	// the services of FE pods
+	if r.CR.Spec.FE != nil && r.CR.Spec.FE.PodService != nil {
+		for ordinal := int32(0); ordinal < r.CR.Spec.FE.Replicas; ordinal++ {
+			components[0].refs = append(components[0].refs,
+				generatedResourceRef{"Service", tran.GetFePodServiceKey(crKey, ordinal), service})
+		}
+	}
+	for _, component := range components {
+		for _, ref := range component.refs {
+			if ref.kind == "ServiceMonitor" && !monitorInstalled || ref.kind == "Ingress" && !ingressAvailable {
+				continue
+			}
+			exist, err := r.Exist(ref.key, ref.objType())
+			if err != nil {
+				return res, err
+			}
+			if exist {
+				*component.target = append(*component.target, dapi.GeneratedResource{
+					Kind:           ref.kind,
+					NamespacedName: dapi.NewNamespacedName(ref.key),
+				})
+			}
+		}
+	}
+	return res, nil
+}
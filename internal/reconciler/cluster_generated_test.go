@@ -0,0 +1,59 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestSyncGeneratedResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	objMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default"}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{ObjectMeta: objMeta("test-fe-config")},
+		&corev1.Service{ObjectMeta: objMeta("test-fe")},
+		&appv1.StatefulSet{ObjectMeta: objMeta("test-fe")},
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	res, err := rec.syncGeneratedResources()
+	assert.NoError(t, err)
+	assert.Equal(t, []dapi.GeneratedResource{
+		{Kind: "ConfigMap", NamespacedName: dapi.NamespacedName{Name: "test-fe-config", Namespace: "default"}},
+		{Kind: "Service", NamespacedName: dapi.NamespacedName{Name: "test-fe", Namespace: "default"}},
+		{Kind: "StatefulSet", NamespacedName: dapi.NamespacedName{Name: "test-fe", Namespace: "default"}},
+	}, res.FE)
+	// the objects that do not exist are pruned
+	assert.Empty(t, res.BE)
+	assert.Empty(t, res.CN)
+	assert.Empty(t, res.Broker)
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the key of the Hadoop credential fingerprint folded into the component config hash
+const hadoopCredentialFingerprintHashKey = "hadoop-credential-fingerprint"
+
+// IsHadoopCredentialRotated checks whether the content of the Hadoop credential secret has been
+// changed since it was applied, the error of reading the secret is only logged, since it would
+// not be resolved by reconciling.
+func (r *DorisClusterReconciler) IsHadoopCredentialRotated() bool {
+	fingerprint, err := r.getHadoopCredentialFingerprint()
+	if err != nil {
+		r.Log.Error(err, "failed to check the rotation of hadoop credential secret")
+		return false
+	}
+	return fingerprint != r.CR.Status.HadoopCredentialFingerprint
+}
+
+// get the fingerprint of the Hadoop credential secret, which is folded into the config hash of
+// components so that the changes of the credentials trigger a rolling restart of them, since
+// neither the environment variables nor the krb5.conf mounted via subPath would be refreshed.
+// Returns empty string when there is no Hadoop credential secret.
+func (r *DorisClusterReconciler) getHadoopCredentialFingerprint() (string, error) {
+	if r.CR.Spec.HadoopConf == nil || r.CR.Spec.HadoopConf.CredentialSecretRef == nil {
+		return "", nil
+	}
+	ref := r.CR.Spec.HadoopConf.CredentialSecretRef
+	secretRef := types.NamespacedName{Namespace: r.CR.ResourceKey().Namespace, Name: ref.Name}
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(secretRef, secret)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return "", fmt.Errorf("hadoop credential secret %s not found", util.K8sObjKeyStr(secretRef))
+	}
+	requiredKeys := ref.EnvKeys
+	if ref.Krb5ConfKey != "" {
+		requiredKeys = append(requiredKeys[:len(requiredKeys):len(requiredKeys)], ref.Krb5ConfKey)
+	}
+	for _, key := range requiredKeys {
+		if _, found := secret.Data[key]; !found {
+			return "", fmt.Errorf("key %s not found in hadoop credential secret %s", key, util.K8sObjKeyStr(secretRef))
+		}
+	}
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	return util.ConfigHash(data), nil
+}
+
+// fold the fingerprint of the Hadoop credential secret into the config hash data of component,
+// and record it as applied.
+func (r *DorisClusterReconciler) withHadoopCredentialFingerprint(confHashData map[string]string) (map[string]string, error) {
+	fingerprint, err := r.getHadoopCredentialFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	r.CR.Status.HadoopCredentialFingerprint = fingerprint
+	if fingerprint == "" {
+		return confHashData, nil
+	}
+	return util.MergeMaps(confHashData, map[string]string{hadoopCredentialFingerprintHashKey: fingerprint}), nil
+}
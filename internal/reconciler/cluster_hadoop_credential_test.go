@@ -0,0 +1,83 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestWithHadoopCredentialFingerprint(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hadoop-cred", Namespace: "default"},
+		Data:       map[string][]byte{"AWS_ACCESS_KEY_ID": []byte("ak"), "krb5.conf": []byte("[libdefaults]")},
+	}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{HadoopConf: &dapi.HadoopConfSpec{}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// the config hash data is untouched without credential secret
+	data, err := rec.withHadoopCredentialFingerprint(map[string]string{"fe.conf": "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"fe.conf": "a"}, data)
+	assert.False(t, rec.IsHadoopCredentialRotated())
+
+	cr.Spec.HadoopConf.CredentialSecretRef = &dapi.HadoopCredentialSecretRef{
+		Name: "hadoop-cred", EnvKeys: []string{"AWS_ACCESS_KEY_ID"}, Krb5ConfKey: "krb5.conf",
+	}
+	assert.True(t, rec.IsHadoopCredentialRotated())
+	data, err = rec.withHadoopCredentialFingerprint(map[string]string{"fe.conf": "a"})
+	assert.Nil(t, err)
+	fingerprint := cr.Status.HadoopCredentialFingerprint
+	assert.NotEmpty(t, fingerprint)
+	assert.Equal(t, fingerprint, data[hadoopCredentialFingerprintHashKey])
+	assert.False(t, rec.IsHadoopCredentialRotated())
+
+	// the fingerprint changes with the content of secret
+	secret.Data["AWS_ACCESS_KEY_ID"] = []byte("ak-rotated")
+	assert.Nil(t, cli.Update(context.Background(), secret))
+	assert.True(t, rec.IsHadoopCredentialRotated())
+	_, err = rec.withHadoopCredentialFingerprint(map[string]string{"fe.conf": "a"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, fingerprint, cr.Status.HadoopCredentialFingerprint)
+
+	// the referenced keys must exist in the secret
+	cr.Spec.HadoopConf.CredentialSecretRef.EnvKeys = append(cr.Spec.HadoopConf.CredentialSecretRef.EnvKeys, "AWS_SECRET_ACCESS_KEY")
+	_, err = rec.withHadoopCredentialFingerprint(map[string]string{"fe.conf": "a"})
+	assert.ErrorContains(t, err, "key AWS_SECRET_ACCESS_KEY not found in hadoop credential secret hadoop-cred.default")
+
+	// the secret must exist
+	cr.Spec.HadoopConf.CredentialSecretRef.Name = "absent"
+	_, err = rec.withHadoopCredentialFingerprint(map[string]string{"fe.conf": "a"})
+	assert.ErrorContains(t, err, "hadoop credential secret absent.default not found")
+	// which is not regarded as rotated
+	assert.False(t, rec.IsHadoopCredentialRotated())
+}
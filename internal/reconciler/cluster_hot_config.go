@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Apply the changed hot-reloadable FE configs to the running FE nodes via SQL. The values of these
+// configs are excluded from the config hash of the pod template, so the change of them does not
+// restart the FE pods, while removing them restarts the FE pods to reset them to the defaults.
+// Returns nil when the hot-reloadable configs have been applied.
+func (r *DorisClusterReconciler) recFeHotConfigs(configMap *corev1.ConfigMap) *ClusterStageRecResult {
+	if r.DryRun != nil {
+		return nil
+	}
+	hotConfigs := tran.ExtractHotReloadConfigs(configMap.Data[tran.FeConfFileKey], tran.FeHotReloadConfigKeys)
+	changed := getChangedHotConfigs(hotConfigs, r.CR.Status.FE.AppliedHotConfigs)
+	if len(changed) > 0 {
+		err := func() error {
+			feCli, err := r.connectFe()
+			if err != nil {
+				return err
+			}
+			defer feCli.Close()
+			for _, key := range changed {
+				if err := feCli.SetAllConfig(key, hotConfigs[key]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+		if err != nil {
+			res := clusterStageFail(dapi.StageFeConfigmap, dapi.StageActionApply, err)
+			return &res
+		}
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "HotConfigApplied",
+			fmt.Sprintf("Apply FE configs %v at runtime without restart", changed))
+	}
+	r.CR.Status.FE.AppliedHotConfigs = hotConfigs
+	return nil
+}
+
+// Apply the changed hot-reloadable BE configs to each running BE node via the http api of BE,
+// see recFeHotConfigs. Returns nil when the hot-reloadable configs have been applied.
+func (r *DorisClusterReconciler) recBeHotConfigs(configMap *corev1.ConfigMap) *ClusterStageRecResult {
+	if r.DryRun != nil {
+		return nil
+	}
+	hotConfigs := tran.ExtractHotReloadConfigs(configMap.Data[tran.BeConfFileKey], tran.BeHotReloadConfigKeys)
+	changed := getChangedHotConfigs(hotConfigs, r.CR.Status.BE.AppliedHotConfigs)
+	if len(changed) > 0 {
+		err := func() error {
+			user, password, err := r.getOprSqlAccount()
+			if err != nil {
+				return err
+			}
+			for _, podName := range tran.GetBeExpectPodNames(r.CR.ResourceKey(), r.CR.Spec.BE.Replicas) {
+				conf := fe.BackendHttpConf{
+					Host:     tran.GetBePodFQDN(r.CR, podName),
+					Port:     tran.GetBeWebserverPort(r.CR),
+					User:     user,
+					Password: password,
+				}
+				for _, key := range changed {
+					if err := fe.UpdateBackendConfig(conf, key, hotConfigs[key]); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}()
+		if err != nil {
+			res := clusterStageFail(dapi.StageBeConfigmap, dapi.StageActionApply, err)
+			return &res
+		}
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "HotConfigApplied",
+			fmt.Sprintf("Apply BE configs %v at runtime without restart", changed))
+	}
+	r.CR.Status.BE.AppliedHotConfigs = hotConfigs
+	return nil
+}
+
+// get the sorted keys of the hot-reloadable configs that are added or modified since last applied.
+func getChangedHotConfigs(hotConfigs map[string]string, applied map[string]string) []string {
+	var changed []string
+	for _, key := range util.MapSortedKeys(hotConfigs) {
+		if value, ok := applied[key]; !ok || value != hotConfigs[key] {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,32 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetChangedHotConfigs(t *testing.T) {
+	hotConfigs := map[string]string{"a": "1", "b": "2", "c": "3"}
+	assert.Equal(t, []string{"a", "b", "c"}, getChangedHotConfigs(hotConfigs, nil))
+	assert.Equal(t, []string{"b", "c"}, getChangedHotConfigs(hotConfigs, map[string]string{"a": "1", "b": "1"}))
+	// the removed configs are not applied
+	assert.Empty(t, getChangedHotConfigs(map[string]string{"a": "1"}, map[string]string{"a": "1", "b": "2"}))
+}
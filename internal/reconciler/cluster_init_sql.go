@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// execute the spec.initSQL against FE via the operator sql account once the Doris cluster is ready
+// for the first time, the execution would be retried on failure until it succeeds.
+func (r *DorisClusterReconciler) recInitSQL() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	if r.CR.Spec.InitSQL == nil || r.CR.Status.InitSQLApplied || r.DryRun != nil {
+		return clusterStageSucc(dapi.StageInitSQL, action)
+	}
+	if !r.CR.Status.AllReady {
+		r.setInitSQLCondition(metav1.ConditionFalse, "Pending", "waiting for the Doris cluster to be ready")
+		return clusterStageWait(dapi.StageInitSQL, action, errors.New("waiting for the Doris cluster to be ready"))
+	}
+	script, err := r.getInitSQLScript()
+	if err == nil {
+		err = r.execInitSQLScript(script)
+	}
+	if err != nil {
+		r.setInitSQLCondition(metav1.ConditionFalse, "Failed", err.Error())
+		return clusterStageFail(dapi.StageInitSQL, action, err)
+	}
+	r.CR.Status.InitSQLApplied = true
+	r.setInitSQLCondition(metav1.ConditionTrue, "Applied", "init SQL has been executed")
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "InitSQLApplied", "Init SQL has been executed against FE")
+	return clusterStageSucc(dapi.StageInitSQL, action)
+}
+
+// get the init SQL script from the inline script or the referenced ConfigMap.
+func (r *DorisClusterReconciler) getInitSQLScript() (string, error) {
+	ref := r.CR.Spec.InitSQL.ConfigMapRef
+	if ref == nil || ref.Name == "" {
+		return r.CR.Spec.InitSQL.Script, nil
+	}
+	// the referenced ConfigMap lives along with the sub resources of DorisCluster
+	namespace := r.CR.ResourceKey().Namespace
+	configMap := &corev1.ConfigMap{}
+	exist, err := r.Exist(types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return "", fmt.Errorf("referenced ConfigMap %s/%s not found", namespace, ref.Name)
+	}
+	script, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in referenced ConfigMap %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return script, nil
+}
+
+func (r *DorisClusterReconciler) execInitSQLScript(script string) error {
+	feCli, err := r.connectFe()
+	if err != nil {
+		return err
+	}
+	defer feCli.Close()
+	return feCli.ExecScript(script)
+}
+
+func (r *DorisClusterReconciler) setInitSQLCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.InitSQLApplied,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
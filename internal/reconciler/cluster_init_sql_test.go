@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecInitSQL(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "init-sql", Namespace: "default"},
+			Data:       map[string]string{"init.sql": "create database if not exists demo;"},
+		},
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// skip when the init sql is not defined
+	res := rec.recInitSQL()
+	assert.Equal(t, dapi.StageResultSucceeded, res.Status)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, dapi.InitSQLApplied))
+
+	// wait for the cluster to be ready
+	cr.Spec.InitSQL = &dapi.InitSQLSpec{ConfigMapRef: &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "init-sql"}, Key: "init.sql"}}
+	res = rec.recInitSQL()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, "Pending", meta.FindStatusCondition(cr.Status.Conditions, dapi.InitSQLApplied).Reason)
+
+	script, err := rec.getInitSQLScript()
+	assert.NoError(t, err)
+	assert.Equal(t, "create database if not exists demo;", script)
+
+	// the failure surfaces as a condition
+	cr.Status.AllReady = true
+	cr.Spec.InitSQL.ConfigMapRef.Key = "missing.sql"
+	res = rec.recInitSQL()
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	cond := meta.FindStatusCondition(cr.Status.Conditions, dapi.InitSQLApplied)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "Failed", cond.Reason)
+	assert.False(t, cr.Status.InitSQLApplied)
+
+	// never executed again once applied
+	cr.Status.InitSQLApplied = true
+	res = rec.recInitSQL()
+	assert.Equal(t, dapi.StageResultSucceeded, res.Status)
+}
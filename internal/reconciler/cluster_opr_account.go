@@ -0,0 +1,197 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// the key of the operator SQL account secret to store the password that is being rotated
+const oprAccountPendingPasswordKey = "pending-password"
+
+// IsOprAccountRotationRequested checks whether the password rotation of the operator SQL account
+// is requested via the annotation of DorisCluster and has not been applied yet.
+func IsOprAccountRotationRequested(cr *dapi.DorisCluster) bool {
+	token := cr.Annotations[tran.OprSqlAccountRotationAnnoKey]
+	return token != "" && token != cr.Status.LastOprAccountRotation
+}
+
+// check the secret of the operator SQL account supplied by user.
+func (r *DorisClusterReconciler) checkRefOprAccountSecret() error {
+	secretRef := tran.GetOprSqlAccountSecretRef(r.CR)
+	secret := &corev1.Secret{}
+	exist, err := r.Exist(secretRef, secret)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("operator sql account secret %s not found", util.K8sObjKeyStr(secretRef))
+	}
+	for _, key := range []string{tran.OprSqlAccountUserKey, tran.OprSqlAccountPasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			return fmt.Errorf("key %s not found in operator sql account secret %s", key, util.K8sObjKeyStr(secretRef))
+		}
+	}
+	return nil
+}
+
+// Rotate the password of the operator SQL account generated by operator when it is requested.
+// The new password is persisted into the secret before being applied to Doris, so that the
+// rotation can be safely retried, the running pods are not restarted since they only read
+// the account on startup.
+// Returns nil when there is no rotation in progress.
+func (r *DorisClusterReconciler) recOprAccountRotation() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageSqlAccountRotate, action, err)
+		return &res
+	}
+	if !IsOprAccountRotationRequested(r.CR) {
+		return nil
+	}
+	if r.DryRun != nil {
+		return fail(errDryRunSkipped)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(r.Ctx, tran.GetOprSqlAccountSecretKey(r.CR.ResourceKey()), secret); err != nil {
+		return fail(err)
+	}
+	user := string(secret.Data[tran.OprSqlAccountUserKey])
+	password := string(secret.Data[tran.OprSqlAccountPasswordKey])
+
+	// persist the new password
+	pending := string(secret.Data[oprAccountPendingPasswordKey])
+	if pending == "" {
+		pending = tran.GenerateRandomDorisPassword(tran.OprSqlAccountPasswordLength)
+		secret.Data[oprAccountPendingPasswordKey] = []byte(pending)
+		if err := r.Update(r.Ctx, secret); err != nil {
+			return fail(err)
+		}
+	}
+
+	// apply the new password to Doris, the password may have been applied by the previous
+	// attempt when the current one is rejected.
+	connConf := fe.ConnConf{
+		Host:     tran.GetFeServiceDNS(r.CR),
+		Port:     tran.GetFeQueryPort(r.CR),
+		User:     user,
+		Password: password,
+	}
+	applied, err := r.isOprAccountPasswordApplied(connConf, pending)
+	if err != nil {
+		return fail(err)
+	}
+	if !applied {
+		feCli, err := r.newFeClient(connConf)
+		if err != nil {
+			return fail(err)
+		}
+		defer feCli.Close()
+		if err := feCli.SetPassword(user, pending); err != nil {
+			return fail(err)
+		}
+	}
+
+	// switch to the new password
+	secret.Data[tran.OprSqlAccountPasswordKey] = []byte(pending)
+	delete(secret.Data, oprAccountPendingPasswordKey)
+	if err := r.Update(r.Ctx, secret); err != nil {
+		return fail(err)
+	}
+	r.CR.Status.LastOprAccountRotation = r.CR.Annotations[tran.OprSqlAccountRotationAnnoKey]
+	r.Log.Info("rotate the password of operator sql account")
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "PasswordRotated", "Password of operator sql account is rotated")
+	return nil
+}
+
+// Switch the operator SQL account generated by operator to the changed username, the account of
+// the new username is created in Doris with the same password via the current account before the
+// secret is switched to it, so that the switching can be safely retried. The previous account is
+// retained since the running pods only read the account on startup.
+// Returns nil when the username is unchanged.
+func (r *DorisClusterReconciler) recOprAccountRename() *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(dapi.StageSqlAccountRename, action, err)
+		return &res
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(r.Ctx, tran.GetOprSqlAccountSecretKey(r.CR.ResourceKey()), secret); err != nil {
+		return fail(err)
+	}
+	user := string(secret.Data[tran.OprSqlAccountUserKey])
+	newUser := tran.GetOprSqlAccountUser(r.CR)
+	if user == newUser {
+		return nil
+	}
+	if r.DryRun != nil {
+		return fail(errDryRunSkipped)
+	}
+	password := string(secret.Data[tran.OprSqlAccountPasswordKey])
+	feCli, err := r.newFeClient(fe.ConnConf{
+		Host:     tran.GetFeServiceDNS(r.CR),
+		Port:     tran.GetFeQueryPort(r.CR),
+		User:     user,
+		Password: password,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	defer feCli.Close()
+	if err := feCli.CreateAdminUser(newUser, password); err != nil {
+		return fail(err)
+	}
+	secret.Data[tran.OprSqlAccountUserKey] = []byte(newUser)
+	if err := r.Update(r.Ctx, secret); err != nil {
+		return fail(err)
+	}
+	r.Log.Info(fmt.Sprintf("switch operator sql account from %s to %s", user, newUser))
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "AccountRenamed", fmt.Sprintf("Operator sql account is switched "+
+		"from %s to %s, the account %s is retained for the running pods", user, newUser, user))
+	return nil
+}
+
+// check whether the pending password has been applied to Doris, returns error when
+// neither the current password nor the pending one is accepted.
+func (r *DorisClusterReconciler) isOprAccountPasswordApplied(connConf fe.ConnConf, pending string) (bool, error) {
+	ping := func(conf fe.ConnConf) error {
+		feCli, err := r.newFeClient(conf)
+		if err != nil {
+			return err
+		}
+		defer feCli.Close()
+		return feCli.Ping()
+	}
+	curErr := ping(connConf)
+	if curErr == nil {
+		return false, nil
+	}
+	pendingConf := connConf
+	pendingConf.Password = pending
+	if ping(pendingConf) == nil {
+		return true, nil
+	}
+	return false, util.MergeErrors(errors.New("failed to connect FE with the operator sql account"), curErr)
+}
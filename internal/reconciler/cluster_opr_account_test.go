@@ -0,0 +1,107 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestIsOprAccountRotationRequested(t *testing.T) {
+	cr := &dapi.DorisCluster{}
+	assert.False(t, IsOprAccountRotationRequested(cr))
+
+	cr.Annotations = map[string]string{tran.OprSqlAccountRotationAnnoKey: "2023-12-01"}
+	assert.True(t, IsOprAccountRotationRequested(cr))
+
+	cr.Status.LastOprAccountRotation = "2023-12-01"
+	assert.False(t, IsOprAccountRotationRequested(cr))
+}
+
+func TestRecOprAccountRename(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	secretKey := tran.GetOprSqlAccountSecretKey(cr.ResourceKey())
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("k8sopr"), tran.OprSqlAccountPasswordKey: []byte("pwd")},
+		},
+	).Build()
+	feCli := &fe.FakeClient{}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// nothing to do with the default username
+	assert.Nil(t, rec.recOprAccountRename())
+	assert.Empty(t, feCli.Passwords)
+
+	// create the account of the new username and switch the secret to it
+	cr.Spec.OprSqlAccount = &dapi.OprSqlAccountSpec{Username: "svc_doris_opr"}
+	assert.Nil(t, rec.recOprAccountRename())
+	assert.Equal(t, map[string]string{"svc_doris_opr": "pwd"}, feCli.Passwords)
+	secret := &corev1.Secret{}
+	assert.NoError(t, cli.Get(context.Background(), secretKey, secret))
+	assert.Equal(t, "svc_doris_opr", string(secret.Data[tran.OprSqlAccountUserKey]))
+	assert.Equal(t, "pwd", string(secret.Data[tran.OprSqlAccountPasswordKey]))
+
+	// the secret is not switched when the account fails to be created
+	cr.Spec.OprSqlAccount.Username = "other"
+	feCli.Err = errors.New("access denied")
+	res := rec.recOprAccountRename()
+	assert.Equal(t, dapi.StageSqlAccountRename, res.Stage)
+	assert.Error(t, res.Err)
+	assert.NoError(t, cli.Get(context.Background(), secretKey, secret))
+	assert.Equal(t, "svc_doris_opr", string(secret.Data[tran.OprSqlAccountUserKey]))
+
+	// the unavailable FE does not block the reconciling of the secret, which the FE depends on
+	assert.Nil(t, rec.recOprAccountSecret().Err)
+	assert.Error(t, rec.recOprAccountChanges().Err)
+}
+
+func TestRecOprAccountChangesWithRefSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default",
+		Annotations: map[string]string{tran.OprSqlAccountRotationAnnoKey: "2023-12-01"}}}
+	cr.Spec.OprSqlAccountSecretRef = &corev1.LocalObjectReference{Name: "my-account"}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, context.Background()),
+		CR:               cr,
+	}
+	// the rotation request of the account supplied by user is recorded as handled
+	assert.True(t, IsOprAccountRotationRequested(cr))
+	assert.NoError(t, rec.recOprAccountChanges().Err)
+	assert.Equal(t, "2023-12-01", cr.Status.LastOprAccountRotation)
+	assert.False(t, IsOprAccountRotationRequested(cr))
+}
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SyncPhase updates the phase and the conditions of DorisCluster according to
+// the last reconciliation result and the statefulsets of the enabled components.
+func (r *DorisClusterReconciler) SyncPhase() error {
+	components := []struct {
+		condType string
+		enabled  bool
+		stsKey   types.NamespacedName
+		stopped  int32
+	}{
+		{dapi.FEReady, r.CR.Spec.FE != nil, tran.GetFeStatefulSetKey(r.CR.ResourceKey()), 0},
+		{dapi.BEReady, r.CR.Spec.BE != nil, tran.GetBeStatefulSetKey(r.CR.ResourceKey()), int32(len(r.CR.Status.BE.CordonedMembers))},
+		{dapi.CNReady, r.CR.Spec.CN != nil, tran.GetCnStatefulSetKey(r.CR.ResourceKey()), 0},
+		{dapi.BrokerReady, r.CR.Spec.Broker != nil, tran.GetBrokerStatefulSetKey(r.CR.ResourceKey()), 0},
+	}
+	allReady, scaling := true, false
+	for _, comp := range components {
+		if !comp.enabled {
+			meta.RemoveStatusCondition(&r.CR.Status.Conditions, comp.condType)
+			continue
+		}
+		sts := &appv1.StatefulSet{}
+		exist, err := r.Exist(comp.stsKey, sts)
+		if err != nil {
+			return err
+		}
+		ready := exist && isStatefulSetAvailable(sts, comp.stopped)
+		allReady = allReady && ready
+		scaling = scaling || (exist && isStatefulSetScaling(sts))
+		r.setReadyCondition(comp.condType, ready, "StatefulSet "+comp.stsKey.Name)
+	}
+	r.setReadyCondition(dapi.DorisClusterReady, allReady, "all enabled components")
+	r.setReconciledCondition()
+	r.CR.Status.Phase = inferDorisClusterPhase(&r.CR.Status, allReady, scaling)
+	return nil
+}
+
+func (r *DorisClusterReconciler) setReadyCondition(condType string, ready bool, subject string) {
+	cond := metav1.Condition{
+		Type:               condType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: r.CR.Generation,
+		Reason:             "Available",
+		Message:            "Desired replicas of " + subject + " are available",
+	}
+	if !ready {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "Unavailable"
+		cond.Message = "Desired replicas of " + subject + " are not available yet"
+	}
+	meta.SetStatusCondition(&r.CR.Status.Conditions, cond)
+}
+
+func (r *DorisClusterReconciler) setReconciledCondition() {
+	recStatus := r.CR.Status.DorisClusterRecStatus
+	cond := metav1.Condition{
+		Type:               dapi.DorisClusterReconciled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: r.CR.Generation,
+		Reason:             "Completed",
+		Message:            "All reconciliation stages have been completed",
+	}
+	if recStatus.Stage != dapi.StageComplete {
+		cond.Status = metav1.ConditionFalse
+		switch recStatus.StageStatus {
+		case dapi.StageResultFailed:
+			cond.Reason = "StageFailed"
+		case dapi.StageResultWaiting:
+			cond.Reason = "StageWaiting"
+		default:
+			cond.Reason = "InProgress"
+		}
+		cond.Message = fmt.Sprintf("Stage %s: %s", recStatus.Stage, recStatus.LastMessage)
+	}
+	meta.SetStatusCondition(&r.CR.Status.Conditions, cond)
+}
+
+// infer the phase of DorisCluster.
+func inferDorisClusterPhase(status *dapi.DorisClusterStatus, allReady bool, scaling bool) dapi.DorisClusterPhase {
+	switch {
+	case status.StageStatus == dapi.StageResultFailed:
+		return dapi.DorisClusterPhaseFailed
+	case allReady && status.Stage == dapi.StageComplete:
+		return dapi.DorisClusterPhaseReady
+	case status.LastApplySpecHash == nil:
+		return dapi.DorisClusterPhaseCreating
+	case scaling || status.Stage == dapi.StageBeDecommission:
+		return dapi.DorisClusterPhaseScaling
+	default:
+		return dapi.DorisClusterPhaseUpdating
+	}
+}
+
+// check whether all the desired replicas of the statefulset are available except the stopped ones.
+func isStatefulSetAvailable(sts *appv1.StatefulSet, stopped int32) bool {
+	desired := util.PointerDeRefer(sts.Spec.Replicas, 1)
+	return sts.Status.Replicas == desired && sts.Status.AvailableReplicas >= desired-stopped
+}
+
+// check whether the statefulset is scaling in or out.
+func isStatefulSetScaling(sts *appv1.StatefulSet) bool {
+	return sts.Status.Replicas != util.PointerDeRefer(sts.Spec.Replicas, 1)
+}
@@ -0,0 +1,65 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	"testing"
+)
+
+func TestInferDorisClusterPhase(t *testing.T) {
+	hash := "hash"
+	newStatus := func(stage dapi.DorisClusterOprStage, stageStatus dapi.OprStageStatus, specHash *string) *dapi.DorisClusterStatus {
+		status := &dapi.DorisClusterStatus{LastApplySpecHash: specHash}
+		status.Stage = stage
+		status.StageStatus = stageStatus
+		return status
+	}
+	assert.Equal(t, dapi.DorisClusterPhaseCreating,
+		inferDorisClusterPhase(newStatus(dapi.StageFeStatefulSet, dapi.StageResultSucceeded, nil), false, false))
+	assert.Equal(t, dapi.DorisClusterPhaseFailed,
+		inferDorisClusterPhase(newStatus(dapi.StageFeStatefulSet, dapi.StageResultFailed, &hash), true, false))
+	assert.Equal(t, dapi.DorisClusterPhaseReady,
+		inferDorisClusterPhase(newStatus(dapi.StageComplete, dapi.StageResultSucceeded, &hash), true, false))
+	assert.Equal(t, dapi.DorisClusterPhaseScaling,
+		inferDorisClusterPhase(newStatus(dapi.StageComplete, dapi.StageResultSucceeded, &hash), false, true))
+	assert.Equal(t, dapi.DorisClusterPhaseScaling,
+		inferDorisClusterPhase(newStatus(dapi.StageBeDecommission, dapi.StageResultWaiting, &hash), false, false))
+	assert.Equal(t, dapi.DorisClusterPhaseUpdating,
+		inferDorisClusterPhase(newStatus(dapi.StageComplete, dapi.StageResultSucceeded, &hash), false, false))
+}
+
+func TestIsStatefulSetAvailable(t *testing.T) {
+	sts := &appv1.StatefulSet{}
+	sts.Spec.Replicas = util.Pointer(int32(3))
+	sts.Status.Replicas = 3
+	sts.Status.AvailableReplicas = 2
+	assert.False(t, isStatefulSetAvailable(sts, 0))
+	assert.False(t, isStatefulSetScaling(sts))
+
+	sts.Status.AvailableReplicas = 3
+	assert.True(t, isStatefulSetAvailable(sts, 0))
+
+	sts.Spec.Replicas = util.Pointer(int32(1))
+	assert.False(t, isStatefulSetAvailable(sts, 0))
+	assert.True(t, isStatefulSetScaling(sts))
+}
@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DryRunAnnotationKey is the annotation of DorisCluster, the operator computes the plan of
+// changes into the status instead of applying them when it is "true".
+var DryRunAnnotationKey = fmt.Sprintf("%s/dry-run", dapi.GroupVersion.Group)
+
+// the operations on Doris cluster via SQL are skipped in dry-run mode.
+var errDryRunSkipped = errors.New("operations on Doris cluster are skipped in dry-run mode")
+
+// IsDryRun checks whether the DorisCluster is in dry-run mode.
+func IsDryRun(cr *dapi.DorisCluster) bool {
+	return cr.Annotations[DryRunAnnotationKey] == "true"
+}
+
+// Plan computes the changes that would be applied to the sub resources of DorisCluster
+// without mutating the cluster. All the stages are planned even if the previous one has
+// failed, the kubernetes objects are written with server-side dry-run and the operations
+// on Doris cluster via SQL are skipped.
+func (r *DorisClusterReconciler) Plan() dapi.DorisClusterPlan {
+	dryRun := &DorisClusterReconciler{ReconcileContext: r.ReconcileContext, CR: r.CR.DeepCopy()}
+	dryRun.Client = client.NewDryRunClient(r.Client)
+	dryRun.Recorder = nil
+	dryRun.DryRun = &DryRunRecorder{}
+
+	var plan dapi.DorisClusterPlan
+	for _, fn := range dryRun.stages() {
+		result := fn()
+		stage := dapi.PlanStage{
+			Stage:   result.Stage,
+			Status:  result.Status,
+			Actions: dryRun.DryRun.Drain(),
+		}
+		if result.Err != nil {
+			stage.Message = result.Err.Error()
+		}
+		plan.Stages = append(plan.Stages, stage)
+	}
+	return plan
+}
@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+)
+
+// reconcile the recommended PriorityClasses of FE and BE when they are enabled by the operator,
+// the existing PriorityClasses are left untouched since they may be shared with other clusters
+// or customized by the administrator.
+func (r *DorisClusterReconciler) recPriorityClasses() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	for _, class := range tran.MakeDefaultPriorityClasses(r.CR) {
+		if err := r.CreateWhenNotExist(class, &schedulingv1.PriorityClass{}); err != nil {
+			return clusterStageFail(dapi.StagePriorityClass, action, err)
+		}
+	}
+	return clusterStageSucc(dapi.StagePriorityClass, action)
+}
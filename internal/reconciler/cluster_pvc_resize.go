@@ -0,0 +1,238 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// volumeExpansionUnsupportedError represents the StorageClass of PVCs does not allow volume expansion,
+// the storage request of such PVCs would never be fulfilled by kubernetes.
+type volumeExpansionUnsupportedError struct {
+	storageClass string
+}
+
+func (e *volumeExpansionUnsupportedError) Error() string {
+	return fmt.Sprintf("StorageClass %s does not allow volume expansion, "+
+		"set allowVolumeExpansion to true on it before increasing the storage request", e.storageClass)
+}
+
+// storageShrinkUnsupportedError represents the storage request of the volumeClaimTemplate is decreased,
+// the PVCs can not be shrunk by kubernetes.
+type storageShrinkUnsupportedError struct {
+	templateName string
+	curSize      resource.Quantity
+	newSize      resource.Quantity
+}
+
+func (e *storageShrinkUnsupportedError) Error() string {
+	return fmt.Sprintf("shrinking the storage of %s from %s to %s is not supported",
+		e.templateName, e.curSize.String(), e.newSize.String())
+}
+
+// Resize the FE metadata PVCs when the storage request of FE is increased.
+// Since the volumeClaimTemplates of statefulset is immutable, the existing PVCs would be
+// patched directly, and the statefulset would be deleted with orphan pods after all PVCs
+// have been resized, then it is recreated with the new volumeClaimTemplates on the next
+// reconciliation once the deletion has completed.
+func (r *DorisClusterReconciler) recFeMetaPvcResize(newSts *appv1.StatefulSet) *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(tran.GetFeStatefulSetKey(r.CR.ResourceKey()), curSts)
+	if err != nil {
+		fail := clusterStageFail(dapi.StageFePvcResize, action, err)
+		return &fail
+	}
+	if !exist {
+		return nil
+	}
+	if curSts.DeletionTimestamp != nil {
+		wait := clusterStageWait(dapi.StageFeStatefulSet, action, fmt.Errorf("waiting for FE statefulset to be deleted"))
+		return &wait
+	}
+	changed, resized, err := r.resizeStatefulSetPvcs(curSts, newSts, "fe-meta")
+	// the resizing can not be fulfilled until the spec or the StorageClass has been changed
+	var unsupportedErr *volumeExpansionUnsupportedError
+	var shrinkErr *storageShrinkUnsupportedError
+	if errors.As(err, &unsupportedErr) {
+		r.setFeMetaResizingCondition(metav1.ConditionFalse, "ResizeUnsupported", err.Error())
+		invalid := clusterStageInvalid(dapi.StageFePvcResize, action, err)
+		return &invalid
+	}
+	if errors.As(err, &shrinkErr) {
+		r.setFeMetaResizingCondition(metav1.ConditionFalse, "ShrinkRejected", err.Error())
+		invalid := clusterStageInvalid(dapi.StageFePvcResize, action, err)
+		return &invalid
+	}
+	if err != nil {
+		fail := clusterStageFail(dapi.StageFePvcResize, action, err)
+		return &fail
+	}
+	switch {
+	case !changed:
+		if meta.IsStatusConditionTrue(r.CR.Status.Conditions, dapi.FEMetaStorageResizing) {
+			r.setFeMetaResizingCondition(metav1.ConditionFalse, "Resized", "FE metadata PVCs have been resized")
+		}
+		return nil
+	case !resized:
+		r.setFeMetaResizingCondition(metav1.ConditionTrue, "Resizing", "FE metadata PVCs are being resized")
+		wait := clusterStageWait(dapi.StageFePvcResize, action, fmt.Errorf("waiting for FE metadata PVCs to be resized"))
+		return &wait
+	}
+	r.setFeMetaResizingCondition(metav1.ConditionFalse, "Resized", "FE metadata PVCs have been resized")
+	if r.DryRun != nil {
+		if err := r.recordDryRun(dapi.PlanActionReplace, newSts, curSts); err != nil {
+			fail := clusterStageFail(dapi.StageFeStatefulSet, action, err)
+			return &fail
+		}
+		return nil
+	}
+	// keep the pods running while the statefulset is being recreated
+	orphan := client.PropagationPolicy(metav1.DeletePropagationOrphan)
+	if err := r.DeleteWhenExist(client.ObjectKeyFromObject(curSts), &appv1.StatefulSet{}, orphan); err != nil {
+		fail := clusterStageFail(dapi.StageFeStatefulSet, action, err)
+		return &fail
+	}
+	wait := clusterStageWait(dapi.StageFeStatefulSet, action, fmt.Errorf("waiting for FE statefulset to be recreated"))
+	return &wait
+}
+
+func (r *DorisClusterReconciler) setFeMetaResizingCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.FEMetaStorageResizing,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// Resize the PVCs created by the volumeClaimTemplate of statefulset when its storage request is increased,
+// shrinking the storage is not supported.
+// Returns whether the storage request has been changed and whether all the PVCs have been resized.
+func (r *DorisClusterReconciler) resizeStatefulSetPvcs(
+	curSts, newSts *appv1.StatefulSet, templateName string) (changed bool, resized bool, err error) {
+
+	findTemplate := func(sts *appv1.StatefulSet) *corev1.PersistentVolumeClaim {
+		for i := range sts.Spec.VolumeClaimTemplates {
+			if sts.Spec.VolumeClaimTemplates[i].Name == templateName {
+				return &sts.Spec.VolumeClaimTemplates[i]
+			}
+		}
+		return nil
+	}
+	curTpl, newTpl := findTemplate(curSts), findTemplate(newSts)
+	if curTpl == nil || newTpl == nil {
+		return false, false, nil
+	}
+	curSize, newSize := curTpl.Spec.Resources.Requests.Storage(), newTpl.Spec.Resources.Requests.Storage()
+	switch newSize.Cmp(*curSize) {
+	case 0:
+		return false, false, nil
+	case -1:
+		return true, false, &storageShrinkUnsupportedError{templateName: templateName, curSize: *curSize, newSize: *newSize}
+	}
+
+	// patch the storage request of existing PVCs
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(r.Ctx, pvcList,
+		client.InNamespace(curSts.Namespace), client.MatchingLabels(curSts.Spec.Selector.MatchLabels)); err != nil {
+		return true, false, err
+	}
+	pvcPrefix := fmt.Sprintf("%s-%s-", templateName, curSts.Name)
+	var pvcs []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcList.Items {
+		if strings.HasPrefix(pvc.Name, pvcPrefix) {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	// check the StorageClasses before patching any PVC, so that the PVCs would not be
+	// left with a storage request that can never be fulfilled.
+	for _, pvc := range pvcs {
+		if pvc.Spec.Resources.Requests.Storage().Cmp(*newSize) < 0 {
+			if err := r.checkVolumeExpansion(pvc); err != nil {
+				return true, false, err
+			}
+		}
+	}
+	resized = true
+	for _, pvc := range pvcs {
+		if pvc.Spec.Resources.Requests.Storage().Cmp(*newSize) < 0 {
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *newSize
+			if err := r.Patch(r.Ctx, &pvc, patch); err != nil {
+				return true, false, err
+			}
+			r.Log.Info(fmt.Sprintf("resize pvc %s/%s to %s", pvc.Namespace, pvc.Name, newSize.String()))
+		}
+		if !isPvcResized(pvc, *newSize) {
+			resized = false
+		}
+	}
+	return true, resized, nil
+}
+
+// Check that the StorageClass of the PVC allows volume expansion, the PVC without
+// StorageClass is skipped since the provisioner of it is unknown.
+func (r *DorisClusterReconciler) checkVolumeExpansion(pvc corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil
+	}
+	className := *pvc.Spec.StorageClassName
+	storageClass := &storagev1.StorageClass{}
+	exist, err := r.Exist(types.NamespacedName{Name: className}, storageClass)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("StorageClass %s of pvc %s/%s not found", className, pvc.Namespace, pvc.Name)
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return &volumeExpansionUnsupportedError{storageClass: className}
+	}
+	return nil
+}
+
+// The PVC is regarded as resized when its capacity has reached the request size,
+// or it is only waiting for the file system resizing on the node.
+func isPvcResized(pvc corev1.PersistentVolumeClaim, size resource.Quantity) bool {
+	if pvc.Status.Capacity.Storage().Cmp(size) >= 0 {
+		return true
+	}
+	for _, cond := range pvc.Status.Conditions {
+		if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
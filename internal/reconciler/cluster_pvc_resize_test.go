@@ -0,0 +1,143 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecFeMetaPvcResizeUnsupported(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	stsKey := tran.GetFeStatefulSetKey(cr.ResourceKey())
+	labels := map[string]string{"app": "fe"}
+	makeSts := func(size string) *appv1.StatefulSet {
+		return &appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec: appv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "fe-meta"},
+					Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+					}},
+				}},
+			},
+		}
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe-meta-" + stsKey.Name + "-0", Namespace: stsKey.Namespace, Labels: labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: util.Pointer("standard"),
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+	}
+	storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, Provisioner: "test"}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(makeSts("10Gi"), pvc, storageClass).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// the StorageClass does not allow volume expansion
+	res := rec.recFeMetaPvcResize(makeSts("20Gi"))
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	assert.True(t, res.Permanent)
+	assert.Contains(t, res.Err.Error(), "StorageClass standard does not allow volume expansion")
+	cond := meta.FindStatusCondition(cr.Status.Conditions, dapi.FEMetaStorageResizing)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "ResizeUnsupported", cond.Reason)
+	// the pvc is left untouched
+	curPvc := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(pvc), curPvc))
+	assert.Equal(t, "10Gi", curPvc.Spec.Resources.Requests.Storage().String())
+
+	// the pvc is patched once the StorageClass allows volume expansion
+	storageClass.AllowVolumeExpansion = util.Pointer(true)
+	assert.NoError(t, cli.Update(context.Background(), storageClass))
+	res = rec.recFeMetaPvcResize(makeSts("20Gi"))
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, dapi.StageFePvcResize, res.Stage)
+	assert.Equal(t, "Resizing", meta.FindStatusCondition(cr.Status.Conditions, dapi.FEMetaStorageResizing).Reason)
+	assert.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(pvc), curPvc))
+	assert.Equal(t, "20Gi", curPvc.Spec.Resources.Requests.Storage().String())
+
+	// the statefulset is deleted without waiting once the pvc has been resized
+	curPvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")}
+	assert.NoError(t, cli.Status().Update(context.Background(), curPvc))
+	res = rec.recFeMetaPvcResize(makeSts("20Gi"))
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, dapi.StageFeStatefulSet, res.Stage)
+	assert.Equal(t, "Resized", meta.FindStatusCondition(cr.Status.Conditions, dapi.FEMetaStorageResizing).Reason)
+	exist, err := rec.Exist(stsKey, &appv1.StatefulSet{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	// and it is left to be recreated on the next reconciliation
+	assert.Nil(t, rec.recFeMetaPvcResize(makeSts("20Gi")))
+}
+
+func TestRecFeMetaPvcResizeShrink(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	stsKey := tran.GetFeStatefulSetKey(cr.ResourceKey())
+	makeSts := func(size string) *appv1.StatefulSet {
+		return &appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec: appv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "fe"}},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "fe-meta"},
+					Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+					}},
+				}},
+			},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(makeSts("20Gi")).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// the shrinking is not retried until the spec has been changed
+	res := rec.recFeMetaPvcResize(makeSts("10Gi"))
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	assert.True(t, res.Permanent)
+	assert.EqualError(t, res.Err, "shrinking the storage of fe-meta from 20Gi to 10Gi is not supported")
+	cond := meta.FindStatusCondition(cr.Status.Conditions, dapi.FEMetaStorageResizing)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "ShrinkRejected", cond.Reason)
+	assert.Equal(t, res.Err.Error(), cond.Message)
+}
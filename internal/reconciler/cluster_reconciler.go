@@ -22,11 +22,17 @@ import (
 	"fmt"
 
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
 	tran "github.com/al-assad/doris-operator/internal/transformer"
 	"github.com/al-assad/doris-operator/internal/util"
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
 )
 
 var (
@@ -40,6 +46,10 @@ var (
 type DorisClusterReconciler struct {
 	ReconcileContext
 	CR *dapi.DorisCluster
+	// NewFeClient creates the client talking to Doris FE, defaults to fe.NewClient.
+	NewFeClient fe.ClientFactory
+	// ShowFrontendsViaHttp lists the FE nodes via the http api of FE, defaults to fe.ShowFrontendsViaHttp.
+	ShowFrontendsViaHttp func(conf fe.FrontendHttpConf) ([]fe.Frontend, error)
 }
 
 // ClusterStageRecResult represents the result of a stage reconciliation for DorisCluster
@@ -48,26 +58,140 @@ type ClusterStageRecResult struct {
 	Status dapi.OprStageStatus
 	Action dapi.OprStageAction
 	Err    error
+	// Permanent marks that the Err can not be recovered by retrying until the spec
+	// has been changed, e.g. the invalid spec, otherwise the Err is transient.
+	Permanent bool
 }
 
 // Reconcile all sub components
 func (r *DorisClusterReconciler) Reconcile() ClusterStageRecResult {
-	stages := []func() ClusterStageRecResult{
-		r.recOprAccountSecret,
-		r.recFeResources,
-		r.recBeResources,
-		r.recCnResources,
-		r.recBrokerResources,
+	result := r.reconcileStages()
+	// the restart request has been rolled out to the targeted components
+	if result.Stage == dapi.StageComplete {
+		r.CR.Status.LastRestartRequest = r.CR.Annotations[RestartRequestAnnotationKey]
+		// the content of referenced ConfigMaps has been merged into the component configs,
+		// whose hash triggers the rolling update of the pods.
+		if fingerprint, err := r.getRefConfigMapFingerprint(); err == nil {
+			r.CR.Status.RefConfigMapFingerprint = fingerprint
+		}
+	}
+	// restore the tablet balancing disabled during the BE rollout once the rollout
+	// is not in progress, even if the previous stages have failed.
+	if result.Stage != dapi.StageBeRollout {
+		start := time.Now()
+		if restoreRes := r.recBeBalanceRestore(); restoreRes != nil {
+			observeStageResult(*restoreRes, time.Since(start))
+			r.recordStageEvent(*restoreRes)
+			if result.Err == nil {
+				return *restoreRes
+			}
+		}
+	}
+	return result
+}
+
+// the reconciling stage groups of DorisCluster in order, each group is reconciled only after
+// the previous groups have succeeded, while the stages within a group are independent of each
+// other, so that the failure of one stage does not block the others in the same group.
+func (r *DorisClusterReconciler) stageGroups() [][]func() ClusterStageRecResult {
+	return [][]func() ClusterStageRecResult{
+		{r.recSpecValidation},
+		{r.recResourceNamespace},
+		{r.recOprAccountSecret, r.recPriorityClasses},
+		{r.recFeResources},
+		// BE, CN and Broker only depend on FE, and so do the changes of operator account
+		{r.recBeResources, r.recCnResources, r.recBrokerResources, r.recServiceMonitors, r.recOprAccountChanges},
+		// the init SQL is executed against the restored databases
+		{r.recRestore},
+		{r.recInitSQL},
+	}
+}
+
+// the reconciling stages of DorisCluster in order.
+func (r *DorisClusterReconciler) stages() []func() ClusterStageRecResult {
+	var stages []func() ClusterStageRecResult
+	for _, group := range r.stageGroups() {
+		stages = append(stages, group...)
 	}
-	for _, fn := range stages {
-		result := fn()
-		if result.Err != nil {
-			return result
+	return stages
+}
+
+func (r *DorisClusterReconciler) reconcileStages() ClusterStageRecResult {
+	for _, group := range r.stageGroups() {
+		var unsettled []ClusterStageRecResult
+		for _, fn := range group {
+			start := time.Now()
+			result := fn()
+			observeStageResult(result, time.Since(start))
+			r.recordStageEvent(result)
+			if result.Err != nil {
+				unsettled = append(unsettled, result)
+			}
+		}
+		if len(unsettled) > 0 {
+			return mergeStageResults(unsettled)
 		}
 	}
 	return ClusterStageRecResult{Stage: dapi.StageComplete, Status: dapi.StageResultSucceeded}
 }
 
+// validate the spec before reconciling any sub resources, since the validating webhook
+// is optional, the invalid spec is not retried until it has been changed.
+func (r *DorisClusterReconciler) recSpecValidation() ClusterStageRecResult {
+	if err := tran.ValidateDorisCluster(r.CR); err != nil {
+		return clusterStageInvalid(dapi.StageSpecValidation, dapi.StageActionApply, err)
+	}
+	return clusterStageSucc(dapi.StageSpecValidation, dapi.StageActionApply)
+}
+
+// merge the unsettled results of the independent stages, the first stage in order is kept
+// as the stage of merged result, and the errors are tagged with their stages. The merged result
+// is waiting only when all of them are waiting, and permanent only when all of them are permanent.
+func mergeStageResults(results []ClusterStageRecResult) ClusterStageRecResult {
+	if len(results) == 1 {
+		return results[0]
+	}
+	merged := results[0]
+	errs := make(map[string]error, len(results))
+	for _, res := range results {
+		errs[string(res.Stage)] = res.Err
+		if res.Status == dapi.StageResultFailed {
+			merged.Status = dapi.StageResultFailed
+		}
+		merged.Permanent = merged.Permanent && res.Permanent
+	}
+	merged.Err = util.MergeErrorsWithTag(errs)
+	return merged
+}
+
+// record the kubernetes event of the stage reconciliation result on its state transitions,
+// compared with the previous result kept in the status: the stage fails with a new error,
+// starts waiting, or recovers from the failure or waiting. The event of the same result
+// is not recorded again on every reconciliation.
+func (r *DorisClusterReconciler) recordStageEvent(result ClusterStageRecResult) {
+	prev := r.CR.Status.DorisClusterRecStatus
+	prevUnsettled := prev.StageStatus == dapi.StageResultFailed || prev.StageStatus == dapi.StageResultWaiting
+	switch result.Status {
+	case dapi.StageResultFailed:
+		if prevUnsettled && result.Err != nil && strings.Contains(prev.LastMessage, result.Err.Error()) {
+			return
+		}
+		r.RecordEvent(r.CR, corev1.EventTypeWarning, "StageFailed",
+			fmt.Sprintf("Stage %s %s failed: %v", result.Stage, result.Action, result.Err))
+	case dapi.StageResultWaiting:
+		if prevUnsettled && result.Err != nil && strings.Contains(prev.LastMessage, result.Err.Error()) {
+			return
+		}
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "StageWaiting",
+			fmt.Sprintf("Stage %s %s is waiting: %v", result.Stage, result.Action, result.Err))
+	default:
+		if prevUnsettled && prev.Stage == result.Stage {
+			r.RecordEvent(r.CR, corev1.EventTypeNormal, "StageRecovered",
+				fmt.Sprintf("Stage %s %s recovered", result.Stage, result.Action))
+		}
+	}
+}
+
 func (r *ClusterStageRecResult) AsDorisClusterRecStatus() dapi.DorisClusterRecStatus {
 	res := dapi.DorisClusterRecStatus{
 		Stage:       r.Stage,
@@ -88,10 +212,28 @@ func clusterStageFail(stage dapi.DorisClusterOprStage, action dapi.OprStageActio
 	return ClusterStageRecResult{Stage: stage, Status: dapi.StageResultFailed, Action: action, Err: err}
 }
 
+// clusterStageInvalid represents the stage is failed due to the invalid spec, which would
+// not be retried until the spec has been changed.
+func clusterStageInvalid(stage dapi.DorisClusterOprStage, action dapi.OprStageAction, err error) ClusterStageRecResult {
+	return ClusterStageRecResult{Stage: stage, Status: dapi.StageResultFailed, Action: action, Err: err, Permanent: true}
+}
+
+// clusterStageWait represents the stage is blocked until the condition described by err is satisfied.
+func clusterStageWait(stage dapi.DorisClusterOprStage, action dapi.OprStageAction, err error) ClusterStageRecResult {
+	return ClusterStageRecResult{Stage: stage, Status: dapi.StageResultWaiting, Action: action, Err: err}
+}
+
 // reconcile secret object that using to store the sql query account info
 // that used by doris-operator.
 func (r *DorisClusterReconciler) recOprAccountSecret() ClusterStageRecResult {
 	action := dapi.StageActionApply
+	// check the secret supplied by user
+	if tran.IsOprSqlAccountSecretReferenced(r.CR) {
+		if err := r.checkRefOprAccountSecret(); err != nil {
+			return clusterStageFail(dapi.StageSqlAccountSecret, action, err)
+		}
+		return clusterStageSucc(dapi.StageSqlAccountSecret, action)
+	}
 	// create secret if not exists
 	secret := tran.MakeOprSqlAccountSecret(r.CR)
 	if err := r.CreateWhenNotExist(secret, &corev1.Secret{}); err != nil {
@@ -100,6 +242,190 @@ func (r *DorisClusterReconciler) recOprAccountSecret() ClusterStageRecResult {
 	return clusterStageSucc(dapi.StageSqlAccountSecret, action)
 }
 
+// reconcile the changes of the operator SQL account generated by operator, which are applied
+// to Doris via the live FE connection, so that they are reconciled after the FE rather than
+// along with the secret, otherwise an unavailable FE would block the reconciling of FE itself.
+func (r *DorisClusterReconciler) recOprAccountChanges() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	if tran.IsOprSqlAccountSecretReferenced(r.CR) {
+		// the password of the account supplied by user is rotated by user via the secret,
+		// record the rotation request as handled so that it does not keep being requested.
+		if IsOprAccountRotationRequested(r.CR) {
+			r.CR.Status.LastOprAccountRotation = r.CR.Annotations[tran.OprSqlAccountRotationAnnoKey]
+			r.Log.Info("skip the password rotation of the operator sql account supplied by user")
+		}
+		return clusterStageSucc(dapi.StageSqlAccountSecret, action)
+	}
+	// switch to the account of the changed username
+	if renameRes := r.recOprAccountRename(); renameRes != nil {
+		return *renameRes
+	}
+	// rotate the password when it is requested via annotation
+	if rotateRes := r.recOprAccountRotation(); rotateRes != nil {
+		return *rotateRes
+	}
+	return clusterStageSucc(dapi.StageSqlAccountSecret, action)
+}
+
+// create or update the PodDisruptionBudget, and delete it when pdb is nil.
+func (r *DorisClusterReconciler) applyPodDisruptionBudget(pdb *policyv1.PodDisruptionBudget, pdbKey types.NamespacedName) error {
+	if pdb == nil {
+		return r.DeleteWhenExist(pdbKey, &policyv1.PodDisruptionBudget{})
+	}
+	return r.CreateOrUpdate(pdb, &policyv1.PodDisruptionBudget{})
+}
+
+// annotate the hash of the component config data on the pod template of the statefulset,
+// so that any change of the configs triggers a rolling restart of the component, except for
+// the hot-reloadable FE/BE configs that are stripped from the data and applied at runtime.
+// The ConfigMap data contains the merged hadoop configs, which makes the change of
+// spec.hadoopConf.config restart every component that consumes it, while the change of
+// spec.hadoopConf.hosts goes directly into the hostAliases of the pod template.
+func annotateConfHash(statefulSet *appv1.StatefulSet, annoKey string, data map[string]string) string {
+	confHash := util.ConfigHash(data)
+	annotatePodTemplate(statefulSet, annoKey, confHash)
+	return confHash
+}
+
+func annotatePodTemplate(statefulSet *appv1.StatefulSet, annoKey string, value string) {
+	if statefulSet.Spec.Template.Annotations == nil {
+		statefulSet.Spec.Template.Annotations = make(map[string]string)
+	}
+	statefulSet.Spec.Template.Annotations[annoKey] = value
+}
+
+// retain the storage class of the volumeClaimTemplates of the existing statefulset, since the
+// volumeClaimTemplates are immutable, e.g. the storage class of legacy BE storage only takes
+// effect when the statefulset is created.
+func (r *DorisClusterReconciler) retainPvcStorageClasses(statefulSet *appv1.StatefulSet) error {
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(client.ObjectKeyFromObject(statefulSet), curSts)
+	if err != nil || !exist {
+		return err
+	}
+	curClasses := make(map[string]*string)
+	for _, tpl := range curSts.Spec.VolumeClaimTemplates {
+		curClasses[tpl.Name] = tpl.Spec.StorageClassName
+	}
+	for i := range statefulSet.Spec.VolumeClaimTemplates {
+		tpl := &statefulSet.Spec.VolumeClaimTemplates[i]
+		if class, ok := curClasses[tpl.Name]; ok {
+			tpl.Spec.StorageClassName = class
+		}
+	}
+	return nil
+}
+
+// the persistent storage volumes are generated as the volumeClaimTemplates of statefulset, which
+// are immutable, so that the storage volumes of the existing statefulset can not be switched between
+// persistent and ephemeral, returns nil when none of them is switched.
+func (r *DorisClusterReconciler) checkStorageVolumeTypes(stage dapi.DorisClusterOprStage, statefulSet *appv1.StatefulSet) *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(client.ObjectKeyFromObject(statefulSet), curSts)
+	if err != nil {
+		fail := clusterStageFail(stage, action, err)
+		return &fail
+	}
+	if !exist {
+		return nil
+	}
+	if changed := findStorageVolumeTypeChanges(curSts, statefulSet); len(changed) > 0 {
+		invalid := clusterStageInvalid(stage, action, fmt.Errorf("the type of storage volumes %v is immutable, "+
+			"the volumeClaimTemplates of statefulset %s can not be changed", changed, util.K8sObjKeyStr(client.ObjectKeyFromObject(curSts))))
+		return &invalid
+	}
+	return nil
+}
+
+// find the names of the volumes that are switched between the volumeClaimTemplates and the emptyDir volumes.
+func findStorageVolumeTypeChanges(curSts, newSts *appv1.StatefulSet) []string {
+	pvcNames := func(sts *appv1.StatefulSet) map[string]bool {
+		names := make(map[string]bool)
+		for _, tpl := range sts.Spec.VolumeClaimTemplates {
+			names[tpl.Name] = true
+		}
+		return names
+	}
+	emptyDirNames := func(sts *appv1.StatefulSet) map[string]bool {
+		names := make(map[string]bool)
+		for _, volume := range sts.Spec.Template.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				names[volume.Name] = true
+			}
+		}
+		return names
+	}
+	curPvcs, curEmptyDirs := pvcNames(curSts), emptyDirNames(curSts)
+	newPvcs, newEmptyDirs := pvcNames(newSts), emptyDirNames(newSts)
+	var changed []string
+	for _, name := range util.MapSortedKeys(newPvcs) {
+		if curEmptyDirs[name] {
+			changed = append(changed, name)
+		}
+	}
+	for _, name := range util.MapSortedKeys(newEmptyDirs) {
+		if curPvcs[name] {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// the max length of the rendered config file preview recorded in the component status.
+const appliedConfigPreviewLimit = 4096
+
+// record the config hash and the preview of the rendered config file last applied to the component,
+// which helps to correlate a rolling restart with the config change that caused it.
+func (r *DorisClusterReconciler) recordAppliedConfig(status *dapi.DorisComponentStatus, confHash string, confContent string) {
+	if r.DryRun != nil {
+		return
+	}
+	if status.LastAppliedConfigHash != confHash {
+		status.LastConfigChangeTime = util.Pointer(metav1.Now())
+	}
+	status.LastAppliedConfigHash = confHash
+	status.LastAppliedConfig = truncateConfigPreview(confContent, appliedConfigPreviewLimit)
+}
+
+// truncate the config content to the limit bytes without breaking the UTF-8 characters.
+func truncateConfigPreview(content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+	return strings.ToValidUTF8(content[:limit], "") + "\n...(truncated)"
+}
+
+// create or update the Service, and delete it when service is nil.
+func (r *DorisClusterReconciler) applyService(service *corev1.Service, serviceKey types.NamespacedName) error {
+	if service == nil {
+		return r.DeleteWhenExist(serviceKey, &corev1.Service{})
+	}
+	return r.CreateOrUpdate(service, &corev1.Service{})
+}
+
+// get the component configs from the config file in the referenced ConfigMap.
+func (r *DorisClusterReconciler) getRefComponentConfigs(ref *corev1.LocalObjectReference, fileKey string) (map[string]string, error) {
+	if ref == nil || ref.Name == "" {
+		return nil, nil
+	}
+	// the referenced ConfigMap lives along with the sub resources of DorisCluster
+	namespace := r.CR.ResourceKey().Namespace
+	configMap := &corev1.ConfigMap{}
+	exist, err := r.Exist(types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("referenced ConfigMap %s/%s not found", namespace, ref.Name)
+	}
+	content, ok := configMap.Data[fileKey]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in referenced ConfigMap %s/%s", fileKey, namespace, ref.Name)
+	}
+	return tran.ParseComponentConf(content), nil
+}
+
 // reconcile Doris FE component resources.
 func (r *DorisClusterReconciler) recFeResources() ClusterStageRecResult {
 
@@ -107,11 +433,34 @@ func (r *DorisClusterReconciler) recFeResources() ClusterStageRecResult {
 	applyRes := func() ClusterStageRecResult {
 		action := dapi.StageActionApply
 		// fe configmap
-		configMap := tran.MakeFeConfigMap(r.CR, r.Schema)
+		refConfigs, err := r.getRefComponentConfigs(r.CR.Spec.FE.ConfigMapRef, tran.FeConfFileKey)
+		if err != nil {
+			return clusterStageFail(dapi.StageFeConfigmap, action, err)
+		}
+		configMap := tran.MakeFeConfigMap(r.CR, r.Schema, refConfigs)
+		tlsFingerprint, err := r.getFeTlsFingerprint()
+		if err != nil {
+			return clusterStageFail(dapi.StageFeStatefulSet, action, err)
+		}
+		confHashData := tran.StripHotReloadConfigs(configMap.Data, tran.FeConfFileKey, tran.FeHotReloadConfigKeys)
+		if tlsFingerprint != "" {
+			confHashData = util.MergeMaps(confHashData, map[string]string{feTlsFingerprintHashKey: tlsFingerprint})
+		}
+		if confHashData, err = r.withHadoopCredentialFingerprint(confHashData); err != nil {
+			return clusterStageFail(dapi.StageFeStatefulSet, action, err)
+		}
+		// the last-known-good config is applied instead when the desired one has been rolled back
+		feConfHash, resolveRes := r.resolveFeConfig(configMap, util.ConfigHash(confHashData))
+		if resolveRes != nil {
+			return *resolveRes
+		}
 		if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
 			return clusterStageFail(dapi.StageFeConfigmap, action, err)
 		}
 		// fe service
+		if err := tran.ValidateFeServiceNodePorts(r.CR); err != nil {
+			return clusterStageInvalid(dapi.StageFeService, action, err)
+		}
 		service := tran.MakeFeService(r.CR, r.Schema)
 		if err := r.CreateOrUpdate(service, &corev1.Service{}); err != nil {
 			return clusterStageFail(dapi.StageFeService, action, err)
@@ -120,41 +469,103 @@ func (r *DorisClusterReconciler) recFeResources() ClusterStageRecResult {
 		if err := r.CreateOrUpdate(peerService, &corev1.Service{}); err != nil {
 			return clusterStageFail(dapi.StageFeService, action, err)
 		}
+		if podSvcRes := r.recFePodServices(); podSvcRes != nil {
+			return *podSvcRes
+		}
+		// fe ingress
+		if ingressRes := r.recFeIngress(); ingressRes != nil {
+			return *ingressRes
+		}
 		// fe statefulset
 		statefulSet := tran.MakeFeStatefulSet(r.CR, r.Schema)
-		statefulSet.Spec.Template.Annotations[FeConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
-		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+		if err := r.annotateRestart(statefulSet, "fe"); err != nil {
 			return clusterStageFail(dapi.StageFeStatefulSet, action, err)
 		}
-		return clusterStageSucc(dapi.StageFe, action)
-	}
-
-	// delete resources
-	deleteRes := func() ClusterStageRecResult {
-		action := dapi.StageActionDelete
-		// fe statefulset
-		statefulsetRef := tran.GetFeStatefulSetKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		annotatePodTemplate(statefulSet, FeConfHashAnnotationKey, feConfHash)
+		r.CR.Status.FeTlsFingerprint = tlsFingerprint
+		if resizeRes := r.recFeMetaPvcResize(statefulSet); resizeRes != nil {
+			return *resizeRes
+		}
+		if typeRes := r.checkStorageVolumeTypes(dapi.StageFeStatefulSet, statefulSet); typeRes != nil {
+			return *typeRes
+		}
+		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
 			return clusterStageFail(dapi.StageFeStatefulSet, action, err)
 		}
-		// fe service
-		serviceRef := tran.GetFeServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageFeService, action, err)
+		r.recordAppliedConfig(&r.CR.Status.FE.DorisComponentStatus, feConfHash, configMap.Data[tran.FeConfFileKey])
+		// roll back the changed config when the fe pods are not ready with it in time
+		if rollbackRes := r.recFeConfigRollback(configMap, statefulSet, feConfHash); rollbackRes != nil {
+			return *rollbackRes
 		}
-		peerServiceRef := tran.GetFePeerServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageFeService, action, err)
+		// fe pod disruption budget
+		if err := r.applyPodDisruptionBudget(tran.MakeFePodDisruptionBudget(r.CR, r.Schema),
+			tran.GetFePodDisruptionBudgetKey(r.CR.ResourceKey())); err != nil {
+			return clusterStageFail(dapi.StageFePdb, action, err)
 		}
-		// fe configmap
-		configMapRef := tran.GetFeConfigMapKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
-			return clusterStageFail(dapi.StageFeConfigmap, action, err)
+		// restart the outdated fe pods in leader-aware order
+		if rolloutRes := r.recFeLeaderAwareRollout(); rolloutRes != nil {
+			return *rolloutRes
+		}
+		// recreate the outdated fe pods one by one with the OnDelete strategy
+		if recreateRes := r.recFeRecreateRollout(); recreateRes != nil {
+			return *recreateRes
+		}
+		// wait for the fe pods to be updated and ready
+		if holdRes := r.holdStatefulSetRollout(dapi.StageFeStatefulSet, tran.GetFeStatefulSetKey(r.CR.ResourceKey()), 0); holdRes != nil {
+			return *holdRes
+		}
+		if readyRes := r.markFeConfigReady(refConfigs, feConfHash); readyRes != nil {
+			return *readyRes
+		}
+		// apply the hot-reloadable configs to the running fe nodes
+		if hotRes := r.recFeHotConfigs(configMap); hotRes != nil {
+			return *hotRes
 		}
 		return clusterStageSucc(dapi.StageFe, action)
 	}
 
-	return util.Elvis(r.CR.Spec.FE != nil, applyRes, deleteRes)()
+	return util.Elvis(r.CR.Spec.FE != nil, applyRes, r.deleteFeResources)()
+}
+
+// delete Doris FE component resources.
+func (r *DorisClusterReconciler) deleteFeResources() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	// fe pod disruption budget
+	pdbRef := tran.GetFePodDisruptionBudgetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(pdbRef, &policyv1.PodDisruptionBudget{}); err != nil {
+		return clusterStageFail(dapi.StageFePdb, action, err)
+	}
+	// fe statefulset
+	statefulsetRef := tran.GetFeStatefulSetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		return clusterStageFail(dapi.StageFeStatefulSet, action, err)
+	}
+	// fe ingress
+	if ingressRes := r.recFeIngress(); ingressRes != nil {
+		return *ingressRes
+	}
+	// fe service
+	serviceRef := tran.GetFeServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageFeService, action, err)
+	}
+	peerServiceRef := tran.GetFePeerServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageFeService, action, err)
+	}
+	if podSvcRes := r.recFePodServices(); podSvcRes != nil {
+		return *podSvcRes
+	}
+	// fe configmap
+	configMapRef := tran.GetFeConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageFeConfigmap, action, err)
+	}
+	lastGoodConfigMapRef := tran.GetFeLastGoodConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(lastGoodConfigMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageFeConfigmap, action, err)
+	}
+	return clusterStageSucc(dapi.StageFe, action)
 }
 
 // reconcile Doris BE component resources.
@@ -164,10 +575,18 @@ func (r *DorisClusterReconciler) recBeResources() ClusterStageRecResult {
 	applyRes := func() ClusterStageRecResult {
 		action := dapi.StageActionApply
 		// be configmap
-		configMap := tran.MakeBeConfigMap(r.CR, r.Schema)
+		refConfigs, err := r.getRefComponentConfigs(r.CR.Spec.BE.ConfigMapRef, tran.BeConfFileKey)
+		if err != nil {
+			return clusterStageFail(dapi.StageBeConfigmap, action, err)
+		}
+		configMap := tran.MakeBeConfigMap(r.CR, r.Schema, refConfigs)
 		if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
 			return clusterStageFail(dapi.StageBeConfigmap, action, err)
 		}
+		cordonConfigMap := tran.MakeBeCordonConfigMap(r.CR, r.Schema)
+		if err := r.CreateOrUpdate(cordonConfigMap, &corev1.ConfigMap{}); err != nil {
+			return clusterStageFail(dapi.StageBeConfigmap, action, err)
+		}
 		// be service
 		service := tran.MakeBeService(r.CR, r.Schema)
 		if err := r.CreateOrUpdate(service, &corev1.Service{}); err != nil {
@@ -177,41 +596,103 @@ func (r *DorisClusterReconciler) recBeResources() ClusterStageRecResult {
 		if err := r.CreateOrUpdate(peerService, &corev1.Service{}); err != nil {
 			return clusterStageFail(dapi.StageBeService, action, err)
 		}
+		// tell the preStop hook of be pods the target replicas
+		if annoRes := r.annotateBeTargetReplicas(); annoRes != nil {
+			return *annoRes
+		}
+		// decommission the BE nodes that would be removed by scaling down
+		if decommRes := r.recBeDecommission(); decommRes != nil {
+			return *decommRes
+		}
 		// be statefulset
 		statefulSet := tran.MakeBeStatefulSet(r.CR, r.Schema)
-		statefulSet.Spec.Template.Annotations[BeConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
-		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+		if err := r.annotateRestart(statefulSet, "be"); err != nil {
 			return clusterStageFail(dapi.StageBeStatefulSet, action, err)
 		}
-		return clusterStageSucc(dapi.StageBe, action)
-	}
-
-	// delete resources
-	deleteRes := func() ClusterStageRecResult {
-		action := dapi.StageActionDelete
-		// be statefulset
-		statefulsetRef := tran.GetBeStatefulSetKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		confHashData, err := r.withHadoopCredentialFingerprint(
+			tran.StripHotReloadConfigs(configMap.Data, tran.BeConfFileKey, tran.BeHotReloadConfigKeys))
+		if err != nil {
 			return clusterStageFail(dapi.StageBeStatefulSet, action, err)
 		}
-		// be service
-		serviceRef := tran.GetBeServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageBeService, action, err)
+		beConfHash := annotateConfHash(statefulSet, BeConfHashAnnotationKey, confHashData)
+		if typeRes := r.checkStorageVolumeTypes(dapi.StageBeStatefulSet, statefulSet); typeRes != nil {
+			return *typeRes
 		}
-		peerServiceRef := tran.GetBePeerServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageBeService, action, err)
+		if err := r.retainPvcStorageClasses(statefulSet); err != nil {
+			return clusterStageFail(dapi.StageBeStatefulSet, action, err)
 		}
-		// be configmap
-		configMapRef := tran.GetBeConfigMapKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
-			return clusterStageFail(dapi.StageBeConfigmap, action, err)
+		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+			return clusterStageFail(dapi.StageBeStatefulSet, action, err)
+		}
+		r.recordAppliedConfig(&r.CR.Status.BE.DorisComponentStatus, beConfHash, configMap.Data[tran.BeConfFileKey])
+		// be pod disruption budget
+		if err := r.applyPodDisruptionBudget(tran.MakeBePodDisruptionBudget(r.CR, r.Schema),
+			tran.GetBePodDisruptionBudgetKey(r.CR.ResourceKey())); err != nil {
+			return clusterStageFail(dapi.StageBePdb, action, err)
+		}
+		// take the cordoned be pods out of service
+		if cordonRes := r.recBeCordon(); cordonRes != nil {
+			return *cordonRes
+		}
+		// pause the tablet balancing during the rolling update of be
+		if pauseRes := r.recBeBalancePause(); pauseRes != nil {
+			return *pauseRes
+		}
+		// recreate the outdated be pods one by one with the OnDelete strategy
+		if recreateRes := r.recBeRecreateRollout(); recreateRes != nil {
+			return *recreateRes
+		}
+		// wait for the be pods to be updated and ready
+		if holdRes := r.holdStatefulSetRollout(dapi.StageBeStatefulSet, tran.GetBeStatefulSetKey(r.CR.ResourceKey()),
+			int32(len(r.CR.Status.BE.CordonedMembers))); holdRes != nil {
+			return *holdRes
+		}
+		// delete the pvcs of the be pods removed by scaling down
+		if reclaimRes := r.recBePvcReclaim(); reclaimRes != nil {
+			return *reclaimRes
+		}
+		// apply the hot-reloadable configs to the running be nodes
+		if hotRes := r.recBeHotConfigs(configMap); hotRes != nil {
+			return *hotRes
 		}
 		return clusterStageSucc(dapi.StageBe, action)
 	}
 
-	return util.Elvis(r.CR.Spec.BE != nil, applyRes, deleteRes)()
+	return util.Elvis(r.CR.Spec.BE != nil, applyRes, r.deleteBeResources)()
+}
+
+// delete Doris BE component resources.
+func (r *DorisClusterReconciler) deleteBeResources() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	// be pod disruption budget
+	pdbRef := tran.GetBePodDisruptionBudgetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(pdbRef, &policyv1.PodDisruptionBudget{}); err != nil {
+		return clusterStageFail(dapi.StageBePdb, action, err)
+	}
+	// be statefulset
+	statefulsetRef := tran.GetBeStatefulSetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		return clusterStageFail(dapi.StageBeStatefulSet, action, err)
+	}
+	// be service
+	serviceRef := tran.GetBeServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageBeService, action, err)
+	}
+	peerServiceRef := tran.GetBePeerServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageBeService, action, err)
+	}
+	// be configmap
+	configMapRef := tran.GetBeConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageBeConfigmap, action, err)
+	}
+	cordonConfigMapRef := tran.GetBeCordonConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(cordonConfigMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageBeConfigmap, action, err)
+	}
+	return clusterStageSucc(dapi.StageBe, action)
 }
 
 // reconcile Doris CN component resources.
@@ -221,7 +702,11 @@ func (r *DorisClusterReconciler) recCnResources() ClusterStageRecResult {
 	applyRes := func() ClusterStageRecResult {
 		action := dapi.StageActionApply
 		// cn configmap
-		configMap := tran.MakeCnConfigMap(r.CR, r.Schema)
+		refConfigs, err := r.getRefComponentConfigs(r.CR.Spec.CN.ConfigMapRef, tran.BeConfFileKey)
+		if err != nil {
+			return clusterStageFail(dapi.StageCnConfigmap, action, err)
+		}
+		configMap := tran.MakeCnConfigMap(r.CR, r.Schema, refConfigs)
 		if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
 			return clusterStageFail(dapi.StageCnConfigmap, action, err)
 		}
@@ -237,49 +722,65 @@ func (r *DorisClusterReconciler) recCnResources() ClusterStageRecResult {
 
 		// cn statefulset
 		statefulSet := tran.MakeCnStatefulSet(r.CR, r.Schema)
-		statefulSet.Spec.Template.Annotations[CnConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+		if err := r.annotateRestart(statefulSet, "cn"); err != nil {
+			return clusterStageFail(dapi.StageCnStatefulSet, action, err)
+		}
+		confHashData, err := r.withHadoopCredentialFingerprint(configMap.Data)
+		if err != nil {
+			return clusterStageFail(dapi.StageCnStatefulSet, action, err)
+		}
+		cnConfHash := annotateConfHash(statefulSet, CnConfHashAnnotationKey, confHashData)
 		// when the corresponding DorisAutoScaler resource exists,
-		// the replica of statefulset would not be overridden
+		// the replicas of statefulset are left to the HPA
 		autoScaler, err := r.FindRefDorisAutoScaler(client.ObjectKeyFromObject(r.CR))
 		if err != nil {
 			return clusterStageFail(dapi.StageCnStatefulSet, action, err)
 		}
 		if autoScaler != nil {
-			statefulSet.Spec.Replicas = nil
+			if err := r.leaveReplicasUnmanaged(statefulSet); err != nil {
+				return clusterStageFail(dapi.StageCnStatefulSet, action, err)
+			}
 		}
-		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
-			return clusterStageFail(dapi.StageCnStatefulSet, action, err)
+		if typeRes := r.checkStorageVolumeTypes(dapi.StageCnStatefulSet, statefulSet); typeRes != nil {
+			return *typeRes
 		}
-
-		return clusterStageSucc(dapi.StageCn, action)
-	}
-
-	// delete resources
-	deleteRes := func() ClusterStageRecResult {
-		action := dapi.StageActionDelete
-		// cn statefulset
-		statefulsetRef := tran.GetCnStatefulSetKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
 			return clusterStageFail(dapi.StageCnStatefulSet, action, err)
 		}
-		// cn service
-		serviceRef := tran.GetCnServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageCnService, action, err)
-		}
-		peerServiceRef := tran.GetCnPeerServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageCnService, action, err)
-		}
-		// cn configmap
-		configMapRef := tran.GetCnConfigMapKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
-			return clusterStageFail(dapi.StageCnConfigmap, action, err)
+		r.recordAppliedConfig(&r.CR.Status.CN.DorisComponentStatus, cnConfHash, configMap.Data[tran.BeConfFileKey])
+		// wait for the cn pods to be updated and ready
+		if holdRes := r.holdStatefulSetRollout(dapi.StageCnStatefulSet, tran.GetCnStatefulSetKey(r.CR.ResourceKey()), 0); holdRes != nil {
+			return *holdRes
 		}
 		return clusterStageSucc(dapi.StageCn, action)
 	}
 
-	return util.Elvis(r.CR.Spec.CN != nil, applyRes, deleteRes)()
+	return util.Elvis(r.CR.Spec.CN != nil, applyRes, r.deleteCnResources)()
+}
+
+// delete Doris CN component resources.
+func (r *DorisClusterReconciler) deleteCnResources() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	// cn statefulset
+	statefulsetRef := tran.GetCnStatefulSetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		return clusterStageFail(dapi.StageCnStatefulSet, action, err)
+	}
+	// cn service
+	serviceRef := tran.GetCnServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageCnService, action, err)
+	}
+	peerServiceRef := tran.GetCnPeerServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageCnService, action, err)
+	}
+	// cn configmap
+	configMapRef := tran.GetCnConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageCnConfigmap, action, err)
+	}
+	return clusterStageSucc(dapi.StageCn, action)
 }
 
 // Reconcile Doris Broker component resources.
@@ -289,44 +790,74 @@ func (r *DorisClusterReconciler) recBrokerResources() ClusterStageRecResult {
 	applyRes := func() ClusterStageRecResult {
 		action := dapi.StageActionApply
 		// broker configmap
-		configMap := tran.MakeBrokerConfigMap(r.CR, r.Schema)
+		refConfigs, err := r.getRefComponentConfigs(r.CR.Spec.Broker.ConfigMapRef, tran.BrokerConfFileKey)
+		if err != nil {
+			return clusterStageFail(dapi.StageBrokerConfigmap, action, err)
+		}
+		configMap := tran.MakeBrokerConfigMap(r.CR, r.Schema, refConfigs)
 		if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
 			return clusterStageFail(dapi.StageBrokerConfigmap, action, err)
 		}
 		// broker service
+		serviceRef := tran.GetBrokerServiceKey(r.CR.ResourceKey())
+		if err := r.applyService(tran.MakeBrokerService(r.CR, r.Schema), serviceRef); err != nil {
+			return clusterStageFail(dapi.StageBrokerService, action, err)
+		}
 		peerService := tran.MakeBrokerPeerService(r.CR, r.Schema)
 		if err := r.CreateOrUpdate(peerService, &corev1.Service{}); err != nil {
 			return clusterStageFail(dapi.StageBrokerService, action, err)
 		}
 		// broker statefulset
 		statefulSet := tran.MakeBrokerStatefulSet(r.CR, r.Schema)
-		statefulSet.Spec.Template.Annotations[BrokerConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
-		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+		if err := r.annotateRestart(statefulSet, "broker"); err != nil {
 			return clusterStageFail(dapi.StageBrokerStatefulSet, action, err)
 		}
-		return clusterStageSucc(dapi.StageBroker, action)
-	}
-
-	// delete resources
-	deleteRes := func() ClusterStageRecResult {
-		action := dapi.StageActionDelete
-		// broker statefulset
-		statefulsetRef := tran.GetBrokerStatefulSetKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		confHashData, err := r.withHadoopCredentialFingerprint(configMap.Data)
+		if err == nil {
+			confHashData, err = r.withBrokerKerberosFingerprint(confHashData)
+		}
+		if err != nil {
 			return clusterStageFail(dapi.StageBrokerStatefulSet, action, err)
 		}
-		// broker service
-		peerServiceRef := tran.GetBrokerPeerServiceKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
-			return clusterStageFail(dapi.StageBrokerService, action, err)
+		brokerConfHash := annotateConfHash(statefulSet, BrokerConfHashAnnotationKey, confHashData)
+		if typeRes := r.checkStorageVolumeTypes(dapi.StageBrokerStatefulSet, statefulSet); typeRes != nil {
+			return *typeRes
 		}
-		// broker configmap
-		configMapRef := tran.GetBrokerConfigMapKey(r.CR.ObjKey())
-		if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
-			return clusterStageFail(dapi.StageBrokerConfigmap, action, err)
+		if err := r.CreateOrUpdate(statefulSet, &appv1.StatefulSet{}); err != nil {
+			return clusterStageFail(dapi.StageBrokerStatefulSet, action, err)
+		}
+		r.recordAppliedConfig(&r.CR.Status.Broker.DorisComponentStatus, brokerConfHash, configMap.Data[tran.BrokerConfFileKey])
+		// wait for the broker pods to be updated and ready
+		if holdRes := r.holdStatefulSetRollout(dapi.StageBrokerStatefulSet, tran.GetBrokerStatefulSetKey(r.CR.ResourceKey()), 0); holdRes != nil {
+			return *holdRes
 		}
 		return clusterStageSucc(dapi.StageBroker, action)
 	}
 
-	return util.Elvis(r.CR.Spec.Broker != nil, applyRes, deleteRes)()
+	return util.Elvis(r.CR.Spec.Broker != nil, applyRes, r.deleteBrokerResources)()
+}
+
+// delete Doris Broker component resources.
+func (r *DorisClusterReconciler) deleteBrokerResources() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	// broker statefulset
+	statefulsetRef := tran.GetBrokerStatefulSetKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(statefulsetRef, &appv1.StatefulSet{}); err != nil {
+		return clusterStageFail(dapi.StageBrokerStatefulSet, action, err)
+	}
+	// broker service
+	serviceRef := tran.GetBrokerServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(serviceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageBrokerService, action, err)
+	}
+	peerServiceRef := tran.GetBrokerPeerServiceKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(peerServiceRef, &corev1.Service{}); err != nil {
+		return clusterStageFail(dapi.StageBrokerService, action, err)
+	}
+	// broker configmap
+	configMapRef := tran.GetBrokerConfigMapKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
+		return clusterStageFail(dapi.StageBrokerConfigmap, action, err)
+	}
+	return clusterStageSucc(dapi.StageBroker, action)
 }
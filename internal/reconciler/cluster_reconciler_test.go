@@ -0,0 +1,272 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotateConfHashOnHadoopConfChange(t *testing.T) {
+	componentSpec := func(image string) dapi.DorisComponentSpec {
+		return dapi.DorisComponentSpec{BaseImage: image, Replicas: 1}
+	}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			Version: "2.0.3",
+			FE:      &dapi.FESpec{DorisComponentSpec: componentSpec("doris-fe")},
+			BE:      &dapi.BESpec{DorisComponentSpec: componentSpec("doris-be")},
+			CN:      &dapi.CNSpec{DorisComponentSpec: componentSpec("doris-cn")},
+			Broker:  &dapi.BrokerSpec{DorisComponentSpec: componentSpec("doris-broker")},
+			HadoopConf: &dapi.HadoopConfSpec{
+				Config: map[string]string{"hdfs-site.xml": "<configuration></configuration>"},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	makeStatefulSets := func() map[string]*appv1.StatefulSet {
+		annotate := func(sts *appv1.StatefulSet, annoKey string, configMap *corev1.ConfigMap) *appv1.StatefulSet {
+			annotateConfHash(sts, annoKey, configMap.Data)
+			return sts
+		}
+		return map[string]*appv1.StatefulSet{
+			FeConfHashAnnotationKey: annotate(tran.MakeFeStatefulSet(cr, scheme),
+				FeConfHashAnnotationKey, tran.MakeFeConfigMap(cr, scheme, nil)),
+			BeConfHashAnnotationKey: annotate(tran.MakeBeStatefulSet(cr, scheme),
+				BeConfHashAnnotationKey, tran.MakeBeConfigMap(cr, scheme, nil)),
+			CnConfHashAnnotationKey: annotate(tran.MakeCnStatefulSet(cr, scheme),
+				CnConfHashAnnotationKey, tran.MakeCnConfigMap(cr, scheme, nil)),
+			BrokerConfHashAnnotationKey: annotate(tran.MakeBrokerStatefulSet(cr, scheme),
+				BrokerConfHashAnnotationKey, tran.MakeBrokerConfigMap(cr, scheme, nil)),
+		}
+	}
+
+	before := makeStatefulSets()
+	cr.Spec.HadoopConf.Config["hdfs-site.xml"] = "<configuration><property/></configuration>"
+	afterConfig := makeStatefulSets()
+	cr.Spec.HadoopConf.Hosts = []dapi.HostnameIpItem{{IP: "10.0.0.1", Name: "namenode"}}
+	afterHosts := makeStatefulSets()
+
+	for annoKey, sts := range before {
+		assert.NotEmpty(t, sts.Spec.Template.Annotations[annoKey])
+		assert.NotEqual(t, sts.Spec.Template.Annotations[annoKey], afterConfig[annoKey].Spec.Template.Annotations[annoKey], annoKey)
+		assert.NotEqual(t, afterConfig[annoKey].Spec.Template.Spec.HostAliases, afterHosts[annoKey].Spec.Template.Spec.HostAliases, annoKey)
+	}
+}
+
+func TestFeTlsFingerprint(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		tran.FeTlsCaCertKey:     []byte("ca"),
+		tran.FeTlsServerCertKey: []byte("server"),
+		"other":                 []byte("other"),
+	}}
+	fingerprint := feTlsFingerprint(secret)
+	assert.NotEmpty(t, fingerprint)
+
+	// the keys not related to TLS are ignored
+	secret.Data["other"] = []byte("changed")
+	assert.Equal(t, fingerprint, feTlsFingerprint(secret))
+
+	// rotating the certificate or the password changes the fingerprint
+	secret.Data[tran.FeTlsServerCertKey] = []byte("server-rotated")
+	rotated := feTlsFingerprint(secret)
+	assert.NotEqual(t, fingerprint, rotated)
+	secret.Data[tran.FeTlsServerPasswordKey] = []byte("secret")
+	assert.NotEqual(t, rotated, feTlsFingerprint(secret))
+}
+
+func TestIsFeTlsRotated(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.FE = &dapi.FESpec{TLS: &dapi.FeTLSSpec{SecretName: "fe-tls"}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, context.Background()),
+		CR:               cr,
+	}
+	// the absent secret is not regarded as rotated
+	assert.False(t, rec.IsFeTlsRotated())
+
+	assert.NoError(t, rec.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe-tls", Namespace: "default"},
+		Data:       map[string][]byte{tran.FeTlsCaCertKey: []byte("ca"), tran.FeTlsServerCertKey: []byte("server")},
+	}))
+	assert.True(t, rec.IsFeTlsRotated())
+}
+
+func TestRecordAppliedConfig(t *testing.T) {
+	r := &DorisClusterReconciler{}
+	status := &dapi.DorisComponentStatus{}
+	r.recordAppliedConfig(status, "hash-1", "http_port = 8030\n")
+	assert.Equal(t, "hash-1", status.LastAppliedConfigHash)
+	assert.Equal(t, "http_port = 8030\n", status.LastAppliedConfig)
+	assert.NotNil(t, status.LastConfigChangeTime)
+
+	// the change time is retained when the config hash is not changed
+	changeTime := metav1.NewTime(status.LastConfigChangeTime.Add(-time.Hour))
+	status.LastConfigChangeTime = &changeTime
+	r.recordAppliedConfig(status, "hash-1", "http_port = 8030\n")
+	assert.Equal(t, changeTime, *status.LastConfigChangeTime)
+	r.recordAppliedConfig(status, "hash-2", "http_port = 8031\n")
+	assert.True(t, status.LastConfigChangeTime.After(changeTime.Time))
+
+	// the large config content is truncated
+	content := strings.Repeat("a", appliedConfigPreviewLimit+10)
+	r.recordAppliedConfig(status, "hash-3", content)
+	assert.Equal(t, content[:appliedConfigPreviewLimit]+"\n...(truncated)", status.LastAppliedConfig)
+	assert.Equal(t, "ab\n...(truncated)", truncateConfigPreview("ab中", 3))
+}
+
+func TestRetainPvcStorageClasses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.BE = &dapi.BESpec{
+		DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-be", Replicas: 1},
+		Storage: []dapi.BEStorage{
+			{Name: "s1", Request: resource.NewQuantity(1<<30, resource.BinarySI), StorageClassName: util.Pointer("ssd")},
+		},
+	}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, context.Background()),
+		CR:               cr,
+	}
+	// the storage class takes effect when the statefulset is created
+	sts := tran.MakeBeStatefulSet(cr, scheme)
+	assert.NoError(t, rec.retainPvcStorageClasses(sts))
+	assert.Equal(t, "ssd", *sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName)
+	assert.NoError(t, rec.Create(context.Background(), sts))
+
+	// while the storage class of the existing statefulset is retained
+	cr.Spec.BE.Storage[0].StorageClassName = util.Pointer("hdd")
+	sts = tran.MakeBeStatefulSet(cr, scheme)
+	assert.NoError(t, rec.retainPvcStorageClasses(sts))
+	assert.Equal(t, "ssd", *sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName)
+}
+
+func TestCheckStorageVolumeTypes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.BE = &dapi.BESpec{
+		DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-be", Replicas: 1, StorageVolumes: []dapi.StorageVolume{
+			{Name: "be-cache", MountPath: "/opt/cache", Type: dapi.StorageVolumeEphemeral},
+		}},
+	}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, context.Background()),
+		CR:               cr,
+	}
+	// nothing to check before the statefulset is created
+	sts := tran.MakeBeStatefulSet(cr, scheme)
+	assert.Nil(t, rec.checkStorageVolumeTypes(dapi.StageBeStatefulSet, sts))
+	assert.NoError(t, rec.Create(context.Background(), sts))
+	assert.Nil(t, rec.checkStorageVolumeTypes(dapi.StageBeStatefulSet, tran.MakeBeStatefulSet(cr, scheme)))
+
+	// the ephemeral volume can not be switched to persistent
+	cr.Spec.BE.StorageVolumes[0].Type = dapi.StorageVolumePersistent
+	res := rec.checkStorageVolumeTypes(dapi.StageBeStatefulSet, tran.MakeBeStatefulSet(cr, scheme))
+	assert.NotNil(t, res)
+	assert.True(t, res.Permanent)
+	assert.ErrorContains(t, res.Err, "the type of storage volumes [be-cache] is immutable")
+}
+
+func TestRecordStageEventOnTransitions(t *testing.T) {
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	recorder := record.NewFakeRecorder(10)
+	rec := DorisClusterReconciler{ReconcileContext: ReconcileContext{Recorder: recorder}, CR: cr}
+	drain := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	// the succeeded stage is not recorded on every reconciliation
+	succ := clusterStageSucc(dapi.StageFeService, dapi.StageActionApply)
+	rec.recordStageEvent(succ)
+	assert.Empty(t, drain())
+
+	// the failure is recorded once until the error changes
+	fail := clusterStageFail(dapi.StageFeService, dapi.StageActionApply, errors.New("boom"))
+	rec.recordStageEvent(fail)
+	assert.Len(t, drain(), 1)
+	cr.Status.DorisClusterRecStatus = fail.AsDorisClusterRecStatus()
+	rec.recordStageEvent(fail)
+	assert.Empty(t, drain())
+	rec.recordStageEvent(clusterStageFail(dapi.StageFeService, dapi.StageActionApply, errors.New("bang")))
+	assert.Len(t, drain(), 1)
+
+	// the recovery of the previously failed stage is recorded
+	rec.recordStageEvent(succ)
+	events := drain()
+	assert.Len(t, events, 1)
+	assert.Contains(t, events[0], "StageRecovered")
+	rec.recordStageEvent(clusterStageSucc(dapi.StageBeService, dapi.StageActionApply))
+	assert.Empty(t, drain())
+}
+
+func TestRecSpecValidation(t *testing.T) {
+	componentSpec := dapi.DorisComponentSpec{Replicas: 3, ResourceRequirements: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+	}}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			FE: &dapi.FESpec{DorisComponentSpec: componentSpec},
+			BE: &dapi.BESpec{DorisComponentSpec: *componentSpec.DeepCopy()},
+		},
+	}
+	rec := DorisClusterReconciler{CR: cr}
+	assert.Equal(t, dapi.StageResultSucceeded, rec.recSpecValidation().Status)
+
+	// the invalid spec is rejected without the validating webhook, and not retried
+	cr.Spec.BE.Configs = map[string]string{"storage_root_path": "/data/doris"}
+	res := rec.recSpecValidation()
+	assert.Equal(t, dapi.StageSpecValidation, res.Stage)
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	assert.True(t, res.Permanent)
+	assert.ErrorContains(t, res.Err, "spec.be.config.storage_root_path: /data/doris does not match")
+
+	// the port conflicts and negative replicas
+	cr.Spec.BE.Configs = nil
+	cr.Spec.FE.Configs = map[string]string{"http_port": "9030"}
+	cr.Spec.BE.Replicas = -1
+	res = rec.recSpecValidation()
+	assert.True(t, res.Permanent)
+	assert.ErrorContains(t, res.Err, "spec.fe.config")
+	assert.ErrorContains(t, res.Err, "spec.be.replicas")
+}
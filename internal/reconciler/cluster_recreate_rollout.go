@@ -0,0 +1,233 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// recreateRollout describes the operator-driven recreation of the pods of a component
+// statefulset with the OnDelete update strategy.
+type recreateRollout struct {
+	stage          dapi.DorisClusterOprStage
+	component      string
+	statefulSetKey types.NamespacedName
+	podLabels      map[string]string
+	// stopped are the pods that are not required to be ready, e.g. the cordoned BE pods.
+	stopped []string
+	// checkCluster returns the reason why the next pod should not be recreated yet
+	// according to the state of the Doris cluster, empty when it is safe to proceed.
+	checkCluster func(feCli fe.Client, podNames []string) (string, error)
+}
+
+// Recreate the outdated FE pods one by one with the OnDelete update strategy, the pods
+// are restarted by recFeLeaderAwareRollout instead when the leader-aware rollout is enabled.
+func (r *DorisClusterReconciler) recFeRecreateRollout() *ClusterStageRecResult {
+	prev := r.CR.Status.FE.Recreate
+	r.CR.Status.FE.Recreate = nil
+	if r.CR.Spec.FE.LeaderAwareRollout {
+		return nil
+	}
+	rollout := recreateRollout{
+		stage:          dapi.StageFeRecreate,
+		component:      "FE",
+		statefulSetKey: tran.GetFeStatefulSetKey(r.CR.ResourceKey()),
+		podLabels:      tran.GetFeComponentLabels(r.CR.ResourceKey()),
+		checkCluster: func(feCli fe.Client, podNames []string) (string, error) {
+			frontends, err := feCli.ShowFrontends()
+			if err != nil {
+				return "", err
+			}
+			alive := make(map[string]bool, len(frontends))
+			for _, frontend := range frontends {
+				alive[frontend.Host] = frontend.Alive
+			}
+			for _, podName := range podNames {
+				if !alive[tran.GetFePodFQDN(r.CR, podName)] {
+					return fmt.Sprintf("waiting for FE %s to rejoin the Doris cluster", podName), nil
+				}
+			}
+			return "", nil
+		},
+	}
+	status, res := r.recRecreateRollout(rollout, prev)
+	r.CR.Status.FE.Recreate = status
+	return res
+}
+
+// Recreate the outdated BE pods one by one with the OnDelete update strategy, the next
+// pod is recreated only after all the tablets are healthy to avoid losing the replicas.
+func (r *DorisClusterReconciler) recBeRecreateRollout() *ClusterStageRecResult {
+	prev := r.CR.Status.BE.Recreate
+	r.CR.Status.BE.Recreate = nil
+	rollout := recreateRollout{
+		stage:          dapi.StageBeRecreate,
+		component:      "BE",
+		statefulSetKey: tran.GetBeStatefulSetKey(r.CR.ResourceKey()),
+		podLabels:      tran.GetBeComponentLabels(r.CR.ResourceKey()),
+		stopped:        r.CR.Status.BE.CordonedMembers,
+		checkCluster: func(feCli fe.Client, podNames []string) (string, error) {
+			backends, err := feCli.ShowBackends()
+			if err != nil {
+				return "", err
+			}
+			alive := make(map[string]bool, len(backends))
+			for _, backend := range backends {
+				alive[backend.Host] = backend.Alive
+			}
+			for _, podName := range podNames {
+				if !alive[tran.GetBePodFQDN(r.CR, podName)] {
+					return fmt.Sprintf("waiting for BE %s to be alive in the Doris cluster", podName), nil
+				}
+			}
+			unhealthy, err := feCli.ShowUnhealthyTabletNum()
+			if err != nil {
+				return "", err
+			}
+			if unhealthy > 0 {
+				return fmt.Sprintf("waiting for %d unhealthy tablets to be repaired", unhealthy), nil
+			}
+			return "", nil
+		},
+	}
+	status, res := r.recRecreateRollout(rollout, prev)
+	r.CR.Status.BE.Recreate = status
+	return res
+}
+
+// Delete the outdated pods of the statefulset with the OnDelete update strategy one by one
+// in descending order of ordinal, and the next pod is deleted only after the replacement
+// is ready and the Doris cluster is healthy. The PVCs are retained so that the recreated
+// pod is bound to the same data.
+// Returns the progress and nil result when all the pods are running with the update revision.
+func (r *DorisClusterReconciler) recRecreateRollout(
+	rollout recreateRollout, prev *dapi.RecreateRolloutStatus) (*dapi.RecreateRolloutStatus, *ClusterStageRecResult) {
+	action := dapi.StageActionApply
+	fail := func(err error) *ClusterStageRecResult {
+		res := clusterStageFail(rollout.stage, action, err)
+		return &res
+	}
+	wait := func(format string, args ...any) *ClusterStageRecResult {
+		res := clusterStageWait(rollout.stage, action, fmt.Errorf(format, args...))
+		return &res
+	}
+	// the pods are not deleted in dry-run mode
+	if r.DryRun != nil {
+		return nil, nil
+	}
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(rollout.statefulSetKey, sts)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if !exist || sts.Spec.UpdateStrategy.Type != appv1.OnDeleteStatefulSetStrategyType {
+		return nil, nil
+	}
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdateRevision == "" {
+		return prev, wait("waiting for %s statefulset to observe the latest spec", rollout.component)
+	}
+
+	// find the pods that are not running with the update revision
+	podList := &corev1.PodList{}
+	if err := r.List(r.Ctx, podList, client.InNamespace(rollout.statefulSetKey.Namespace),
+		client.MatchingLabels(rollout.podLabels)); err != nil {
+		return prev, fail(err)
+	}
+	stopped := make(map[string]bool, len(rollout.stopped))
+	for _, podName := range rollout.stopped {
+		stopped[podName] = true
+	}
+	var running, updated, outdated []string
+	var notReady string
+	for _, pod := range podList.Items {
+		if pod.Labels[appv1.StatefulSetRevisionLabel] == sts.Status.UpdateRevision {
+			updated = append(updated, pod.Name)
+		} else {
+			outdated = append(outdated, pod.Name)
+		}
+		if stopped[pod.Name] {
+			continue
+		}
+		running = append(running, pod.Name)
+		if pod.DeletionTimestamp != nil || !util.IsPodReady(pod) {
+			notReady = pod.Name
+		}
+	}
+	if len(outdated) == 0 {
+		if prev != nil {
+			r.RecordEvent(r.CR, corev1.EventTypeNormal, "RecreateCompleted",
+				fmt.Sprintf("all %s pods have been recreated with revision %s", rollout.component, sts.Status.UpdateRevision))
+		}
+		return nil, nil
+	}
+	sort.Strings(updated)
+	pending := orderFeRestartMembers(outdated, "")
+	status := &dapi.RecreateRolloutStatus{
+		UpdateRevision: sts.Status.UpdateRevision,
+		UpdatedMembers: updated,
+		PendingMembers: pending,
+	}
+	if prev != nil {
+		status.RecreatingMember = prev.RecreatingMember
+	}
+
+	// wait for the previous recreated pod to be ready
+	replicas := util.PointerDeRefer(sts.Spec.Replicas, 1)
+	if len(podList.Items) < int(replicas) {
+		return status, wait("waiting for all %s pods to be created before recreating the next one", rollout.component)
+	}
+	if notReady != "" {
+		return status, wait("waiting for %s pod %s to be ready before recreating the next one", rollout.component, notReady)
+	}
+	feCli, err := r.connectFe()
+	if err != nil {
+		return status, fail(err)
+	}
+	defer feCli.Close()
+	reason, err := rollout.checkCluster(feCli, running)
+	if err != nil {
+		return status, fail(err)
+	}
+	if reason != "" {
+		return status, wait("%s before recreating the next one", reason)
+	}
+
+	// recreate the next pod
+	target := &corev1.Pod{}
+	target.Name = pending[0]
+	target.Namespace = rollout.statefulSetKey.Namespace
+	if err := client.IgnoreNotFound(r.Delete(r.Ctx, target)); err != nil {
+		return status, fail(err)
+	}
+	r.Log.Info(fmt.Sprintf("recreate %s pod: %s", rollout.component, target.Name))
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "RecreatingPod",
+		fmt.Sprintf("recreate %s pod %s with revision %s", rollout.component, target.Name, sts.Status.UpdateRevision))
+	status.RecreatingMember = target.Name
+	status.PendingMembers = pending[1:]
+	return status, wait("recreating %s pod %s", rollout.component, target.Name)
+}
@@ -0,0 +1,155 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func newRecreateTestPod(cr *dapi.DorisCluster, name string, revision string, ready bool) *corev1.Pod {
+	labels := util.MergeMaps(tran.GetBeComponentLabels(cr.ResourceKey()),
+		map[string]string{appv1.StatefulSetRevisionLabel: revision})
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cr.Namespace, Labels: labels},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
+
+func TestRecBeRecreateRollout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}}},
+	}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 3)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec: appv1.StatefulSetSpec{
+				Replicas:       util.Pointer(int32(3)),
+				UpdateStrategy: appv1.StatefulSetUpdateStrategy{Type: appv1.OnDeleteStatefulSetStrategyType},
+			},
+			Status: appv1.StatefulSetStatus{CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+		newRecreateTestPod(cr, pods[0], "rev-1", true),
+		newRecreateTestPod(cr, pods[1], "rev-1", true),
+		newRecreateTestPod(cr, pods[2], "rev-2", true),
+	).Build()
+	feCli := &fe.FakeClient{UnhealthyTablets: 5}
+	for _, pod := range pods {
+		feCli.Backends = append(feCli.Backends, fe.Backend{Host: tran.GetBePodFQDN(cr, pod), Alive: true})
+	}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+	podExist := func(name string) bool {
+		exist, err := rec.Exist(types.NamespacedName{Name: name, Namespace: cr.Namespace}, &corev1.Pod{})
+		assert.NoError(t, err)
+		return exist
+	}
+
+	// wait for the unhealthy tablets to be repaired
+	res := rec.recBeRecreateRollout()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Contains(t, res.Err.Error(), "5 unhealthy tablets")
+	assert.Equal(t, &dapi.RecreateRolloutStatus{
+		UpdateRevision: "rev-2",
+		UpdatedMembers: []string{pods[2]},
+		PendingMembers: []string{pods[1], pods[0]},
+	}, cr.Status.BE.Recreate)
+	assert.True(t, podExist(pods[1]))
+
+	// recreate the outdated pod with the highest ordinal
+	feCli.UnhealthyTablets = 0
+	res = rec.recBeRecreateRollout()
+	assert.NotNil(t, res)
+	assert.Equal(t, dapi.StageBeRecreate, res.Stage)
+	assert.False(t, podExist(pods[1]))
+	assert.True(t, podExist(pods[0]))
+	assert.Equal(t, pods[1], cr.Status.BE.Recreate.RecreatingMember)
+	assert.Equal(t, []string{pods[0]}, cr.Status.BE.Recreate.PendingMembers)
+
+	// wait for the replacement to be ready
+	assert.NoError(t, cli.Create(context.Background(), newRecreateTestPod(cr, pods[1], "rev-2", false)))
+	res = rec.recBeRecreateRollout()
+	assert.NotNil(t, res)
+	assert.Contains(t, res.Err.Error(), pods[1]+" to be ready")
+	assert.True(t, podExist(pods[0]))
+	assert.Equal(t, pods[1], cr.Status.BE.Recreate.RecreatingMember)
+
+	// wait for the backend of the replacement to be alive
+	replacement := newRecreateTestPod(cr, pods[1], "rev-2", true)
+	assert.NoError(t, cli.Status().Update(context.Background(), replacement))
+	feCli.Backends[1].Alive = false
+	res = rec.recBeRecreateRollout()
+	assert.NotNil(t, res)
+	assert.Contains(t, res.Err.Error(), "BE "+pods[1]+" to be alive")
+	assert.True(t, podExist(pods[0]))
+
+	// recreate the next pod
+	feCli.Backends[1].Alive = true
+	assert.NotNil(t, rec.recBeRecreateRollout())
+	assert.False(t, podExist(pods[0]))
+	assert.Empty(t, cr.Status.BE.Recreate.PendingMembers)
+
+	// completed once all the pods are running with the update revision
+	assert.NoError(t, cli.Create(context.Background(), newRecreateTestPod(cr, pods[0], "rev-2", true)))
+	assert.Nil(t, rec.recBeRecreateRollout())
+	assert.Nil(t, cr.Status.BE.Recreate)
+
+	// the pods are left to statefulset with RollingUpdate strategy
+	sts := &appv1.StatefulSet{}
+	assert.NoError(t, cli.Get(context.Background(), stsKey, sts))
+	sts.Spec.UpdateStrategy.Type = appv1.RollingUpdateStatefulSetStrategyType
+	assert.NoError(t, cli.Update(context.Background(), sts))
+	assert.NoError(t, cli.Delete(context.Background(), newRecreateTestPod(cr, pods[0], "rev-2", true)))
+	assert.NoError(t, cli.Create(context.Background(), newRecreateTestPod(cr, pods[0], "rev-1", true)))
+	assert.Nil(t, rec.recBeRecreateRollout())
+	assert.True(t, podExist(pods[0]))
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// IsRefConfigMapChanged checks whether the content of the ConfigMaps referenced by the
+// spec.<component>.configMapRef has been changed since it was rolled out, the error of
+// reading them is only logged and would be reported by the reconciling process once it runs.
+func (r *DorisClusterReconciler) IsRefConfigMapChanged() bool {
+	fingerprint, err := r.getRefConfigMapFingerprint()
+	if err != nil {
+		r.Log.Info(fmt.Sprintf("fail to read the referenced ConfigMaps: %v", err))
+		return false
+	}
+	return fingerprint != r.CR.Status.RefConfigMapFingerprint
+}
+
+// get the fingerprint of all the ConfigMaps referenced by components.
+// Returns empty string when no ConfigMap is referenced.
+func (r *DorisClusterReconciler) getRefConfigMapFingerprint() (string, error) {
+	refs := make(map[string]*corev1.LocalObjectReference)
+	if r.CR.Spec.FE != nil {
+		refs["fe"] = r.CR.Spec.FE.ConfigMapRef
+	}
+	if r.CR.Spec.BE != nil {
+		refs["be"] = r.CR.Spec.BE.ConfigMapRef
+	}
+	if r.CR.Spec.CN != nil {
+		refs["cn"] = r.CR.Spec.CN.ConfigMapRef
+	}
+	if r.CR.Spec.Broker != nil {
+		refs["broker"] = r.CR.Spec.Broker.ConfigMapRef
+	}
+	namespace := r.CR.ResourceKey().Namespace
+	data := make(map[string]string)
+	for component, ref := range refs {
+		if ref == nil || ref.Name == "" {
+			continue
+		}
+		configMapRef := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+		configMap := &corev1.ConfigMap{}
+		exist, err := r.Exist(configMapRef, configMap)
+		if err != nil {
+			return "", err
+		}
+		if !exist {
+			return "", fmt.Errorf("referenced ConfigMap %s not found", util.K8sObjKeyStr(configMapRef))
+		}
+		data[component] = ref.Name + "/" + util.ConfigHash(configMap.Data)
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	return util.ConfigHash(data), nil
+}
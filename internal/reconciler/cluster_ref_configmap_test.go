@@ -0,0 +1,67 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestIsRefConfigMapChanged(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe-conf", Namespace: "default"},
+		Data:       map[string]string{"fe.conf": "qe_max_connection = 1024"},
+	}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       dapi.DorisClusterSpec{FE: &dapi.FESpec{}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	// nothing is referenced
+	assert.False(t, rec.IsRefConfigMapChanged())
+
+	cr.Spec.FE.ConfigMapRef = &corev1.LocalObjectReference{Name: "fe-conf"}
+	assert.True(t, rec.IsRefConfigMapChanged())
+	fingerprint, err := rec.getRefConfigMapFingerprint()
+	assert.Nil(t, err)
+	cr.Status.RefConfigMapFingerprint = fingerprint
+	assert.False(t, rec.IsRefConfigMapChanged())
+
+	// the edit of the referenced ConfigMap is detected
+	configMap.Data["fe.conf"] = "qe_max_connection = 2048"
+	assert.Nil(t, cli.Update(context.Background(), configMap))
+	assert.True(t, rec.IsRefConfigMapChanged())
+
+	// the missing ConfigMap does not force the reconciling
+	cr.Spec.FE.ConfigMapRef = &corev1.LocalObjectReference{Name: "not-exist"}
+	assert.False(t, rec.IsRefConfigMapChanged())
+	_, err = rec.getRefConfigMapFingerprint()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,181 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NodeRegistrationCheckInterval is the interval to verify the registration of BE and CN nodes,
+// since the registration drift in the Doris cluster does not trigger the reconciliation.
+const NodeRegistrationCheckInterval = 5 * time.Minute
+
+// nodeRegistration describes the BE or CN pods whose nodes are expected to be registered
+// in the Doris cluster, both of them are registered via "alter system add backend".
+type nodeRegistration struct {
+	stsKey    types.NamespacedName
+	podLabels map[string]string
+	// the expected replicas, the pods beyond it are being removed by scaling down
+	replicas int32
+	// the pods that are not expected to be registered, e.g. the cordoned BE pods
+	excluded map[string]bool
+	podFQDN  func(podName string) string
+	// the heartbeat service port of the nodes
+	heartbeatPort int32
+}
+
+// RecNodeRegistration verifies that the nodes of the ready BE and CN pods are registered in the
+// Doris cluster and adds back the missing ones, which may be lost after the FE master changes.
+// It runs on every reconciliation once the DorisCluster has been reconciled completely.
+func (r *DorisClusterReconciler) RecNodeRegistration() error {
+	errs := make(map[string]error)
+	if r.CR.Spec.BE != nil {
+		if err := r.recBeRegistration(); err != nil {
+			errs["be"] = err
+		}
+	}
+	if r.CR.Spec.CN != nil {
+		if err := r.recCnRegistration(); err != nil {
+			errs["cn"] = err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrorsWithTag(errs)
+}
+
+// Verify the registration of BE nodes. The expected replicas are taken from spec rather than
+// the cached statefulset which may not have observed the scaling down yet, and the cordoned and
+// decommissioning BE pods are excluded so that the removed backends are never added back.
+func (r *DorisClusterReconciler) recBeRegistration() error {
+	excluded := make(map[string]bool)
+	for _, pod := range tran.GetBeCordonedPodNames(r.CR) {
+		excluded[pod] = true
+	}
+	for _, pod := range r.CR.Status.BE.CordonedMembers {
+		excluded[pod] = true
+	}
+	for _, pod := range r.CR.Status.BE.DecommissioningMembers {
+		excluded[pod] = true
+	}
+	for _, decommission := range r.CR.Status.BE.Decommissioning {
+		excluded[decommission.Member] = true
+	}
+	unregistered, err := r.recNodeRegistration(nodeRegistration{
+		stsKey:        tran.GetBeStatefulSetKey(r.CR.ResourceKey()),
+		podLabels:     tran.GetBeComponentLabels(r.CR.ResourceKey()),
+		replicas:      r.CR.Spec.BE.Replicas,
+		excluded:      excluded,
+		podFQDN:       func(podName string) string { return tran.GetBePodFQDN(r.CR, podName) },
+		heartbeatPort: tran.GetBeHeartbeatServicePort(r.CR),
+	})
+	if err != nil {
+		return err
+	}
+	r.CR.Status.BE.UnregisteredMembers = unregistered
+	return nil
+}
+
+// Verify the registration of CN nodes. The expected replicas are taken from the statefulset
+// since they may be scaled by the autoscaler, the CN nodes hold no data and are not decommissioned.
+func (r *DorisClusterReconciler) recCnRegistration() error {
+	stsKey := tran.GetCnStatefulSetKey(r.CR.ResourceKey())
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(stsKey, sts)
+	if err != nil || !exist {
+		return err
+	}
+	unregistered, err := r.recNodeRegistration(nodeRegistration{
+		stsKey:        stsKey,
+		podLabels:     tran.GetCnComponentLabels(r.CR.ResourceKey()),
+		replicas:      util.PointerDeRefer(sts.Spec.Replicas, 0),
+		podFQDN:       func(podName string) string { return tran.GetCnPodFQDN(r.CR, podName) },
+		heartbeatPort: tran.GetCnHeartbeatServicePort(r.CR),
+	})
+	if err != nil {
+		return err
+	}
+	r.CR.Status.CN.UnregisteredMembers = unregistered
+	return nil
+}
+
+// Cross-check the ready pods within the expected replicas against "show backends", and add
+// the nodes of the pods that are not registered. Returns the pods that were found unregistered.
+func (r *DorisClusterReconciler) recNodeRegistration(reg nodeRegistration) ([]string, error) {
+	podList := &corev1.PodList{}
+	if err := r.List(r.Ctx, podList, client.InNamespace(reg.stsKey.Namespace),
+		client.MatchingLabels(reg.podLabels)); err != nil {
+		return nil, err
+	}
+	var readyPods []string
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp != nil || !util.IsPodReady(pod) ||
+			getPodOrdinal(pod.Name) >= int(reg.replicas) || reg.excluded[pod.Name] {
+			continue
+		}
+		readyPods = append(readyPods, pod.Name)
+	}
+	if len(readyPods) == 0 {
+		return nil, nil
+	}
+	sort.Slice(readyPods, func(i, j int) bool {
+		return getPodOrdinal(readyPods[i]) < getPodOrdinal(readyPods[j])
+	})
+
+	feCli, err := r.connectFe()
+	if err != nil {
+		return nil, err
+	}
+	defer feCli.Close()
+	backends, err := feCli.ShowBackends()
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool)
+	for _, be := range backends {
+		registered[be.Host] = true
+	}
+	var unregistered []string
+	for _, podName := range readyPods {
+		host := reg.podFQDN(podName)
+		if registered[host] {
+			continue
+		}
+		unregistered = append(unregistered, podName)
+		hostPort := fmt.Sprintf("%s:%d", host, reg.heartbeatPort)
+		// the node may have registered itself in the meantime
+		if err := feCli.AddBackend(hostPort); err != nil && !strings.Contains(err.Error(), "already exists") {
+			return unregistered, err
+		}
+		r.Log.Info(fmt.Sprintf("add unregistered backend: %s", hostPort))
+		r.RecordEvent(r.CR, corev1.EventTypeWarning, "NodeReRegistered",
+			fmt.Sprintf("node of pod %s was not registered in the Doris cluster and has been added back", podName))
+	}
+	return unregistered, nil
+}
@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecBeRegistration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{BE: &dapi.BESpec{
+			DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 5},
+			CordonedOrdinals:   []int32{3},
+		}},
+	}
+	// the scaling down from 6 replicas is not observed by the cached statefulset yet
+	cr.Status.BE.Decommissioning = []dapi.BEDecommissionStatus{{Member: "test-be-4"}}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	pods := tran.GetBeExpectPodNames(cr.ResourceKey(), 6)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+			Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(6))},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+		newRecreateTestPod(cr, pods[0], "rev-1", true),
+		newRecreateTestPod(cr, pods[1], "rev-1", true),
+		// not ready yet
+		newRecreateTestPod(cr, pods[2], "rev-1", false),
+		// cordoned
+		newRecreateTestPod(cr, pods[3], "rev-1", true),
+		// being decommissioned
+		newRecreateTestPod(cr, pods[4], "rev-1", true),
+		// removed by scaling down
+		newRecreateTestPod(cr, pods[5], "rev-1", true),
+	).Build()
+	feCli := &fe.FakeClient{Backends: []fe.Backend{{Host: tran.GetBePodFQDN(cr, pods[0]), Alive: true}}}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// add back the backend of the ready pod that is not registered
+	assert.NoError(t, rec.RecNodeRegistration())
+	assert.Equal(t, []string{pods[1]}, cr.Status.BE.UnregisteredMembers)
+	assert.Equal(t, []string{tran.GetBePodFQDN(cr, pods[1]) + ":9050"}, feCli.Added)
+
+	// all the ready pods have been registered
+	assert.NoError(t, rec.RecNodeRegistration())
+	assert.Empty(t, cr.Status.BE.UnregisteredMembers)
+	assert.Len(t, feCli.Added, 1)
+
+	// the failure of FE is reported
+	feCli.Err = fmt.Errorf("connection refused")
+	assert.Error(t, rec.RecNodeRegistration())
+}
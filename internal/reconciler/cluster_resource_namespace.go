@@ -0,0 +1,82 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcile the namespace where the sub resources of DorisCluster are placed, which refuses
+// the change of spec.resourceNamespace that would leave the existing sub resources behind,
+// and the sub resources that have been owned by another DorisCluster with the same name
+// pointing at the same namespace, since the names of sub resources are derived from the
+// name of DorisCluster only.
+func (r *DorisClusterReconciler) recResourceNamespace() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	namespace := r.CR.ResourceKey().Namespace
+	if applied := r.CR.Status.ResourceNamespace; applied != "" && applied != namespace {
+		return clusterStageInvalid(dapi.StageResourceNamespace, action, fmt.Errorf(
+			"spec.resourceNamespace is immutable, the sub resources have been placed in namespace %s", applied))
+	}
+	stsKeys := []types.NamespacedName{
+		tran.GetFeStatefulSetKey(r.CR.ResourceKey()),
+		tran.GetBeStatefulSetKey(r.CR.ResourceKey()),
+		tran.GetCnStatefulSetKey(r.CR.ResourceKey()),
+		tran.GetBrokerStatefulSetKey(r.CR.ResourceKey()),
+	}
+	for _, key := range stsKeys {
+		sts := &appv1.StatefulSet{}
+		exist, err := r.Exist(key, sts)
+		if err != nil {
+			return clusterStageFail(dapi.StageResourceNamespace, action, err)
+		}
+		if exist && isOwnedByOtherCluster(r.CR, sts) {
+			return clusterStageInvalid(dapi.StageResourceNamespace, action, fmt.Errorf(
+				"statefulset %s has been owned by another DorisCluster with the same name", util.K8sObjKeyStr(key)))
+		}
+	}
+	r.CR.Status.ResourceNamespace = namespace
+	return clusterStageSucc(dapi.StageResourceNamespace, action)
+}
+
+// check whether the sub resource is owned by a DorisCluster other than the given one,
+// via either the owner labels or the owner references.
+func isOwnedByOtherCluster(cr *dapi.DorisCluster, obj metav1.Object) bool {
+	labels := obj.GetLabels()
+	namespace, name := labels[tran.ClusterOwnerNamespaceLabelKey], labels[tran.ClusterOwnerNameLabelKey]
+	if namespace != "" || name != "" {
+		return namespace != cr.Namespace || name != cr.Name
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind != "DorisCluster" || ref.APIVersion != dapi.GroupVersion.String() {
+			continue
+		}
+		// the owner references only point at the DorisCluster in the same namespace
+		if tran.IsCrossNamespace(cr) || ref.Name != cr.Name || (cr.UID != "" && ref.UID != cr.UID) {
+			return true
+		}
+	}
+	return false
+}
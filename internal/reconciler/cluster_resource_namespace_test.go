@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecResourceNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = dapi.AddToScheme(scheme)
+	// the statefulset placed in namespace doris by the DorisCluster ns-a/test
+	sts := &appv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "test-fe", Namespace: "doris",
+		Labels: map[string]string{tran.ClusterOwnerNamespaceLabelKey: "ns-a", tran.ClusterOwnerNameLabelKey: "test"},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sts).Build()
+	newRec := func(namespace string) *DorisClusterReconciler {
+		cr := &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace},
+			Spec:       dapi.DorisClusterSpec{ResourceNamespace: "doris"},
+		}
+		return &DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	}
+
+	// the owner of sub resources
+	rec := newRec("ns-a")
+	rs := rec.recResourceNamespace()
+	assert.Nil(t, rs.Err)
+	assert.Equal(t, "doris", rec.CR.Status.ResourceNamespace)
+
+	// the same-named DorisCluster in another namespace is refused
+	rs = newRec("ns-b").recResourceNamespace()
+	assert.ErrorContains(t, rs.Err, "statefulset test-fe.doris has been owned by another DorisCluster")
+	assert.True(t, rs.Permanent)
+
+	// the change of spec.resourceNamespace is refused
+	rec.CR.Spec.ResourceNamespace = "other"
+	rs = rec.recResourceNamespace()
+	assert.ErrorContains(t, rs.Err, "spec.resourceNamespace is immutable")
+	assert.Equal(t, "doris", rec.CR.Status.ResourceNamespace)
+}
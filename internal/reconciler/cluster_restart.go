@@ -0,0 +1,99 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"strings"
+)
+
+var (
+	// RestartRequestAnnotationKey is the annotation of DorisCluster to request a rolling restart of
+	// the components without changing their configs. The value is a token like a timestamp, e.g.
+	// "2024-05-01T10:00:00Z", which restarts all the components, it could be scoped to the specified
+	// components by appending them after ";", e.g. "2024-05-01T10:00:00Z;fe,be". Changing the token
+	// requests another restart. Note that the key is under doris.apache.com rather than the
+	// group of the operator.
+	RestartRequestAnnotationKey = "doris.apache.com/restart-requested"
+	// RestartedAtAnnotationKey is the annotation of the component pod template that holds the
+	// token of the restart request applied to the component.
+	RestartedAtAnnotationKey = fmt.Sprintf("%s/restarted-at", dapi.GroupVersion.Group)
+)
+
+// IsRestartRequested checks whether the rolling restart is requested via the annotation of
+// DorisCluster and has not been applied yet.
+func IsRestartRequested(cr *dapi.DorisCluster) bool {
+	request := cr.Annotations[RestartRequestAnnotationKey]
+	return request != "" && request != cr.Status.LastRestartRequest
+}
+
+// parse the restart request annotation value into the token and the targeted components,
+// the components are nil when all of them are targeted.
+func parseRestartRequest(request string) (string, map[string]bool) {
+	token, scope, scoped := strings.Cut(request, ";")
+	token = strings.TrimSpace(token)
+	if !scoped {
+		return token, nil
+	}
+	components := make(map[string]bool)
+	for _, comp := range strings.Split(scope, ",") {
+		if comp = strings.ToLower(strings.TrimSpace(comp)); comp != "" {
+			components[comp] = true
+		}
+	}
+	return token, components
+}
+
+// annotate the token of the restart request on the pod template of the component statefulset,
+// which triggers a rolling restart of the component once the token changes. The token that has
+// been applied to the statefulset is kept when the component is not targeted by the request,
+// so that a restart scoped to other components never restarts it.
+func (r *DorisClusterReconciler) annotateRestart(statefulSet *appv1.StatefulSet, component string) error {
+	appliedToken := ""
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(types.NamespacedName{Namespace: statefulSet.Namespace, Name: statefulSet.Name}, curSts)
+	if err != nil {
+		return err
+	}
+	if exist {
+		appliedToken = curSts.Spec.Template.Annotations[RestartedAtAnnotationKey]
+	}
+	token := appliedToken
+	if IsRestartRequested(r.CR) {
+		reqToken, components := parseRestartRequest(r.CR.Annotations[RestartRequestAnnotationKey])
+		if reqToken != "" && (components == nil || components[component]) {
+			token = reqToken
+		}
+	}
+	if token == "" {
+		return nil
+	}
+	annotatePodTemplate(statefulSet, RestartedAtAnnotationKey, token)
+	// the statefulset is not restarted when it is created for the first time
+	if exist && token != appliedToken {
+		r.Log.Info(fmt.Sprintf("request the rolling restart of %s: %s", component, token))
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "RestartRequested",
+			fmt.Sprintf("Rolling restart of %s is requested at %s", component, token))
+	}
+	return nil
+}
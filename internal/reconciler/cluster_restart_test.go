@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestParseRestartRequest(t *testing.T) {
+	token, components := parseRestartRequest("2024-05-01T10:00:00Z")
+	assert.Equal(t, "2024-05-01T10:00:00Z", token)
+	assert.Nil(t, components)
+
+	token, components = parseRestartRequest("2024-05-01T10:00:00Z; FE, be,")
+	assert.Equal(t, "2024-05-01T10:00:00Z", token)
+	assert.Equal(t, map[string]bool{"fe": true, "be": true}, components)
+}
+
+func TestAnnotateRestart(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}},
+			BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}},
+		},
+	}
+	feStsKey := tran.GetFeStatefulSetKey(cr.ResourceKey())
+	beStsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: feStsKey.Name, Namespace: feStsKey.Namespace}},
+		&appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: beStsKey.Name, Namespace: beStsKey.Namespace},
+			Spec: appv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{RestartedAtAnnotationKey: "t0"},
+			}}},
+		},
+	).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+	annotate := func(stsKey metav1.ObjectMeta, component string) map[string]string {
+		sts := &appv1.StatefulSet{ObjectMeta: stsKey}
+		assert.NoError(t, rec.annotateRestart(sts, component))
+		return sts.Spec.Template.Annotations
+	}
+	feMeta := metav1.ObjectMeta{Name: feStsKey.Name, Namespace: feStsKey.Namespace}
+	beMeta := metav1.ObjectMeta{Name: beStsKey.Name, Namespace: beStsKey.Namespace}
+
+	// the applied token is kept without restart request
+	assert.Nil(t, annotate(feMeta, "fe"))
+	assert.Equal(t, "t0", annotate(beMeta, "be")[RestartedAtAnnotationKey])
+
+	// the restart scoped to fe
+	cr.Annotations = map[string]string{"doris.apache.com/restart-requested": "t1;fe"}
+	assert.True(t, IsRestartRequested(cr))
+	assert.Equal(t, "t1", annotate(feMeta, "fe")[RestartedAtAnnotationKey])
+	assert.Equal(t, "t0", annotate(beMeta, "be")[RestartedAtAnnotationKey])
+
+	// the restart of all components
+	cr.Annotations[RestartRequestAnnotationKey] = "t2"
+	assert.Equal(t, "t2", annotate(feMeta, "fe")[RestartedAtAnnotationKey])
+	assert.Equal(t, "t2", annotate(beMeta, "be")[RestartedAtAnnotationKey])
+
+	// the applied restart request is not requested again
+	cr.Status.LastRestartRequest = "t2"
+	assert.False(t, IsRestartRequested(cr))
+	assert.Equal(t, "t0", annotate(beMeta, "be")[RestartedAtAnnotationKey])
+}
@@ -0,0 +1,151 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InitRestoreStatus marks the restoring from the spec.restoreFrom as pending, it should only be
+// called on the creation of DorisCluster, so that an existing cluster is never overwritten by
+// the snapshots.
+func InitRestoreStatus(cr *dapi.DorisCluster) {
+	if cr.Spec.RestoreFrom == nil || cr.Status.Restore != nil {
+		return
+	}
+	cr.Status.Restore = &dapi.RestoreStatus{Phase: dapi.RestorePending}
+}
+
+// restore the snapshots of spec.restoreFrom one by one via the read only backup repository
+// once the Doris cluster is ready for the first time, the progress is tracked in the status.
+// The failed restoring would not be retried, since the databases may have been partially restored.
+func (r *DorisClusterReconciler) recRestore() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	status := r.CR.Status.Restore
+	if r.CR.Spec.RestoreFrom == nil || status == nil || r.DryRun != nil ||
+		status.Phase == dapi.RestoreCompleted || status.Phase == dapi.RestoreFailed {
+		return clusterStageSucc(dapi.StageRestore, action)
+	}
+	if !r.CR.Status.AllReady {
+		r.setRestoreCondition(metav1.ConditionFalse, "Pending", "waiting for the Doris cluster to be ready")
+		return clusterStageWait(dapi.StageRestore, action, errors.New("waiting for the Doris cluster to be ready"))
+	}
+	feCli, err := r.connectFe()
+	if err != nil {
+		return clusterStageFail(dapi.StageRestore, action, err)
+	}
+	defer feCli.Close()
+
+	if status.Phase == dapi.RestorePending {
+		repoName := tran.GetRestoreRepositoryName(r.CR)
+		if err := r.createBackupRepository(feCli, repoName, r.CR.Spec.RestoreFrom.BackupRepositorySpec, true); err != nil {
+			r.setRestoreCondition(metav1.ConditionFalse, "Failed", err.Error())
+			return clusterStageFail(dapi.StageRestore, action, err)
+		}
+		status.Phase = dapi.RestoreRunning
+		status.Snapshots = nil
+		for _, snapshot := range r.CR.Spec.RestoreFrom.Snapshots {
+			status.Snapshots = append(status.Snapshots, dapi.RestoreSnapshotStatus{
+				Database: snapshot.Database,
+				Snapshot: snapshot.Snapshot,
+			})
+		}
+		r.RecordEvent(r.CR, corev1.EventTypeNormal, "RestoreStarted",
+			fmt.Sprintf("Restoring %d snapshots from %s", len(status.Snapshots), r.CR.Spec.RestoreFrom.Location))
+	}
+
+	for i := range status.Snapshots {
+		snapStatus := &status.Snapshots[i]
+		if snapStatus.State == fe.RestoreJobFinished {
+			continue
+		}
+		job, err := r.progressRestoreJob(feCli, i)
+		if err != nil {
+			r.setRestoreCondition(metav1.ConditionFalse, "Failed", err.Error())
+			return clusterStageFail(dapi.StageRestore, action, err)
+		}
+		if job != nil {
+			snapStatus.JobId = job.JobId
+			snapStatus.State = job.State
+		}
+		switch {
+		case job != nil && job.State == fe.RestoreJobCancelled:
+			status.Phase = dapi.RestoreFailed
+			status.Message = fmt.Sprintf("restore job %s of snapshot %s.%s has been cancelled: %s",
+				job.JobId, snapStatus.Database, snapStatus.Snapshot, job.Status)
+			r.setRestoreCondition(metav1.ConditionFalse, "Failed", status.Message)
+			r.RecordEvent(r.CR, corev1.EventTypeWarning, "RestoreFailed", status.Message)
+			return clusterStageFail(dapi.StageRestore, action, errors.New(status.Message))
+		case job != nil && job.State == fe.RestoreJobFinished:
+			r.RecordEvent(r.CR, corev1.EventTypeNormal, "SnapshotRestored",
+				fmt.Sprintf("Snapshot %s.%s has been restored", snapStatus.Database, snapStatus.Snapshot))
+		default:
+			msg := fmt.Sprintf("restoring snapshot %s.%s", snapStatus.Database, snapStatus.Snapshot)
+			r.setRestoreCondition(metav1.ConditionFalse, "Running", msg)
+			return clusterStageWait(dapi.StageRestore, action, errors.New(msg))
+		}
+	}
+
+	// the snapshots are retained in the backup location
+	if err := feCli.DropRepository(tran.GetRestoreRepositoryName(r.CR)); err != nil {
+		return clusterStageFail(dapi.StageRestore, action, err)
+	}
+	status.Phase = dapi.RestoreCompleted
+	r.setRestoreCondition(metav1.ConditionTrue, "Completed", "all snapshots have been restored")
+	r.RecordEvent(r.CR, corev1.EventTypeNormal, "RestoreCompleted", "All snapshots have been restored")
+	return clusterStageSucc(dapi.StageRestore, action)
+}
+
+// get the latest restore job of the i-th snapshot, the job is submitted when it has not been
+// submitted yet, in which case nil is returned. The jobs are identified by the snapshot name
+// since the cluster is restored only on its creation.
+func (r *DorisClusterReconciler) progressRestoreJob(feCli fe.Client, i int) (*fe.RestoreJob, error) {
+	snapshot := r.CR.Spec.RestoreFrom.Snapshots[i]
+	jobs, err := feCli.ShowRestore(snapshot.Database)
+	if err != nil {
+		return nil, err
+	}
+	for j := len(jobs) - 1; j >= 0; j-- {
+		if jobs[j].Label == snapshot.Snapshot {
+			return &jobs[j], nil
+		}
+	}
+	if err := feCli.ExecScript(fmt.Sprintf("create database if not exists `%s`", snapshot.Database)); err != nil {
+		return nil, err
+	}
+	properties := util.MergeMaps(snapshot.Properties, map[string]string{"backup_timestamp": snapshot.BackupTimestamp})
+	return nil, feCli.RestoreSnapshot(snapshot.Database, snapshot.Snapshot, tran.GetRestoreRepositoryName(r.CR), properties)
+}
+
+func (r *DorisClusterReconciler) setRestoreCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.CR.Status.Conditions, metav1.Condition{
+		Type:    dapi.Restored,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
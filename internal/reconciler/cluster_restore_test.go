@@ -0,0 +1,126 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestRecRestore(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{RestoreFrom: &dapi.RestoreFromSpec{
+			BackupRepositorySpec: dapi.BackupRepositorySpec{
+				Location:            "s3://bucket/backup",
+				Properties:          map[string]string{"s3.endpoint": "http://minio:9000"},
+				CredentialSecretRef: &corev1.LocalObjectReference{Name: "restore-credential"},
+			},
+			Snapshots: []dapi.RestoreSnapshotSpec{
+				{Database: "db1", Snapshot: "snap1", BackupTimestamp: "2024-01-01-12-00-00"},
+				{Database: "db2", Snapshot: "snap2", BackupTimestamp: "2024-01-01-12-00-00"},
+			},
+		}},
+	}
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("root"), tran.OprSqlAccountPasswordKey: []byte("")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore-credential", Namespace: "default"},
+			Data:       map[string][]byte{"s3.access_key": []byte("ak"), "s3.secret_key": []byte("sk")},
+		},
+	).Build()
+	feCli := &fe.FakeClient{}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// skip the cluster that is not created with the restoreFrom
+	res := rec.recRestore()
+	assert.Equal(t, dapi.StageResultSucceeded, res.Status)
+	assert.Empty(t, feCli.Repositories)
+
+	// wait for the cluster to be ready
+	InitRestoreStatus(cr)
+	assert.Equal(t, dapi.RestorePending, cr.Status.Restore.Phase)
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, "Pending", meta.FindStatusCondition(cr.Status.Conditions, dapi.Restored).Reason)
+
+	// create the repository and submit the restore job of the first snapshot
+	cr.Status.AllReady = true
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, dapi.RestoreRunning, cr.Status.Restore.Phase)
+	repo := feCli.Repositories["test_cluster_restore"]
+	assert.Equal(t, fe.RepositoryTypeS3, repo.Type)
+	assert.Equal(t, map[string]string{"s3.endpoint": "http://minio:9000", "s3.access_key": "ak", "s3.secret_key": "sk"}, repo.Properties)
+	assert.Len(t, feCli.RestoreJobs["db1"], 1)
+	assert.Empty(t, feCli.RestoreJobs["db2"])
+	assert.Equal(t, []string{"create database if not exists `db1`"}, feCli.Scripts)
+
+	// track the running job without submitting it again
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Len(t, feCli.RestoreJobs["db1"], 1)
+	assert.Equal(t, dapi.RestoreSnapshotStatus{Database: "db1", Snapshot: "snap1", JobId: "10000", State: "PENDING"},
+		cr.Status.Restore.Snapshots[0])
+
+	// move on to the next snapshot once the job has finished
+	feCli.RestoreJobs["db1"][0].State = fe.RestoreJobFinished
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultWaiting, res.Status)
+	assert.Equal(t, fe.RestoreJobFinished, cr.Status.Restore.Snapshots[0].State)
+	assert.Len(t, feCli.RestoreJobs["db2"], 1)
+
+	// complete and drop the repository once all jobs have finished
+	feCli.RestoreJobs["db2"][0].State = fe.RestoreJobFinished
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultSucceeded, res.Status)
+	assert.Equal(t, dapi.RestoreCompleted, cr.Status.Restore.Phase)
+	assert.Empty(t, feCli.Repositories)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, dapi.Restored))
+
+	// the cancelled job fails the restoring without retrying
+	cr.Status.Restore = &dapi.RestoreStatus{Phase: dapi.RestorePending}
+	feCli.RestoreJobs = map[string][]fe.RestoreJob{"db1": {{JobId: "1", Label: "snap1", State: fe.RestoreJobCancelled, Status: "snapshot not found"}}}
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultFailed, res.Status)
+	assert.Equal(t, dapi.RestoreFailed, cr.Status.Restore.Phase)
+	assert.Contains(t, cr.Status.Restore.Message, "snapshot not found")
+	res = rec.recRestore()
+	assert.Equal(t, dapi.StageResultSucceeded, res.Status)
+}
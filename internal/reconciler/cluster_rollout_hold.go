@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	appv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Hold the component stage until the pods of the applied statefulset have been updated
+// and are ready, the stage is requeued with backoff while waiting instead of blocking.
+// The stopped pods, e.g. the cordoned BE pods, are not required to be ready.
+// Returns nil when the statefulset has been rolled out.
+func (r *DorisClusterReconciler) holdStatefulSetRollout(
+	stage dapi.DorisClusterOprStage, statefulSetKey types.NamespacedName, stopped int32) *ClusterStageRecResult {
+	action := dapi.StageActionApply
+	// the statefulset is not changed in dry-run mode
+	if r.DryRun != nil {
+		return nil
+	}
+	sts := &appv1.StatefulSet{}
+	exist, err := r.Exist(statefulSetKey, sts)
+	if err != nil {
+		res := clusterStageFail(stage, action, err)
+		return &res
+	}
+	pending := "waiting for statefulset to be created"
+	if exist {
+		pending = getStatefulSetRolloutPending(sts, stopped)
+	}
+	if pending == "" {
+		return nil
+	}
+	res := clusterStageWait(stage, action, fmt.Errorf("%s: %s", statefulSetKey.Name, pending))
+	return &res
+}
+
+// Get the reason why the statefulset has not been rolled out, returns empty when all
+// the pods have been updated and are ready. The pods held back by the partition or the
+// OnDelete strategy are not required to be updated, and the stopped pods are not
+// required to be ready.
+func getStatefulSetRolloutPending(sts *appv1.StatefulSet, stopped int32) string {
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return "waiting for statefulset to observe the latest spec"
+	}
+	replicas := util.PointerDeRefer(sts.Spec.Replicas, 1)
+	if sts.Status.Replicas != replicas {
+		return fmt.Sprintf("waiting for statefulset to scale from %d to %d pods", sts.Status.Replicas, replicas)
+	}
+	if sts.Spec.UpdateStrategy.Type != appv1.OnDeleteStatefulSetStrategyType && isStatefulSetRolling(sts) {
+		return fmt.Sprintf("waiting for pods to be updated (%d/%d)", sts.Status.UpdatedReplicas, replicas)
+	}
+	if sts.Status.ReadyReplicas < replicas-stopped {
+		return fmt.Sprintf("waiting for pods to be ready (%d/%d)", sts.Status.ReadyReplicas, replicas)
+	}
+	return ""
+}
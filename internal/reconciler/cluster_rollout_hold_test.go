@@ -0,0 +1,60 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	"testing"
+)
+
+func TestGetStatefulSetRolloutPending(t *testing.T) {
+	sts := &appv1.StatefulSet{}
+	sts.Generation = 2
+	sts.Spec.Replicas = util.Pointer(int32(3))
+	sts.Status.ObservedGeneration = 1
+	assert.Equal(t, "waiting for statefulset to observe the latest spec", getStatefulSetRolloutPending(sts, 0))
+
+	sts.Status.ObservedGeneration = 2
+	sts.Status.Replicas = 2
+	assert.Equal(t, "waiting for statefulset to scale from 2 to 3 pods", getStatefulSetRolloutPending(sts, 0))
+
+	sts.Status.Replicas = 3
+	sts.Status.CurrentRevision = "rev-1"
+	sts.Status.UpdateRevision = "rev-2"
+	sts.Status.UpdatedReplicas = 1
+	sts.Status.ReadyReplicas = 3
+	assert.Equal(t, "waiting for pods to be updated (1/3)", getStatefulSetRolloutPending(sts, 0))
+
+	// the pods are not required to be updated with OnDelete strategy
+	sts.Spec.UpdateStrategy.Type = appv1.OnDeleteStatefulSetStrategyType
+	assert.Empty(t, getStatefulSetRolloutPending(sts, 0))
+
+	sts.Spec.UpdateStrategy.Type = appv1.RollingUpdateStatefulSetStrategyType
+	sts.Status.UpdatedReplicas = 3
+	sts.Status.CurrentRevision = "rev-2"
+	sts.Status.ReadyReplicas = 2
+	assert.Equal(t, "waiting for pods to be ready (2/3)", getStatefulSetRolloutPending(sts, 0))
+	// the stopped pods are not required to be ready
+	assert.Empty(t, getStatefulSetRolloutPending(sts, 1))
+
+	sts.Status.ReadyReplicas = 3
+	assert.Empty(t, getStatefulSetRolloutPending(sts, 0))
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcile the Prometheus Operator ServiceMonitors of Doris components,
+// it would be skipped when the ServiceMonitor CRD is not installed.
+func (r *DorisClusterReconciler) recServiceMonitors() ClusterStageRecResult {
+	action := dapi.StageActionApply
+	installed, err := r.isServiceMonitorCrdInstalled()
+	if err != nil {
+		return clusterStageFail(dapi.StageServiceMonitor, action, err)
+	}
+	if !installed {
+		if r.CR.Spec.EnableServiceMonitor {
+			r.Log.Info("skip creating ServiceMonitor since the ServiceMonitor CRD is not installed")
+		}
+		return clusterStageSucc(dapi.StageServiceMonitor, action)
+	}
+	monitors := []struct {
+		obj *unstructured.Unstructured
+		key types.NamespacedName
+	}{
+		{tran.MakeFeServiceMonitor(r.CR, r.Schema), tran.GetFeServiceMonitorKey(r.CR.ResourceKey())},
+		{tran.MakeBeServiceMonitor(r.CR, r.Schema), tran.GetBeServiceMonitorKey(r.CR.ResourceKey())},
+		{tran.MakeCnServiceMonitor(r.CR, r.Schema), tran.GetCnServiceMonitorKey(r.CR.ResourceKey())},
+	}
+	for _, monitor := range monitors {
+		if monitor.obj == nil {
+			if err := r.DeleteWhenExist(monitor.key, tran.NewServiceMonitorObject()); err != nil {
+				return clusterStageFail(dapi.StageServiceMonitor, dapi.StageActionDelete, err)
+			}
+			continue
+		}
+		if err := r.applyServiceMonitor(monitor.obj); err != nil {
+			return clusterStageFail(dapi.StageServiceMonitor, action, err)
+		}
+	}
+	return clusterStageSucc(dapi.StageServiceMonitor, action)
+}
+
+// check whether the ServiceMonitor CRD of Prometheus Operator is installed.
+func (r *DorisClusterReconciler) isServiceMonitorCrdInstalled() (bool, error) {
+	return r.isKindInstalled(tran.ServiceMonitorGVK)
+}
+
+// create or update the ServiceMonitor.
+func (r *DorisClusterReconciler) applyServiceMonitor(obj *unstructured.Unstructured) error {
+	return r.applyUnstructured(obj, tran.NewServiceMonitorObject())
+}
@@ -69,6 +69,13 @@ func (r *DorisClusterReconciler) Sync() (dapi.DorisClusterSyncStatus, error) {
 				c.Collect(err)
 			}
 		},
+		func() MuteFn {
+			resources, err := r.syncGeneratedResources()
+			return func(s SyncStatus, c ErrCollector) {
+				s.GeneratedResources = resources
+				c.Collect(err)
+			}
+		},
 	}
 	// serial collect
 	//for _, fn := range syncFns {
@@ -95,9 +102,12 @@ func (r *DorisClusterReconciler) inferIsDorisClusterAllReady() (bool, error) {
 		if int(r.CR.Spec.FE.Replicas) != len(r.CR.Status.FE.ReadyMembers) {
 			return false, nil
 		}
+		if r.CR.Spec.FE.Replicas != r.CR.Status.FE.AliveMembers {
+			return false, nil
+		}
 	}
 	if r.CR.Spec.BE != nil {
-		if int(r.CR.Spec.BE.Replicas) != len(r.CR.Status.BE.ReadyMembers) {
+		if int(r.CR.Spec.BE.Replicas)-len(r.CR.Status.BE.CordonedMembers) > len(r.CR.Status.BE.ReadyMembers) {
 			return false, nil
 		}
 	}
@@ -132,12 +142,24 @@ func (r *DorisClusterReconciler) syncFeStatus() (dapi.FEStatus, error) {
 		return dapi.FEStatus{}, nil
 	}
 	feStatus := util.PointerDeRefer(r.CR.Status.FE.DeepCopy(), dapi.FEStatus{})
-	feStatus.ServiceRef = dapi.NewNamespacedName(tran.GetFeServiceKey(r.CR.ObjKey()))
-	statefulSetRef := tran.GetFeStatefulSetKey(r.CR.ObjKey())
+	feStatus.ServiceRef = dapi.NewNamespacedName(tran.GetFeServiceKey(r.CR.ResourceKey()))
+	statefulSetRef := tran.GetFeStatefulSetKey(r.CR.ResourceKey())
 	image := tran.GetFeImage(r.CR)
 
-	err := r.fillDorisComponentStatus(&feStatus.DorisComponentStatus, statefulSetRef, tran.GetFeComponentLabels(r.CR.ObjKey()), image)
-	return feStatus, err
+	err := r.fillDorisComponentStatus(&feStatus.DorisComponentStatus, statefulSetRef, tran.GetFeComponentLabels(r.CR.ResourceKey()), image)
+	if err != nil {
+		return feStatus, err
+	}
+	// collect the FE members from Doris cluster when there are FE pods ready, the previous
+	// members are retained when FE fails to respond, which should not fail the whole sync.
+	if len(feStatus.ReadyMembers) == 0 {
+		feStatus.AliveMembers = 0
+		feStatus.Followers = nil
+		feStatus.Observers = nil
+	} else if err := r.fillFrontendMembers(&feStatus); err != nil && r.DryRun == nil {
+		r.Log.Error(err, "failed to collect the FE members, retain the previous ones")
+	}
+	return feStatus, nil
 }
 
 // sync BE status
@@ -146,10 +168,10 @@ func (r *DorisClusterReconciler) syncBeStatus() (dapi.BEStatus, error) {
 		return dapi.BEStatus{}, nil
 	}
 	beStatus := util.PointerDeRefer(r.CR.Status.BE.DeepCopy(), dapi.BEStatus{})
-	statefulSetRef := tran.GetBeStatefulSetKey(r.CR.ObjKey())
+	statefulSetRef := tran.GetBeStatefulSetKey(r.CR.ResourceKey())
 	image := tran.GetBeImage(r.CR)
 
-	err := r.fillDorisComponentStatus(&beStatus.DorisComponentStatus, statefulSetRef, tran.GetBeComponentLabels(r.CR.ObjKey()), image)
+	err := r.fillDorisComponentStatus(&beStatus.DorisComponentStatus, statefulSetRef, tran.GetBeComponentLabels(r.CR.ResourceKey()), image)
 	return beStatus, err
 }
 
@@ -159,10 +181,10 @@ func (r *DorisClusterReconciler) syncCnStatus() (dapi.CNStatus, error) {
 		return dapi.CNStatus{}, nil
 	}
 	cnStatus := util.PointerDeRefer(r.CR.Status.CN.DeepCopy(), dapi.CNStatus{})
-	statefulSetRef := tran.GetCnStatefulSetKey(r.CR.ObjKey())
+	statefulSetRef := tran.GetCnStatefulSetKey(r.CR.ResourceKey())
 	image := tran.GetCnImage(r.CR)
 
-	err := r.fillDorisComponentStatus(&cnStatus.DorisComponentStatus, statefulSetRef, tran.GetCnComponentLabels(r.CR.ObjKey()), image)
+	err := r.fillDorisComponentStatus(&cnStatus.DorisComponentStatus, statefulSetRef, tran.GetCnComponentLabels(r.CR.ResourceKey()), image)
 	return cnStatus, err
 }
 
@@ -173,9 +195,9 @@ func (r *DorisClusterReconciler) syncBrokerStatus() (dapi.BrokerStatus, error) {
 	}
 	status := util.PointerDeRefer(r.CR.Status.Broker.DeepCopy(), dapi.BrokerStatus{})
 	image := tran.GetBrokerImage(r.CR)
-	statefulSetRef := tran.GetBrokerStatefulSetKey(r.CR.ObjKey())
+	statefulSetRef := tran.GetBrokerStatefulSetKey(r.CR.ResourceKey())
 
-	err := r.fillDorisComponentStatus(&status.DorisComponentStatus, statefulSetRef, tran.GetBrokerComponentLabels(r.CR.ObjKey()), image)
+	err := r.fillDorisComponentStatus(&status.DorisComponentStatus, statefulSetRef, tran.GetBrokerComponentLabels(r.CR.ResourceKey()), image)
 	return status, err
 }
 
@@ -197,7 +219,11 @@ func (r *DorisClusterReconciler) fillDorisComponentStatus(
 	if exist {
 		baseStatus.Members = r.getComponentMembers(sts)
 		baseStatus.Conditions = sts.Status.Conditions
-		readyMembers, err := r.getComponentReadyMembers(r.CR.Namespace, statefulSetLabels)
+		baseStatus.Replicas = util.PointerDeRefer(sts.Spec.Replicas, 1)
+		baseStatus.CurrentReplicas = sts.Status.CurrentReplicas
+		baseStatus.ReadyReplicas = sts.Status.ReadyReplicas
+		baseStatus.UpdatedReplicas = sts.Status.UpdatedReplicas
+		readyMembers, err := r.getComponentReadyMembers(r.CR.ResourceKey().Namespace, statefulSetLabels)
 		if err != nil {
 			return err
 		}
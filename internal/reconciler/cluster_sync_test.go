@@ -0,0 +1,105 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestFillDorisComponentStatusReplicas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	sts := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-be", Namespace: "default"},
+		Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(3))},
+		Status: appv1.StatefulSetStatus{
+			Replicas:        3,
+			CurrentReplicas: 2,
+			ReadyReplicas:   2,
+			UpdatedReplicas: 1,
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sts).Build()
+	rec := DorisClusterReconciler{ReconcileContext: NewReconcileContext(cli, scheme, context.Background()), CR: cr}
+
+	status := &dapi.DorisComponentStatus{}
+	err := rec.fillDorisComponentStatus(status, tran.GetBeStatefulSetKey(cr.ResourceKey()),
+		tran.GetBeComponentLabels(cr.ResourceKey()), "apache/doris:be")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), status.Replicas)
+	assert.Equal(t, int32(2), status.CurrentReplicas)
+	assert.Equal(t, int32(2), status.ReadyReplicas)
+	assert.Equal(t, int32(1), status.UpdatedReplicas)
+	assert.Len(t, status.Members, 3)
+}
+
+func TestSyncFeStatusMembers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	cr.Spec.FE = &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}}
+	secretKey := tran.GetOprSqlAccountSecretRef(cr)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "test-fe", Namespace: "default"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-fe-0", Namespace: "default", Labels: tran.GetFeComponentLabels(cr.ResourceKey())},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{tran.OprSqlAccountUserKey: []byte("k8sopr"), tran.OprSqlAccountPasswordKey: []byte("pwd")},
+		},
+	).Build()
+	var showErr error
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		ShowFrontendsViaHttp: func(conf fe.FrontendHttpConf) ([]fe.Frontend, error) {
+			assert.Equal(t, tran.GetFeHttpPort(cr), conf.Port)
+			assert.Equal(t, "pwd", conf.Password)
+			return []fe.Frontend{{Host: "test-fe-0.test-fe-peer", Role: fe.FrontendRoleFollower, Alive: true}}, showErr
+		},
+	}
+	status, err := rec.syncFeStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), status.AliveMembers)
+	assert.Equal(t, []string{"test-fe-0"}, status.Followers)
+
+	// the previous members are retained when FE fails to respond
+	cr.Status.FE = status
+	showErr = errors.New("connection refused")
+	status, err = rec.syncFeStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), status.AliveMembers)
+	assert.Equal(t, []string{"test-fe-0"}, status.Followers)
+}
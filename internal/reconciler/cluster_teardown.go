@@ -0,0 +1,86 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// DorisClusterFinalizer is the finalizer that makes the DorisCluster to be torn down in order.
+	DorisClusterFinalizer = fmt.Sprintf("%s/teardown", dapi.GroupVersion.Group)
+	// ForceDeleteAnnotationKey is the escape-hatch annotation of DorisCluster, the ordered teardown
+	// would be skipped and the sub resources are left to the cascading deletion when it is "true",
+	// except that the sub resources placed in another namespace are still garbage collected.
+	ForceDeleteAnnotationKey = fmt.Sprintf("%s/force-delete", dapi.GroupVersion.Group)
+)
+
+// Teardown the sub resources of the deleting DorisCluster in order: delete CN, Broker, BE, FE and
+// the operator sql account secret, and finally garbage collect the remaining sub resources placed
+// in another namespace. The BE nodes are not decommissioned, since there is no BE left to receive
+// their tablets when the whole Doris cluster is torn down.
+// It is idempotent and would be retried until it returns the StageComplete result.
+func (r *DorisClusterReconciler) Teardown() ClusterStageRecResult {
+	if IsForceDelete(r.CR) {
+		r.Log.Info("skip the ordered teardown of DorisCluster due to the force-delete annotation")
+		// the cascading deletion does not apply to the sub resources in another namespace
+		if result := r.gcCrossNamespaceResources(); result.Err != nil {
+			return result
+		}
+		return ClusterStageRecResult{Stage: dapi.StageComplete, Status: dapi.StageResultSucceeded}
+	}
+	stages := []func() ClusterStageRecResult{
+		r.deleteCnResources,
+		r.deleteBrokerResources,
+		r.deleteBeResources,
+		r.deleteFeResources,
+		r.deleteOprAccountSecret,
+		r.gcCrossNamespaceResources,
+	}
+	for _, fn := range stages {
+		result := fn()
+		r.recordStageEvent(result)
+		if result.Err != nil {
+			return result
+		}
+	}
+	return ClusterStageRecResult{Stage: dapi.StageComplete, Status: dapi.StageResultSucceeded}
+}
+
+// IsForceDelete checks whether the ordered teardown of DorisCluster should be skipped.
+func IsForceDelete(cr *dapi.DorisCluster) bool {
+	return cr.Annotations[ForceDeleteAnnotationKey] == "true"
+}
+
+// delete the secret of the operator sql account.
+func (r *DorisClusterReconciler) deleteOprAccountSecret() ClusterStageRecResult {
+	action := dapi.StageActionDelete
+	// the secret supplied by user is left untouched
+	if tran.IsOprSqlAccountSecretReferenced(r.CR) {
+		return clusterStageSucc(dapi.StageSqlAccountSecret, action)
+	}
+	secretRef := tran.GetOprSqlAccountSecretKey(r.CR.ResourceKey())
+	if err := r.DeleteWhenExist(secretRef, &corev1.Secret{}); err != nil {
+		return clusterStageFail(dapi.StageSqlAccountSecret, action, err)
+	}
+	return clusterStageSucc(dapi.StageSqlAccountSecret, action)
+}
@@ -0,0 +1,75 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestIsForceDelete(t *testing.T) {
+	cr := &dapi.DorisCluster{}
+	assert.False(t, IsForceDelete(cr))
+	cr.Annotations = map[string]string{ForceDeleteAnnotationKey: "false"}
+	assert.False(t, IsForceDelete(cr))
+	cr.Annotations[ForceDeleteAnnotationKey] = "true"
+	assert.True(t, IsForceDelete(cr))
+}
+
+func TestTeardownWithoutBeDecommission(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}},
+			BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}},
+		},
+	}
+	stsKey := tran.GetBeStatefulSetKey(cr.ResourceKey())
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: stsKey.Name, Namespace: stsKey.Namespace},
+		Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(3))},
+	}).Build()
+	feCli := &fe.FakeClient{}
+	rec := DorisClusterReconciler{
+		ReconcileContext: NewReconcileContext(cli, scheme, context.Background()),
+		CR:               cr,
+		NewFeClient:      feCli.Factory(),
+	}
+
+	// the BE nodes are deleted without the decommission that can never complete
+	res := rec.Teardown()
+	assert.Nil(t, res.Err)
+	assert.Equal(t, dapi.StageComplete, res.Stage)
+	assert.Empty(t, feCli.Decommissioned)
+	exist, err := rec.Exist(stsKey, &appv1.StatefulSet{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
@@ -102,8 +102,9 @@ func (r *DorisInitializerReconciler) Reconcile() (dapi.DorisInitializerRecStatus
 		}
 		// replace job
 		feQueryPort := tran.GetFeQueryPort(clusterCr)
-		if job := tran.MakeInitializerJob(r.CR, feQueryPort, r.Schema); job != nil {
-			job.Spec.Template.Annotations[InitializerConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+		accountSecretRef := tran.GetOprSqlAccountSecretRef(clusterCr)
+		if job := tran.MakeInitializerJob(r.CR, tran.GetFeServiceDNS(clusterCr), feQueryPort, accountSecretRef, r.Schema); job != nil {
+			job.Spec.Template.Annotations[InitializerConfHashAnnotationKey] = util.ConfigHash(configMap.Data)
 			if err := r.Replace(job, &batchv1.Job{}, 30*time.Second); err != nil {
 				return err
 			}
@@ -0,0 +1,97 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"time"
+)
+
+const metricsNamespace = "doris_operator"
+
+var (
+	stageDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_stage_duration_seconds",
+		Help:      "Duration of reconciling each stage of DorisCluster in seconds.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"stage"})
+
+	stageFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_stage_failures_total",
+		Help:      "Total number of the failed stages when reconciling DorisCluster.",
+	}, []string{"stage", "action"})
+
+	managedClusters = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "managed_clusters",
+		Help:      "Number of DorisCluster managed by the operator in each phase.",
+	}, []string{"phase"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stageDurationSeconds, stageFailuresTotal, managedClusters)
+}
+
+// the phase label of DorisCluster that has not been synced yet
+const unknownPhaseLabel = "Unknown"
+
+// observe the duration and the result of the reconciled stage.
+func observeStageResult(result ClusterStageRecResult, duration time.Duration) {
+	stageDurationSeconds.WithLabelValues(string(result.Stage)).Observe(duration.Seconds())
+	if result.Status == dapi.StageResultFailed {
+		stageFailuresTotal.WithLabelValues(string(result.Stage), string(result.Action)).Inc()
+	}
+}
+
+// RecordManagedClusters counts the DorisCluster in each phase as the managed clusters metrics,
+// the DorisCluster being deleted is not counted.
+func RecordManagedClusters(ctx context.Context, reader client.Reader) error {
+	crList := &dapi.DorisClusterList{}
+	if err := reader.List(ctx, crList); err != nil {
+		return err
+	}
+	counts := map[string]float64{
+		string(dapi.DorisClusterPhaseCreating): 0,
+		string(dapi.DorisClusterPhaseUpdating): 0,
+		string(dapi.DorisClusterPhaseScaling):  0,
+		string(dapi.DorisClusterPhaseReady):    0,
+		string(dapi.DorisClusterPhaseFailed):   0,
+	}
+	for _, cr := range crList.Items {
+		if !cr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		phase := string(cr.Status.Phase)
+		if phase == "" {
+			phase = unknownPhaseLabel
+		}
+		counts[phase]++
+	}
+	managedClusters.Reset()
+	for phase, count := range counts {
+		managedClusters.WithLabelValues(phase).Set(count)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestObserveStageResult(t *testing.T) {
+	failures := testutil.ToFloat64(stageFailuresTotal.WithLabelValues(string(dapi.StageInitSQL), string(dapi.StageActionApply)))
+	observeStageResult(clusterStageSucc(dapi.StageInitSQL, dapi.StageActionApply), time.Second)
+	observeStageResult(clusterStageWait(dapi.StageInitSQL, dapi.StageActionApply, fmt.Errorf("waiting")), time.Second)
+	observeStageResult(clusterStageFail(dapi.StageInitSQL, dapi.StageActionApply, fmt.Errorf("failed")), time.Second)
+	assert.Equal(t, failures+1,
+		testutil.ToFloat64(stageFailuresTotal.WithLabelValues(string(dapi.StageInitSQL), string(dapi.StageActionApply))))
+	assert.Equal(t, 1, testutil.CollectAndCount(stageDurationSeconds.MustCurryWith(map[string]string{"stage": string(dapi.StageInitSQL)})))
+}
+
+func TestRecordManagedClusters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = dapi.AddToScheme(scheme)
+	newCr := func(name string, phase dapi.DorisClusterPhase) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     dapi.DorisClusterStatus{Phase: phase},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCr("ready-0", dapi.DorisClusterPhaseReady),
+		newCr("ready-1", dapi.DorisClusterPhaseReady),
+		newCr("scaling", dapi.DorisClusterPhaseScaling),
+		newCr("new", ""),
+	).Build()
+	assert.NoError(t, RecordManagedClusters(context.Background(), cli))
+	assert.Equal(t, float64(2), testutil.ToFloat64(managedClusters.WithLabelValues(string(dapi.DorisClusterPhaseReady))))
+	assert.Equal(t, float64(1), testutil.ToFloat64(managedClusters.WithLabelValues(string(dapi.DorisClusterPhaseScaling))))
+	assert.Equal(t, float64(1), testutil.ToFloat64(managedClusters.WithLabelValues(unknownPhaseLabel)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(managedClusters.WithLabelValues(string(dapi.DorisClusterPhaseFailed))))
+}
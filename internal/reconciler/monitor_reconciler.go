@@ -56,6 +56,8 @@ func (r *DorisMonitorReconciler) Reconcile() MonitorStageRecResult {
 		r.recGrafanaResources,
 		r.recLokiResources,
 		r.recPromtailResources,
+		r.recDashboardResources,
+		r.recAlertRuleResources,
 	}
 	for _, stageFn := range stages {
 		result := stageFn()
@@ -125,7 +127,7 @@ func (r *DorisMonitorReconciler) recPrometheusResources() MonitorStageRecResult
 	}
 	// statefulset
 	statefulset := tran.MakePrometheusStatefulset(r.CR, r.Schema)
-	statefulset.Spec.Template.Annotations[PrometheusConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+	statefulset.Spec.Template.Annotations[PrometheusConfHashAnnotationKey] = util.ConfigHash(configMap.Data)
 	if err := r.CreateOrUpdate(statefulset, &appv1.StatefulSet{}); err != nil {
 		return mnrStageFail(dapi.MnrOprStagePrometheusStatefulset, action, err)
 	}
@@ -155,7 +157,7 @@ func (r *DorisMonitorReconciler) recGrafanaResources() MonitorStageRecResult {
 	}
 	// statefulset
 	statefulset := tran.MakeGrafanaStatefulset(r.CR, r.Schema)
-	statefulset.Spec.Template.Annotations[GrafanaConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+	statefulset.Spec.Template.Annotations[GrafanaConfHashAnnotationKey] = util.ConfigHash(configMap.Data)
 	if err := r.CreateOrUpdate(statefulset, &appv1.StatefulSet{}); err != nil {
 		return mnrStageFail(dapi.MnrOprStageGrafanaStatefulset, action, err)
 	}
@@ -182,7 +184,7 @@ func (r *DorisMonitorReconciler) recLokiResources() MonitorStageRecResult {
 		}
 		// statefulset
 		statefulset := tran.MakeLokiStatefulset(r.CR, r.Schema)
-		statefulset.Spec.Template.Annotations[LokiConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+		statefulset.Spec.Template.Annotations[LokiConfHashAnnotationKey] = util.ConfigHash(configMap.Data)
 		if err := r.CreateOrUpdate(statefulset, &appv1.StatefulSet{}); err != nil {
 			return mnrStageFail(dapi.MnrOprStageLokiStatefulset, action, err)
 		}
@@ -228,7 +230,7 @@ func (r *DorisMonitorReconciler) recPromtailResources() MonitorStageRecResult {
 		}
 		// daemonset
 		daemonSet := tran.MakePromtailDaemonSet(r.CR, r.Schema)
-		daemonSet.Spec.Template.Annotations[PromtailConfHashAnnotationKey] = util.Md5HashOr(configMap.Data, "")
+		daemonSet.Spec.Template.Annotations[PromtailConfHashAnnotationKey] = util.ConfigHash(configMap.Data)
 		if err := r.CreateOrUpdate(daemonSet, &appv1.DaemonSet{}); err != nil {
 			return mnrStageFail(dapi.MnrOprStagePromtailDaemonSet, action, err)
 		}
@@ -253,3 +255,54 @@ func (r *DorisMonitorReconciler) recPromtailResources() MonitorStageRecResult {
 
 	return util.Elvis(r.CR.Spec.DisableLoki, deleteRes, applyRes)()
 }
+
+// reconcile the Grafana dashboards ConfigMap used by the dashboard sidecar of Grafana
+func (r *DorisMonitorReconciler) recDashboardResources() MonitorStageRecResult {
+	configMap := tran.MakeGrafanaDashboardsConfigMap(r.CR, r.Schema)
+	if configMap == nil {
+		action := dapi.StageActionDelete
+		configMapRef := tran.GetGrafanaDashboardsConfigMapKey(r.CR.ObjKey())
+		if err := r.DeleteWhenExist(configMapRef, &corev1.ConfigMap{}); err != nil {
+			return mnrStageFail(dapi.MnrOprStageDashboards, action, err)
+		}
+		return mnrStageSucc(dapi.MnrOprStageDashboards, action)
+	}
+	action := dapi.StageActionApply
+	if err := r.CreateOrUpdate(configMap, &corev1.ConfigMap{}); err != nil {
+		return mnrStageFail(dapi.MnrOprStageDashboards, action, err)
+	}
+	return mnrStageSucc(dapi.MnrOprStageDashboards, action)
+}
+
+// reconcile the PrometheusRule of the Doris alert rules, the alert rules file of the
+// Prometheus of DorisMonitor is reconciled along with the Prometheus ConfigMap.
+// It would be skipped when the PrometheusRule CRD is not installed.
+func (r *DorisMonitorReconciler) recAlertRuleResources() MonitorStageRecResult {
+	action := dapi.StageActionApply
+	installed, err := r.isKindInstalled(tran.PrometheusRuleGVK)
+	if err != nil {
+		return mnrStageFail(dapi.MnrOprStageAlertRules, action, err)
+	}
+	if !installed {
+		if r.CR.Spec.AlertRules != nil && r.CR.Spec.AlertRules.PrometheusRule {
+			r.Log.Info("skip creating PrometheusRule since the PrometheusRule CRD is not installed")
+		}
+		return mnrStageSucc(dapi.MnrOprStageAlertRules, action)
+	}
+	rule, err := tran.MakePrometheusRule(r.CR, r.Schema)
+	if err != nil {
+		return mnrStageFail(dapi.MnrOprStageAlertRules, action, err)
+	}
+	if rule == nil {
+		action = dapi.StageActionDelete
+		ruleRef := tran.GetPrometheusRuleKey(r.CR.ObjKey())
+		if err := r.DeleteWhenExist(ruleRef, tran.NewPrometheusRuleObject()); err != nil {
+			return mnrStageFail(dapi.MnrOprStageAlertRules, action, err)
+		}
+		return mnrStageSucc(dapi.MnrOprStageAlertRules, action)
+	}
+	if err := r.applyUnstructured(rule, tran.NewPrometheusRuleObject()); err != nil {
+		return mnrStageFail(dapi.MnrOprStageAlertRules, action, err)
+	}
+	return mnrStageSucc(dapi.MnrOprStageAlertRules, action)
+}
@@ -23,11 +23,19 @@ import (
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
 	"github.com/al-assad/doris-operator/internal/util"
 	"github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"time"
 )
@@ -35,9 +43,53 @@ import (
 // ReconcileContext is the context for reconciling CRD.
 type ReconcileContext struct {
 	client.Client
-	Schema *runtime.Scheme
-	Ctx    context.Context
-	Log    logr.Logger
+	Schema   *runtime.Scheme
+	Ctx      context.Context
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// DryRun records the changes of kubernetes objects instead of applying them when it is set.
+	DryRun *DryRunRecorder
+	// FieldManager enables the server-side apply of kubernetes objects in CreateOrUpdate when
+	// it is set, the operator only owns the fields it sets with this field manager, so that
+	// it coexists with the other controllers like HPA and mutating webhooks.
+	FieldManager string
+}
+
+// DryRunRecorder records the planned changes of kubernetes objects in dry-run mode.
+type DryRunRecorder struct {
+	Actions []dapi.PlanAction
+}
+
+// Drain returns the recorded actions and resets the recorder.
+func (d *DryRunRecorder) Drain() []dapi.PlanAction {
+	actions := d.Actions
+	d.Actions = nil
+	return actions
+}
+
+// record the planned change of the object, the update without any difference is ignored.
+func (r *ReconcileContext) recordDryRun(action dapi.PlanActionType, obj client.Object, existing client.Object) error {
+	planAction := dapi.PlanAction{Action: action, Kind: objKind(obj), Name: obj.GetName()}
+	if action == dapi.PlanActionUpdate || action == dapi.PlanActionReplace {
+		_, isSecret := obj.(*corev1.Secret)
+		diff, err := util.DiffObjectFields(obj, existing, isSecret)
+		if err != nil {
+			return err
+		}
+		if len(diff) == 0 {
+			return nil
+		}
+		planAction.Diff = diff
+	}
+	r.DryRun.Actions = append(r.DryRun.Actions, planAction)
+	return nil
+}
+
+func objKind(obj client.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
 }
 
 func NewReconcileContext(client client.Client, schema *runtime.Scheme, ctx context.Context) ReconcileContext {
@@ -49,6 +101,13 @@ func NewReconcileContext(client client.Client, schema *runtime.Scheme, ctx conte
 	}
 }
 
+// RecordEvent records a kubernetes event of the object when the event recorder is available.
+func (r *ReconcileContext) RecordEvent(obj runtime.Object, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventType, reason, message)
+	}
+}
+
 // Exist checks if the kubernetes object exists.
 func (r *ReconcileContext) Exist(key types.NamespacedName, objType client.Object) (bool, error) {
 	if err := r.Get(r.Ctx, key, objType); err != nil {
@@ -70,6 +129,9 @@ func (r *ReconcileContext) CreateWhenNotExist(obj client.Object, objType client.
 	if exist {
 		return nil
 	}
+	if r.DryRun != nil {
+		return r.recordDryRun(dapi.PlanActionCreate, obj, nil)
+	}
 	if err := r.Create(r.Ctx, obj); err != nil {
 		return err
 	}
@@ -84,6 +146,9 @@ func (r *ReconcileContext) DeleteWhenExist(key types.NamespacedName, objType cli
 		return err
 	}
 	if exist {
+		if r.DryRun != nil {
+			return r.recordDryRun(dapi.PlanActionDelete, objType, nil)
+		}
 		if err := r.Delete(r.Ctx, objType, deleteOpts...); err != nil {
 			return err
 		}
@@ -99,6 +164,18 @@ func (r *ReconcileContext) CreateOrUpdate(obj client.Object, objType client.Obje
 	if err != nil {
 		return err
 	}
+	if r.DryRun != nil {
+		return r.recordDryRun(util.Elvis(exist, dapi.PlanActionUpdate, dapi.PlanActionCreate), obj, objType)
+	}
+	if r.FieldManager != "" {
+		if err := r.apply(obj); err != nil {
+			return err
+		}
+		if !exist {
+			r.Log.Info("create object: " + util.K8sObjKeyStr(key))
+		}
+		return nil
+	}
 	if !exist {
 		// create object
 		if err := r.Create(r.Ctx, obj); err != nil {
@@ -111,6 +188,67 @@ func (r *ReconcileContext) CreateOrUpdate(obj client.Object, objType client.Obje
 	}
 }
 
+// apply the kubernetes object with the server-side apply, the conflicts with the
+// other field managers are forced to be owned by the operator. The status and the null
+// fields of the typed object are stripped from the patch, so that the operator does
+// not own the fields it never sets.
+func (r *ReconcileContext) apply(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Schema)
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(content, "status")
+	pruneNullFields(content)
+	patch := &unstructured.Unstructured{Object: content}
+	patch.SetGroupVersionKind(gvk)
+	if err := r.Patch(r.Ctx, patch, client.Apply, client.FieldOwner(r.FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(patch.Object, obj)
+}
+
+// remove the null fields from the unstructured content recursively, e.g. the zero
+// creationTimestamp of the typed object.
+func pruneNullFields(content map[string]any) {
+	for key, value := range content {
+		switch v := value.(type) {
+		case nil:
+			delete(content, key)
+		case map[string]any:
+			pruneNullFields(v)
+		case []any:
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					pruneNullFields(m)
+				}
+			}
+		}
+	}
+}
+
+// leave the replicas of the existing statefulset to the other controller like HPA, the replicas
+// are omitted from the patch of server-side apply, or retained from the existing statefulset
+// otherwise. The replicas of the statefulset to be created are kept.
+func (r *ReconcileContext) leaveReplicasUnmanaged(statefulSet *appv1.StatefulSet) error {
+	curSts := &appv1.StatefulSet{}
+	exist, err := r.Exist(client.ObjectKeyFromObject(statefulSet), curSts)
+	if err != nil || !exist {
+		return err
+	}
+	if r.FieldManager != "" {
+		statefulSet.Spec.Replicas = nil
+	} else {
+		statefulSet.Spec.Replicas = curSts.Spec.Replicas
+	}
+	return nil
+}
+
 // Replace deletes and creates the kubernetes object.
 func (r *ReconcileContext) Replace(obj client.Object, objType client.Object, timeout time.Duration) error {
 	key := client.ObjectKeyFromObject(obj)
@@ -118,6 +256,9 @@ func (r *ReconcileContext) Replace(obj client.Object, objType client.Object, tim
 	if err != nil {
 		return err
 	}
+	if r.DryRun != nil {
+		return r.recordDryRun(util.Elvis(exist, dapi.PlanActionReplace, dapi.PlanActionCreate), obj, objType)
+	}
 	// create
 	if !exist {
 		if err := r.Create(r.Ctx, obj); err != nil {
@@ -146,6 +287,32 @@ func (r *ReconcileContext) Replace(obj client.Object, objType client.Object, tim
 	return nil
 }
 
+// check whether the kind of custom resource is served by the api-server,
+// which is false when its CRD is not installed.
+func (r *ReconcileContext) isKindInstalled(gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// create or update the unstructured custom resource, the resourceVersion is required
+// when updating the custom resource.
+func (r *ReconcileContext) applyUnstructured(obj *unstructured.Unstructured, existing *unstructured.Unstructured) error {
+	exist, err := r.Exist(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return r.CreateOrUpdate(obj, existing)
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return r.Update(r.Ctx, obj)
+}
+
 // FindRefDorisAutoScaler finds the DorisAutoscaler CR that refer to the DorisCluster CR.
 // A DorisCluster CR can only be bound to one additional DorisAutoScaler CR.
 func (r *ReconcileContext) FindRefDorisAutoScaler(dorisClusterRef client.ObjectKey) (*dapi.DorisAutoscaler, error) {
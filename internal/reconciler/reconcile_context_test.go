@@ -0,0 +1,150 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"testing"
+)
+
+func TestReconcileContextDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-fe-config", Namespace: "default"},
+		Data:       map[string]string{"fe.conf": "a=1"},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing.DeepCopy()).Build()
+	ctx := NewReconcileContext(client.NewDryRunClient(cli), scheme, context.Background())
+	ctx.DryRun = &DryRunRecorder{}
+
+	// update
+	desired := existing.DeepCopy()
+	desired.Data["fe.conf"] = "a=2"
+	assert.NoError(t, ctx.CreateOrUpdate(desired, &corev1.ConfigMap{}))
+	// update without difference is ignored
+	assert.NoError(t, ctx.CreateOrUpdate(existing.DeepCopy(), &corev1.ConfigMap{}))
+	// create
+	created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-be-config", Namespace: "default"}}
+	assert.NoError(t, ctx.CreateOrUpdate(created, &corev1.ConfigMap{}))
+	// delete
+	assert.NoError(t, ctx.DeleteWhenExist(types.NamespacedName{Name: "test-fe-config", Namespace: "default"}, &corev1.ConfigMap{}))
+	assert.NoError(t, ctx.DeleteWhenExist(types.NamespacedName{Name: "not-exist", Namespace: "default"}, &corev1.ConfigMap{}))
+
+	assert.Equal(t, []dapi.PlanAction{
+		{Action: dapi.PlanActionUpdate, Kind: "ConfigMap", Name: "test-fe-config", Diff: []string{`data.fe.conf: "a=1" -> "a=2"`}},
+		{Action: dapi.PlanActionCreate, Kind: "ConfigMap", Name: "test-be-config"},
+		{Action: dapi.PlanActionDelete, Kind: "ConfigMap", Name: "test-fe-config"},
+	}, ctx.DryRun.Drain())
+	assert.Empty(t, ctx.DryRun.Actions)
+
+	// the cluster is not mutated
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, cli.Get(context.Background(), client.ObjectKeyFromObject(existing), cm))
+	assert.Equal(t, "a=1", cm.Data["fe.conf"])
+	exist, err := ctx.Exist(client.ObjectKeyFromObject(created), &corev1.ConfigMap{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestReconcileContextServerSideApply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	var patchType types.PatchType
+	var patchOpts client.PatchOptions
+	var patchedKind string
+	var patchContent map[string]any
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, client client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patchType = patch.Type()
+			patchOpts.ApplyOptions(opts)
+			patchedKind = obj.GetObjectKind().GroupVersionKind().Kind
+			patchContent = obj.(*unstructured.Unstructured).Object
+			return nil
+		},
+	}).Build()
+	ctx := NewReconcileContext(cli, scheme, context.Background())
+	ctx.FieldManager = "doris-operator"
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-fe-config", Namespace: "default", ResourceVersion: "1"}}
+	assert.NoError(t, ctx.CreateOrUpdate(cm, &corev1.ConfigMap{}))
+	assert.Equal(t, types.ApplyPatchType, patchType)
+	assert.Equal(t, "doris-operator", patchOpts.FieldManager)
+	assert.True(t, *patchOpts.Force)
+	assert.Equal(t, "ConfigMap", patchedKind)
+	assert.Empty(t, cm.ResourceVersion)
+
+	// the fields not set by operator are not in the patch
+	sts := &appv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "test-cn", Namespace: "default"}}
+	assert.NoError(t, ctx.CreateOrUpdate(sts, &appv1.StatefulSet{}))
+	assert.NotContains(t, patchContent, "status")
+	assert.NotContains(t, patchContent["metadata"], "creationTimestamp")
+	assert.NotContains(t, patchContent["spec"], "replicas")
+}
+
+func TestLeaveReplicasUnmanaged(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	existing := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cn", Namespace: "default"},
+		Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(5))},
+	}
+	ctx := NewReconcileContext(fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(), scheme, context.Background())
+	newSts := func(name string) *appv1.StatefulSet {
+		return &appv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appv1.StatefulSetSpec{Replicas: util.Pointer(int32(3))},
+		}
+	}
+	// the replicas of the statefulset to be created are kept
+	sts := newSts("other")
+	assert.NoError(t, ctx.leaveReplicasUnmanaged(sts))
+	assert.Equal(t, int32(3), *sts.Spec.Replicas)
+
+	// the replicas of the existing statefulset are retained
+	sts = newSts("test-cn")
+	assert.NoError(t, ctx.leaveReplicasUnmanaged(sts))
+	assert.Equal(t, int32(5), *sts.Spec.Replicas)
+
+	// and omitted from the patch of server-side apply
+	ctx.FieldManager = "doris-operator"
+	sts = newSts("test-cn")
+	assert.NoError(t, ctx.leaveReplicasUnmanaged(sts))
+	assert.Nil(t, sts.Spec.Replicas)
+}
+
+func TestIsDryRun(t *testing.T) {
+	cr := &dapi.DorisCluster{}
+	assert.False(t, IsDryRun(cr))
+	cr.Annotations = map[string]string{DryRunAnnotationKey: "true"}
+	assert.True(t, IsDryRun(cr))
+}
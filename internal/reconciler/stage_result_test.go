@@ -0,0 +1,85 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package reconciler
+
+import (
+	"errors"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClusterStageResultStatus(t *testing.T) {
+	succ := clusterStageSucc(dapi.StageFeStatefulSet, dapi.StageActionApply)
+	assert.Equal(t, dapi.StageResultSucceeded, succ.Status)
+	assert.Nil(t, succ.Err)
+
+	err := errors.New("boom")
+	fail := clusterStageFail(dapi.StageFeStatefulSet, dapi.StageActionApply, err)
+	assert.Equal(t, dapi.StageResultFailed, fail.Status)
+	assert.Equal(t, err, fail.Err)
+
+	recStatus := fail.AsDorisClusterRecStatus()
+	assert.Equal(t, dapi.StageResultFailed, recStatus.StageStatus)
+	assert.Equal(t, "boom", recStatus.LastMessage)
+
+	wait := clusterStageWait(dapi.StageBeDecommission, dapi.StageActionApply, err)
+	assert.Equal(t, dapi.StageResultWaiting, wait.Status)
+}
+
+func TestMonitorStageResultStatus(t *testing.T) {
+	fail := mnrStageFail(dapi.MnrOprStageCompleted, dapi.StageActionApply, errors.New("boom"))
+	assert.Equal(t, dapi.StageResultFailed, fail.Status)
+}
+
+func TestClusterStageInvalidResult(t *testing.T) {
+	err := errors.New("invalid spec")
+	invalid := clusterStageInvalid(dapi.StageFeService, dapi.StageActionApply, err)
+	assert.Equal(t, dapi.StageResultFailed, invalid.Status)
+	assert.True(t, invalid.Permanent)
+	assert.Equal(t, "invalid spec", invalid.AsDorisClusterRecStatus().LastMessage)
+
+	assert.False(t, clusterStageFail(dapi.StageFeService, dapi.StageActionApply, err).Permanent)
+	assert.False(t, clusterStageWait(dapi.StageBeDecommission, dapi.StageActionApply, err).Permanent)
+}
+
+func TestMergeStageResults(t *testing.T) {
+	action := dapi.StageActionApply
+	beWait := clusterStageWait(dapi.StageBeStatefulSet, action, errors.New("be rolling"))
+	cnFail := clusterStageFail(dapi.StageCnStatefulSet, action, errors.New("cn boom"))
+	brokerInvalid := clusterStageInvalid(dapi.StageBrokerService, action, errors.New("invalid broker"))
+
+	assert.Equal(t, beWait, mergeStageResults([]ClusterStageRecResult{beWait}))
+
+	// the first stage is kept and the errors are tagged with stages
+	merged := mergeStageResults([]ClusterStageRecResult{beWait, cnFail})
+	assert.Equal(t, dapi.StageBeStatefulSet, merged.Stage)
+	assert.Equal(t, dapi.StageResultFailed, merged.Status)
+	assert.False(t, merged.Permanent)
+	assert.Equal(t, "[be/Statefulset] be rolling; [cn/Statefulset] cn boom", merged.Err.Error())
+
+	// waiting only when all of them are waiting
+	cnWait := clusterStageWait(dapi.StageCnStatefulSet, action, errors.New("cn rolling"))
+	assert.Equal(t, dapi.StageResultWaiting, mergeStageResults([]ClusterStageRecResult{beWait, cnWait}).Status)
+
+	// permanent only when all of them are permanent
+	assert.False(t, mergeStageResults([]ClusterStageRecResult{brokerInvalid, cnFail}).Permanent)
+	feInvalid := clusterStageInvalid(dapi.StageFeService, action, errors.New("invalid fe"))
+	assert.True(t, mergeStageResults([]ClusterStageRecResult{feInvalid, brokerInvalid}).Permanent)
+}
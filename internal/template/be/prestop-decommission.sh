@@ -0,0 +1,49 @@
+# Decommission the current BE from the Doris cluster before it is stopped when it is removed
+# by scaling down, then wait until all tablets on it have been migrated. The BE is stopped
+# directly on the other terminations, e.g. the rolling restarts and evictions.
+
+host=$FE_SVC
+port=$FE_QUERY_PORT
+self_host=${POD_FQDN:-$(hostname -f)}
+be_addr="$self_host:$HEARTBEAT_PORT"
+
+# the target replicas of BE is passed in by operator via downward API, the operator waits for
+# the changed target replicas to be refreshed into the pod before scaling down BE
+pod_name=${self_host%%.*}
+ordinal=${pod_name##*-}
+target_replicas=$(cat /etc/apache-doris/be-pod-info/target-replicas 2>/dev/null)
+case "$target_replicas" in
+  ''|*[!0-9]*)
+    echo "info: target replicas of BE is unknown, stop BE $be_addr directly"
+    bin/stop_be.sh
+    exit 0
+    ;;
+esac
+if [ "$ordinal" -lt "$target_replicas" ]; then
+  echo "info: BE $be_addr is not removed by scaling down, stop it directly"
+  bin/stop_be.sh
+  exit 0
+fi
+
+run_sql() {
+  mysql -h $host -P $port -u"$ACC_USER" ${ACC_PWD:+"-p$ACC_PWD"} -N -e "$1"
+}
+
+# retry until it succeeds or the terminationGracePeriodSeconds runs out
+until run_sql "ALTER SYSTEM DECOMMISSION BACKEND \"$be_addr\""; do
+  # the backend may have been dropped by the operator
+  backends=$(run_sql "SHOW BACKENDS") && ! echo "$backends" | grep -q "$self_host" && break
+  echo "warn: failed to decommission BE $be_addr, retry later"
+  sleep 5
+done
+echo "info: decommissioning BE $be_addr"
+while true; do
+  # the failure of query is not regarded as the decommission has completed
+  if backends=$(run_sql "SHOW BACKENDS") && ! echo "$backends" | grep -q "$self_host"; then
+    echo "info: BE $be_addr has been decommissioned"
+    break
+  fi
+  sleep 5
+done
+
+bin/stop_be.sh
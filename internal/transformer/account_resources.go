@@ -21,6 +21,7 @@ package transformer
 import (
 	"fmt"
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,9 +37,43 @@ func GetOprSqlAccountSecretKey(dorisClusterKey types.NamespacedName) types.Names
 	}
 }
 
+const (
+	OprSqlAccountUserKey        = "user"
+	OprSqlAccountPasswordKey    = "password"
+	OprSqlAccountDefaultUser    = "k8sopr"
+	OprSqlAccountPasswordLength = 24
+)
+
+// OprSqlAccountRotationAnnoKey is the annotation key of DorisCluster to request the password
+// rotation of the operator SQL account, the password is rotated every time its value changes.
+var OprSqlAccountRotationAnnoKey = fmt.Sprintf("%s/rotate-opr-account-password", dapi.GroupVersion.Group)
+
+// GetOprSqlAccountSecretRef returns the secret of the operator SQL account that actually used
+// by the Doris cluster, which is the secret referenced by spec.oprSqlAccountSecretRef or the
+// secret generated by operator.
+func GetOprSqlAccountSecretRef(cr *dapi.DorisCluster) types.NamespacedName {
+	if IsOprSqlAccountSecretReferenced(cr) {
+		return types.NamespacedName{Namespace: cr.ResourceKey().Namespace, Name: cr.Spec.OprSqlAccountSecretRef.Name}
+	}
+	return GetOprSqlAccountSecretKey(cr.ResourceKey())
+}
+
+// IsOprSqlAccountSecretReferenced returns whether the operator SQL account is supplied by user.
+func IsOprSqlAccountSecretReferenced(cr *dapi.DorisCluster) bool {
+	return cr.Spec.OprSqlAccountSecretRef != nil && cr.Spec.OprSqlAccountSecretRef.Name != ""
+}
+
+// GetOprSqlAccountUser returns the username of the operator SQL account generated by operator.
+func GetOprSqlAccountUser(cr *dapi.DorisCluster) string {
+	if cr.Spec.OprSqlAccount != nil && cr.Spec.OprSqlAccount.Username != "" {
+		return cr.Spec.OprSqlAccount.Username
+	}
+	return OprSqlAccountDefaultUser
+}
+
 // MakeOprSqlAccountSecret generates a Secret for the operator SQL account.
 func MakeOprSqlAccountSecret(cr *dapi.DorisCluster) *corev1.Secret {
-	secretRef := GetOprSqlAccountSecretKey(cr.ObjKey())
+	secretRef := GetOprSqlAccountSecretKey(cr.ResourceKey())
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretRef.Name,
@@ -47,10 +82,13 @@ func MakeOprSqlAccountSecret(cr *dapi.DorisCluster) *corev1.Secret {
 		},
 		Type: corev1.SecretTypeOpaque,
 		StringData: map[string]string{
-			"user":     "k8sopr",
-			"password": GenerateRandomDorisPassword(15),
+			OprSqlAccountUserKey:     GetOprSqlAccountUser(cr),
+			OprSqlAccountPasswordKey: GenerateRandomDorisPassword(OprSqlAccountPasswordLength),
 		},
 	}
+	if IsCrossNamespace(cr) {
+		secret.Labels = util.MergeMaps(secret.Labels, GetClusterOwnerLabels(cr))
+	}
 	return secret
 }
 
@@ -0,0 +1,163 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultTabletImbalancePercent   int32 = 20
+	DefaultCompactionScoreThreshold int32 = 100
+
+	// PrometheusAlertRulesFileKey is the key of the alert rules file in the Prometheus configmap.
+	PrometheusAlertRulesFileKey = "doris-alert-rules.yml"
+)
+
+// PrometheusRuleGVK is the GroupVersionKind of Prometheus Operator PrometheusRule.
+var PrometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+// NewPrometheusRuleObject returns an empty PrometheusRule object.
+func NewPrometheusRuleObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(PrometheusRuleGVK)
+	return obj
+}
+
+func GetPrometheusRuleKey(monitorKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: monitorKey.Namespace,
+		Name:      fmt.Sprintf("%s-doris-alerts", monitorKey.Name),
+	}
+}
+
+// AlertRule is a Prometheus alerting rule.
+type AlertRule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// AlertRuleGroup is a group of Prometheus alerting rules.
+type AlertRuleGroup struct {
+	Name  string      `json:"name"`
+	Rules []AlertRule `json:"rules"`
+}
+
+// MakeDorisAlertRuleGroups makes the alert rules of the FE/BE health, tablet imbalance and compaction
+// backlog of the Doris cluster. The FE/BE metrics are matched by the jobs of the Prometheus of
+// DorisMonitor and the ServiceMonitors of DorisCluster, whose job is the name of the component service.
+func MakeDorisAlertRuleGroups(cr *dapi.DorisMonitor) []AlertRuleGroup {
+	if cr.Spec.Cluster == "" || cr.Spec.AlertRules == nil {
+		return nil
+	}
+	clusterKey := types.NamespacedName{Namespace: cr.Namespace, Name: cr.Spec.Cluster}
+	feJob := fmt.Sprintf(`job=~"doris-fe|%s"`, GetFeServiceKey(clusterKey).Name)
+	beJob := fmt.Sprintf(`job=~"doris-be|%s"`, GetBeServiceKey(clusterKey).Name)
+	imbalancePercent := util.PointerDeRefer(cr.Spec.AlertRules.TabletImbalancePercent, DefaultTabletImbalancePercent)
+	compactionScore := util.PointerDeRefer(cr.Spec.AlertRules.CompactionScoreThreshold, DefaultCompactionScoreThreshold)
+	tabletNum := fmt.Sprintf(`max by (backend) (doris_fe_tablet_num{%s})`, feJob)
+
+	rule := func(alert, expr, duration, severity, summary string) AlertRule {
+		return AlertRule{
+			Alert:       alert,
+			Expr:        expr,
+			For:         duration,
+			Labels:      map[string]string{"severity": severity, "doris_cluster": cr.Spec.Cluster},
+			Annotations: map[string]string{"summary": summary},
+		}
+	}
+	return []AlertRuleGroup{
+		{
+			Name: fmt.Sprintf("doris-%s-health", cr.Spec.Cluster),
+			Rules: []AlertRule{
+				rule("DorisFEDown", fmt.Sprintf(`up{%s} == 0`, feJob), "1m", "critical",
+					"Doris FE {{ $labels.instance }} is down"),
+				rule("DorisBEDown", fmt.Sprintf(`up{%s} == 0`, beJob), "1m", "critical",
+					"Doris BE {{ $labels.instance }} is down"),
+				rule("DorisDeadFENodes", fmt.Sprintf(`max(doris_fe_node_info{%s,type="fe_node_num",state="dead"}) > 0`, feJob),
+					"5m", "warning", "{{ $value }} FE nodes are dead in Doris cluster"),
+				rule("DorisDeadBENodes", fmt.Sprintf(`max(doris_fe_node_info{%s,type="be_node_num",state="dead"}) > 0`, feJob),
+					"5m", "warning", "{{ $value }} BE nodes are dead in Doris cluster"),
+			},
+		},
+		{
+			Name: fmt.Sprintf("doris-%s-storage", cr.Spec.Cluster),
+			Rules: []AlertRule{
+				rule("DorisTabletImbalance",
+					fmt.Sprintf(`(max(%s) - min(%s)) / avg(%s) * 100 > %d`, tabletNum, tabletNum, tabletNum, imbalancePercent),
+					"30m", "warning", "The tablets of Doris BE nodes are imbalanced by {{ $value }}%"),
+				rule("DorisCompactionBacklog",
+					fmt.Sprintf(`max by (instance) (doris_be_tablet_cumulative_max_compaction_score{%s}) > %d`, beJob, compactionScore),
+					"15m", "warning", "The compaction score of Doris BE {{ $labels.instance }} is {{ $value }}"),
+			},
+		},
+	}
+}
+
+// MakePrometheusAlertRulesFile makes the content of the alert rules file of Prometheus,
+// returns empty when the alert rules are not enabled.
+func MakePrometheusAlertRulesFile(cr *dapi.DorisMonitor) (string, error) {
+	groups := MakeDorisAlertRuleGroups(cr)
+	if groups == nil {
+		return "", nil
+	}
+	content, err := yaml.Marshal(map[string]any{"groups": groups})
+	if err != nil {
+		return "", util.MergeErrors(fmt.Errorf("fail to marshal the alert rules"), err)
+	}
+	return string(content), nil
+}
+
+// MakePrometheusRule makes the PrometheusRule of the Doris alert rules,
+// returns nil when the PrometheusRule is not enabled.
+func MakePrometheusRule(cr *dapi.DorisMonitor, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	if cr.Spec.AlertRules == nil || !cr.Spec.AlertRules.PrometheusRule {
+		return nil, nil
+	}
+	groups, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&struct {
+		Groups []AlertRuleGroup `json:"groups"`
+	}{MakeDorisAlertRuleGroups(cr)})
+	if err != nil {
+		return nil, err
+	}
+	ruleRef := GetPrometheusRuleKey(cr.ObjKey())
+	clusterRef := types.NamespacedName{Namespace: cr.Namespace, Name: cr.Spec.Cluster}
+	obj := NewPrometheusRuleObject()
+	obj.SetName(ruleRef.Name)
+	obj.SetNamespace(ruleRef.Namespace)
+	obj.SetLabels(util.MergeMaps(cr.Spec.AlertRules.Labels, GetMonitorPrometheusLabels(clusterRef)))
+	obj.Object["spec"] = groups
+	_ = controllerutil.SetOwnerReference(cr, obj, scheme)
+	return obj, nil
+}
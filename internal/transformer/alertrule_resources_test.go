@@ -0,0 +1,115 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
+	"testing"
+)
+
+func newTestDorisMonitor() *dapi.DorisMonitor {
+	return &dapi.DorisMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "mnr", Namespace: "default"},
+		Spec:       dapi.DorisMonitorSpec{Cluster: "test", Prometheus: &dapi.PrometheusSpec{}},
+	}
+}
+
+func TestMakeDorisAlertRuleGroups(t *testing.T) {
+	cr := newTestDorisMonitor()
+	assert.Nil(t, MakeDorisAlertRuleGroups(cr))
+
+	cr.Spec.AlertRules = &dapi.MonitorAlertRulesSpec{CompactionScoreThreshold: util.Pointer(int32(200))}
+	rules := make(map[string]AlertRule)
+	for _, group := range MakeDorisAlertRuleGroups(cr) {
+		for _, rule := range group.Rules {
+			rules[rule.Alert] = rule
+		}
+	}
+	assert.Len(t, rules, 6)
+	assert.Equal(t, `up{job=~"doris-fe|test-fe"} == 0`, rules["DorisFEDown"].Expr)
+	assert.Equal(t, `up{job=~"doris-be|test-be"} == 0`, rules["DorisBEDown"].Expr)
+	assert.True(t, strings.HasSuffix(rules["DorisTabletImbalance"].Expr, "* 100 > 20"))
+	assert.True(t, strings.HasSuffix(rules["DorisCompactionBacklog"].Expr, "> 200"))
+	assert.Equal(t, "test", rules["DorisBEDown"].Labels["doris_cluster"])
+}
+
+func TestMakePrometheusConfigMapWithAlertRules(t *testing.T) {
+	cr := newTestDorisMonitor()
+	configMap, err := MakePrometheusConfigMap(cr, runtime.NewScheme())
+	assert.Nil(t, err)
+	assert.NotContains(t, configMap.Data["prometheus.yml"], "rule_files")
+	assert.NotContains(t, configMap.Data, PrometheusAlertRulesFileKey)
+
+	cr.Spec.AlertRules = &dapi.MonitorAlertRulesSpec{}
+	configMap, err = MakePrometheusConfigMap(cr, runtime.NewScheme())
+	assert.Nil(t, err)
+	assert.Contains(t, configMap.Data["prometheus.yml"], "rule_files:\n  - /etc/prometheus/"+PrometheusAlertRulesFileKey)
+	assert.Contains(t, configMap.Data[PrometheusAlertRulesFileKey], "alert: DorisFEDown")
+
+	statefulset := MakePrometheusStatefulset(cr, runtime.NewScheme())
+	items := statefulset.Spec.Template.Spec.Volumes[0].ConfigMap.Items
+	assert.Len(t, items, 2)
+}
+
+func TestMakePrometheusRule(t *testing.T) {
+	cr := newTestDorisMonitor()
+	cr.Spec.AlertRules = &dapi.MonitorAlertRulesSpec{}
+	rule, err := MakePrometheusRule(cr, runtime.NewScheme())
+	assert.Nil(t, err)
+	assert.Nil(t, rule)
+
+	cr.Spec.AlertRules.PrometheusRule = true
+	cr.Spec.AlertRules.Labels = map[string]string{"release": "prometheus"}
+	rule, err = MakePrometheusRule(cr, runtime.NewScheme())
+	assert.Nil(t, err)
+	assert.Equal(t, PrometheusRuleGVK, rule.GroupVersionKind())
+	assert.Equal(t, "mnr-doris-alerts", rule.GetName())
+	assert.Equal(t, "prometheus", rule.GetLabels()["release"])
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	assert.Len(t, groups, 2)
+}
+
+func TestMakeGrafanaDashboardsConfigMap(t *testing.T) {
+	cr := newTestDorisMonitor()
+	assert.Nil(t, MakeGrafanaDashboardsConfigMap(cr, runtime.NewScheme()))
+
+	cr.Spec.Dashboards = &dapi.MonitorDashboardsSpec{}
+	configMap := MakeGrafanaDashboardsConfigMap(cr, runtime.NewScheme())
+	assert.Equal(t, "mnr-grafana-dashboards", configMap.Name)
+	assert.Equal(t, "1", configMap.Labels["grafana_dashboard"])
+	assert.Equal(t, GrafanaDashboardsConfContent, configMap.Data["doris-test-dashboards.json"])
+
+	cr.Spec.Dashboards = &dapi.MonitorDashboardsSpec{
+		Labels:        map[string]string{"dashboards": "doris"},
+		DatasourceUID: "prometheus",
+	}
+	configMap = MakeGrafanaDashboardsConfigMap(cr, runtime.NewScheme())
+	assert.NotContains(t, configMap.Labels, "grafana_dashboard")
+	assert.Equal(t, "doris", configMap.Labels["dashboards"])
+	dashboards := configMap.Data["doris-test-dashboards.json"]
+	assert.NotContains(t, dashboards, "PEB833E60655F2EBA")
+	assert.NotContains(t, dashboards, "gMxvgUxVk")
+	assert.Contains(t, dashboards, `"uid": "prometheus"`)
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
 	"github.com/al-assad/doris-operator/internal/util"
+	u "github.com/rjNemo/underscore"
 	acv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -58,8 +59,9 @@ func MakeCnScaleUpHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.Ho
 	}
 	cpuRuleExist := cr.Spec.CN.Rules.Cpu != nil && cr.Spec.CN.Rules.Cpu.Max != nil
 	memRuleExist := cr.Spec.CN.Rules.Memory != nil && cr.Spec.CN.Rules.Memory.Max != nil
+	extRules := u.Filter(cr.Spec.CN.Rules.External, func(rule dapi.ExternalMetricRule) bool { return rule.Max != nil })
 	// empty rule means no autoscaling
-	if !cpuRuleExist && !memRuleExist {
+	if !cpuRuleExist && !memRuleExist && len(extRules) == 0 {
 		return nil
 	}
 
@@ -69,7 +71,7 @@ func MakeCnScaleUpHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.Ho
 		Name:      cr.Spec.Cluster,
 	}
 	// hpa metrics
-	metricsList := make([]acv2.MetricSpec, 0, 2)
+	metricsList := make([]acv2.MetricSpec, 0, 2+len(extRules))
 	if cpuRuleExist {
 		metricsList = append(metricsList,
 			util.NewResourceAvgUtilizationMetricSpec(corev1.ResourceCPU, cr.Spec.CN.Rules.Cpu.Max))
@@ -78,15 +80,23 @@ func MakeCnScaleUpHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.Ho
 		metricsList = append(metricsList,
 			util.NewResourceAvgUtilizationMetricSpec(corev1.ResourceMemory, cr.Spec.CN.Rules.Memory.Max))
 	}
+	for _, rule := range extRules {
+		metricsList = append(metricsList, util.NewExternalAvgValueMetricSpec(rule.Name, rule.Selector, rule.Max))
+	}
 	// hpa behavior
 	selectPolicy := acv2.MaxChangePolicySelect
 	periodSec := DefaultHpaPeriodSeconds
 	if cr.Spec.CN.ScalePeriodSeconds != nil && cr.Spec.CN.ScalePeriodSeconds.ScaleUp != nil {
 		periodSec = *cr.Spec.CN.ScalePeriodSeconds.ScaleUp
 	}
+	var stabilizationSec *int32
+	if cr.Spec.CN.StabilizationWindowSeconds != nil {
+		stabilizationSec = cr.Spec.CN.StabilizationWindowSeconds.ScaleUp
+	}
 	behavior := &acv2.HorizontalPodAutoscalerBehavior{
 		ScaleUp: &acv2.HPAScalingRules{
-			SelectPolicy: &selectPolicy,
+			StabilizationWindowSeconds: stabilizationSec,
+			SelectPolicy:               &selectPolicy,
 			Policies: []acv2.HPAScalingPolicy{{
 				Type:          acv2.PodsScalingPolicy,
 				Value:         1,
@@ -124,8 +134,9 @@ func MakeCnScaleDownHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.
 	}
 	cpuRuleExist := cr.Spec.CN.Rules.Cpu != nil && cr.Spec.CN.Rules.Cpu.Min != nil
 	memRuleExist := cr.Spec.CN.Rules.Memory != nil && cr.Spec.CN.Rules.Memory.Min != nil
+	extRules := u.Filter(cr.Spec.CN.Rules.External, func(rule dapi.ExternalMetricRule) bool { return rule.Min != nil })
 	// empty rule means no autoscaling
-	if !cpuRuleExist && !memRuleExist {
+	if !cpuRuleExist && !memRuleExist && len(extRules) == 0 {
 		return nil
 	}
 
@@ -135,7 +146,7 @@ func MakeCnScaleDownHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.
 		Name:      cr.Spec.Cluster,
 	}
 	// hpa metrics
-	metricsList := make([]acv2.MetricSpec, 0, 2)
+	metricsList := make([]acv2.MetricSpec, 0, 2+len(extRules))
 	if cpuRuleExist {
 		metricsList = append(metricsList,
 			util.NewResourceAvgUtilizationMetricSpec(corev1.ResourceCPU, cr.Spec.CN.Rules.Cpu.Min))
@@ -144,15 +155,23 @@ func MakeCnScaleDownHpa(cr *dapi.DorisAutoscaler, scheme *runtime.Scheme) *acv2.
 		metricsList = append(metricsList,
 			util.NewResourceAvgUtilizationMetricSpec(corev1.ResourceMemory, cr.Spec.CN.Rules.Memory.Min))
 	}
+	for _, rule := range extRules {
+		metricsList = append(metricsList, util.NewExternalAvgValueMetricSpec(rule.Name, rule.Selector, rule.Min))
+	}
 	// hpa behavior
 	selectPolicy := acv2.MinChangePolicySelect
 	periodSec := DefaultHpaPeriodSeconds
 	if cr.Spec.CN.ScalePeriodSeconds != nil && cr.Spec.CN.ScalePeriodSeconds.ScaleDown != nil {
 		periodSec = *cr.Spec.CN.ScalePeriodSeconds.ScaleDown
 	}
+	var stabilizationSec *int32
+	if cr.Spec.CN.StabilizationWindowSeconds != nil {
+		stabilizationSec = cr.Spec.CN.StabilizationWindowSeconds.ScaleDown
+	}
 	behavior := &acv2.HorizontalPodAutoscalerBehavior{
 		ScaleDown: &acv2.HPAScalingRules{
-			SelectPolicy: &selectPolicy,
+			StabilizationWindowSeconds: stabilizationSec,
+			SelectPolicy:               &selectPolicy,
 			Policies: []acv2.HPAScalingPolicy{{
 				Type:          acv2.PodsScalingPolicy,
 				Value:         1,
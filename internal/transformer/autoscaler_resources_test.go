@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	acv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func newTestDorisAutoscaler() *dapi.DorisAutoscaler {
+	return &dapi.DorisAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisAutoscalerSpec{
+			Cluster: "test",
+			CN: &dapi.CNAutoscalerSpec{
+				Replicas: dapi.ReplicasRange{Max: 5, Min: util.Pointer(int32(1))},
+			},
+		},
+	}
+}
+
+func TestMakeCnHpaExternalMetrics(t *testing.T) {
+	cr := newTestDorisAutoscaler()
+	assert.Nil(t, MakeCnScaleUpHpa(cr, runtime.NewScheme()))
+	assert.Nil(t, MakeCnScaleDownHpa(cr, runtime.NewScheme()))
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"cluster": "test"}}
+	maxValue, minValue := resource.MustParse("10"), resource.MustParse("2")
+	cr.Spec.CN.Rules.External = []dapi.ExternalMetricRule{
+		{Name: "doris_fe_running_query", Selector: selector, Max: &maxValue, Min: &minValue},
+	}
+	cr.Spec.CN.StabilizationWindowSeconds = &dapi.ScalePeriodSeconds{
+		ScaleUp:   util.Pointer(int32(60)),
+		ScaleDown: util.Pointer(int32(300)),
+	}
+
+	upHpa := MakeCnScaleUpHpa(cr, runtime.NewScheme())
+	assert.Equal(t, []acv2.MetricSpec{
+		util.NewExternalAvgValueMetricSpec("doris_fe_running_query", selector, &maxValue),
+	}, upHpa.Spec.Metrics)
+	assert.Equal(t, int32(60), *upHpa.Spec.Behavior.ScaleUp.StabilizationWindowSeconds)
+
+	downHpa := MakeCnScaleDownHpa(cr, runtime.NewScheme())
+	assert.Equal(t, acv2.ExternalMetricSourceType, downHpa.Spec.Metrics[0].Type)
+	assert.Equal(t, minValue, *downHpa.Spec.Metrics[0].External.Target.AverageValue)
+	assert.Equal(t, int32(300), *downHpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+}
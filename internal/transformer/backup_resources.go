@@ -0,0 +1,57 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/fe"
+	"strings"
+	"time"
+)
+
+// GetRestoreRepositoryName returns the name of the read only backup repository created in Doris
+// for restoring from the spec.restoreFrom, like "<cluster>_restore".
+func GetRestoreRepositoryName(cr *dapi.DorisCluster) string {
+	return strings.ReplaceAll(cr.Name, "-", "_") + "_restore"
+}
+
+// GetBackupRepositoryName returns the name of the backup repository created in Doris for the
+// scheduled backups of spec.backup, like "<cluster>_backup".
+func GetBackupRepositoryName(cr *dapi.DorisCluster) string {
+	return strings.ReplaceAll(cr.Name, "-", "_") + "_backup"
+}
+
+// GetBackupSnapshotName returns the snapshot name of the backup scheduled at the time,
+// like "backup_20240101020000".
+func GetBackupSnapshotName(scheduleTime time.Time) string {
+	return "backup_" + scheduleTime.UTC().Format("20060102150405")
+}
+
+// GetBackupRepositoryType returns the storage type of Doris backup repository according to the
+// scheme of location, and an empty string for the unsupported location.
+func GetBackupRepositoryType(location string) string {
+	switch {
+	case strings.HasPrefix(location, "s3://"):
+		return fe.RepositoryTypeS3
+	case strings.HasPrefix(location, "hdfs://"):
+		return fe.RepositoryTypeHdfs
+	default:
+		return ""
+	}
+}
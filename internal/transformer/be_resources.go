@@ -21,13 +21,15 @@ package transformer
 import (
 	"fmt"
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/template"
 	"github.com/al-assad/doris-operator/internal/util"
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"strconv"
 	"strings"
 )
@@ -40,10 +42,37 @@ const (
 
 	BeProbeTimeoutSec = 200
 
+	DefaultBeTerminationGracePeriodSeconds int64 = 120
+	// DefaultBeMinReadySeconds gives the BE a buffer to serve queries after it reports ready
+	// before the next BE pod is updated.
+	DefaultBeMinReadySeconds int32 = 15
+
 	BeRootPath              = "/opt/apache-doris/be"
 	BeCustomStorageRootPath = "/var/lib/doris/data"
+
+	DefaultBeStorageMountPath = BeRootPath + "/storage"
+	DefaultBeLogMountPath     = BeRootPath + "/log"
+
+	// BeCordonFileKey is the key of the BE cordon configmap that lists the names of cordoned pods.
+	BeCordonFileKey           = "cordoned"
+	BeCordonGateContainerName = "cordon-gate"
+	BeCordonMountPath         = "/etc/apache-doris/be-cordon/"
+
+	// Mount path of the pod info that tells the preStop hook of BE the target replicas
+	BePodInfoMountPath = "/etc/apache-doris/be-pod-info/"
 )
 
+var BePreStopDecommissionScriptContent = template.ReadOrPanic("be/prestop-decommission.sh")
+
+// BeTargetReplicasPodAnnoKey is the annotation key of BE pod that records the target replicas
+// of BE, which is exposed to the preStop hook via downward API, so that the BE is decommissioned
+// only when it is removed by scaling down rather than restarted.
+var BeTargetReplicasPodAnnoKey = fmt.Sprintf("%s/be-target-replicas", dapi.GroupVersion.Group)
+
+// BeTargetReplicasUpdatedAtPodAnnoKey is the annotation key of BE pod that records when the
+// target replicas annotation was changed, which is used to wait for the downward API refreshing.
+var BeTargetReplicasUpdatedAtPodAnnoKey = fmt.Sprintf("%s/be-target-replicas-updated-at", dapi.GroupVersion.Group)
+
 func GetBeComponentLabels(dorisClusterKey types.NamespacedName) map[string]string {
 	return MakeResourceLabels(dorisClusterKey.Name, "be")
 }
@@ -55,6 +84,15 @@ func GetBeConfigMapKey(dorisClusterKey types.NamespacedName) types.NamespacedNam
 	}
 }
 
+// GetBeCordonConfigMapKey returns the key of the configmap that lists the cordoned BE pods,
+// which is kept apart from the BE configmap so that cordoning does not restart all BE pods.
+func GetBeCordonConfigMapKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-be-cordon", dorisClusterKey.Name),
+	}
+}
+
 func GetBeServiceKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: dorisClusterKey.Namespace,
@@ -76,37 +114,64 @@ func GetBeStatefulSetKey(dorisClusterKey types.NamespacedName) types.NamespacedN
 	}
 }
 
+func GetBePodDisruptionBudgetKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-be", dorisClusterKey.Name),
+	}
+}
+
+// GetBeBaseImage returns the base image of BE, which falls back to the operator-wide default.
+func GetBeBaseImage(r *dapi.DorisCluster) string {
+	return util.Coalesce(r.Spec.BE.BaseImage, DefaultBeBaseImage)
+}
+
 func GetBeImage(r *dapi.DorisCluster) string {
-	version := util.StringFallback(r.Spec.BE.Version, r.Spec.Version)
-	return fmt.Sprintf("%s:%s", r.Spec.BE.BaseImage, version)
+	version := util.Coalesce(r.Spec.BE.Version, r.Spec.Version)
+	return withImageRegistry(r, fmt.Sprintf("%s:%s", GetBeBaseImage(r), version))
 }
 
 func GetBeHeartbeatServicePort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.BE == nil {
 		return DefaultBeHeartbeatServicePort
 	}
-	return getPortValueFromRawConf(cr.Spec.BE.Configs, "be_port", DefaultBeHeartbeatServicePort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.BE.DorisComponentSpec), "heartbeat_service_port", DefaultBeHeartbeatServicePort)
 }
 
 func GetBePort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.BE == nil {
 		return DefaultBePort
 	}
-	return getPortValueFromRawConf(cr.Spec.BE.Configs, "be_port", DefaultBePort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.BE.DorisComponentSpec), "be_port", DefaultBePort)
 }
 
 func GetBeWebserverPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.BE == nil {
 		return DefaultBeWebserverPort
 	}
-	return getPortValueFromRawConf(cr.Spec.BE.Configs, "webserver_port", DefaultBeWebserverPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.BE.DorisComponentSpec), "webserver_port", DefaultBeWebserverPort)
 }
 
 func GetBeBrpcPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.BE == nil {
 		return DefaultBeBrpcPort
 	}
-	return getPortValueFromRawConf(cr.Spec.BE.Configs, "brpc_port", DefaultBeBrpcPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.BE.DorisComponentSpec), "brpc_port", DefaultBeBrpcPort)
+}
+
+// GetBeLogMountPath returns the mount path of the BE log volume.
+func GetBeLogMountPath(cr *dapi.DorisCluster) string {
+	return strings.TrimSuffix(util.Coalesce(cr.Spec.BE.LogMountPath, DefaultBeLogMountPath), "/")
+}
+
+// Get the mount path of the default BE data storage.
+func getBeDefaultStorageMountPath(beSpec *dapi.BESpec) string {
+	return strings.TrimSuffix(util.Coalesce(beSpec.StorageMountPath, DefaultBeStorageMountPath), "/")
+}
+
+// GetBePodFQDN returns the FQDN of BE pod that is used as the host of backend in Doris cluster.
+func GetBePodFQDN(cr *dapi.DorisCluster, podName string) string {
+	return makePodFQDN(cr, GetBePeerServiceKey(cr.ResourceKey()).Name, podName)
 }
 
 func GetBeExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) []string {
@@ -118,20 +183,86 @@ func GetBeExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) [
 	return expectPods
 }
 
-func MakeBeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.ConfigMap {
+// GetBeCordonedPodNames returns the names of the BE pods cordoned by spec.be.cordonedOrdinals
+// in the order of ordinals, the ordinals out of the replicas are ignored.
+func GetBeCordonedPodNames(cr *dapi.DorisCluster) []string {
+	if cr.Spec.BE == nil {
+		return nil
+	}
+	cordoned := make(map[int32]bool)
+	for _, ordinal := range cr.Spec.BE.CordonedOrdinals {
+		cordoned[ordinal] = true
+	}
+	var pods []string
+	for i, pod := range GetBeExpectPodNames(cr.ResourceKey(), cr.Spec.BE.Replicas) {
+		if cordoned[int32(i)] {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// MakeBeCordonConfigMap makes the configmap that lists the cordoned BE pods line by line,
+// which is read by the cordon gate init container of BE pods.
+func MakeBeCordonConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.ConfigMap {
 	if cr.Spec.BE == nil {
 		return nil
 	}
-	configMapRef := GetBeConfigMapKey(cr.ObjKey())
-	configs := util.MapFallback(cr.Spec.BE.Configs, make(map[string]string))
-	configs["be_node_role"] = "mix"
+	configMapRef := GetBeCordonConfigMapKey(cr.ResourceKey())
+	var content string
+	for _, pod := range GetBeCordonedPodNames(cr) {
+		content += pod + "\n"
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapRef.Name,
+			Namespace: configMapRef.Namespace,
+			Labels:    GetBeComponentLabels(cr.ResourceKey()),
+		},
+		Data: map[string]string{BeCordonFileKey: content},
+	}
+	setClusterOwner(cr, configMap, scheme, false)
+	return configMap
+}
+
+// make the init container that holds the BE pod while it is listed in the cordon configmap,
+// the mounted configmap is refreshed by kubelet so that the pod is released once uncordoned.
+func makeBeCordonGateInitContainer(cr *dapi.DorisCluster) corev1.Container {
+	script := fmt.Sprintf(`while grep -qx "$POD_NAME" %s%s 2>/dev/null; do
+  echo "info: $POD_NAME is cordoned, waiting for it to be uncordoned"
+  sleep 10
+done`, BeCordonMountPath, BeCordonFileKey)
+	return corev1.Container{
+		Name:            BeCordonGateContainerName,
+		Image:           GetBusyBoxImage(cr),
+		ImagePullPolicy: cr.Spec.ImagePullPolicy,
+		Command:         []string{"/bin/sh", "-c", script},
+		Env:             []corev1.EnvVar{{Name: "POD_NAME", ValueFrom: util.NewEnvVarFieldSource("metadata.name")}},
+		VolumeMounts:    []corev1.VolumeMount{{Name: "cordon", MountPath: BeCordonMountPath, ReadOnly: true}},
+	}
+}
 
-	// inject storage_root_path config when be.storage was set
-	if len(cr.Spec.BE.Storage) > 0 {
-		configs["storage_root_path"] = extractBeStorageRootPath(cr.Spec.BE)
+// MakeBeConfigMap makes the BE configmap, refConfigs are the configs from
+// the ConfigMap referenced by spec.be.configMapRef.
+func MakeBeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, refConfigs map[string]string) *corev1.ConfigMap {
+	if cr.Spec.BE == nil {
+		return nil
+	}
+	configMapRef := GetBeConfigMapKey(cr.ResourceKey())
+	injectConfigs := map[string]string{"be_node_role": "mix"}
+	// inject storage_root_path config when be.storage or the custom default storage mount path was set
+	if len(cr.Spec.BE.Storage) > 0 || getBeDefaultStorageMountPath(cr.Spec.BE) != DefaultBeStorageMountPath {
+		injectConfigs["storage_root_path"] = extractBeStorageRootPath(cr.Spec.BE)
+	}
+	if logPath := GetBeLogMountPath(cr); logPath != DefaultBeLogMountPath {
+		injectConfigs["LOG_DIR"] = logPath
 	}
+	configs := util.MergeMaps(util.MergeMaps(refConfigs, cr.Spec.BE.Configs), injectConfigs)
 	data := map[string]string{
-		"be.conf": dumpCppBasedComponentConf(configs),
+		BeConfFileKey: withRawComponentConf(cr.Spec.BE.ConfigFileContent, dumpCppBasedComponentConf(configs)),
+	}
+	if cr.Spec.BE.PreStopDecommission {
+		data["prestop-decommission.sh"] = BePreStopDecommissionScriptContent
 	}
 	// merge hadoop config data
 	if cr.Spec.HadoopConf != nil {
@@ -140,13 +271,14 @@ func MakeBeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Conf
 	// gen configmap
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapRef.Name,
-			Namespace: configMapRef.Namespace,
-			Labels:    GetBeComponentLabels(cr.ObjKey()),
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.BE.ConfigMapLabels, GetBeComponentLabels(cr.ResourceKey())),
+			Annotations: cr.Spec.BE.ConfigMapAnnotations,
 		},
 		Data: data,
 	}
-	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
+	setClusterOwner(cr, configMap, scheme, false)
 	return configMap
 }
 
@@ -154,26 +286,27 @@ func MakeBeService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Servic
 	if cr.Spec.BE == nil {
 		return nil
 	}
-	serviceRef := GetBeServiceKey(cr.ObjKey())
-	beLabels := GetBeComponentLabels(cr.ObjKey())
+	serviceRef := GetBeServiceKey(cr.ResourceKey())
+	beLabels := GetBeComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    beLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.BE.ServiceLabels, beLabels),
+			Annotations: cr.Spec.BE.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
+			Ports: append([]corev1.ServicePort{
 				{
 					Name: "webserver-port",
 					Port: GetBeWebserverPort(cr),
 				},
-			},
+			}, makeExtraServicePorts(&cr.Spec.BE.DorisComponentSpec)...),
 			Selector: beLabels,
 			Type:     corev1.ServiceTypeClusterIP,
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
@@ -181,13 +314,14 @@ func MakeBePeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Se
 	if cr.Spec.BE == nil {
 		return nil
 	}
-	serviceRef := GetBePeerServiceKey(cr.ObjKey())
-	beLabels := GetBeComponentLabels(cr.ObjKey())
+	serviceRef := GetBePeerServiceKey(cr.ResourceKey())
+	beLabels := GetBeComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    beLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      mergePodMeta(cr.Spec.BE.ServiceLabels, beLabels, PeerServiceLabels),
+			Annotations: cr.Spec.BE.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
@@ -200,22 +334,60 @@ func MakeBePeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Se
 			ClusterIP: "None",
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
+// makeBeContainerPorts returns the container ports of BE managed by operator.
+func makeBeContainerPorts(cr *dapi.DorisCluster) []corev1.ContainerPort {
+	return []corev1.ContainerPort{
+		{Name: "webserver-port", ContainerPort: GetBeWebserverPort(cr)},
+		{Name: "heart-port", ContainerPort: GetBeHeartbeatServicePort(cr)},
+		{Name: "be-port", ContainerPort: GetBePort(cr)},
+		{Name: "brpc-port", ContainerPort: GetBeBrpcPort(cr)},
+	}
+}
+
+// makeBeContainerPortsWithExtra returns the managed and extra container ports of BE,
+// which are exposed as the same host ports when the host network is enabled.
+func makeBeContainerPortsWithExtra(cr *dapi.DorisCluster) []corev1.ContainerPort {
+	ports := withExtraContainerPorts(makeBeContainerPorts(cr), &cr.Spec.BE.DorisComponentSpec)
+	if cr.Spec.BE.HostNetwork {
+		for i := range ports {
+			ports[i].HostPort = ports[i].ContainerPort
+		}
+	}
+	return ports
+}
+
 func MakeBeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.StatefulSet {
 	if cr.Spec.BE == nil {
 		return nil
 	}
-	statefulSetRef := GetBeStatefulSetKey(cr.ObjKey())
-	accountSecretRef := GetOprSqlAccountSecretKey(cr.ObjKey())
-	beLabels := GetBeComponentLabels(cr.ObjKey())
+	statefulSetRef := GetBeStatefulSetKey(cr.ResourceKey())
+	accountSecretRef := GetOprSqlAccountSecretRef(cr)
+	beLabels := GetBeComponentLabels(cr.ResourceKey())
 
 	// pod template: volumes
 	volumes := []corev1.Volume{
-		{Name: "conf", VolumeSource: util.NewConfigMapVolumeSource(GetBeConfigMapKey(cr.ObjKey()).Name)},
+		{Name: "conf", VolumeSource: util.NewConfigMapVolumeSource(GetBeConfigMapKey(cr.ResourceKey()).Name)},
 		{Name: "be-log", VolumeSource: util.NewEmptyDirVolumeSource()},
+		{Name: "cordon", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: GetBeCordonConfigMapKey(cr.ResourceKey()).Name},
+			Optional:             util.Pointer(true),
+		}}},
+	}
+	if cr.Spec.BE.PreStopDecommission {
+		volumes = append(volumes, corev1.Volume{Name: "be-pod-info", VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{{
+					Path: "target-replicas",
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: fmt.Sprintf("metadata.annotations['%s']", BeTargetReplicasPodAnnoKey),
+					},
+				}},
+			},
+		}})
 	}
 	// merge addition volumes defined by user
 	volumes = append(volumes, cr.Spec.BE.AdditionalVolumes...)
@@ -223,50 +395,50 @@ func MakeBeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	// pod template:  volume mount
 	volumeMounts := []corev1.VolumeMount{
 		{Name: "conf", MountPath: "/etc/apache-doris/be/"},
-		{Name: "be-log", MountPath: fmt.Sprintf("%s/log", BeRootPath)},
+		{Name: "be-log", MountPath: GetBeLogMountPath(cr)},
 	}
-	volumeMounts = append(volumeMounts, genBeDataPVCVolumeMounts(cr.Spec.BE)...)
-	// merge addition volume mounts defined by user
-	volumeMounts = append(cr.Spec.BE.AdditionalVolumeMounts, volumeMounts...)
+	if cr.Spec.BE.PreStopDecommission {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "be-pod-info", MountPath: BePodInfoMountPath, ReadOnly: true})
+	}
+	// pod template: storage volumes
+	pvcTemplates, storagePodVolumes, storageMounts := genStorageVolumes(getBeStorageVolumes(cr.Spec.BE), cr.Spec.BE.StorageClassName, cr.Spec.BE.StorageAnnotations)
+	volumes = append(volumes, storagePodVolumes...)
+	volumeMounts = mergeStorageVolumeMounts(volumeMounts, storageMounts)
 
 	// pod template: main container
 	mainContainer := corev1.Container{
 		Name:            "be",
+		SecurityContext: getContainerSecurityContext(cr, &cr.Spec.BE.DorisComponentSpec),
 		Image:           GetBeImage(cr),
-		ImagePullPolicy: cr.Spec.ImagePullPolicy,
-		Resources:       formatContainerResourcesRequirement(cr.Spec.BE.ResourceRequirements),
-		Ports: []corev1.ContainerPort{
-			{Name: "webserver-port", ContainerPort: GetBeWebserverPort(cr)},
-			{Name: "heart-port", ContainerPort: GetBeHeartbeatServicePort(cr)},
-			{Name: "be-port", ContainerPort: GetBePort(cr)},
-			{Name: "brpc-port", ContainerPort: GetBeBrpcPort(cr)},
-		},
+		ImagePullPolicy: GetImagePullPolicy(cr, GetBeImage(cr)),
+		Command:         cr.Spec.BE.Command,
+		Args:            cr.Spec.BE.Args,
+		Resources:       formatContainerResourcesRequirement(GetComponentResources(cr, &cr.Spec.BE.DorisComponentSpec)),
+		Ports:           makeBeContainerPortsWithExtra(cr),
 		Env: []corev1.EnvVar{
-			{Name: "FE_SVC", Value: GetFeServiceKey(cr.ObjKey()).Name},
+			{Name: "FE_SVC", Value: GetFeServiceDNS(cr)},
 			{Name: "FE_QUERY_PORT", Value: strconv.Itoa(int(GetFeQueryPort(cr)))},
 			{Name: "ACC_USER", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "user")},
 			{Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "password")},
 			{Name: "BE_PROBE_TIMEOUT", Value: strconv.Itoa(BeProbeTimeoutSec)},
+			{Name: "HEARTBEAT_PORT", Value: strconv.Itoa(int(GetBeHeartbeatServicePort(cr)))},
 		},
 		VolumeMounts: volumeMounts,
 		Lifecycle: &corev1.Lifecycle{
-			PreStop: util.NewExecLifecycleHandler("/bin/sh", "-c", "bin/stop_be.sh"),
-		},
-		ReadinessProbe: &corev1.Probe{
-			ProbeHandler:     util.NewTcpSocketProbeHandler(GetBeHeartbeatServicePort(cr)),
-			TimeoutSeconds:   1,
-			PeriodSeconds:    5,
-			SuccessThreshold: 1,
-			FailureThreshold: 3,
+			PreStop: util.Elvis(cr.Spec.BE.PreStopDecommission,
+				util.NewExecLifecycleHandler("/bin/sh", "/etc/apache-doris/be/prestop-decommission.sh"),
+				util.NewExecLifecycleHandler("/bin/sh", "-c", "bin/stop_be.sh")),
 		},
-		LivenessProbe: &corev1.Probe{
+		ReadinessProbe: makeReadinessProbe(cr.Spec.BE.ReadinessProbeType, GetBeHeartbeatServicePort(cr), GetBeWebserverPort(cr), nil),
+		LivenessProbe: util.Coalesce(cr.Spec.BE.LivenessProbe, &corev1.Probe{
 			ProbeHandler:        util.NewTcpSocketProbeHandler(GetBeHeartbeatServicePort(cr)),
 			InitialDelaySeconds: 20,
 			TimeoutSeconds:      1,
 			PeriodSeconds:       5,
 			SuccessThreshold:    1,
 			FailureThreshold:    5,
-		},
+		}),
+		StartupProbe: util.Coalesce(cr.Spec.BE.StartupProbe, makeDefaultStartupProbe(GetBeHeartbeatServicePort(cr))),
 	}
 	// pod template: init container
 	privileged := true
@@ -276,10 +448,24 @@ func MakeBeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 		Command:         []string{"sysctl", "-w", "vm.max_map_count=2000000"},
 		SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
 	}
+	initContainers := []corev1.Container{initContainer}
+	if IsWaitForFe(cr) {
+		initContainers = append(initContainers, makeWaitForFeInitContainer(cr, GetBeImage(cr)))
+	}
+	initContainers = append(initContainers, makeBeCordonGateInitContainer(cr))
+	// pod template: FQDN of the pod resolved via the peer service
+	mainContainer.Env = append(mainContainer.Env, makePodFQDNEnvs(cr, GetBePeerServiceKey(cr.ResourceKey()).Name)...)
+	// pod template: credentials for accessing HDFS/S3
+	volumes = injectHadoopCredentials(cr, &mainContainer, volumes)
 	// pod template: merge additional pod containers configs defined by user
 	mainContainer.Env = append(mainContainer.Env, cr.Spec.BE.AdditionalEnvs...)
+	mainContainer.EnvFrom = append(mainContainer.EnvFrom, cr.Spec.BE.AdditionalEnvFrom...)
 	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, cr.Spec.BE.AdditionalVolumeMounts...)
-	containers := append([]corev1.Container{mainContainer}, cr.Spec.BE.AdditionalContainers...)
+	sidecars := cr.Spec.BE.AdditionalContainers
+	if cr.Spec.BE.ShareLogVolume {
+		sidecars = shareLogVolumeMount(sidecars, corev1.VolumeMount{Name: "be-log", MountPath: GetBeLogMountPath(cr)})
+	}
+	containers := append([]corev1.Container{mainContainer}, sidecars...)
 
 	// pod template: host alias
 	var hostAlias []corev1.HostAlias
@@ -290,40 +476,47 @@ func MakeBeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	}
 
 	// pod templateL annotations
-	podAnnotations := util.MergeMaps(cr.Annotations, cr.Spec.BE.Annotations)
-	metricsAnnotations := MakePrometheusAnnotations("/metrics", GetBeWebserverPort(cr))
-	podAnnotations = util.MergeMaps(metricsAnnotations, podAnnotations)
+	metricsAnnotations := makePodPrometheusAnnotations(cr,
+		func(p *dapi.MetricsPortsSpec) *int32 { return p.BE }, GetBeWebserverPort(cr))
+	podAnnotations := mergePodMeta(metricsAnnotations, cr.Annotations, cr.Spec.BE.Annotations, cr.Spec.BE.PodAnnotations)
 
 	// pod template
 	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      beLabels,
+			Labels:      mergePodMeta(cr.Spec.BE.PodLabels, beLabels),
 			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:            volumes,
-			Containers:         containers,
-			InitContainers:     []corev1.Container{initContainer},
-			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			ServiceAccountName: util.StringFallback(cr.Spec.BE.ServiceAccount, cr.Spec.ServiceAccount),
-			NodeSelector:       util.MapFallback(cr.Spec.BE.NodeSelector, cr.Spec.NodeSelector),
-			Affinity:           util.PointerFallback(cr.Spec.BE.Affinity, cr.Spec.Affinity),
-			Tolerations:        util.ArrayFallback(cr.Spec.BE.Tolerations, cr.Spec.Tolerations),
-			PriorityClassName:  util.StringFallback(cr.Spec.BE.PriorityClassName, cr.Spec.PriorityClassName),
-			HostAliases:        hostAlias,
+			Volumes:                   volumes,
+			Containers:                containers,
+			InitContainers:            initContainers,
+			ImagePullSecrets:          cr.Spec.ImagePullSecrets,
+			ServiceAccountName:        util.Coalesce(cr.Spec.BE.ServiceAccount, cr.Spec.ServiceAccount),
+			Affinity:                  util.Coalesce(cr.Spec.BE.Affinity, cr.Spec.Affinity),
+			NodeSelector:              util.MergeMaps(cr.Spec.NodeSelector, cr.Spec.BE.NodeSelector),
+			Tolerations:               util.Fallback(cr.Spec.BE.Tolerations, cr.Spec.Tolerations),
+			TopologySpreadConstraints: getTopologySpreadConstraints(cr, &cr.Spec.BE.DorisComponentSpec, beLabels),
+			PriorityClassName:         GetBePriorityClassName(cr),
+			PreemptionPolicy:          util.Coalesce(cr.Spec.BE.PreemptionPolicy, cr.Spec.PreemptionPolicy),
+			SecurityContext:           getPodSecurityContext(cr, &cr.Spec.BE.DorisComponentSpec),
+			DNSConfig:                 getPodDNSConfig(cr, &cr.Spec.BE.DorisComponentSpec),
+			DNSPolicy:                 getPodDNSPolicy(cr, &cr.Spec.BE.DorisComponentSpec),
+			RuntimeClassName:          getPodRuntimeClassName(cr, &cr.Spec.BE.DorisComponentSpec),
+			HostAliases:               hostAlias,
+			TerminationGracePeriodSeconds: util.Coalesce(cr.Spec.BE.TerminationGracePeriodSeconds,
+				util.Pointer(DefaultBeTerminationGracePeriodSeconds)),
 		},
 	}
-
-	// update strategy
-	updateStg := appv1.StatefulSetUpdateStrategy{
-		Type: util.PointerFallbackAndDeRefer(
-			cr.Spec.BE.StatefulSetUpdateStrategy,
-			cr.Spec.StatefulSetUpdateStrategy,
-			appv1.RollingUpdateStatefulSetStrategyType),
+	if cr.Spec.BE.HostNetwork {
+		podTemplate.Spec.HostNetwork = true
+		if podTemplate.Spec.DNSPolicy == "" {
+			podTemplate.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+		}
 	}
 
-	// volume claim templates
-	pvcTemplates := genBePvcTemplates(cr.Spec.BE)
+	// update strategy
+	updateStg := makeStatefulSetUpdateStrategy(
+		cr.Spec.BE.StatefulSetUpdateStrategy, cr.Spec.StatefulSetUpdateStrategy, cr.Spec.BE.Partition)
 
 	// statefulset
 	statefulSet := &appv1.StatefulSet{
@@ -334,17 +527,17 @@ func MakeBeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 		},
 		Spec: appv1.StatefulSetSpec{
 			Replicas:             &cr.Spec.BE.Replicas,
-			ServiceName:          GetBePeerServiceKey(cr.ObjKey()).Name,
+			ServiceName:          GetBePeerServiceKey(cr.ResourceKey()).Name,
 			Selector:             &metav1.LabelSelector{MatchLabels: beLabels},
 			VolumeClaimTemplates: pvcTemplates,
 			Template:             podTemplate,
 			UpdateStrategy:       updateStg,
 			PodManagementPolicy:  appv1.ParallelPodManagement,
+			MinReadySeconds:      util.PointerDeRefer(cr.Spec.BE.MinReadySeconds, DefaultBeMinReadySeconds),
 		},
 	}
 
-	_ = controllerutil.SetOwnerReference(cr, statefulSet, scheme)
-	_ = controllerutil.SetControllerReference(cr, statefulSet, scheme)
+	setClusterOwner(cr, statefulSet, scheme, true)
 	return statefulSet
 }
 
@@ -357,54 +550,62 @@ func extractBeStorageRootPath(beSpec *dapi.BESpec) string {
 		}
 		parts = append(parts, fmt.Sprintf("%s/%s,medium:%s", BeCustomStorageRootPath, storage.Name, storage.Medium))
 	}
-	if beSpec.RetainDefaultStorage {
-		parts = append(parts, fmt.Sprintf("%s/storage,medium:HDD", BeRootPath))
+	if len(beSpec.Storage) == 0 || beSpec.RetainDefaultStorage {
+		parts = append(parts, fmt.Sprintf("%s,medium:HDD", getBeDefaultStorageMountPath(beSpec)))
 	}
 	return strings.Join(parts, ";")
 }
 
-// Generate the PVC templates for the BE statefulset
-func genBePvcTemplates(beSpec *dapi.BESpec) []corev1.PersistentVolumeClaim {
-	var pvcTemplates []corev1.PersistentVolumeClaim
+// Get all storage volumes of BE, the legacy data storage defined by "requests.storage"
+// and "storage" fields are mapped onto the storage volume entries.
+func getBeStorageVolumes(beSpec *dapi.BESpec) []dapi.StorageVolume {
+	return append(getBeDataStorageVolumes(beSpec), beSpec.StorageVolumes...)
+}
 
-	defaultPvc := func() corev1.PersistentVolumeClaim {
-		return util.NewReadWriteOncePVC("be-storage", beSpec.StorageClassName, beSpec.Requests.Storage())
+// Get the storage volumes of BE mapped from the legacy data storage.
+func getBeDataStorageVolumes(beSpec *dapi.BESpec) []dapi.StorageVolume {
+	var volumes []dapi.StorageVolume
+	defaultVolume := func() dapi.StorageVolume {
+		return dapi.StorageVolume{
+			Name:      "be-storage",
+			MountPath: getBeDefaultStorageMountPath(beSpec),
+			Request:   beSpec.Requests.Storage(),
+		}
 	}
 	if len(beSpec.Storage) == 0 {
 		// default storage
-		pvcTemplates = []corev1.PersistentVolumeClaim{defaultPvc()}
+		volumes = append(volumes, defaultVolume())
 	} else {
 		// custom storage
 		for _, storage := range beSpec.Storage {
-			pvc := util.NewReadWriteOncePVC(storage.Name, beSpec.StorageClassName, storage.Request)
-			pvcTemplates = append(pvcTemplates, pvc)
+			volumes = append(volumes, dapi.StorageVolume{
+				Name:             storage.Name,
+				MountPath:        fmt.Sprintf("%s/%s", BeCustomStorageRootPath, storage.Name),
+				Request:          storage.Request,
+				StorageClassName: storage.StorageClassName,
+			})
 		}
 		if beSpec.RetainDefaultStorage {
-			pvcTemplates = append(pvcTemplates, defaultPvc())
+			volumes = append(volumes, defaultVolume())
 		}
 	}
-	return pvcTemplates
+	return volumes
 }
 
-// Generate the volume mounts for the BE data storage PVC.
-func genBeDataPVCVolumeMounts(beSpec *dapi.BESpec) []corev1.VolumeMount {
-	var volumeMounts []corev1.VolumeMount
-
-	defaultVolumeMount := func() corev1.VolumeMount {
-		return corev1.VolumeMount{Name: "be-storage", MountPath: fmt.Sprintf("%s/storage", BeRootPath)}
+// MakeBePodDisruptionBudget makes the PodDisruptionBudget of BE that allows at most one
+// BE pod to be unavailable by default, returns nil when there is only one BE replica and
+// no PodDisruptionBudget is defined by user.
+func MakeBePodDisruptionBudget(cr *dapi.DorisCluster, scheme *runtime.Scheme) *policyv1.PodDisruptionBudget {
+	if cr.Spec.BE == nil {
+		return nil
 	}
-	if len(beSpec.Storage) == 0 {
-		// default storage
-		volumeMounts = append(volumeMounts, defaultVolumeMount())
-	} else {
-		// custom storage
-		for _, storage := range beSpec.Storage {
-			vm := corev1.VolumeMount{Name: storage.Name, MountPath: fmt.Sprintf("%s/%s", BeCustomStorageRootPath, storage.Name)}
-			volumeMounts = append(volumeMounts, vm)
-		}
-		if beSpec.RetainDefaultStorage {
-			volumeMounts = append(volumeMounts, defaultVolumeMount())
-		}
+	if cr.Spec.BE.PodDisruptionBudget == nil && cr.Spec.BE.Replicas < 2 {
+		return nil
 	}
-	return volumeMounts
+	maxUnavailable := intstr.FromInt(1)
+	return makePodDisruptionBudget(cr, scheme,
+		GetBePodDisruptionBudgetKey(cr.ResourceKey()),
+		GetBeComponentLabels(cr.ResourceKey()),
+		cr.Spec.BE.PodDisruptionBudget,
+		dapi.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable})
 }
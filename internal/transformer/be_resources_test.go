@@ -0,0 +1,329 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestGetBeAndCnPorts(t *testing.T) {
+	configs := map[string]string{
+		"heartbeat_service_port": "19050",
+		"be_port":                "19060",
+		"webserver_port":         "18040",
+		"brpc_port":              "18060",
+	}
+	cr := newTestDorisCluster()
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Configs: configs}}
+	cr.Spec.CN = &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{Configs: configs}}
+
+	assert.Equal(t, int32(19050), GetBeHeartbeatServicePort(cr))
+	assert.Equal(t, int32(19060), GetBePort(cr))
+	assert.Equal(t, int32(18040), GetBeWebserverPort(cr))
+	assert.Equal(t, int32(18060), GetBeBrpcPort(cr))
+
+	assert.Equal(t, int32(19050), GetCnHeartbeatServicePort(cr))
+	assert.Equal(t, int32(19060), GetCnPort(cr))
+	assert.Equal(t, int32(18040), GetCnWebserverPort(cr))
+	assert.Equal(t, int32(18060), GetCnBrpcPort(cr))
+}
+
+func TestMakeBeStatefulSetReadinessProbe(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	probe := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].ReadinessProbe
+	assert.NotNil(t, probe.TCPSocket)
+	assert.Equal(t, int32(DefaultBeHeartbeatServicePort), probe.TCPSocket.Port.IntVal)
+
+	cr.Spec.BE.ReadinessProbeType = dapi.ProbeHTTP
+	probe = MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].ReadinessProbe
+	assert.Nil(t, probe.TCPSocket)
+	assert.Equal(t, DorisHealthApiPath, probe.HTTPGet.Path)
+	assert.Equal(t, int32(DefaultBeWebserverPort), probe.HTTPGet.Port.IntVal)
+}
+
+func TestMakeBeStatefulSetTermination(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, DefaultBeTerminationGracePeriodSeconds, *sts.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, []string{"/bin/sh", "-c", "bin/stop_be.sh"},
+		sts.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command)
+
+	cr.Spec.BE.TerminationGracePeriodSeconds = util.Pointer[int64](600)
+	cr.Spec.BE.PreStopDecommission = true
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, int64(600), *sts.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, []string{"/bin/sh", "/etc/apache-doris/be/prestop-decommission.sh"},
+		sts.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command)
+	assert.Contains(t, MakeBeConfigMap(cr, runtime.NewScheme(), nil).Data, "prestop-decommission.sh")
+	// the target replicas is exposed to the preStop hook
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: "be-pod-info", MountPath: BePodInfoMountPath, ReadOnly: true})
+	podInfo := sts.Spec.Template.Spec.Volumes[len(sts.Spec.Template.Spec.Volumes)-1]
+	assert.Equal(t, "be-pod-info", podInfo.Name)
+	assert.Equal(t, fmt.Sprintf("metadata.annotations['%s']", BeTargetReplicasPodAnnoKey),
+		podInfo.DownwardAPI.Items[0].FieldRef.FieldPath)
+}
+
+func TestMakeBeStatefulSetWaitForFe(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	initContainers := sts.Spec.Template.Spec.InitContainers
+	assert.Len(t, initContainers, 3)
+	assert.Equal(t, "wait-for-fe", initContainers[1].Name)
+	assert.Equal(t, GetBeImage(cr), initContainers[1].Image)
+
+	cr.Spec.HelperImage = util.Pointer("bash:5.2")
+	cr.Spec.ImageRegistry = "registry.example.com"
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, "registry.example.com/library/bash:5.2", sts.Spec.Template.Spec.InitContainers[1].Image)
+	assert.Equal(t, GetBeImage(cr), sts.Spec.Template.Spec.Containers[0].Image)
+
+	cr.Spec.WaitForFE = util.Pointer(false)
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Len(t, sts.Spec.Template.Spec.InitContainers, 2)
+}
+
+func TestMakeBeStatefulSetStorageVolumes(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.BE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("500Gi")}
+	cr.Spec.BE.StorageVolumes = []dapi.StorageVolume{{
+		Name:             "be-log-storage",
+		MountPath:        "/opt/apache-doris/be/log",
+		Request:          util.Pointer(resource.MustParse("50Gi")),
+		StorageClassName: util.Pointer("hdd"),
+	}}
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	pvcs := sts.Spec.VolumeClaimTemplates
+	assert.Len(t, pvcs, 2)
+	// legacy storage is mapped onto the default entry
+	assert.Equal(t, "be-storage", pvcs[0].Name)
+	assert.Equal(t, "500Gi", pvcs[0].Spec.Resources.Requests.Storage().String())
+	assert.Equal(t, "be-log-storage", pvcs[1].Name)
+	assert.Equal(t, "hdd", *pvcs[1].Spec.StorageClassName)
+
+	// the built-in log emptyDir mount is replaced by the storage volume
+	mountNames := make(map[string]string)
+	for _, vm := range sts.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mountNames[vm.MountPath] = vm.Name
+	}
+	assert.Equal(t, "be-log-storage", mountNames["/opt/apache-doris/be/log"])
+	assert.Equal(t, "be-storage", mountNames["/opt/apache-doris/be/storage"])
+}
+
+func TestMakeBeStatefulSetEphemeralStorageVolumes(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.BE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("500Gi")}
+	cr.Spec.BE.StorageVolumes = []dapi.StorageVolume{
+		{Name: "be-spill", MountPath: "/opt/apache-doris/be/spill", Type: dapi.StorageVolumeEphemeral,
+			Request: util.Pointer(resource.MustParse("100Gi"))},
+		{Name: "be-cache", MountPath: "/opt/apache-doris/be/cache", Type: dapi.StorageVolumeEphemeral},
+	}
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	// only the persistent storage is backed by the PVC
+	pvcs := sts.Spec.VolumeClaimTemplates
+	assert.Len(t, pvcs, 1)
+	assert.Equal(t, "be-storage", pvcs[0].Name)
+	// the ephemeral storage volumes are backed by emptyDir
+	podSpec := sts.Spec.Template.Spec
+	assert.Contains(t, podSpec.Volumes, corev1.Volume{Name: "be-spill", VolumeSource: corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: util.Pointer(resource.MustParse("100Gi"))},
+	}})
+	assert.Contains(t, podSpec.Volumes, corev1.Volume{Name: "be-cache", VolumeSource: corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "be-spill", MountPath: "/opt/apache-doris/be/spill"})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "be-cache", MountPath: "/opt/apache-doris/be/cache"})
+}
+
+func TestMakeBeStatefulSetEphemeralStorage(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.BE.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("4"),
+		corev1.ResourceMemory:           resource.MustParse("16Gi"),
+		corev1.ResourceStorage:          resource.MustParse("500Gi"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("20Gi"),
+	}
+	cr.Spec.BE.Limits = corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("50Gi")}
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	// the ephemeral-storage flows into the container resources without the storage
+	res := sts.Spec.Template.Spec.Containers[0].Resources
+	assert.Equal(t, corev1.ResourceList{
+		corev1.ResourceCPU:              resource.MustParse("4"),
+		corev1.ResourceMemory:           resource.MustParse("16Gi"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("20Gi"),
+	}, res.Requests)
+	assert.Equal(t, corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("50Gi")}, res.Limits)
+	// only the storage is requested by the PVC
+	pvcs := sts.Spec.VolumeClaimTemplates
+	assert.Len(t, pvcs, 1)
+	assert.Equal(t, corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("500Gi")}, pvcs[0].Spec.Resources.Requests)
+	// the spec is not mutated
+	assert.Contains(t, cr.Spec.BE.Requests, corev1.ResourceStorage)
+}
+
+func TestMakeBeStatefulSetPodFQDN(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, "test-be-peer", GetBePeerServiceKey(cr.ObjKey()).Name)
+	assert.Equal(t, GetBePeerServiceKey(cr.ObjKey()).Name, sts.Spec.ServiceName)
+	env := sts.Spec.Template.Spec.Containers[0].Env
+	assert.Contains(t, env, corev1.EnvVar{Name: "POD_NAME", ValueFrom: util.NewEnvVarFieldSource("metadata.name")})
+	assert.Contains(t, env, corev1.EnvVar{Name: "POD_FQDN", Value: "$(POD_NAME).test-be-peer.default.svc.cluster.local"})
+	assert.Equal(t, "test-be-1.test-be-peer.default.svc.cluster.local", GetBePodFQDN(cr, "test-be-1"))
+
+	// custom cluster domain
+	cr.Spec.ClusterDomain = "doris.internal"
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "POD_FQDN", Value: "$(POD_NAME).test-be-peer.default.svc.doris.internal"})
+	assert.Equal(t, "test-be-1.test-be-peer.default.svc.doris.internal", GetBePodFQDN(cr, "test-be-1"))
+}
+
+func TestMakeBeStatefulSetCommandOverride(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	// image default entrypoint
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Empty(t, sts.Spec.Template.Spec.Containers[0].Command)
+	assert.Empty(t, sts.Spec.Template.Spec.Containers[0].Args)
+
+	cr.Spec.BE.Command = []string{"/opt/profiler/wrap.sh"}
+	cr.Spec.BE.Args = []string{"/opt/apache-doris/be_entrypoint.sh"}
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, []string{"/opt/profiler/wrap.sh"}, sts.Spec.Template.Spec.Containers[0].Command)
+	assert.Equal(t, []string{"/opt/apache-doris/be_entrypoint.sh"}, sts.Spec.Template.Spec.Containers[0].Args)
+	// init containers are not affected
+	for _, c := range sts.Spec.Template.Spec.InitContainers {
+		assert.NotEqual(t, cr.Spec.BE.Command, c.Command)
+	}
+}
+
+func TestMakeBeStatefulSetHostNetwork(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.False(t, sts.Spec.Template.Spec.HostNetwork)
+	for _, p := range sts.Spec.Template.Spec.Containers[0].Ports {
+		assert.Zero(t, p.HostPort)
+	}
+
+	cr.Spec.BE.HostNetwork = true
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.True(t, sts.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, corev1.DNSClusterFirstWithHostNet, sts.Spec.Template.Spec.DNSPolicy)
+	for _, p := range sts.Spec.Template.Spec.Containers[0].Ports {
+		assert.Equal(t, p.ContainerPort, p.HostPort)
+	}
+}
+
+func TestMakeBeExtraPorts(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.BE.ExtraPorts = []corev1.ContainerPort{
+		{Name: "jvm-debug", ContainerPort: 5005},
+		{Name: "statsd", ContainerPort: 8125, Protocol: corev1.ProtocolUDP},
+	}
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	containerPorts := sts.Spec.Template.Spec.Containers[0].Ports
+	assert.Len(t, containerPorts, 6)
+	assert.Equal(t, cr.Spec.BE.ExtraPorts, containerPorts[4:])
+
+	svc := MakeBeService(cr, runtime.NewScheme())
+	assert.Equal(t, []corev1.ServicePort{
+		{Name: "webserver-port", Port: GetBeWebserverPort(cr)},
+		{Name: "jvm-debug", Port: 5005},
+		{Name: "statsd", Port: 8125, Protocol: corev1.ProtocolUDP},
+	}, svc.Spec.Ports)
+
+	// extra ports are exposed as host ports as well in the host network
+	cr.Spec.BE.HostNetwork = true
+	sts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	for _, p := range sts.Spec.Template.Spec.Containers[0].Ports {
+		assert.Equal(t, p.ContainerPort, p.HostPort)
+	}
+	assert.Zero(t, cr.Spec.BE.ExtraPorts[0].HostPort)
+}
+
+func TestMakeStatefulSetStorageAnnotations(t *testing.T) {
+	annotations := map[string]string{"csi.example.com/encryption-key": "doris"}
+	cr := newTestDorisCluster()
+	cr.Spec.FE.StorageAnnotations = annotations
+	cr.Spec.FE.StorageVolumes = []dapi.StorageVolume{{Name: "fe-audit", MountPath: "/opt/audit"}}
+	withBE(cr, 3)
+	cr.Spec.BE.StorageAnnotations = annotations
+	cr.Spec.BE.Storage = []dapi.BEStorage{{Name: "ssd", Medium: "SSD", Request: util.Pointer(resource.MustParse("100Gi"))}}
+
+	feSts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Len(t, feSts.Spec.VolumeClaimTemplates, 2)
+	for _, tpl := range feSts.Spec.VolumeClaimTemplates {
+		assert.Equal(t, annotations, tpl.Annotations)
+	}
+	beSts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.NotEmpty(t, beSts.Spec.VolumeClaimTemplates)
+	for _, tpl := range beSts.Spec.VolumeClaimTemplates {
+		assert.Equal(t, annotations, tpl.Annotations)
+	}
+
+	cr.Spec.FE.StorageAnnotations = nil
+	feSts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Nil(t, feSts.Spec.VolumeClaimTemplates[0].Annotations)
+}
+
+func TestMakeStatefulSetMinReadySeconds(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	assert.Equal(t, DefaultBeMinReadySeconds, MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.MinReadySeconds)
+	assert.Zero(t, MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.MinReadySeconds)
+
+	cr.Spec.BE.MinReadySeconds = util.Pointer(int32(0))
+	cr.Spec.FE.MinReadySeconds = util.Pointer(int32(30))
+	assert.Zero(t, MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.MinReadySeconds)
+	assert.Equal(t, int32(30), MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.MinReadySeconds)
+}
+
+func TestMakeBeCordonConfigMap(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	assert.Empty(t, GetBeCordonedPodNames(cr))
+	assert.Equal(t, "", MakeBeCordonConfigMap(cr, runtime.NewScheme()).Data[BeCordonFileKey])
+
+	// the ordinals out of the replicas are ignored
+	cr.Spec.BE.CordonedOrdinals = []int32{2, 0, 5}
+	assert.Equal(t, []string{"test-be-0", "test-be-2"}, GetBeCordonedPodNames(cr))
+	configMap := MakeBeCordonConfigMap(cr, runtime.NewScheme())
+	assert.Equal(t, "test-be-cordon", configMap.Name)
+	assert.Equal(t, "test-be-0\ntest-be-2\n", configMap.Data[BeCordonFileKey])
+
+	// the cordon gate is not changed by the cordoned ordinals
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	initContainers := sts.Spec.Template.Spec.InitContainers
+	gate := initContainers[len(initContainers)-1]
+	assert.Equal(t, BeCordonGateContainerName, gate.Name)
+	assert.Contains(t, gate.Command[2], BeCordonMountPath+BeCordonFileKey)
+	cr.Spec.BE.CordonedOrdinals = nil
+	assert.Equal(t, sts.Spec.Template, MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template)
+}
@@ -28,13 +28,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"strconv"
+	"strings"
 )
 
 const (
 	DefaultBrokerIpcPort  = 8000
 	BrokerProbeTimeoutSec = 100
+
+	// BrokerKerberosMountPath is where the keys of the Kerberos secret of Broker are mounted.
+	BrokerKerberosMountPath  = "/etc/apache-doris/broker-kerberos/"
+	BrokerKerberosVolumeName = "broker-kerberos"
+	DefaultBrokerKeytabKey   = "keytab"
 )
 
 var DefaultBrokerLog4jContent = template.ReadOrPanic("broker/log4j.properties")
@@ -50,6 +55,13 @@ func GetBrokerConfigMapKey(dorisClusterKey types.NamespacedName) types.Namespace
 	}
 }
 
+func GetBrokerServiceKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-broker", dorisClusterKey.Name),
+	}
+}
+
 func GetBrokerPeerServiceKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: dorisClusterKey.Namespace,
@@ -64,16 +76,26 @@ func GetBrokerStatefulSetKey(dorisClusterKey types.NamespacedName) types.Namespa
 	}
 }
 
+// GetBrokerBaseImage returns the base image of Broker, which falls back to the operator-wide default.
+func GetBrokerBaseImage(r *dapi.DorisCluster) string {
+	return util.Coalesce(r.Spec.Broker.BaseImage, DefaultBrokerBaseImage)
+}
+
 func GetBrokerImage(r *dapi.DorisCluster) string {
-	version := util.StringFallback(r.Spec.Broker.Version, r.Spec.Version)
-	return fmt.Sprintf("%s:%s", r.Spec.Broker.BaseImage, version)
+	version := util.Coalesce(r.Spec.Broker.Version, r.Spec.Version)
+	return withImageRegistry(r, fmt.Sprintf("%s:%s", GetBrokerBaseImage(r), version))
 }
 
 func GetBrokerIpcPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.Broker == nil {
 		return DefaultBrokerIpcPort
 	}
-	return getPortValueFromRawConf(cr.Spec.Broker.Configs, "broker_ipc_port", DefaultBrokerIpcPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.Broker.DorisComponentSpec), "broker_ipc_port", DefaultBrokerIpcPort)
+}
+
+// GetBrokerPodFQDN returns the FQDN of Broker pod that is used as the host of broker in Doris cluster.
+func GetBrokerPodFQDN(cr *dapi.DorisCluster, podName string) string {
+	return makePodFQDN(cr, GetBrokerPeerServiceKey(cr.ResourceKey()).Name, podName)
 }
 
 func GetBrokerExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) []string {
@@ -85,15 +107,46 @@ func GetBrokerExpectPodNames(dorisClusterKey types.NamespacedName, replicas int3
 	return expectPods
 }
 
-func MakeBrokerConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.ConfigMap {
+// GetBrokerKeytabKey returns the key of the keytab in the Kerberos secret of Broker.
+func GetBrokerKeytabKey(spec *dapi.BrokerKerberosSpec) string {
+	return util.Coalesce(spec.KeytabKey, DefaultBrokerKeytabKey)
+}
+
+// GetBrokerKeytabPath returns the path of the keytab mounted into the Broker container.
+func GetBrokerKeytabPath(spec *dapi.BrokerKerberosSpec) string {
+	return BrokerKerberosMountPath + GetBrokerKeytabKey(spec)
+}
+
+// GetBrokerKrb5ConfPath returns the path of the krb5.conf used by Broker, which falls back
+// to the krb5.conf of the Hadoop credential secret, returns empty string when there is none.
+func GetBrokerKrb5ConfPath(cr *dapi.DorisCluster) string {
+	if cr.Spec.Broker == nil || cr.Spec.Broker.Kerberos == nil {
+		return ""
+	}
+	if cr.Spec.Broker.Kerberos.Krb5ConfKey != "" {
+		return BrokerKerberosMountPath + cr.Spec.Broker.Kerberos.Krb5ConfKey
+	}
+	if cr.Spec.HadoopConf != nil && cr.Spec.HadoopConf.CredentialSecretRef != nil &&
+		cr.Spec.HadoopConf.CredentialSecretRef.Krb5ConfKey != "" {
+		return Krb5ConfPath
+	}
+	return ""
+}
+
+// MakeBrokerConfigMap makes the Broker configmap, refConfigs are the configs from
+// the ConfigMap referenced by spec.broker.configMapRef.
+func MakeBrokerConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, refConfigs map[string]string) *corev1.ConfigMap {
 	if cr.Spec.Broker == nil {
 		return nil
 	}
-	configMapRef := GetBrokerConfigMapKey(cr.ObjKey())
-	configs := util.MapFallback(cr.Spec.Broker.Configs, make(map[string]string))
+	configMapRef := GetBrokerConfigMapKey(cr.ResourceKey())
+	configs := util.MergeMaps(getRawJvmOptConfigs(cr.Spec.Broker.ConfigFileContent), refConfigs)
+	configs = util.Fallback(util.MergeMaps(configs, cr.Spec.Broker.Configs), make(map[string]string))
+	configs = withBrokerKrb5ConfJvmOpt(cr, configs)
 	data := map[string]string{
-		"apache_hdfs_broker.conf": dumpJavaBasedComponentConf(configs),
-		"log4j.properties":        DefaultBrokerLog4jContent,
+		BrokerConfFileKey: withRawComponentConf(cr.Spec.Broker.ConfigFileContent,
+			dumpJavaBasedComponentConf(configs, makeJvmHeapOpt(cr.Spec.Broker.JvmHeap, GetComponentResources(cr, &cr.Spec.Broker.DorisComponentSpec)))),
+		"log4j.properties": DefaultBrokerLog4jContent,
 	}
 	// merge hadoop config data
 	if cr.Spec.HadoopConf != nil {
@@ -101,27 +154,90 @@ func MakeBrokerConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.
 	}
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapRef.Name,
-			Namespace: configMapRef.Namespace,
-			Labels:    GetBrokerComponentLabels(cr.ObjKey()),
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.Broker.ConfigMapLabels, GetBrokerComponentLabels(cr.ResourceKey())),
+			Annotations: cr.Spec.Broker.ConfigMapAnnotations,
 		},
 		Data: data,
 	}
-	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
+	setClusterOwner(cr, configMap, scheme, false)
 	return configMap
 }
 
+// Append the -Djava.security.krb5.conf option to the JVM options of Broker when Kerberos is enabled,
+// the krb5.conf specified by user in the JVM options is kept.
+func withBrokerKrb5ConfJvmOpt(cr *dapi.DorisCluster, configs map[string]string) map[string]string {
+	krb5ConfPath := GetBrokerKrb5ConfPath(cr)
+	if krb5ConfPath == "" || strings.Contains(configs[JvmOptKey], "-Djava.security.krb5.conf=") {
+		return configs
+	}
+	krb5ConfOpt := "-Djava.security.krb5.conf=" + krb5ConfPath
+	result := util.MergeMaps(configs, map[string]string{
+		JvmOptKey: strings.TrimSpace(configs[JvmOptKey] + " " + krb5ConfOpt),
+	})
+	if jvm9Opt, ok := configs[JvmOpt9Key]; ok {
+		result[JvmOpt9Key] = strings.TrimSpace(jvm9Opt + " " + krb5ConfOpt)
+	}
+	return result
+}
+
+// Mount all the keys of the Kerberos secret into the Broker container under BrokerKerberosMountPath,
+// which are referred by the literal paths in the properties of broker load. Returns the volumes of
+// pod with the Kerberos volume appended.
+func injectBrokerKerberos(cr *dapi.DorisCluster, container *corev1.Container, volumes []corev1.Volume) []corev1.Volume {
+	spec := cr.Spec.Broker.Kerberos
+	if spec == nil {
+		return volumes
+	}
+	volumes = append(volumes, corev1.Volume{Name: BrokerKerberosVolumeName, VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: spec.SecretName},
+	}})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name: BrokerKerberosVolumeName, MountPath: BrokerKerberosMountPath, ReadOnly: true,
+	})
+	return volumes
+}
+
+// MakeBrokerService makes the ClusterIP service exposing the Broker ipc port,
+// returns nil when spec.broker.service is not specified.
+func MakeBrokerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Service {
+	if cr.Spec.Broker == nil || cr.Spec.Broker.Service == nil {
+		return nil
+	}
+	serviceRef := GetBrokerServiceKey(cr.ResourceKey())
+	brokerLabels := GetBrokerComponentLabels(cr.ResourceKey())
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.Broker.ServiceLabels, brokerLabels),
+			Annotations: util.MergeMaps(cr.Spec.Broker.ServiceAnnotations, cr.Spec.Broker.Service.Annotations),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: append([]corev1.ServicePort{
+				{Name: "ipc-port", Port: GetBrokerIpcPort(cr)},
+			}, makeExtraServicePorts(&cr.Spec.Broker.DorisComponentSpec)...),
+			Selector: brokerLabels,
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+	setClusterOwner(cr, service, scheme, false)
+	return service
+}
+
 func MakeBrokerPeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Service {
 	if cr.Spec.Broker == nil {
 		return nil
 	}
-	serviceRef := GetBrokerPeerServiceKey(cr.ObjKey())
-	brokerLabels := GetBrokerComponentLabels(cr.ObjKey())
+	serviceRef := GetBrokerPeerServiceKey(cr.ResourceKey())
+	brokerLabels := GetBrokerComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    brokerLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      mergePodMeta(cr.Spec.Broker.ServiceLabels, brokerLabels, PeerServiceLabels),
+			Annotations: cr.Spec.Broker.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
@@ -131,21 +247,29 @@ func MakeBrokerPeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev
 			ClusterIP: "None",
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
+// makeBrokerContainerPorts returns the container ports of Broker managed by operator.
+func makeBrokerContainerPorts(cr *dapi.DorisCluster) []corev1.ContainerPort {
+	return []corev1.ContainerPort{
+		{Name: "ipc-port", ContainerPort: GetBrokerIpcPort(cr)},
+	}
+}
+
 func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.StatefulSet {
 	if cr.Spec.Broker == nil {
 		return nil
 	}
-	statefulSetRef := GetBrokerStatefulSetKey(cr.ObjKey())
-	accountSecretRef := GetOprSqlAccountSecretKey(cr.ObjKey())
-	brokerLabels := GetBrokerComponentLabels(cr.ObjKey())
+	statefulSetRef := GetBrokerStatefulSetKey(cr.ResourceKey())
+	accountSecretRef := GetOprSqlAccountSecretRef(cr)
+	brokerLabels := GetBrokerComponentLabels(cr.ResourceKey())
 
 	// pod template: volumes
 	volumes := []corev1.Volume{
-		{Name: "conf", VolumeSource: util.NewConfigMapVolumeSource(GetBrokerConfigMapKey(cr.ObjKey()).Name)},
+		{Name: "conf", VolumeSource: util.NewConfigMapVolumeSource(GetBrokerConfigMapKey(cr.ResourceKey()).Name)},
+		{Name: "broker-log", VolumeSource: util.NewEmptyDirVolumeSource()},
 	}
 	// merge addition volumes defined by user
 	volumes = append(volumes, cr.Spec.Broker.AdditionalVolumes...)
@@ -153,14 +277,15 @@ func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1
 	// pod template: main container
 	mainContainer := corev1.Container{
 		Name:            "broker",
+		SecurityContext: getContainerSecurityContext(cr, &cr.Spec.Broker.DorisComponentSpec),
 		Image:           GetBrokerImage(cr),
-		ImagePullPolicy: cr.Spec.ImagePullPolicy,
-		Resources:       formatContainerResourcesRequirement(cr.Spec.Broker.ResourceRequirements),
-		Ports: []corev1.ContainerPort{
-			{Name: "ipc-port", ContainerPort: GetBrokerIpcPort(cr)},
-		},
+		ImagePullPolicy: GetImagePullPolicy(cr, GetBrokerImage(cr)),
+		Command:         cr.Spec.Broker.Command,
+		Args:            cr.Spec.Broker.Args,
+		Resources:       formatContainerResourcesRequirement(GetComponentResources(cr, &cr.Spec.Broker.DorisComponentSpec)),
+		Ports:           withExtraContainerPorts(makeBrokerContainerPorts(cr), &cr.Spec.Broker.DorisComponentSpec),
 		Env: []corev1.EnvVar{
-			{Name: "FE_SVC", Value: GetFeServiceKey(cr.ObjKey()).Name},
+			{Name: "FE_SVC", Value: GetFeServiceDNS(cr)},
 			{Name: "FE_QUERY_PORT", Value: strconv.Itoa(int(GetFeQueryPort(cr)))},
 			{Name: "ACC_USER", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "user")},
 			{Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "password")},
@@ -168,6 +293,7 @@ func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			{Name: "conf", MountPath: "/opt/apache-doris/broker/conf"},
+			{Name: "broker-log", MountPath: "/opt/apache-doris/broker/log"},
 		},
 		Lifecycle: &corev1.Lifecycle{
 			PreStop: util.NewExecLifecycleHandler("/bin/sh", "-c", "bin/stop_broker.sh"),
@@ -179,19 +305,35 @@ func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1
 			SuccessThreshold: 1,
 			FailureThreshold: 3,
 		},
-		LivenessProbe: &corev1.Probe{
+		LivenessProbe: util.Coalesce(cr.Spec.Broker.LivenessProbe, &corev1.Probe{
 			ProbeHandler:        util.NewTcpSocketProbeHandler(GetBrokerIpcPort(cr)),
 			InitialDelaySeconds: 20,
 			TimeoutSeconds:      1,
 			PeriodSeconds:       5,
 			SuccessThreshold:    1,
 			FailureThreshold:    5,
-		},
+		}),
+		StartupProbe: util.Coalesce(cr.Spec.Broker.StartupProbe, makeDefaultStartupProbe(GetBrokerIpcPort(cr))),
 	}
+	// pod template: storage volumes
+	storagePvcTemplates, storagePodVolumes, storageMounts := genStorageVolumes(cr.Spec.Broker.StorageVolumes, nil, cr.Spec.Broker.StorageAnnotations)
+	volumes = append(volumes, storagePodVolumes...)
+	mainContainer.VolumeMounts = mergeStorageVolumeMounts(mainContainer.VolumeMounts, storageMounts)
+	// pod template: FQDN of the pod resolved via the peer service
+	mainContainer.Env = append(mainContainer.Env, makePodFQDNEnvs(cr, GetBrokerPeerServiceKey(cr.ResourceKey()).Name)...)
+	// pod template: credentials for accessing HDFS/S3
+	volumes = injectHadoopCredentials(cr, &mainContainer, volumes)
+	// pod template: Kerberos keytab for accessing the Kerberized HDFS
+	volumes = injectBrokerKerberos(cr, &mainContainer, volumes)
 	// pod template: merge additional pod containers configs defined by user
 	mainContainer.Env = append(mainContainer.Env, cr.Spec.Broker.AdditionalEnvs...)
+	mainContainer.EnvFrom = append(mainContainer.EnvFrom, cr.Spec.Broker.AdditionalEnvFrom...)
 	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, cr.Spec.Broker.AdditionalVolumeMounts...)
-	containers := append([]corev1.Container{mainContainer}, cr.Spec.Broker.AdditionalContainers...)
+	sidecars := cr.Spec.Broker.AdditionalContainers
+	if cr.Spec.Broker.ShareLogVolume {
+		sidecars = shareLogVolumeMount(sidecars, corev1.VolumeMount{Name: "broker-log", MountPath: "/opt/apache-doris/broker/log"})
+	}
+	containers := append([]corev1.Container{mainContainer}, sidecars...)
 
 	// pod template: host alias
 	var hostAlias []corev1.HostAlias
@@ -201,32 +343,40 @@ func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1
 		hostAlias = cr.Spec.Broker.HostAliases
 	}
 
+	// pod template: annotations, the broker exposes no metrics port by default
+	metricsAnnotations := makePodPrometheusAnnotations(cr,
+		func(p *dapi.MetricsPortsSpec) *int32 { return p.Broker }, 0)
+	podAnnotations := mergePodMeta(metricsAnnotations, cr.Annotations, cr.Spec.Broker.Annotations, cr.Spec.Broker.PodAnnotations)
+
 	// pod template
 	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      brokerLabels,
-			Annotations: util.MergeMaps(cr.Annotations, cr.Spec.Broker.Annotations),
+			Labels:      mergePodMeta(cr.Spec.Broker.PodLabels, brokerLabels),
+			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:            volumes,
-			Containers:         containers,
-			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			ServiceAccountName: util.StringFallback(cr.Spec.Broker.ServiceAccount, cr.Spec.ServiceAccount),
-			NodeSelector:       util.MapFallback(cr.Spec.Broker.NodeSelector, cr.Spec.NodeSelector),
-			Affinity:           util.PointerFallback(cr.Spec.Broker.Affinity, cr.Spec.Affinity),
-			Tolerations:        util.ArrayFallback(cr.Spec.Broker.Tolerations, cr.Spec.Tolerations),
-			PriorityClassName:  util.StringFallback(cr.Spec.Broker.PriorityClassName, cr.Spec.PriorityClassName),
-			HostAliases:        hostAlias,
+			Volumes:                       volumes,
+			Containers:                    containers,
+			ImagePullSecrets:              cr.Spec.ImagePullSecrets,
+			ServiceAccountName:            util.Coalesce(cr.Spec.Broker.ServiceAccount, cr.Spec.ServiceAccount),
+			Affinity:                      util.Coalesce(cr.Spec.Broker.Affinity, cr.Spec.Affinity),
+			NodeSelector:                  util.MergeMaps(cr.Spec.NodeSelector, cr.Spec.Broker.NodeSelector),
+			Tolerations:                   util.Fallback(cr.Spec.Broker.Tolerations, cr.Spec.Tolerations),
+			TopologySpreadConstraints:     getTopologySpreadConstraints(cr, &cr.Spec.Broker.DorisComponentSpec, brokerLabels),
+			PriorityClassName:             util.Coalesce(cr.Spec.Broker.PriorityClassName, cr.Spec.PriorityClassName),
+			PreemptionPolicy:              util.Coalesce(cr.Spec.Broker.PreemptionPolicy, cr.Spec.PreemptionPolicy),
+			SecurityContext:               getPodSecurityContext(cr, &cr.Spec.Broker.DorisComponentSpec),
+			DNSConfig:                     getPodDNSConfig(cr, &cr.Spec.Broker.DorisComponentSpec),
+			DNSPolicy:                     getPodDNSPolicy(cr, &cr.Spec.Broker.DorisComponentSpec),
+			RuntimeClassName:              getPodRuntimeClassName(cr, &cr.Spec.Broker.DorisComponentSpec),
+			HostAliases:                   hostAlias,
+			TerminationGracePeriodSeconds: cr.Spec.Broker.TerminationGracePeriodSeconds,
 		},
 	}
 
 	// update strategy
-	updateStg := appv1.StatefulSetUpdateStrategy{
-		Type: util.PointerFallbackAndDeRefer(
-			cr.Spec.Broker.StatefulSetUpdateStrategy,
-			cr.Spec.StatefulSetUpdateStrategy,
-			appv1.RollingUpdateStatefulSetStrategyType),
-	}
+	updateStg := makeStatefulSetUpdateStrategy(
+		cr.Spec.Broker.StatefulSetUpdateStrategy, cr.Spec.StatefulSetUpdateStrategy, cr.Spec.Broker.Partition)
 
 	// statefulset
 	statefulSet := &appv1.StatefulSet{
@@ -236,16 +386,17 @@ func MakeBrokerStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1
 			Labels:    brokerLabels,
 		},
 		Spec: appv1.StatefulSetSpec{
-			Replicas:            &cr.Spec.Broker.Replicas,
-			ServiceName:         GetBrokerPeerServiceKey(cr.ObjKey()).Name,
-			Selector:            &metav1.LabelSelector{MatchLabels: brokerLabels},
-			Template:            podTemplate,
-			UpdateStrategy:      updateStg,
-			PodManagementPolicy: appv1.ParallelPodManagement,
+			Replicas:             &cr.Spec.Broker.Replicas,
+			ServiceName:          GetBrokerPeerServiceKey(cr.ResourceKey()).Name,
+			Selector:             &metav1.LabelSelector{MatchLabels: brokerLabels},
+			VolumeClaimTemplates: storagePvcTemplates,
+			Template:             podTemplate,
+			UpdateStrategy:       updateStg,
+			MinReadySeconds:      util.PointerDeRefer(cr.Spec.Broker.MinReadySeconds, 0),
+			PodManagementPolicy:  appv1.ParallelPodManagement,
 		},
 	}
 
-	_ = controllerutil.SetOwnerReference(cr, statefulSet, scheme)
-	_ = controllerutil.SetControllerReference(cr, statefulSet, scheme)
+	setClusterOwner(cr, statefulSet, scheme, true)
 	return statefulSet
 }
@@ -0,0 +1,97 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestMakeBrokerService(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-broker", Replicas: 1}}
+	// not created without spec.broker.service
+	assert.Nil(t, MakeBrokerService(cr, runtime.NewScheme()))
+
+	cr.Spec.Broker.Service = &dapi.BrokerServiceSpec{Annotations: map[string]string{"foo": "bar"}}
+	cr.Spec.Broker.Configs = map[string]string{"broker_ipc_port": "18000"}
+	svc := MakeBrokerService(cr, runtime.NewScheme())
+	assert.Equal(t, GetBrokerServiceKey(cr.ObjKey()).Name, svc.Name)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, svc.Spec.Type)
+	assert.Equal(t, map[string]string{"foo": "bar"}, svc.Annotations)
+	assert.Equal(t, GetBrokerComponentLabels(cr.ObjKey()), svc.Spec.Selector)
+	assert.Equal(t, []corev1.ServicePort{{Name: "ipc-port", Port: 18000}}, svc.Spec.Ports)
+
+	// the annotations of spec.broker.service take precedence over the component ones
+	cr.Spec.Broker.ServiceAnnotations = map[string]string{"foo": "baz", "cost-center": "doris"}
+	cr.Spec.Broker.ServiceLabels = map[string]string{"network-policy": "doris"}
+	svc = MakeBrokerService(cr, runtime.NewScheme())
+	assert.Equal(t, map[string]string{"foo": "bar", "cost-center": "doris"}, svc.Annotations)
+	assert.Equal(t, "doris", svc.Labels["network-policy"])
+	assert.Equal(t, "broker", svc.Labels[K8sComponentLabelKey])
+}
+
+func TestBrokerKerberos(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-broker", Replicas: 1}}
+	// nothing is injected without kerberos
+	configMap := MakeBrokerConfigMap(cr, runtime.NewScheme(), nil)
+	assert.NotContains(t, configMap.Data[BrokerConfFileKey], "java.security.krb5.conf")
+	sts := MakeBrokerStatefulSet(cr, runtime.NewScheme())
+	for _, vol := range sts.Spec.Template.Spec.Volumes {
+		assert.NotEqual(t, BrokerKerberosVolumeName, vol.Name)
+	}
+
+	cr.Spec.Broker.Kerberos = &dapi.BrokerKerberosSpec{SecretName: "broker-krb"}
+	cr.Spec.Broker.Configs = map[string]string{JvmOptKey: "-Dfoo=bar"}
+	// no krb5.conf to be passed to the JVM
+	configMap = MakeBrokerConfigMap(cr, runtime.NewScheme(), nil)
+	assert.NotContains(t, configMap.Data[BrokerConfFileKey], "java.security.krb5.conf")
+	sts = MakeBrokerStatefulSet(cr, runtime.NewScheme())
+	podSpec := sts.Spec.Template.Spec
+	assert.Contains(t, podSpec.Volumes, corev1.Volume{Name: BrokerKerberosVolumeName, VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: "broker-krb"},
+	}})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name: BrokerKerberosVolumeName, MountPath: BrokerKerberosMountPath, ReadOnly: true,
+	})
+	assert.Equal(t, "/etc/apache-doris/broker-kerberos/keytab", GetBrokerKeytabPath(cr.Spec.Broker.Kerberos))
+
+	// fall back to the krb5.conf of the Hadoop credential secret
+	cr.Spec.HadoopConf = &dapi.HadoopConfSpec{CredentialSecretRef: &dapi.HadoopCredentialSecretRef{Name: "hadoop-cred", Krb5ConfKey: "krb5.conf"}}
+	configMap = MakeBrokerConfigMap(cr, runtime.NewScheme(), nil)
+	assert.Contains(t, configMap.Data[BrokerConfFileKey], `JAVA_OPTS="-Dfoo=bar -Djava.security.krb5.conf=/etc/krb5.conf `)
+
+	// the krb5.conf of the kerberos secret takes precedence
+	cr.Spec.Broker.Kerberos.Krb5ConfKey = "krb5.conf"
+	cr.Spec.Broker.Kerberos.KeytabKey = "doris.keytab"
+	configMap = MakeBrokerConfigMap(cr, runtime.NewScheme(), nil)
+	assert.Contains(t, configMap.Data[BrokerConfFileKey],
+		`JAVA_OPTS="-Dfoo=bar -Djava.security.krb5.conf=/etc/apache-doris/broker-kerberos/krb5.conf `)
+	assert.Equal(t, "/etc/apache-doris/broker-kerberos/doris.keytab", GetBrokerKeytabPath(cr.Spec.Broker.Kerberos))
+
+	// the krb5.conf specified by user is kept
+	cr.Spec.Broker.Configs = map[string]string{JvmOptKey: "-Djava.security.krb5.conf=/opt/krb5.conf"}
+	configMap = MakeBrokerConfigMap(cr, runtime.NewScheme(), nil)
+	assert.Contains(t, configMap.Data[BrokerConfFileKey], `JAVA_OPTS="-Djava.security.krb5.conf=/opt/krb5.conf `)
+	assert.NotContains(t, configMap.Data[BrokerConfFileKey], "broker-kerberos/krb5.conf")
+}
@@ -27,7 +27,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"strconv"
 )
 
@@ -67,37 +66,47 @@ func GetCnStatefulSetKey(dorisClusterKey types.NamespacedName) types.NamespacedN
 	}
 }
 
+// GetCnBaseImage returns the base image of CN, which falls back to the operator-wide default.
+func GetCnBaseImage(r *dapi.DorisCluster) string {
+	return util.Coalesce(r.Spec.CN.BaseImage, DefaultCnBaseImage)
+}
+
 func GetCnImage(r *dapi.DorisCluster) string {
-	version := util.StringFallback(r.Spec.CN.Version, r.Spec.Version)
-	return fmt.Sprintf("%s:%s", r.Spec.CN.BaseImage, version)
+	version := util.Coalesce(r.Spec.CN.Version, r.Spec.Version)
+	return withImageRegistry(r, fmt.Sprintf("%s:%s", GetCnBaseImage(r), version))
 }
 
 func GetCnHeartbeatServicePort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.CN == nil {
 		return DefaultBeHeartbeatServicePort
 	}
-	return getPortValueFromRawConf(cr.Spec.CN.Configs, "be_port", DefaultBeHeartbeatServicePort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.CN.DorisComponentSpec), "heartbeat_service_port", DefaultBeHeartbeatServicePort)
 }
 
 func GetCnPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.CN == nil {
 		return DefaultBePort
 	}
-	return getPortValueFromRawConf(cr.Spec.CN.Configs, "be_port", DefaultBePort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.CN.DorisComponentSpec), "be_port", DefaultBePort)
 }
 
 func GetCnWebserverPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.CN == nil {
 		return DefaultBeWebserverPort
 	}
-	return getPortValueFromRawConf(cr.Spec.CN.Configs, "webserver_port", DefaultBeWebserverPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.CN.DorisComponentSpec), "webserver_port", DefaultBeWebserverPort)
 }
 
 func GetCnBrpcPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.CN == nil {
 		return DefaultBeBrpcPort
 	}
-	return getPortValueFromRawConf(cr.Spec.CN.Configs, "brpc_port", DefaultBeBrpcPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.CN.DorisComponentSpec), "brpc_port", DefaultBeBrpcPort)
+}
+
+// GetCnPodFQDN returns the FQDN of CN pod that is used as the host of backend in Doris cluster.
+func GetCnPodFQDN(cr *dapi.DorisCluster, podName string) string {
+	return makePodFQDN(cr, GetCnPeerServiceKey(cr.ResourceKey()).Name, podName)
 }
 
 func GetCnExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) []string {
@@ -109,15 +118,17 @@ func GetCnExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) [
 	return expectPods
 }
 
-func MakeCnConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.ConfigMap {
+// MakeCnConfigMap makes the CN configmap, refConfigs are the configs from
+// the ConfigMap referenced by spec.cn.configMapRef.
+func MakeCnConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, refConfigs map[string]string) *corev1.ConfigMap {
 	if cr.Spec.CN == nil {
 		return nil
 	}
-	configs := util.MapFallback(cr.Spec.CN.Configs, make(map[string]string))
-	configs["enable_fqdn_mode"] = "true"
-	configMapRef := GetCnConfigMapKey(cr.ObjKey())
+	configs := util.MergeMaps(refConfigs, cr.Spec.CN.Configs)
+	configs = util.MergeMaps(configs, map[string]string{"enable_fqdn_mode": "true"})
+	configMapRef := GetCnConfigMapKey(cr.ResourceKey())
 	data := map[string]string{
-		"be.conf": dumpCppBasedComponentConf(configs),
+		BeConfFileKey: withRawComponentConf(cr.Spec.CN.ConfigFileContent, dumpCppBasedComponentConf(configs)),
 	}
 	// merge hadoop config data
 	if cr.Spec.HadoopConf != nil {
@@ -125,13 +136,14 @@ func MakeCnConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Conf
 	}
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapRef.Name,
-			Namespace: configMapRef.Namespace,
-			Labels:    GetCnComponentLabels(cr.ObjKey()),
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.CN.ConfigMapLabels, GetCnComponentLabels(cr.ResourceKey())),
+			Annotations: cr.Spec.CN.ConfigMapAnnotations,
 		},
 		Data: data,
 	}
-	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
+	setClusterOwner(cr, configMap, scheme, false)
 	return configMap
 }
 
@@ -139,26 +151,27 @@ func MakeCnService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Servic
 	if cr.Spec.CN == nil {
 		return nil
 	}
-	serviceRef := GetCnServiceKey(cr.ObjKey())
-	cnLabels := GetCnComponentLabels(cr.ObjKey())
+	serviceRef := GetCnServiceKey(cr.ResourceKey())
+	cnLabels := GetCnComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    cnLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.CN.ServiceLabels, cnLabels),
+			Annotations: cr.Spec.CN.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
+			Ports: append([]corev1.ServicePort{
 				{
 					Name: "webserver-port",
 					Port: GetCnWebserverPort(cr),
 				},
-			},
+			}, makeExtraServicePorts(&cr.Spec.CN.DorisComponentSpec)...),
 			Selector: cnLabels,
 			Type:     corev1.ServiceTypeClusterIP,
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
@@ -166,13 +179,14 @@ func MakeCnPeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Se
 	if cr.Spec.CN == nil {
 		return nil
 	}
-	serviceRef := GetCnPeerServiceKey(cr.ObjKey())
-	cnLabels := GetCnComponentLabels(cr.ObjKey())
+	serviceRef := GetCnPeerServiceKey(cr.ResourceKey())
+	cnLabels := GetCnComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    cnLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      mergePodMeta(cr.Spec.CN.ServiceLabels, cnLabels, PeerServiceLabels),
+			Annotations: cr.Spec.CN.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
@@ -185,18 +199,28 @@ func MakeCnPeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Se
 			ClusterIP: "None",
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
+// makeCnContainerPorts returns the container ports of CN managed by operator.
+func makeCnContainerPorts(cr *dapi.DorisCluster) []corev1.ContainerPort {
+	return []corev1.ContainerPort{
+		{Name: "webserver-port", ContainerPort: GetCnWebserverPort(cr)},
+		{Name: "heart-port", ContainerPort: GetCnHeartbeatServicePort(cr)},
+		{Name: "be-port", ContainerPort: GetCnPort(cr)},
+		{Name: "brpc-port", ContainerPort: GetCnBrpcPort(cr)},
+	}
+}
+
 func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.StatefulSet {
 	if cr.Spec.CN == nil {
 		return nil
 	}
-	statefulSetRef := GetCnStatefulSetKey(cr.ObjKey())
-	accountSecretRef := GetOprSqlAccountSecretKey(cr.ObjKey())
-	configMapRef := GetCnConfigMapKey(cr.ObjKey())
-	cnLabels := GetCnComponentLabels(cr.ObjKey())
+	statefulSetRef := GetCnStatefulSetKey(cr.ResourceKey())
+	accountSecretRef := GetOprSqlAccountSecretRef(cr)
+	configMapRef := GetCnConfigMapKey(cr.ResourceKey())
+	cnLabels := GetCnComponentLabels(cr.ResourceKey())
 
 	// pod template: volumes
 	volumes := []corev1.Volume{
@@ -209,17 +233,15 @@ func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	// pod template: main container
 	mainContainer := corev1.Container{
 		Name:            "cn",
+		SecurityContext: getContainerSecurityContext(cr, &cr.Spec.CN.DorisComponentSpec),
 		Image:           GetCnImage(cr),
-		ImagePullPolicy: cr.Spec.ImagePullPolicy,
-		Resources:       formatContainerResourcesRequirement(cr.Spec.CN.ResourceRequirements),
-		Ports: []corev1.ContainerPort{
-			{Name: "webserver-port", ContainerPort: GetCnWebserverPort(cr)},
-			{Name: "heart-port", ContainerPort: GetCnHeartbeatServicePort(cr)},
-			{Name: "be-port", ContainerPort: GetCnPort(cr)},
-			{Name: "brpc-port", ContainerPort: GetCnBrpcPort(cr)},
-		},
+		ImagePullPolicy: GetImagePullPolicy(cr, GetCnImage(cr)),
+		Command:         cr.Spec.CN.Command,
+		Args:            cr.Spec.CN.Args,
+		Resources:       formatContainerResourcesRequirement(GetComponentResources(cr, &cr.Spec.CN.DorisComponentSpec)),
+		Ports:           withExtraContainerPorts(makeCnContainerPorts(cr), &cr.Spec.CN.DorisComponentSpec),
 		Env: []corev1.EnvVar{
-			{Name: "FE_SVC", Value: GetFeServiceKey(cr.ObjKey()).Name},
+			{Name: "FE_SVC", Value: GetFeServiceDNS(cr)},
 			{Name: "FE_QUERY_PORT", Value: strconv.Itoa(int(GetFeQueryPort(cr)))},
 			{Name: "ACC_USER", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "user")},
 			{Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "password")},
@@ -232,21 +254,16 @@ func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 		Lifecycle: &corev1.Lifecycle{
 			PreStop: util.NewExecLifecycleHandler("/bin/sh", "-c", "bin/stop_be.sh"),
 		},
-		ReadinessProbe: &corev1.Probe{
-			ProbeHandler:     util.NewTcpSocketProbeHandler(GetCnHeartbeatServicePort(cr)),
-			TimeoutSeconds:   1,
-			PeriodSeconds:    5,
-			SuccessThreshold: 1,
-			FailureThreshold: 3,
-		},
-		LivenessProbe: &corev1.Probe{
+		ReadinessProbe: makeReadinessProbe(cr.Spec.CN.ReadinessProbeType, GetCnHeartbeatServicePort(cr), GetCnWebserverPort(cr), nil),
+		LivenessProbe: util.Coalesce(cr.Spec.CN.LivenessProbe, &corev1.Probe{
 			ProbeHandler:        util.NewTcpSocketProbeHandler(GetCnHeartbeatServicePort(cr)),
 			InitialDelaySeconds: 20,
 			TimeoutSeconds:      1,
 			PeriodSeconds:       5,
 			SuccessThreshold:    1,
 			FailureThreshold:    5,
-		},
+		}),
+		StartupProbe: util.Coalesce(cr.Spec.CN.StartupProbe, makeDefaultStartupProbe(GetCnHeartbeatServicePort(cr))),
 	}
 	// pod template: init container
 	privileged := true
@@ -256,10 +273,27 @@ func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 		Command:         []string{"sysctl", "-w", "vm.max_map_count=2000000"},
 		SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
 	}
+	initContainers := []corev1.Container{initContainer}
+	if IsWaitForFe(cr) {
+		initContainers = append(initContainers, makeWaitForFeInitContainer(cr, GetCnImage(cr)))
+	}
+	// pod template: storage volumes
+	storagePvcTemplates, storagePodVolumes, storageMounts := genStorageVolumes(cr.Spec.CN.StorageVolumes, nil, cr.Spec.CN.StorageAnnotations)
+	volumes = append(volumes, storagePodVolumes...)
+	mainContainer.VolumeMounts = mergeStorageVolumeMounts(mainContainer.VolumeMounts, storageMounts)
+	// pod template: FQDN of the pod resolved via the peer service
+	mainContainer.Env = append(mainContainer.Env, makePodFQDNEnvs(cr, GetCnPeerServiceKey(cr.ResourceKey()).Name)...)
+	// pod template: credentials for accessing HDFS/S3
+	volumes = injectHadoopCredentials(cr, &mainContainer, volumes)
 	// pod template: merge additional pod containers configs defined by user
 	mainContainer.Env = append(mainContainer.Env, cr.Spec.CN.AdditionalEnvs...)
+	mainContainer.EnvFrom = append(mainContainer.EnvFrom, cr.Spec.CN.AdditionalEnvFrom...)
 	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, cr.Spec.CN.AdditionalVolumeMounts...)
-	containers := append([]corev1.Container{mainContainer}, cr.Spec.CN.AdditionalContainers...)
+	sidecars := cr.Spec.CN.AdditionalContainers
+	if cr.Spec.CN.ShareLogVolume {
+		sidecars = shareLogVolumeMount(sidecars, corev1.VolumeMount{Name: "cn-log", MountPath: "/opt/apache-doris/be/log"})
+	}
+	containers := append([]corev1.Container{mainContainer}, sidecars...)
 
 	// pod template: host alias
 	var hostAlias []corev1.HostAlias
@@ -270,37 +304,40 @@ func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	}
 
 	// pod templateL annotations
-	podAnnotations := util.MergeMaps(cr.Annotations, cr.Spec.CN.Annotations)
-	metricsAnnotations := MakePrometheusAnnotations("/metrics", GetCnWebserverPort(cr))
-	podAnnotations = util.MergeMaps(metricsAnnotations, podAnnotations)
+	metricsAnnotations := makePodPrometheusAnnotations(cr,
+		func(p *dapi.MetricsPortsSpec) *int32 { return p.CN }, GetCnWebserverPort(cr))
+	podAnnotations := mergePodMeta(metricsAnnotations, cr.Annotations, cr.Spec.CN.Annotations, cr.Spec.CN.PodAnnotations)
 
 	// pod template
 	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      cnLabels,
+			Labels:      mergePodMeta(cr.Spec.CN.PodLabels, cnLabels),
 			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:            volumes,
-			Containers:         containers,
-			InitContainers:     []corev1.Container{initContainer},
-			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			ServiceAccountName: util.StringFallback(cr.Spec.CN.ServiceAccount, cr.Spec.ServiceAccount),
-			NodeSelector:       util.MapFallback(cr.Spec.CN.NodeSelector, cr.Spec.NodeSelector),
-			Affinity:           util.PointerFallback(cr.Spec.CN.Affinity, cr.Spec.Affinity),
-			Tolerations:        util.ArrayFallback(cr.Spec.CN.Tolerations, cr.Spec.Tolerations),
-			PriorityClassName:  util.StringFallback(cr.Spec.CN.PriorityClassName, cr.Spec.PriorityClassName),
-			HostAliases:        hostAlias,
+			Volumes:                       volumes,
+			Containers:                    containers,
+			InitContainers:                initContainers,
+			ImagePullSecrets:              cr.Spec.ImagePullSecrets,
+			ServiceAccountName:            util.Coalesce(cr.Spec.CN.ServiceAccount, cr.Spec.ServiceAccount),
+			Affinity:                      util.Coalesce(cr.Spec.CN.Affinity, cr.Spec.Affinity),
+			NodeSelector:                  util.MergeMaps(cr.Spec.NodeSelector, cr.Spec.CN.NodeSelector),
+			Tolerations:                   util.Fallback(cr.Spec.CN.Tolerations, cr.Spec.Tolerations),
+			TopologySpreadConstraints:     getTopologySpreadConstraints(cr, &cr.Spec.CN.DorisComponentSpec, cnLabels),
+			PriorityClassName:             util.Coalesce(cr.Spec.CN.PriorityClassName, cr.Spec.PriorityClassName),
+			PreemptionPolicy:              util.Coalesce(cr.Spec.CN.PreemptionPolicy, cr.Spec.PreemptionPolicy),
+			SecurityContext:               getPodSecurityContext(cr, &cr.Spec.CN.DorisComponentSpec),
+			DNSConfig:                     getPodDNSConfig(cr, &cr.Spec.CN.DorisComponentSpec),
+			DNSPolicy:                     getPodDNSPolicy(cr, &cr.Spec.CN.DorisComponentSpec),
+			RuntimeClassName:              getPodRuntimeClassName(cr, &cr.Spec.CN.DorisComponentSpec),
+			HostAliases:                   hostAlias,
+			TerminationGracePeriodSeconds: cr.Spec.CN.TerminationGracePeriodSeconds,
 		},
 	}
 
 	// update strategy
-	updateStg := appv1.StatefulSetUpdateStrategy{
-		Type: util.PointerFallbackAndDeRefer(
-			cr.Spec.CN.StatefulSetUpdateStrategy,
-			cr.Spec.StatefulSetUpdateStrategy,
-			appv1.RollingUpdateStatefulSetStrategyType),
-	}
+	updateStg := makeStatefulSetUpdateStrategy(
+		cr.Spec.CN.StatefulSetUpdateStrategy, cr.Spec.StatefulSetUpdateStrategy, cr.Spec.CN.Partition)
 
 	// statefulset
 	statefulSet := &appv1.StatefulSet{
@@ -310,16 +347,17 @@ func MakeCnStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 			Labels:    cnLabels,
 		},
 		Spec: appv1.StatefulSetSpec{
-			Replicas:            &cr.Spec.CN.Replicas,
-			ServiceName:         GetCnPeerServiceKey(cr.ObjKey()).Name,
-			Selector:            &metav1.LabelSelector{MatchLabels: cnLabels},
-			Template:            podTemplate,
-			UpdateStrategy:      updateStg,
-			PodManagementPolicy: appv1.ParallelPodManagement,
+			Replicas:             &cr.Spec.CN.Replicas,
+			ServiceName:          GetCnPeerServiceKey(cr.ResourceKey()).Name,
+			Selector:             &metav1.LabelSelector{MatchLabels: cnLabels},
+			VolumeClaimTemplates: storagePvcTemplates,
+			Template:             podTemplate,
+			UpdateStrategy:       updateStg,
+			MinReadySeconds:      util.PointerDeRefer(cr.Spec.CN.MinReadySeconds, 0),
+			PodManagementPolicy:  appv1.ParallelPodManagement,
 		},
 	}
 
-	_ = controllerutil.SetOwnerReference(cr, statefulSet, scheme)
-	_ = controllerutil.SetControllerReference(cr, statefulSet, scheme)
+	setClusterOwner(cr, statefulSet, scheme, true)
 	return statefulSet
 }
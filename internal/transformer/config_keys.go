@@ -0,0 +1,170 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/template"
+	"github.com/al-assad/doris-operator/internal/util"
+	"sort"
+	"strings"
+)
+
+// known config key lists are bundled per major version of Doris like "config-keys/fe-2.0.txt",
+// with one key per line.
+const configKeysDir = "config-keys"
+
+// GetDorisMajorVersion returns the "<major>.<minor>" of the Doris version like "2.0.3" or "v2.1.0-rc01",
+// and an empty string when the version is not in the semantic form like "latest".
+func GetDorisMajorVersion(version string) string {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 || !isDigits(parts[0]) {
+		return ""
+	}
+	minor := parts[1]
+	if idx := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); idx >= 0 {
+		minor = minor[:idx]
+	}
+	if minor == "" {
+		return ""
+	}
+	return parts[0] + "." + minor
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetKnownConfigKeys returns the known config keys of the component ("fe" or "be") for the
+// major version of Doris, the second return value is false when no key list is bundled.
+func GetKnownConfigKeys(component string, majorVersion string) (map[string]struct{}, bool) {
+	if majorVersion == "" {
+		return nil, false
+	}
+	content, err := template.Read(fmt.Sprintf("%s/%s-%s.txt", configKeysDir, component, majorVersion))
+	if err != nil {
+		return nil, false
+	}
+	keys := make(map[string]struct{})
+	for _, line := range strings.Split(content, "\n") {
+		if key := strings.TrimSpace(line); key != "" && !strings.HasPrefix(key, "#") {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys, true
+}
+
+// FindUnknownConfigKeys checks the keys of FE, BE and CN configs against the known config keys
+// of their Doris major version, and returns a message for each unknown key. CN shares the
+// config keys of BE. The components without a bundled key list are skipped.
+func FindUnknownConfigKeys(cr *dapi.DorisCluster) []string {
+	var msgs []string
+	if cr.Spec.FE != nil {
+		msgs = append(msgs, findUnknownConfigKeys("spec.fe.config", "fe",
+			util.Coalesce(cr.Spec.FE.Version, cr.Spec.Version), cr.Spec.FE.Configs)...)
+	}
+	if cr.Spec.BE != nil {
+		msgs = append(msgs, findUnknownConfigKeys("spec.be.config", "be",
+			util.Coalesce(cr.Spec.BE.Version, cr.Spec.Version), cr.Spec.BE.Configs)...)
+	}
+	if cr.Spec.CN != nil {
+		msgs = append(msgs, findUnknownConfigKeys("spec.cn.config", "be",
+			util.Coalesce(cr.Spec.CN.Version, cr.Spec.Version), cr.Spec.CN.Configs)...)
+	}
+	return msgs
+}
+
+func findUnknownConfigKeys(path string, component string, version string, configs map[string]string) []string {
+	if len(configs) == 0 {
+		return nil
+	}
+	majorVersion := GetDorisMajorVersion(version)
+	known, ok := GetKnownConfigKeys(component, majorVersion)
+	if !ok {
+		return nil
+	}
+	var msgs []string
+	for key := range configs {
+		if _, exist := known[key]; exist {
+			continue
+		}
+		msg := fmt.Sprintf("%s: unknown config key %q for Doris %s", path, key, majorVersion)
+		if suggestion := suggestConfigKey(key, known); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+// suggestConfigKey returns the closest known key within a small edit distance, which
+// catches the typos like "http_prot".
+func suggestConfigKey(key string, known map[string]struct{}) string {
+	maxDistance := 2
+	if len(key) <= 4 {
+		maxDistance = 1
+	}
+	best, bestDistance := "", maxDistance+1
+	for candidate := range known {
+		d := levenshtein(key, candidate)
+		if d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
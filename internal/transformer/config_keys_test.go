@@ -0,0 +1,59 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetDorisMajorVersion(t *testing.T) {
+	assert.Equal(t, "2.0", GetDorisMajorVersion("2.0.3"))
+	assert.Equal(t, "2.1", GetDorisMajorVersion("v2.1.0-rc01"))
+	assert.Equal(t, "2.1", GetDorisMajorVersion("2.1"))
+	assert.Equal(t, "", GetDorisMajorVersion("latest"))
+	assert.Equal(t, "", GetDorisMajorVersion(""))
+}
+
+func TestFindUnknownConfigKeys(t *testing.T) {
+	cr := &dapi.DorisCluster{Spec: dapi.DorisClusterSpec{
+		Version: "2.1.2",
+		FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Configs: map[string]string{
+			"http_port": "8030", "arrow_flight_sql_port": "9090", "JAVA_OPTS": "-Xmx8g", "foo_bar_baz": "1",
+		}}},
+		BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Configs: map[string]string{
+			"storage_root_path": "/opt/doris", "webserver_prot": "8040",
+		}}},
+		CN: &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{Version: "latest", Configs: map[string]string{
+			"unknown_key": "1",
+		}}},
+	}}
+	assert.Equal(t, []string{
+		`spec.fe.config: unknown config key "foo_bar_baz" for Doris 2.1`,
+		`spec.be.config: unknown config key "webserver_prot" for Doris 2.1, did you mean "webserver_port"?`,
+	}, FindUnknownConfigKeys(cr))
+
+	// the keys introduced by a later version are unknown to the earlier version
+	cr.Spec.FE.Version = "2.0.3"
+	cr.Spec.FE.Configs = map[string]string{"arrow_flight_sql_port": "9090"}
+	cr.Spec.BE = nil
+	assert.Equal(t, []string{`spec.fe.config: unknown config key "arrow_flight_sql_port" for Doris 2.0`},
+		FindUnknownConfigKeys(cr))
+}
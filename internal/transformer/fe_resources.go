@@ -24,11 +24,14 @@ import (
 	"github.com/al-assad/doris-operator/internal/util"
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -36,6 +39,44 @@ const (
 	DefaultFeEditLogPort = 9010
 	DefaultFeRpcPort     = 9020
 	DefaultFeQueryPort   = 9030
+	// The Arrow Flight SQL port of FE is disabled by default
+	DefaultFeArrowFlightSqlPort = -1
+
+	// Keys of the FE TLS secret and the mount path of the keystores
+	FeTlsCaCertKey         = "ca.p12"
+	FeTlsServerCertKey     = "server.p12"
+	FeTlsCaPasswordKey     = "ca.password"
+	FeTlsServerPasswordKey = "server.password"
+	FeTlsMountPath         = "/etc/apache-doris/fe-tls/"
+
+	// Mount path of the pod info that tells FE whether to start with the metadata failure recovery
+	FeRecoveryMountPath = "/etc/apache-doris/fe-recovery/"
+
+	DefaultFeMetaMountPath = "/opt/apache-doris/fe/doris-meta"
+	DefaultFeLogMountPath  = "/opt/apache-doris/fe/log"
+
+	// Keys of the last-known-good FE ConfigMap that snapshot the configFileContent and
+	// the merged configs from configMapRef and config.
+	FeLastGoodRawConfKey = "raw.conf"
+	FeLastGoodConfKey    = "configs.conf"
+)
+
+var (
+	// FeMetadataRecoveryAnnoKey is the annotation key of DorisCluster to opt in the automatic
+	// recovery of the FE pod that is crash-looping due to the metadata failure.
+	FeMetadataRecoveryAnnoKey = fmt.Sprintf("%s/fe-metadata-recovery", dapi.GroupVersion.Group)
+	// FeMetadataRecoveryRestartsAnnoKey is the annotation key of DorisCluster to override the
+	// number of consecutive restarts of the FE pod that triggers the metadata recovery.
+	FeMetadataRecoveryRestartsAnnoKey = fmt.Sprintf("%s/fe-metadata-recovery-restarts", dapi.GroupVersion.Group)
+	// FeMetadataFailureRecoveryPodAnnoKey is the annotation key of FE pod to start FE in the
+	// metadata failure recovery mode, which is exposed to the FE container via downward API.
+	FeMetadataFailureRecoveryPodAnnoKey = fmt.Sprintf("%s/metadata-failure-recovery", dapi.GroupVersion.Group)
+	// FeConfigHashAnnoKey is the annotation key of the last-known-good FE ConfigMap
+	// that records the hash of the config.
+	FeConfigHashAnnoKey = fmt.Sprintf("%s/fe-config-hash", dapi.GroupVersion.Group)
+	// FePodServiceOrdinalLabelKey is the label key of the service of FE pod that records
+	// the ordinal of the pod.
+	FePodServiceOrdinalLabelKey = fmt.Sprintf("%s/fe-pod-ordinal", dapi.GroupVersion.Group)
 )
 
 func GetFeComponentLabels(dorisClusterKey types.NamespacedName) map[string]string {
@@ -49,6 +90,13 @@ func GetFeConfigMapKey(dorisClusterKey types.NamespacedName) types.NamespacedNam
 	}
 }
 
+func GetFeLastGoodConfigMapKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-fe-config-last-good", dorisClusterKey.Name),
+	}
+}
+
 func GetFeServiceKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: dorisClusterKey.Namespace,
@@ -63,6 +111,14 @@ func GetFePeerServiceKey(dorisClusterKey types.NamespacedName) types.NamespacedN
 	}
 }
 
+// GetFePodServiceKey returns the key of the service of the FE pod, which is named after the pod.
+func GetFePodServiceKey(dorisClusterKey types.NamespacedName, ordinal int32) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-fe-%d", dorisClusterKey.Name, ordinal),
+	}
+}
+
 func GetFeStatefulSetKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: dorisClusterKey.Namespace,
@@ -70,42 +126,98 @@ func GetFeStatefulSetKey(dorisClusterKey types.NamespacedName) types.NamespacedN
 	}
 }
 
+func GetFePodDisruptionBudgetKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-fe", dorisClusterKey.Name),
+	}
+}
+
+func GetFeIngressKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-fe", dorisClusterKey.Name),
+	}
+}
+
+// GetFeBaseImage returns the base image of FE, which falls back to the operator-wide default.
+func GetFeBaseImage(r *dapi.DorisCluster) string {
+	return util.Coalesce(r.Spec.FE.BaseImage, DefaultFeBaseImage)
+}
+
 func GetFeImage(r *dapi.DorisCluster) string {
-	version := util.StringFallback(r.Spec.FE.Version, r.Spec.Version)
-	return fmt.Sprintf("%s:%s", r.Spec.FE.BaseImage, version)
+	version := util.Coalesce(r.Spec.FE.Version, r.Spec.Version)
+	return withImageRegistry(r, fmt.Sprintf("%s:%s", GetFeBaseImage(r), version))
 }
 
 func GetFeHttpPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.FE == nil {
 		return DefaultFeHttpPort
 	}
-	return getPortValueFromRawConf(cr.Spec.FE.Configs, "http_port", DefaultFeHttpPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec), "http_port", DefaultFeHttpPort)
 }
 
 func GetFeQueryPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.FE == nil {
 		return DefaultFeQueryPort
 	}
-	return getPortValueFromRawConf(cr.Spec.FE.Configs, "query_port", DefaultFeQueryPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec), "query_port", DefaultFeQueryPort)
+}
+
+// GetFeArrowFlightPort returns the Arrow Flight SQL port of FE, which is only
+// enabled when "arrow_flight_sql_port" is configured with a positive value.
+func GetFeArrowFlightPort(cr *dapi.DorisCluster) int32 {
+	if cr.Spec.FE == nil {
+		return DefaultFeArrowFlightSqlPort
+	}
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec), "arrow_flight_sql_port", DefaultFeArrowFlightSqlPort)
 }
 
 func GetFeRpcPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.FE == nil {
 		return DefaultFeRpcPort
 	}
-	return getPortValueFromRawConf(cr.Spec.FE.Configs, "rpc_port", DefaultFeRpcPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec), "rpc_port", DefaultFeRpcPort)
 }
 
 func GetFeEditLogPort(cr *dapi.DorisCluster) int32 {
 	if cr.Spec.FE == nil {
 		return DefaultFeEditLogPort
 	}
-	return getPortValueFromRawConf(cr.Spec.FE.Configs, "edit_log_port", DefaultFeEditLogPort)
+	return getPortValueFromRawConf(getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec), "edit_log_port", DefaultFeEditLogPort)
+}
+
+// GetFeMetaMountPath returns the mount path of the FE metadata volume.
+func GetFeMetaMountPath(cr *dapi.DorisCluster) string {
+	return strings.TrimSuffix(util.Coalesce(cr.Spec.FE.MetaMountPath, DefaultFeMetaMountPath), "/")
+}
+
+// GetFeLogMountPath returns the mount path of the FE log volume.
+func GetFeLogMountPath(cr *dapi.DorisCluster) string {
+	return strings.TrimSuffix(util.Coalesce(cr.Spec.FE.LogMountPath, DefaultFeLogMountPath), "/")
+}
+
+// GetFeServiceDNS returns the FQDN of FE service composed with the cluster domain.
+func GetFeServiceDNS(cr *dapi.DorisCluster) string {
+	key := GetFeServiceKey(cr.ResourceKey())
+	return fmt.Sprintf("%s.%s.svc.%s", key.Name, key.Namespace, GetClusterDomain(cr))
+}
+
+// GetFeFollowerNum returns the number of FE followers, the rest of FE replicas are observers.
+func GetFeFollowerNum(cr *dapi.DorisCluster) int32 {
+	if cr.Spec.FE == nil {
+		return 0
+	}
+	followers := util.PointerDeRefer(cr.Spec.FE.Followers, cr.Spec.FE.Replicas)
+	if followers > cr.Spec.FE.Replicas {
+		return cr.Spec.FE.Replicas
+	}
+	return followers
 }
 
-func GetFeServiceDNS(dorisClusterKey types.NamespacedName) string {
-	key := GetFeServiceKey(dorisClusterKey)
-	return fmt.Sprintf("%s.%s", key.Name, key.Namespace)
+// GetFePodFQDN returns the FQDN of FE pod that is used as the host of frontend in Doris cluster.
+func GetFePodFQDN(cr *dapi.DorisCluster, podName string) string {
+	return makePodFQDN(cr, GetFePeerServiceKey(cr.ResourceKey()).Name, podName)
 }
 
 func GetFeExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) []string {
@@ -117,15 +229,72 @@ func GetFeExpectPodNames(dorisClusterKey types.NamespacedName, replicas int32) [
 	return expectFePods
 }
 
-func MakeFeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.ConfigMap {
+// The slow startup of FE that replays its metadata on a large cluster is covered by
+// the startup probe, so the default liveness probe starts checking without a long delay.
+func makeFeDefaultLivenessProbe(cr *dapi.DorisCluster) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler:     util.NewHttpGetProbeHandler("/api/bootstrap", GetFeHttpPort(cr)),
+		TimeoutSeconds:   5,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 6,
+	}
+}
+
+// The exec readiness probe of FE confirms that the FE is able to serve queries and the cluster
+// has an elected master, via the operator SQL account from the container env.
+func makeFeReadinessProbe(cr *dapi.DorisCluster) *corev1.Probe {
+	// the mysql client prompts for the password when "-p" is given with an empty one
+	script := fmt.Sprintf(`mysql --connect-timeout 2 -h 127.0.0.1 -P %d -u"$ACC_USER" ${ACC_PWD:+"-p$ACC_PWD"} --batch -E -e 'SHOW FRONTENDS' | grep -q 'IsMaster: true'`,
+		GetFeQueryPort(cr))
+	probe := makeReadinessProbe(cr.Spec.FE.ReadinessProbeType, GetFeEditLogPort(cr), GetFeHttpPort(cr),
+		[]string{"/bin/sh", "-c", script})
+	probe.InitialDelaySeconds = 3
+	return probe
+}
+
+// Get the affinity of FE pods, the affinity set by user replaces the default pod anti-affinity
+// across nodes, which is only applied when there are more than one FE replicas.
+func getFeAffinity(cr *dapi.DorisCluster) *corev1.Affinity {
+	if affinity := util.Coalesce(cr.Spec.FE.Affinity, cr.Spec.Affinity); affinity != nil {
+		return affinity
+	}
+	if cr.Spec.FE.Replicas <= 1 {
+		return nil
+	}
+	term := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: GetFeComponentLabels(cr.ResourceKey())},
+		TopologyKey:   corev1.LabelHostname,
+	}
+	switch cr.Spec.FE.DefaultAntiAffinity {
+	case dapi.AntiAffinityNone:
+		return nil
+	case dapi.AntiAffinityRequired:
+		return &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{term},
+		}}
+	default:
+		return &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: term}},
+		}}
+	}
+}
+
+// MakeFeConfigMap makes the FE configmap, refConfigs are the configs from
+// the ConfigMap referenced by spec.fe.configMapRef.
+func MakeFeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, refConfigs map[string]string) *corev1.ConfigMap {
 	if cr.Spec.FE == nil {
 		return nil
 	}
-	configs := util.MapFallback(cr.Spec.FE.Configs, make(map[string]string))
-	configs["enable_fqdn_mode"] = "true"
-	configMapRef := GetFeConfigMapKey(cr.ObjKey())
+	configs := util.MergeMaps(getRawJvmOptConfigs(cr.Spec.FE.ConfigFileContent), refConfigs)
+	configs = util.MergeMaps(configs, cr.Spec.FE.Configs)
+	configs = util.MergeMaps(configs, map[string]string{"enable_fqdn_mode": "true"})
+	configs = util.MergeMaps(configs, makeFeTlsConfigs(cr.Spec.FE.TLS))
+	configs = util.MergeMaps(configs, makeFePathConfigs(cr))
+	configMapRef := GetFeConfigMapKey(cr.ResourceKey())
 	data := map[string]string{
-		"fe.conf": dumpJavaBasedComponentConf(configs),
+		FeConfFileKey: withRawComponentConf(cr.Spec.FE.ConfigFileContent,
+			dumpJavaBasedComponentConf(configs, makeJvmHeapOpt(cr.Spec.FE.JvmHeap, GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec)))),
 	}
 	// merge hadoop config data
 	if cr.Spec.HadoopConf != nil {
@@ -133,27 +302,98 @@ func MakeFeConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Conf
 	}
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapRef.Name,
-			Namespace: configMapRef.Namespace,
-			Labels:    GetFeComponentLabels(cr.ObjKey()),
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.FE.ConfigMapLabels, GetFeComponentLabels(cr.ResourceKey())),
+			Annotations: cr.Spec.FE.ConfigMapAnnotations,
 		},
 		Data: data,
 	}
-	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
+	setClusterOwner(cr, configMap, scheme, false)
 	return configMap
 }
 
+// MakeFeLastGoodConfigMap makes the snapshot of the FE configs that all the FE pods have been
+// ready with, which is restored by the automatic config rollback of FE. Only the configs provided
+// by user are snapshotted, the configs derived from the other parts of spec, e.g. the JVM heap,
+// TLS and mount paths, always follow the current spec.
+func MakeFeLastGoodConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, refConfigs map[string]string, confHash string) *corev1.ConfigMap {
+	if cr.Spec.FE == nil {
+		return nil
+	}
+	configMapRef := GetFeLastGoodConfigMapKey(cr.ResourceKey())
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      GetFeComponentLabels(cr.ResourceKey()),
+			Annotations: map[string]string{FeConfigHashAnnoKey: confHash},
+		},
+		Data: map[string]string{
+			FeLastGoodRawConfKey: cr.Spec.FE.ConfigFileContent,
+			FeLastGoodConfKey:    dumpJavaBasedComponentConf(util.MergeMaps(refConfigs, cr.Spec.FE.Configs), ""),
+		},
+	}
+	setClusterOwner(cr, configMap, scheme, false)
+	return configMap
+}
+
+// MakeFeRolledBackConfigMap makes the FE ConfigMap with the user configs restored from the
+// last-known-good snapshot, while the other parts of spec are kept.
+func MakeFeRolledBackConfigMap(cr *dapi.DorisCluster, scheme *runtime.Scheme, lastGood *corev1.ConfigMap) *corev1.ConfigMap {
+	if cr.Spec.FE == nil {
+		return nil
+	}
+	rolledBack := cr.DeepCopy()
+	rolledBack.Spec.FE.ConfigFileContent = lastGood.Data[FeLastGoodRawConfKey]
+	rolledBack.Spec.FE.Configs = ParseComponentConf(lastGood.Data[FeLastGoodConfKey])
+	return MakeFeConfigMap(rolledBack, scheme, nil)
+}
+
+// Make the directory configs of FE that follow the custom mount paths, the default
+// paths are left to the FE defaults.
+func makeFePathConfigs(cr *dapi.DorisCluster) map[string]string {
+	configs := make(map[string]string)
+	if metaPath := GetFeMetaMountPath(cr); metaPath != DefaultFeMetaMountPath {
+		configs["meta_dir"] = metaPath
+	}
+	if logPath := GetFeLogMountPath(cr); logPath != DefaultFeLogMountPath {
+		configs["LOG_DIR"] = logPath
+	}
+	return configs
+}
+
+// Make the SSL configs of the FE MySQL protocol, the passwords of keystores are
+// injected by the entrypoint from the secret to keep them out of the ConfigMap.
+func makeFeTlsConfigs(tls *dapi.FeTLSSpec) map[string]string {
+	if tls == nil {
+		return nil
+	}
+	return map[string]string{
+		"enable_ssl":                           "true",
+		"ssl_force_client_auth":                strconv.FormatBool(tls.ForceClientAuth),
+		"mysql_ssl_default_ca_certificate":     FeTlsMountPath + FeTlsCaCertKey,
+		"mysql_ssl_default_server_certificate": FeTlsMountPath + FeTlsServerCertKey,
+	}
+}
+
+// GetFeTlsRequiredSecretKeys returns the keys that the FE TLS secret must contain.
+func GetFeTlsRequiredSecretKeys() []string {
+	return []string{FeTlsCaCertKey, FeTlsServerCertKey}
+}
+
 func MakeFeService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Service {
 	if cr.Spec.FE == nil {
 		return nil
 	}
-	serviceRef := GetFeServiceKey(cr.ObjKey())
-	feLabels := GetFeComponentLabels(cr.ObjKey())
+	serviceRef := GetFeServiceKey(cr.ResourceKey())
+	feLabels := GetFeComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    feLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      util.MergeMaps(cr.Spec.FE.ServiceLabels, feLabels),
+			Annotations: cr.Spec.FE.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: feLabels,
@@ -174,6 +414,11 @@ func MakeFeService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Servic
 		if crSvc.Type != "" {
 			service.Spec.Type = crSvc.Type
 		}
+		service.Annotations = util.MergeMaps(service.Annotations, crSvc.Annotations)
+		if crSvc.Type == corev1.ServiceTypeLoadBalancer {
+			service.Spec.LoadBalancerClass = crSvc.LoadBalancerClass
+			service.Spec.LoadBalancerSourceRanges = crSvc.LoadBalancerSourceRanges
+		}
 		if crSvc.ExternalTrafficPolicy != nil {
 			service.Spec.ExternalTrafficPolicy = *crSvc.ExternalTrafficPolicy
 		}
@@ -185,21 +430,148 @@ func MakeFeService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Servic
 		}
 	}
 	service.Spec.Ports = []corev1.ServicePort{httpPort, queryPort}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	if arrowFlightPort := GetFeArrowFlightPort(cr); arrowFlightPort > 0 {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name: "arrow-flight", Port: arrowFlightPort,
+		})
+	}
+	service.Spec.Ports = append(service.Spec.Ports, makeExtraServicePorts(&cr.Spec.FE.DorisComponentSpec)...)
+	// expose the edit log and rpc port only when NodePort of them is specified,
+	// or they are published explicitly
+	if crSvc != nil {
+		isNodePort := crSvc.Type == corev1.ServiceTypeNodePort
+		if crSvc.ExposeInternalPorts || isNodePort && crSvc.EditLogPort != nil {
+			editLogPort := corev1.ServicePort{Name: "edit-log-port", Port: GetFeEditLogPort(cr)}
+			if isNodePort {
+				editLogPort.NodePort = util.PointerDeRefer(crSvc.EditLogPort, 0)
+			}
+			service.Spec.Ports = append(service.Spec.Ports, editLogPort)
+		}
+		if crSvc.ExposeInternalPorts || isNodePort && crSvc.RpcPort != nil {
+			rpcPort := corev1.ServicePort{Name: "rpc-port", Port: GetFeRpcPort(cr)}
+			if isNodePort {
+				rpcPort.NodePort = util.PointerDeRefer(crSvc.RpcPort, 0)
+			}
+			service.Spec.Ports = append(service.Spec.Ports, rpcPort)
+		}
+	}
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
+// MakeFePodServices makes the services of each FE pod selected by the pod name label of
+// statefulset, returns nil when the podService is not enabled.
+func MakeFePodServices(cr *dapi.DorisCluster, scheme *runtime.Scheme) []*corev1.Service {
+	if cr.Spec.FE == nil || cr.Spec.FE.PodService == nil {
+		return nil
+	}
+	crSvc := cr.Spec.FE.PodService
+	feLabels := GetFeComponentLabels(cr.ResourceKey())
+	ports := []corev1.ServicePort{
+		{Name: "http-port", Port: GetFeHttpPort(cr)},
+		{Name: "query-port", Port: GetFeQueryPort(cr)},
+	}
+	if arrowFlightPort := GetFeArrowFlightPort(cr); arrowFlightPort > 0 {
+		ports = append(ports, corev1.ServicePort{Name: "arrow-flight", Port: arrowFlightPort})
+	}
+	services := make([]*corev1.Service, 0, cr.Spec.FE.Replicas)
+	for ordinal := int32(0); ordinal < cr.Spec.FE.Replicas; ordinal++ {
+		serviceRef := GetFePodServiceKey(cr.ResourceKey(), ordinal)
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceRef.Name,
+				Namespace: serviceRef.Namespace,
+				Labels: util.MergeMaps(feLabels, map[string]string{
+					FePodServiceOrdinalLabelKey: strconv.Itoa(int(ordinal)),
+				}),
+				Annotations: crSvc.Annotations,
+			},
+			Spec: corev1.ServiceSpec{
+				Type: util.Coalesce(crSvc.Type, corev1.ServiceTypeClusterIP),
+				Selector: util.MergeMaps(feLabels, map[string]string{
+					appv1.StatefulSetPodNameLabel: fmt.Sprintf("%s-%d", GetFeStatefulSetKey(cr.ResourceKey()).Name, ordinal),
+				}),
+				Ports: append([]corev1.ServicePort(nil), ports...),
+			},
+		}
+		if crSvc.Type == corev1.ServiceTypeLoadBalancer {
+			service.Spec.LoadBalancerClass = crSvc.LoadBalancerClass
+			service.Spec.LoadBalancerSourceRanges = crSvc.LoadBalancerSourceRanges
+		}
+		if crSvc.ExternalTrafficPolicy != nil {
+			service.Spec.ExternalTrafficPolicy = *crSvc.ExternalTrafficPolicy
+		}
+		setClusterOwner(cr, service, scheme, false)
+		services = append(services, service)
+	}
+	return services
+}
+
+// The NodePort range of kube-apiserver "--service-node-port-range", which defaults to
+// 30000-32767 and can be overridden by the flag of operator.
+var (
+	NodePortRangeMin int32 = 30000
+	NodePortRangeMax int32 = 32767
+)
+
+// ParseNodePortRange parses the NodePort range in the format of "--service-node-port-range"
+// of kube-apiserver, e.g. "30000-32767".
+func ParseNodePortRange(value string) (int32, int32, error) {
+	invalid := fmt.Errorf("invalid NodePort range %q, it should be like 30000-32767", value)
+	lower, upper, found := strings.Cut(strings.TrimSpace(value), "-")
+	if !found {
+		return 0, 0, invalid
+	}
+	minPort, err := strconv.ParseInt(strings.TrimSpace(lower), 10, 32)
+	if err != nil {
+		return 0, 0, invalid
+	}
+	maxPort, err := strconv.ParseInt(strings.TrimSpace(upper), 10, 32)
+	if err != nil || minPort < 1 || maxPort > 65535 || minPort > maxPort {
+		return 0, 0, invalid
+	}
+	return int32(minPort), int32(maxPort), nil
+}
+
+// ValidateFeServiceNodePorts checks that the NodePort values specified in FE service spec
+// are within the NodePort range of Kubernetes, 0 means assigning a random port.
+func ValidateFeServiceNodePorts(cr *dapi.DorisCluster) error {
+	if cr.Spec.FE == nil || cr.Spec.FE.Service == nil {
+		return nil
+	}
+	crSvc := cr.Spec.FE.Service
+	nodePorts := map[string]*int32{
+		"queryPort":   crSvc.QueryPort,
+		"httpPort":    crSvc.HttpPort,
+		"editLogPort": crSvc.EditLogPort,
+		"rpcPort":     crSvc.RpcPort,
+	}
+	var errs []error
+	for _, name := range util.MapSortedKeys(nodePorts) {
+		port := util.PointerDeRefer(nodePorts[name], 0)
+		if port != 0 && (port < NodePortRangeMin || port > NodePortRangeMax) {
+			errs = append(errs, fmt.Errorf("spec.fe.service.%s: NodePort %d is out of the valid range %d-%d",
+				name, port, NodePortRangeMin, NodePortRangeMax))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrors(errs...)
+}
+
 func MakeFePeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Service {
 	if cr.Spec.FE == nil {
 		return nil
 	}
-	serviceRef := GetFePeerServiceKey(cr.ObjKey())
-	feLabels := GetFeComponentLabels(cr.ObjKey())
+	serviceRef := GetFePeerServiceKey(cr.ResourceKey())
+	feLabels := GetFeComponentLabels(cr.ResourceKey())
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      serviceRef.Name,
-			Namespace: serviceRef.Namespace,
-			Labels:    feLabels,
+			Name:        serviceRef.Name,
+			Namespace:   serviceRef.Namespace,
+			Labels:      mergePodMeta(cr.Spec.FE.ServiceLabels, feLabels, PeerServiceLabels),
+			Annotations: cr.Spec.FE.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
@@ -209,25 +581,45 @@ func MakeFePeerService(cr *dapi.DorisCluster, scheme *runtime.Scheme) *corev1.Se
 			},
 			Selector:  feLabels,
 			ClusterIP: "None",
+			// the HTTP and Exec readiness probes only pass once the FE has joined the cluster,
+			// the joining FE needs its DNS record to be resolved by the others before it is ready.
+			PublishNotReadyAddresses: cr.Spec.FE.ReadinessProbeType == dapi.ProbeHTTP ||
+				cr.Spec.FE.ReadinessProbeType == dapi.ProbeExec,
 		},
 	}
-	_ = controllerutil.SetOwnerReference(cr, service, scheme)
+	setClusterOwner(cr, service, scheme, false)
 	return service
 }
 
+// makeFeContainerPorts returns the container ports of FE managed by operator,
+// including the Arrow Flight SQL port when it is enabled.
+func makeFeContainerPorts(cr *dapi.DorisCluster) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{
+		{Name: "http-port", ContainerPort: GetFeHttpPort(cr)},
+		{Name: "edit-log-port", ContainerPort: GetFeEditLogPort(cr)},
+		{Name: "rpc-port", ContainerPort: GetFeRpcPort(cr)},
+		{Name: "query-port", ContainerPort: GetFeQueryPort(cr)},
+	}
+	if arrowFlightPort := GetFeArrowFlightPort(cr); arrowFlightPort > 0 {
+		ports = append(ports, corev1.ContainerPort{Name: "arrow-flight", ContainerPort: arrowFlightPort})
+	}
+	return ports
+}
+
 func MakeFeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.StatefulSet {
 	if cr.Spec.FE == nil {
 		return nil
 	}
-	statefulSetRef := GetFeStatefulSetKey(cr.ObjKey())
-	configMapRef := GetFeConfigMapKey(cr.ObjKey())
-	accountSecretRef := GetOprSqlAccountSecretKey(cr.ObjKey())
-	feLabels := GetFeComponentLabels(cr.ObjKey())
+	statefulSetRef := GetFeStatefulSetKey(cr.ResourceKey())
+	configMapRef := GetFeConfigMapKey(cr.ResourceKey())
+	accountSecretRef := GetOprSqlAccountSecretRef(cr)
+	feLabels := GetFeComponentLabels(cr.ResourceKey())
 
 	// volume claim template
 	pvcTemplate := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "fe-meta",
+			Name:        "fe-meta",
+			Annotations: cr.Spec.FE.StorageAnnotations,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
@@ -245,6 +637,16 @@ func MakeFeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	volumes := []corev1.Volume{
 		{Name: "conf", VolumeSource: util.NewConfigMapVolumeSource(configMapRef.Name)},
 		{Name: "fe-log", VolumeSource: util.NewEmptyDirVolumeSource()},
+		{Name: "fe-recovery", VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{{
+					Path: "metadata-failure-recovery",
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: fmt.Sprintf("metadata.annotations['%s']", FeMetadataFailureRecoveryPodAnnoKey),
+					},
+				}},
+			},
+		}},
 	}
 	// merge addition volumes defined by user
 	volumes = append(volumes, cr.Spec.FE.AdditionalVolumes...)
@@ -252,49 +654,78 @@ func MakeFeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	// pod template: main container
 	mainContainer := corev1.Container{
 		Name:            "fe",
+		SecurityContext: getContainerSecurityContext(cr, &cr.Spec.FE.DorisComponentSpec),
 		Image:           GetFeImage(cr),
-		ImagePullPolicy: cr.Spec.ImagePullPolicy,
-		Resources:       formatContainerResourcesRequirement(cr.Spec.FE.ResourceRequirements),
-		Ports: []corev1.ContainerPort{
-			{Name: "http-port", ContainerPort: GetFeHttpPort(cr)},
-			{Name: "edit-log-port", ContainerPort: GetFeEditLogPort(cr)},
-			{Name: "rpc-port", ContainerPort: GetFeRpcPort(cr)},
-			{Name: "query-port", ContainerPort: GetFeQueryPort(cr)},
-		},
+		ImagePullPolicy: GetImagePullPolicy(cr, GetFeImage(cr)),
+		Command:         cr.Spec.FE.Command,
+		Args:            cr.Spec.FE.Args,
+		Resources:       formatContainerResourcesRequirement(GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec)),
+		Ports:           withExtraContainerPorts(makeFeContainerPorts(cr), &cr.Spec.FE.DorisComponentSpec),
 		Env: []corev1.EnvVar{
-			{Name: "FE_SVC", Value: GetFeServiceKey(cr.ObjKey()).Name},
+			{Name: "FE_SVC", Value: GetFeServiceDNS(cr)},
+			{Name: "FE_META_DIR", Value: GetFeMetaMountPath(cr)},
 			{Name: "ACC_USER", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "user")},
 			{Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource(accountSecretRef.Name, "password")},
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			{Name: "conf", MountPath: "/etc/apache-doris/fe/"},
-			{Name: "fe-meta", MountPath: "/opt/apache-doris/fe/doris-meta"},
-			{Name: "fe-log", MountPath: "/opt/apache-doris/fe/log"},
+			{Name: "fe-meta", MountPath: GetFeMetaMountPath(cr)},
+			{Name: "fe-log", MountPath: GetFeLogMountPath(cr)},
+			{Name: "fe-recovery", MountPath: FeRecoveryMountPath, ReadOnly: true},
 		},
 		Lifecycle: &corev1.Lifecycle{
 			PreStop: util.NewExecLifecycleHandler("/bin/sh", "-c", "bin/stop_fe.sh"),
 		},
-		ReadinessProbe: &corev1.Probe{
-			ProbeHandler:        util.NewTcpSocketProbeHandler(GetFeEditLogPort(cr)),
-			InitialDelaySeconds: 3,
-			TimeoutSeconds:      1,
-			PeriodSeconds:       5,
-			SuccessThreshold:    1,
-			FailureThreshold:    3,
-		},
-		LivenessProbe: &corev1.Probe{
-			ProbeHandler:        util.NewTcpSocketProbeHandler(GetFeEditLogPort(cr)),
-			InitialDelaySeconds: 20,
-			TimeoutSeconds:      1,
-			PeriodSeconds:       5,
-			SuccessThreshold:    1,
-			FailureThreshold:    5,
-		},
+		ReadinessProbe: makeFeReadinessProbe(cr),
+		LivenessProbe:  util.Coalesce(cr.Spec.FE.LivenessProbe, makeFeDefaultLivenessProbe(cr)),
+		StartupProbe:   util.Coalesce(cr.Spec.FE.StartupProbe, makeDefaultStartupProbe(GetFeHttpPort(cr))),
 	}
+	// pod template: the FE pods with ordinal >= FE_FOLLOWER_NUM join as observers
+	if cr.Spec.FE.Followers != nil {
+		mainContainer.Env = append(mainContainer.Env,
+			corev1.EnvVar{Name: "FE_FOLLOWER_NUM", Value: strconv.Itoa(int(GetFeFollowerNum(cr)))})
+	}
+	// pod template: storage volumes
+	storagePvcTemplates, storagePodVolumes, storageMounts := genStorageVolumes(cr.Spec.FE.StorageVolumes, cr.Spec.FE.StorageClassName, cr.Spec.FE.StorageAnnotations)
+	volumes = append(volumes, storagePodVolumes...)
+	mainContainer.VolumeMounts = mergeStorageVolumeMounts(mainContainer.VolumeMounts, storageMounts)
+	// pod template: SSL keystores of the MySQL protocol
+	if cr.Spec.FE.TLS != nil {
+		volumes = append(volumes, corev1.Volume{Name: "fe-tls", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: cr.Spec.FE.TLS.SecretName,
+				Items: []corev1.KeyToPath{
+					{Key: FeTlsCaCertKey, Path: FeTlsCaCertKey},
+					{Key: FeTlsServerCertKey, Path: FeTlsServerCertKey},
+				},
+			},
+		}})
+		mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+			corev1.VolumeMount{Name: "fe-tls", MountPath: FeTlsMountPath, ReadOnly: true})
+		// the passwords of keystores are optional
+		passwordEnv := func(name, key string) corev1.EnvVar {
+			source := util.NewEnvVarSecretSource(cr.Spec.FE.TLS.SecretName, key)
+			source.SecretKeyRef.Optional = util.Pointer(true)
+			return corev1.EnvVar{Name: name, ValueFrom: source}
+		}
+		mainContainer.Env = append(mainContainer.Env,
+			passwordEnv("FE_SSL_CA_CERT_PASSWORD", FeTlsCaPasswordKey),
+			passwordEnv("FE_SSL_SERVER_CERT_PASSWORD", FeTlsServerPasswordKey),
+		)
+	}
+	// pod template: FQDN of the pod resolved via the peer service
+	mainContainer.Env = append(mainContainer.Env, makePodFQDNEnvs(cr, GetFePeerServiceKey(cr.ResourceKey()).Name)...)
+	// pod template: credentials for accessing HDFS/S3
+	volumes = injectHadoopCredentials(cr, &mainContainer, volumes)
 	// pod template: merge additional pod containers configs defined by user
 	mainContainer.Env = append(mainContainer.Env, cr.Spec.FE.AdditionalEnvs...)
+	mainContainer.EnvFrom = append(mainContainer.EnvFrom, cr.Spec.FE.AdditionalEnvFrom...)
 	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts, cr.Spec.FE.AdditionalVolumeMounts...)
-	containers := append([]corev1.Container{mainContainer}, cr.Spec.FE.AdditionalContainers...)
+	sidecars := cr.Spec.FE.AdditionalContainers
+	if cr.Spec.FE.ShareLogVolume {
+		sidecars = shareLogVolumeMount(sidecars, corev1.VolumeMount{Name: "fe-log", MountPath: GetFeLogMountPath(cr)})
+	}
+	containers := append([]corev1.Container{mainContainer}, sidecars...)
 
 	// pod template: host alias
 	var hostAlias []corev1.HostAlias
@@ -305,38 +736,42 @@ func MakeFeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 	}
 
 	// pod template: annotation
-	podAnnotations := util.MergeMaps(cr.Annotations, cr.Spec.FE.Annotations)
-	metricsAnnotations := map[string]string{
-		PrometheusPathAnnoKey:   "/metrics",
-		PrometheusPortAnnoKey:   strconv.Itoa(int(GetFeHttpPort(cr))),
-		PrometheusScrapeAnnoKey: "true",
-	}
-	podAnnotations = util.MergeMaps(metricsAnnotations, podAnnotations)
+	metricsAnnotations := makePodPrometheusAnnotations(cr,
+		func(p *dapi.MetricsPortsSpec) *int32 { return p.FE }, GetFeHttpPort(cr))
+	podAnnotations := mergePodMeta(metricsAnnotations, cr.Annotations, cr.Spec.FE.Annotations, cr.Spec.FE.PodAnnotations)
 
 	// pod template
 	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      feLabels,
+			Labels:      mergePodMeta(cr.Spec.FE.PodLabels, feLabels),
 			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
-			Volumes:            volumes,
-			Containers:         containers,
-			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			ServiceAccountName: util.StringFallback(cr.Spec.FE.ServiceAccount, cr.Spec.ServiceAccount),
-			NodeSelector:       util.MapFallback(cr.Spec.FE.NodeSelector, cr.Spec.NodeSelector),
-			Affinity:           util.PointerFallback(cr.Spec.FE.Affinity, cr.Spec.Affinity),
-			Tolerations:        util.ArrayFallback(cr.Spec.FE.Tolerations, cr.Spec.Tolerations),
-			PriorityClassName:  util.StringFallback(cr.Spec.FE.PriorityClassName, cr.Spec.PriorityClassName),
-			HostAliases:        hostAlias,
+			Volumes:                       volumes,
+			Containers:                    containers,
+			ImagePullSecrets:              cr.Spec.ImagePullSecrets,
+			ServiceAccountName:            util.Coalesce(cr.Spec.FE.ServiceAccount, cr.Spec.ServiceAccount),
+			Affinity:                      getFeAffinity(cr),
+			NodeSelector:                  util.MergeMaps(cr.Spec.NodeSelector, cr.Spec.FE.NodeSelector),
+			Tolerations:                   util.Fallback(cr.Spec.FE.Tolerations, cr.Spec.Tolerations),
+			TopologySpreadConstraints:     getTopologySpreadConstraints(cr, &cr.Spec.FE.DorisComponentSpec, feLabels),
+			PriorityClassName:             GetFePriorityClassName(cr),
+			PreemptionPolicy:              util.Coalesce(cr.Spec.FE.PreemptionPolicy, cr.Spec.PreemptionPolicy),
+			SecurityContext:               getPodSecurityContext(cr, &cr.Spec.FE.DorisComponentSpec),
+			DNSConfig:                     getPodDNSConfig(cr, &cr.Spec.FE.DorisComponentSpec),
+			DNSPolicy:                     getPodDNSPolicy(cr, &cr.Spec.FE.DorisComponentSpec),
+			RuntimeClassName:              getPodRuntimeClassName(cr, &cr.Spec.FE.DorisComponentSpec),
+			HostAliases:                   hostAlias,
+			TerminationGracePeriodSeconds: cr.Spec.FE.TerminationGracePeriodSeconds,
 		},
 	}
 
 	// update strategy
-	updateStg := appv1.StatefulSetUpdateStrategy{
-		Type: util.PointerFallbackAndDeRefer(
-			cr.Spec.FE.StatefulSetUpdateStrategy, cr.Spec.StatefulSetUpdateStrategy,
-			appv1.RollingUpdateStatefulSetStrategyType),
+	updateStg := makeStatefulSetUpdateStrategy(
+		cr.Spec.FE.StatefulSetUpdateStrategy, cr.Spec.StatefulSetUpdateStrategy, cr.Spec.FE.Partition)
+	// the pods are restarted by operator when leader-aware rollout is enabled
+	if cr.Spec.FE.LeaderAwareRollout {
+		updateStg = appv1.StatefulSetUpdateStrategy{Type: appv1.OnDeleteStatefulSetStrategyType}
 	}
 
 	// statefulset
@@ -348,15 +783,88 @@ func MakeFeStatefulSet(cr *dapi.DorisCluster, scheme *runtime.Scheme) *appv1.Sta
 		},
 		Spec: appv1.StatefulSetSpec{
 			Replicas:             &cr.Spec.FE.Replicas,
-			ServiceName:          GetFePeerServiceKey(cr.ObjKey()).Name,
+			ServiceName:          GetFePeerServiceKey(cr.ResourceKey()).Name,
 			Selector:             &metav1.LabelSelector{MatchLabels: feLabels},
-			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{pvcTemplate},
+			VolumeClaimTemplates: append([]corev1.PersistentVolumeClaim{pvcTemplate}, storagePvcTemplates...),
 			Template:             podTemplate,
 			UpdateStrategy:       updateStg,
+			MinReadySeconds:      util.PointerDeRefer(cr.Spec.FE.MinReadySeconds, 0),
 		},
 	}
 
-	_ = controllerutil.SetOwnerReference(cr, statefulSet, scheme)
-	_ = controllerutil.SetControllerReference(cr, statefulSet, scheme)
+	setClusterOwner(cr, statefulSet, scheme, true)
 	return statefulSet
 }
+
+// MakeFePodDisruptionBudget makes the PodDisruptionBudget of FE that keeps the quorum
+// of FE followers available by default, returns nil when there is only one FE replica and
+// no PodDisruptionBudget is defined by user.
+func MakeFePodDisruptionBudget(cr *dapi.DorisCluster, scheme *runtime.Scheme) *policyv1.PodDisruptionBudget {
+	if cr.Spec.FE == nil {
+		return nil
+	}
+	if cr.Spec.FE.PodDisruptionBudget == nil && cr.Spec.FE.Replicas < 2 {
+		return nil
+	}
+	// the quorum only counts the followers, since the pods of followers and observers share
+	// the same labels, at most one FE pod is allowed to be evicted when there are observers,
+	// otherwise the followers may be evicted in place of the observers.
+	followers := GetFeFollowerNum(cr)
+	defaultSpec := dapi.PodDisruptionBudgetSpec{MinAvailable: util.Pointer(intstr.FromInt(int(followers/2 + 1)))}
+	if cr.Spec.FE.Replicas > followers {
+		defaultSpec = dapi.PodDisruptionBudgetSpec{MaxUnavailable: util.Pointer(intstr.FromInt(1))}
+	}
+	return makePodDisruptionBudget(cr, scheme,
+		GetFePodDisruptionBudgetKey(cr.ResourceKey()),
+		GetFeComponentLabels(cr.ResourceKey()),
+		cr.Spec.FE.PodDisruptionBudget,
+		defaultSpec)
+}
+
+// MakeFeIngress makes the Ingress that routes to the http port of the FE service,
+// returns nil when the ingress is not defined.
+func MakeFeIngress(cr *dapi.DorisCluster, scheme *runtime.Scheme) *networkingv1.Ingress {
+	if cr.Spec.FE == nil || cr.Spec.FE.Ingress == nil {
+		return nil
+	}
+	spec := cr.Spec.FE.Ingress
+	ingressRef := GetFeIngressKey(cr.ResourceKey())
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressRef.Name,
+			Namespace:   ingressRef.Namespace,
+			Labels:      GetFeComponentLabels(cr.ResourceKey()),
+			Annotations: spec.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: spec.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: GetFeServiceKey(cr.ResourceKey()).Name,
+									Port: networkingv1.ServiceBackendPort{Name: "http-port"},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if spec.TLSSecretName != "" {
+		tls := networkingv1.IngressTLS{SecretName: spec.TLSSecretName}
+		if spec.Host != "" {
+			tls.Hosts = []string{spec.Host}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+	setClusterOwner(cr, ingress, scheme, false)
+	return ingress
+}
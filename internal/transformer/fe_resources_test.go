@@ -0,0 +1,666 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestDorisCluster() *dapi.DorisCluster {
+	return &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			Version: "2.0.3",
+			FE: &dapi.FESpec{
+				DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-fe", Replicas: 3},
+			},
+		},
+	}
+}
+
+// withBE adds the BE of the given replicas to the test DorisCluster.
+func withBE(cr *dapi.DorisCluster, replicas int32) *dapi.DorisCluster {
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-be", Replicas: replicas}}
+	return cr
+}
+
+func TestMakeFeStatefulSetLivenessProbe(t *testing.T) {
+	// default liveness probe
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	probe := sts.Spec.Template.Spec.Containers[0].LivenessProbe
+	assert.NotNil(t, probe.HTTPGet)
+	assert.Equal(t, "/api/bootstrap", probe.HTTPGet.Path)
+	assert.Equal(t, int32(DefaultFeHttpPort), probe.HTTPGet.Port.IntVal)
+	// the slow startup is covered by the startup probe instead of the initial delay
+	assert.Zero(t, probe.InitialDelaySeconds)
+
+	// overridden by user
+	cr.Spec.FE.LivenessProbe = &corev1.Probe{
+		ProbeHandler:        corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{}},
+		InitialDelaySeconds: 300,
+	}
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, cr.Spec.FE.LivenessProbe, sts.Spec.Template.Spec.Containers[0].LivenessProbe)
+}
+
+func TestMakeFeStatefulSetReadinessProbe(t *testing.T) {
+	cr := newTestDorisCluster()
+	probe := MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].ReadinessProbe
+	assert.NotNil(t, probe.TCPSocket)
+	assert.Equal(t, int32(DefaultFeEditLogPort), probe.TCPSocket.Port.IntVal)
+	assert.Equal(t, int32(3), probe.InitialDelaySeconds)
+
+	cr.Spec.FE.ReadinessProbeType = dapi.ProbeHTTP
+	probe = MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].ReadinessProbe
+	assert.Nil(t, probe.TCPSocket)
+	assert.Equal(t, DorisHealthApiPath, probe.HTTPGet.Path)
+	assert.Equal(t, int32(DefaultFeHttpPort), probe.HTTPGet.Port.IntVal)
+
+	cr.Spec.FE.ReadinessProbeType = dapi.ProbeExec
+	probe = MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].ReadinessProbe
+	assert.Nil(t, probe.HTTPGet)
+	assert.Len(t, probe.Exec.Command, 3)
+	assert.Contains(t, probe.Exec.Command[2], "-P 9030")
+	assert.Contains(t, probe.Exec.Command[2], "SHOW FRONTENDS")
+	assert.Contains(t, probe.Exec.Command[2], "IsMaster: true")
+	assert.Contains(t, probe.Exec.Command[2], `${ACC_PWD:+"-p$ACC_PWD"}`)
+}
+
+func TestMakeFePeerServicePublishNotReadyAddresses(t *testing.T) {
+	cr := newTestDorisCluster()
+	assert.False(t, MakeFePeerService(cr, runtime.NewScheme()).Spec.PublishNotReadyAddresses)
+	for _, probeType := range []dapi.ProbeType{dapi.ProbeHTTP, dapi.ProbeExec} {
+		cr.Spec.FE.ReadinessProbeType = probeType
+		assert.True(t, MakeFePeerService(cr, runtime.NewScheme()).Spec.PublishNotReadyAddresses)
+	}
+}
+
+func TestGetFePorts(t *testing.T) {
+	cr := newTestDorisCluster()
+	assert.Equal(t, int32(DefaultFeHttpPort), GetFeHttpPort(cr))
+	assert.Equal(t, int32(DefaultFeQueryPort), GetFeQueryPort(cr))
+	assert.Equal(t, int32(DefaultFeRpcPort), GetFeRpcPort(cr))
+	assert.Equal(t, int32(DefaultFeEditLogPort), GetFeEditLogPort(cr))
+
+	cr.Spec.FE.Configs = map[string]string{
+		"http_port":     "18030",
+		"query_port":    "19030",
+		"rpc_port":      "19020",
+		"edit_log_port": "19010",
+	}
+	assert.Equal(t, int32(18030), GetFeHttpPort(cr))
+	assert.Equal(t, int32(19030), GetFeQueryPort(cr))
+	assert.Equal(t, int32(19020), GetFeRpcPort(cr))
+	assert.Equal(t, int32(19010), GetFeEditLogPort(cr))
+}
+
+func TestMakeFeServiceNodePorts(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Service = &dapi.FeServiceSpec{
+		Type:        corev1.ServiceTypeNodePort,
+		QueryPort:   util.Pointer[int32](30001),
+		HttpPort:    util.Pointer[int32](30002),
+		EditLogPort: util.Pointer[int32](30003),
+		RpcPort:     util.Pointer[int32](30004),
+	}
+	assert.Nil(t, ValidateFeServiceNodePorts(cr))
+
+	svc := MakeFeService(cr, runtime.NewScheme())
+	nodePorts := make(map[string]int32)
+	for _, port := range svc.Spec.Ports {
+		nodePorts[port.Name] = port.NodePort
+	}
+	assert.Equal(t, map[string]int32{
+		"query-port":    30001,
+		"http-port":     30002,
+		"edit-log-port": 30003,
+		"rpc-port":      30004,
+	}, nodePorts)
+
+	// edit log and rpc port are not exposed for ClusterIP service
+	cr.Spec.FE.Service.Type = corev1.ServiceTypeClusterIP
+	svc = MakeFeService(cr, runtime.NewScheme())
+	assert.Len(t, svc.Spec.Ports, 2)
+
+	// out of range
+	cr.Spec.FE.Service.RpcPort = util.Pointer[int32](9020)
+	assert.NotNil(t, ValidateFeServiceNodePorts(cr))
+}
+
+func TestParseNodePortRange(t *testing.T) {
+	minPort, maxPort, err := ParseNodePortRange("20000-22767")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(20000), minPort)
+	assert.Equal(t, int32(22767), maxPort)
+	for _, value := range []string{"", "30000", "a-b", "32767-30000", "0-100", "30000-70000"} {
+		_, _, err = ParseNodePortRange(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMakeFeServiceExposeInternalPorts(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Service = &dapi.FeServiceSpec{Type: corev1.ServiceTypeClusterIP, ExposeInternalPorts: true}
+	ports := func() map[string]corev1.ServicePort {
+		res := make(map[string]corev1.ServicePort)
+		for _, port := range MakeFeService(cr, runtime.NewScheme()).Spec.Ports {
+			res[port.Name] = port
+		}
+		return res
+	}
+	svcPorts := ports()
+	assert.Equal(t, GetFeEditLogPort(cr), svcPorts["edit-log-port"].Port)
+	assert.Equal(t, GetFeRpcPort(cr), svcPorts["rpc-port"].Port)
+	assert.Zero(t, svcPorts["rpc-port"].NodePort)
+
+	// the specified NodePort is retained for NodePort service
+	cr.Spec.FE.Service.Type = corev1.ServiceTypeNodePort
+	cr.Spec.FE.Service.RpcPort = util.Pointer[int32](30004)
+	svcPorts = ports()
+	assert.Equal(t, int32(30004), svcPorts["rpc-port"].NodePort)
+	assert.Zero(t, svcPorts["edit-log-port"].NodePort)
+
+	// not exposed by default
+	cr.Spec.FE.Service = &dapi.FeServiceSpec{Type: corev1.ServiceTypeClusterIP}
+	assert.NotContains(t, ports(), "rpc-port")
+	assert.NotContains(t, ports(), "edit-log-port")
+}
+
+func TestMakeFeServiceLoadBalancer(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Service = &dapi.FeServiceSpec{
+		Type:                     corev1.ServiceTypeLoadBalancer,
+		Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"},
+		LoadBalancerClass:        util.Pointer("service.k8s.aws/nlb"),
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+	}
+	svc := MakeFeService(cr, runtime.NewScheme())
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, cr.Spec.FE.Service.Annotations, svc.Annotations)
+	assert.Equal(t, "service.k8s.aws/nlb", *svc.Spec.LoadBalancerClass)
+	assert.Equal(t, []string{"10.0.0.0/8"}, svc.Spec.LoadBalancerSourceRanges)
+
+	// load balancer options are ignored for other service types
+	cr.Spec.FE.Service.Type = corev1.ServiceTypeNodePort
+	svc = MakeFeService(cr, runtime.NewScheme())
+	assert.Equal(t, cr.Spec.FE.Service.Annotations, svc.Annotations)
+	assert.Nil(t, svc.Spec.LoadBalancerClass)
+	assert.Nil(t, svc.Spec.LoadBalancerSourceRanges)
+}
+
+func TestMakeFePodServices(t *testing.T) {
+	cr := newTestDorisCluster()
+	assert.Nil(t, MakeFePodServices(cr, runtime.NewScheme()))
+
+	cr.Spec.FE.PodService = &dapi.FePodServiceSpec{
+		Type:              corev1.ServiceTypeLoadBalancer,
+		LoadBalancerClass: util.Pointer("service.k8s.aws/nlb"),
+	}
+	services := MakeFePodServices(cr, runtime.NewScheme())
+	assert.Len(t, services, 3)
+	for i, svc := range services {
+		assert.Equal(t, fmt.Sprintf("test-fe-%d", i), svc.Name)
+		assert.Equal(t, strconv.Itoa(i), svc.Labels[FePodServiceOrdinalLabelKey])
+		assert.Equal(t, fmt.Sprintf("test-fe-%d", i), svc.Spec.Selector[appv1.StatefulSetPodNameLabel])
+		assert.Equal(t, "fe", svc.Spec.Selector[K8sComponentLabelKey])
+		assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+		assert.Equal(t, "service.k8s.aws/nlb", *svc.Spec.LoadBalancerClass)
+		assert.Equal(t, []string{"http-port", "query-port"}, []string{svc.Spec.Ports[0].Name, svc.Spec.Ports[1].Name})
+	}
+
+	cr.Spec.FE.PodService = &dapi.FePodServiceSpec{}
+	cr.Spec.FE.Replicas = 1
+	services = MakeFePodServices(cr, runtime.NewScheme())
+	assert.Len(t, services, 1)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, services[0].Spec.Type)
+}
+
+func TestMakeFeStatefulSetPodMeta(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.PodLabels = map[string]string{
+		"sidecar.istio.io/inject": "true",
+		K8sComponentLabelKey:      "custom",
+	}
+	cr.Spec.FE.PodAnnotations = map[string]string{"cost-center": "doris"}
+
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	podMeta := sts.Spec.Template.ObjectMeta
+	assert.Equal(t, "true", podMeta.Labels["sidecar.istio.io/inject"])
+	assert.Equal(t, "doris", podMeta.Annotations["cost-center"])
+	assert.Equal(t, "true", podMeta.Annotations[PrometheusScrapeAnnoKey])
+	// user labels should not clobber the selector labels
+	for k, v := range sts.Spec.Selector.MatchLabels {
+		assert.Equal(t, v, podMeta.Labels[k])
+	}
+	assert.Equal(t, "fe", podMeta.Labels[K8sComponentLabelKey])
+}
+
+func TestMakeFeServiceAndConfigMapMeta(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.ServiceLabels = map[string]string{"network-policy": "doris", K8sComponentLabelKey: "custom"}
+	cr.Spec.FE.ServiceAnnotations = map[string]string{"cost-center": "doris", "lb-scheme": "default"}
+	cr.Spec.FE.ConfigMapLabels = map[string]string{"team": "olap", K8sComponentLabelKey: "custom"}
+	cr.Spec.FE.ConfigMapAnnotations = map[string]string{"cost-center": "doris"}
+	cr.Spec.FE.Service = &dapi.FeServiceSpec{Annotations: map[string]string{"lb-scheme": "internal"}}
+
+	// the managed labels and the dedicated service annotations take precedence
+	svc := MakeFeService(cr, runtime.NewScheme())
+	assert.Equal(t, "doris", svc.Labels["network-policy"])
+	assert.Equal(t, "fe", svc.Labels[K8sComponentLabelKey])
+	assert.Equal(t, GetFeComponentLabels(cr.ObjKey()), svc.Spec.Selector)
+	assert.Equal(t, map[string]string{"cost-center": "doris", "lb-scheme": "internal"}, svc.Annotations)
+
+	peerSvc := MakeFePeerService(cr, runtime.NewScheme())
+	assert.Equal(t, "doris", peerSvc.Labels["network-policy"])
+	assert.Equal(t, "fe", peerSvc.Labels[K8sComponentLabelKey])
+	for k, v := range PeerServiceLabels {
+		assert.Equal(t, v, peerSvc.Labels[k])
+	}
+	assert.Equal(t, cr.Spec.FE.ServiceAnnotations, peerSvc.Annotations)
+
+	configMap := MakeFeConfigMap(cr, runtime.NewScheme(), nil)
+	assert.Equal(t, "olap", configMap.Labels["team"])
+	assert.Equal(t, "fe", configMap.Labels[K8sComponentLabelKey])
+	assert.Equal(t, map[string]string{"cost-center": "doris"}, configMap.Annotations)
+
+	// nothing is added by default
+	cr = newTestDorisCluster()
+	assert.Equal(t, GetFeComponentLabels(cr.ObjKey()), MakeFeService(cr, runtime.NewScheme()).Labels)
+	assert.Nil(t, MakeFeService(cr, runtime.NewScheme()).Annotations)
+	assert.Nil(t, MakeFeConfigMap(cr, runtime.NewScheme(), nil).Annotations)
+}
+
+func TestMakeFeStatefulSetTopologySpreadConstraints(t *testing.T) {
+	// default constraints spread across nodes
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	constraints := sts.Spec.Template.Spec.TopologySpreadConstraints
+	assert.Len(t, constraints, 1)
+	assert.Equal(t, "kubernetes.io/hostname", constraints[0].TopologyKey)
+	assert.Equal(t, sts.Spec.Selector.MatchLabels, constraints[0].LabelSelector.MatchLabels)
+
+	// no default constraints for single replica
+	cr.Spec.FE.Replicas = 1
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Empty(t, sts.Spec.Template.Spec.TopologySpreadConstraints)
+
+	// cluster-level constraints overridden by component-level
+	zoneConstraint := corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone"}
+	rackConstraint := corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: "rack"}
+	cr.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{zoneConstraint}
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, []corev1.TopologySpreadConstraint{zoneConstraint}, sts.Spec.Template.Spec.TopologySpreadConstraints)
+
+	cr.Spec.FE.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{rackConstraint}
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, []corev1.TopologySpreadConstraint{rackConstraint}, sts.Spec.Template.Spec.TopologySpreadConstraints)
+}
+
+func TestMakeFeConfigMapWithRefConfigs(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Configs = map[string]string{"http_port": "8080", "qe_max_connection": "2048"}
+	refConfigs := ParseComponentConf("# comment\nhttp_port = 8031\nsys_log_level=INFO\nJAVA_OPTS=\"-Xmx8g -Dfoo=bar\"\n")
+	assert.Equal(t, "-Xmx8g -Dfoo=bar", refConfigs[JvmOptKey])
+
+	configMap := MakeFeConfigMap(cr, runtime.NewScheme(), refConfigs)
+	configs := ParseComponentConf(configMap.Data[FeConfFileKey])
+	// inline configs take precedence
+	assert.Equal(t, "8080", configs["http_port"])
+	assert.Equal(t, "2048", configs["qe_max_connection"])
+	assert.Equal(t, "INFO", configs["sys_log_level"])
+	assert.Equal(t, "true", configs["enable_fqdn_mode"])
+	assert.NotContains(t, configs[JvmOptKey], "-Xmx8g")
+	// the spec configs should not be mutated
+	assert.NotContains(t, cr.Spec.FE.Configs, "enable_fqdn_mode")
+}
+
+func TestMakeFeConfigMapWithConfigFileContent(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.ConfigFileContent = "# custom fe.conf\nhttp_port = 8031\nsys_log_level = WARN\nJAVA_OPTS=\"-Xmx8g -Dfoo=bar\"\n"
+	cr.Spec.FE.Configs = map[string]string{"sys_log_level": "INFO"}
+
+	conf := MakeFeConfigMap(cr, runtime.NewScheme(), nil).Data[FeConfFileKey]
+	// the raw content is kept verbatim at the head of the config file
+	assert.True(t, strings.HasPrefix(conf, "# custom fe.conf\nhttp_port = 8031\nsys_log_level = WARN\n"))
+	// the later configs override the raw content
+	configs := ParseComponentConf(conf)
+	assert.Equal(t, "8031", configs["http_port"])
+	assert.Equal(t, "INFO", configs["sys_log_level"])
+	assert.Contains(t, configs[JvmOptKey], "-Dfoo=bar")
+	assert.NotContains(t, configs[JvmOptKey], "-Xmx8g")
+	// the ports are resolved from the raw content too
+	assert.Equal(t, int32(8031), GetFeHttpPort(cr))
+}
+
+func TestMakeFeStatefulSetLeaderAwareRollout(t *testing.T) {
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, appv1.RollingUpdateStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+
+	cr.Spec.FE.LeaderAwareRollout = true
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, appv1.OnDeleteStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+}
+
+func TestMakeFeStatefulSetPartition(t *testing.T) {
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Nil(t, sts.Spec.UpdateStrategy.RollingUpdate)
+
+	cr.Spec.FE.Partition = util.Pointer(int32(2))
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, appv1.RollingUpdateStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+	assert.Equal(t, int32(2), *sts.Spec.UpdateStrategy.RollingUpdate.Partition)
+
+	// partition is ignored when the pods are not updated by the RollingUpdate strategy
+	cr.Spec.FE.LeaderAwareRollout = true
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, appv1.OnDeleteStatefulSetStrategyType, sts.Spec.UpdateStrategy.Type)
+	assert.Nil(t, sts.Spec.UpdateStrategy.RollingUpdate)
+}
+
+func TestMakeFeStatefulSetShareLogVolume(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.AdditionalContainers = []corev1.Container{
+		{Name: "filebeat", Image: "elastic/filebeat"},
+		{Name: "agent", Image: "agent", VolumeMounts: []corev1.VolumeMount{{Name: "fe-log", MountPath: "/logs"}}},
+	}
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Empty(t, sts.Spec.Template.Spec.Containers[1].VolumeMounts)
+
+	cr.Spec.FE.ShareLogVolume = true
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	containers := sts.Spec.Template.Spec.Containers
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "fe-log", MountPath: "/opt/apache-doris/fe/log", ReadOnly: true},
+	}, containers[1].VolumeMounts)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "fe-log", MountPath: "/logs"}}, containers[2].VolumeMounts)
+	// the containers of spec are not mutated
+	assert.Empty(t, cr.Spec.FE.AdditionalContainers[0].VolumeMounts)
+}
+
+func TestMakeFeStatefulSetResources(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("1"),
+		corev1.ResourceStorage: resource.MustParse("10Gi"),
+	}
+	cr.Spec.FE.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		},
+	}, sts.Spec.Template.Spec.Containers[0].Resources)
+	assert.Equal(t, resource.MustParse("10Gi"), sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+}
+
+func TestMakeFePodDisruptionBudget(t *testing.T) {
+	cr := newTestDorisCluster()
+	pdb := MakeFePodDisruptionBudget(cr, runtime.NewScheme())
+	assert.Equal(t, "test-fe", pdb.Name)
+	assert.Equal(t, intstr.FromInt(2), *pdb.Spec.MinAvailable)
+	assert.Nil(t, pdb.Spec.MaxUnavailable)
+	assert.Equal(t, GetFeComponentLabels(cr.ObjKey()), pdb.Spec.Selector.MatchLabels)
+
+	maxUnavailable := intstr.FromString("50%")
+	cr.Spec.FE.PodDisruptionBudget = &dapi.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}
+	pdb = MakeFePodDisruptionBudget(cr, runtime.NewScheme())
+	assert.Nil(t, pdb.Spec.MinAvailable)
+	assert.Equal(t, maxUnavailable, *pdb.Spec.MaxUnavailable)
+
+	cr.Spec.FE.PodDisruptionBudget = nil
+	cr.Spec.FE.Replicas = 1
+	assert.Nil(t, MakeFePodDisruptionBudget(cr, runtime.NewScheme()))
+}
+
+func TestMakeFeServiceMonitor(t *testing.T) {
+	cr := newTestDorisCluster()
+	assert.Nil(t, MakeFeServiceMonitor(cr, runtime.NewScheme()))
+
+	cr.Spec.EnableServiceMonitor = true
+	monitor := MakeFeServiceMonitor(cr, runtime.NewScheme())
+	assert.Equal(t, ServiceMonitorGVK, monitor.GroupVersionKind())
+	assert.Equal(t, "test-fe", monitor.GetName())
+	endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	assert.Equal(t, []any{map[string]any{"port": "http-port", "path": "/metrics"}}, endpoints)
+	// the peer service should be excluded
+	peerSvc := MakeFePeerService(cr, runtime.NewScheme())
+	for k := range PeerServiceLabels {
+		assert.Contains(t, peerSvc.Labels, k)
+	}
+}
+
+func TestMakeFeStatefulSetObservers(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Replicas = 5
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	for _, env := range sts.Spec.Template.Spec.Containers[0].Env {
+		assert.NotEqual(t, "FE_FOLLOWER_NUM", env.Name)
+	}
+	assert.Equal(t, intstr.FromInt(3), *MakeFePodDisruptionBudget(cr, runtime.NewScheme()).Spec.MinAvailable)
+
+	cr.Spec.FE.Followers = util.Pointer(int32(3))
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "FE_FOLLOWER_NUM", Value: "3"})
+	// only one pod can be evicted when there are observers
+	pdb := MakeFePodDisruptionBudget(cr, runtime.NewScheme())
+	assert.Nil(t, pdb.Spec.MinAvailable)
+	assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MaxUnavailable)
+}
+
+func TestMakeFeStatefulSetOprSqlAccountSecretRef(t *testing.T) {
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource("test-opr-account", "password"),
+	})
+
+	cr.Spec.OprSqlAccountSecretRef = &corev1.LocalObjectReference{Name: "my-account"}
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "my-account"}, GetOprSqlAccountSecretRef(cr))
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name: "ACC_PWD", ValueFrom: util.NewEnvVarSecretSource("my-account", "password"),
+	})
+}
+
+func TestMakeFeStatefulSetNodeSelector(t *testing.T) {
+	cr := newTestDorisCluster()
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Empty(t, sts.Spec.Template.Spec.NodeSelector)
+
+	cr.Spec.NodeSelector = map[string]string{"pool": "doris", "disk": "hdd"}
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, map[string]string{"pool": "doris", "disk": "hdd"}, sts.Spec.Template.Spec.NodeSelector)
+
+	// component keys take precedence
+	cr.Spec.FE.NodeSelector = map[string]string{"disk": "ssd", "zone": "a"}
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, map[string]string{"pool": "doris", "disk": "ssd", "zone": "a"}, sts.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, map[string]string{"pool": "doris", "disk": "hdd"}, cr.Spec.NodeSelector)
+}
+
+func TestMakeFeStatefulSetDefaultAntiAffinity(t *testing.T) {
+	cr := newTestDorisCluster()
+	feLabels := GetFeComponentLabels(cr.ResourceKey())
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	antiAffinity := sts.Spec.Template.Spec.Affinity.PodAntiAffinity
+	assert.Empty(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	assert.Len(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+	term := antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+	assert.Equal(t, corev1.LabelHostname, term.TopologyKey)
+	assert.Equal(t, feLabels, term.LabelSelector.MatchLabels)
+
+	cr.Spec.FE.DefaultAntiAffinity = dapi.AntiAffinityRequired
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	antiAffinity = sts.Spec.Template.Spec.Affinity.PodAntiAffinity
+	assert.Empty(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	assert.Len(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+	assert.Equal(t, feLabels, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels)
+
+	cr.Spec.FE.DefaultAntiAffinity = dapi.AntiAffinityNone
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Nil(t, sts.Spec.Template.Spec.Affinity)
+
+	// single FE replica
+	cr.Spec.FE.DefaultAntiAffinity = ""
+	cr.Spec.FE.Replicas = 1
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Nil(t, sts.Spec.Template.Spec.Affinity)
+}
+
+func TestMakeFeStatefulSetUserAffinity(t *testing.T) {
+	nodeAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"doris"}},
+			}}},
+		},
+	}}
+	// the user affinity replaces the default anti-affinity as a whole
+	cr := newTestDorisCluster()
+	cr.Spec.FE.DefaultAntiAffinity = dapi.AntiAffinityRequired
+	cr.Spec.Affinity = nodeAffinity
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, nodeAffinity, sts.Spec.Template.Spec.Affinity)
+
+	feAffinity := &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+	cr.Spec.FE.Affinity = feAffinity
+	sts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, feAffinity, sts.Spec.Template.Spec.Affinity)
+	assert.Nil(t, sts.Spec.Template.Spec.Affinity.PodAntiAffinity)
+}
+
+func TestMakeFeArrowFlightPort(t *testing.T) {
+	portNames := func(cr *dapi.DorisCluster) ([]string, []string) {
+		var svcPorts, containerPorts []string
+		for _, port := range MakeFeService(cr, runtime.NewScheme()).Spec.Ports {
+			svcPorts = append(svcPorts, port.Name)
+		}
+		for _, port := range MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].Ports {
+			containerPorts = append(containerPorts, port.Name)
+		}
+		return svcPorts, containerPorts
+	}
+	// absent
+	cr := newTestDorisCluster()
+	assert.Equal(t, int32(DefaultFeArrowFlightSqlPort), GetFeArrowFlightPort(cr))
+	svcPorts, containerPorts := portNames(cr)
+	assert.NotContains(t, svcPorts, "arrow-flight")
+	assert.NotContains(t, containerPorts, "arrow-flight")
+
+	// present
+	cr.Spec.FE.Configs = map[string]string{"arrow_flight_sql_port": "9090"}
+	assert.Equal(t, int32(9090), GetFeArrowFlightPort(cr))
+	svcPorts, containerPorts = portNames(cr)
+	assert.Contains(t, svcPorts, "arrow-flight")
+	assert.Contains(t, containerPorts, "arrow-flight")
+	assert.Equal(t, int32(9090), MakeFeService(cr, runtime.NewScheme()).Spec.Ports[2].Port)
+
+	// disabled explicitly
+	cr.Spec.FE.Configs = map[string]string{"arrow_flight_sql_port": "-1"}
+	svcPorts, _ = portNames(cr)
+	assert.NotContains(t, svcPorts, "arrow-flight")
+
+	// conflicts with other ports
+	cr.Spec.FE.Configs = map[string]string{"arrow_flight_sql_port": "9030"}
+	assert.Error(t, ValidateDorisCluster(cr))
+}
+
+func TestMakeFeTls(t *testing.T) {
+	cr := newTestDorisCluster()
+	conf := MakeFeConfigMap(cr, runtime.NewScheme(), nil).Data[FeConfFileKey]
+	assert.NotContains(t, conf, "enable_ssl")
+
+	cr.Spec.FE.TLS = &dapi.FeTLSSpec{SecretName: "fe-tls", ForceClientAuth: true}
+	conf = MakeFeConfigMap(cr, runtime.NewScheme(), nil).Data[FeConfFileKey]
+	assert.Contains(t, conf, "enable_ssl=true")
+	assert.Contains(t, conf, "ssl_force_client_auth=true")
+	assert.Contains(t, conf, "mysql_ssl_default_ca_certificate=/etc/apache-doris/fe-tls/ca.p12")
+	assert.Contains(t, conf, "mysql_ssl_default_server_certificate=/etc/apache-doris/fe-tls/server.p12")
+	assert.NotContains(t, conf, "password")
+
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	var tlsVolume *corev1.Volume
+	for i, volume := range sts.Spec.Template.Spec.Volumes {
+		if volume.Name == "fe-tls" {
+			tlsVolume = &sts.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	assert.NotNil(t, tlsVolume)
+	assert.Equal(t, "fe-tls", tlsVolume.Secret.SecretName)
+	container := sts.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "fe-tls", MountPath: FeTlsMountPath, ReadOnly: true})
+	envs := make(map[string]*corev1.EnvVarSource)
+	for _, env := range container.Env {
+		envs[env.Name] = env.ValueFrom
+	}
+	assert.Equal(t, FeTlsServerPasswordKey, envs["FE_SSL_SERVER_CERT_PASSWORD"].SecretKeyRef.Key)
+	assert.True(t, *envs["FE_SSL_CA_CERT_PASSWORD"].SecretKeyRef.Optional)
+
+	// empty secret name
+	cr.Spec.FE.TLS.SecretName = ""
+	assert.Error(t, ValidateDorisCluster(cr))
+}
+
+func TestMakeFeIngress(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE = &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-fe", Replicas: 3}}
+	assert.Nil(t, MakeFeIngress(cr, runtime.NewScheme()))
+
+	cr.Spec.FE.Ingress = &dapi.FeIngressSpec{
+		Host:             "doris.example.com",
+		IngressClassName: util.Pointer("nginx"),
+		TLSSecretName:    "doris-tls",
+		Annotations:      map[string]string{"nginx.ingress.kubernetes.io/ssl-redirect": "true"},
+	}
+	ingress := MakeFeIngress(cr, runtime.NewScheme())
+	assert.Equal(t, GetFeIngressKey(cr.ResourceKey()).Name, ingress.Name)
+	assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	assert.Equal(t, cr.Spec.FE.Ingress.Annotations, ingress.Annotations)
+	assert.Equal(t, "doris.example.com", ingress.Spec.Rules[0].Host)
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	assert.Equal(t, GetFeServiceKey(cr.ResourceKey()).Name, backend.Name)
+	assert.Equal(t, "http-port", backend.Port.Name)
+	assert.Equal(t, "doris-tls", ingress.Spec.TLS[0].SecretName)
+	assert.Equal(t, []string{"doris.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	cr.Spec.FE.Ingress.TLSSecretName = ""
+	assert.Empty(t, MakeFeIngress(cr, runtime.NewScheme()).Spec.TLS)
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strings"
 )
 
 const (
@@ -41,6 +42,10 @@ var (
 	GrafanaDashboardsConfContent = template.ReadOrPanic("monitor/grafana-dashboards.json")
 )
 
+// grafanaDashboardsDatasourceUIDs are the uids of the Prometheus datasource referenced by the
+// built-in Doris dashboards.
+var grafanaDashboardsDatasourceUIDs = []string{"PEB833E60655F2EBA", "gMxvgUxVk"}
+
 type GrafanaDataSourceTmplData struct {
 	PrometheusName      string
 	PrometheusNamespace string
@@ -65,6 +70,13 @@ func GetGrafanaConfigMapKey(monitorKey types.NamespacedName) types.NamespacedNam
 	}
 }
 
+func GetGrafanaDashboardsConfigMapKey(monitorKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: monitorKey.Namespace,
+		Name:      fmt.Sprintf("%s-grafana-dashboards", monitorKey.Name),
+	}
+}
+
 func GetGrafanaServiceKey(monitorKey types.NamespacedName) types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: monitorKey.Namespace,
@@ -120,6 +132,40 @@ func MakeGrafanaConfigMap(cr *dapi.DorisMonitor, scheme *runtime.Scheme) (*corev
 	return configMap, nil
 }
 
+// MakeGrafanaDashboardsConfigMap makes the ConfigMap of the Doris Grafana dashboards for the
+// dashboard sidecar of Grafana, returns nil when the dashboards provisioning is not enabled.
+func MakeGrafanaDashboardsConfigMap(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *corev1.ConfigMap {
+	if cr.Spec.Cluster == "" || cr.Spec.Dashboards == nil {
+		return nil
+	}
+	clusterRef := types.NamespacedName{
+		Namespace: cr.Namespace,
+		Name:      cr.Spec.Cluster,
+	}
+	configMapRef := GetGrafanaDashboardsConfigMapKey(cr.ObjKey())
+	dashboardLabels := util.Fallback(cr.Spec.Dashboards.Labels, map[string]string{"grafana_dashboard": "1"})
+
+	dashboards := GrafanaDashboardsConfContent
+	if uid := cr.Spec.Dashboards.DatasourceUID; uid != "" {
+		for _, builtinUID := range grafanaDashboardsDatasourceUIDs {
+			dashboards = strings.ReplaceAll(dashboards, fmt.Sprintf(`"uid": "%s"`, builtinUID), fmt.Sprintf(`"uid": "%s"`, uid))
+		}
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        configMapRef.Name,
+			Namespace:   configMapRef.Namespace,
+			Labels:      util.MergeMaps(dashboardLabels, GetGrafanaLabels(clusterRef)),
+			Annotations: cr.Spec.Dashboards.Annotations,
+		},
+		Data: map[string]string{
+			fmt.Sprintf("doris-%s-dashboards.json", cr.Spec.Cluster): dashboards,
+		},
+	}
+	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
+	return configMap
+}
+
 func MakeGrafanaSecret(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *corev1.Secret {
 	if cr.Spec.Cluster == "" {
 		return nil
@@ -139,8 +185,8 @@ func MakeGrafanaSecret(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *corev1.Se
 		},
 		Type: corev1.SecretTypeOpaque,
 		StringData: map[string]string{
-			"user":     util.StringFallback(cr.Spec.Grafana.AdminUser, "admin"),
-			"password": util.StringFallback(cr.Spec.Grafana.AdminPassword, "admin"),
+			"user":     util.Coalesce(cr.Spec.Grafana.AdminUser, "admin"),
+			"password": util.Coalesce(cr.Spec.Grafana.AdminPassword, "admin"),
 		},
 	}
 	_ = controllerutil.SetOwnerReference(cr, secret, scheme)
@@ -214,7 +260,7 @@ func MakeGrafanaStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv
 		Spec: corev1.PodSpec{
 			ServiceAccountName: cr.Spec.ServiceAccount,
 			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			NodeSelector:       util.MapFallback(cr.Spec.Grafana.NodeSelector, cr.Spec.NodeSelector),
+			NodeSelector:       util.Fallback(cr.Spec.Grafana.NodeSelector, cr.Spec.NodeSelector),
 			Volumes: []corev1.Volume{
 				{
 					Name: "grafana-datasource",
@@ -231,7 +277,7 @@ func MakeGrafanaStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv
 			},
 			Containers: []corev1.Container{{
 				Name:            "grafana",
-				Image:           util.StringFallback(cr.Spec.Grafana.Image, DefaultGrafanaImage),
+				Image:           util.Coalesce(cr.Spec.Grafana.Image, DefaultGrafanaImage),
 				ImagePullPolicy: cr.Spec.ImagePullPolicy,
 				Resources:       formatContainerResourcesRequirement(cr.Spec.Grafana.ResourceRequirements),
 				Ports: []corev1.ContainerPort{{
@@ -273,7 +319,7 @@ func MakeGrafanaStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			StorageClassName: util.PointerFallback(cr.Spec.Grafana.StorageClassName, cr.Spec.StorageClassName),
+			StorageClassName: util.Coalesce(cr.Spec.Grafana.StorageClassName, cr.Spec.StorageClassName),
 		},
 	}
 	if storageRequest := cr.Spec.Grafana.Requests.Storage(); storageRequest != nil {
@@ -0,0 +1,106 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import "strings"
+
+// FeHotReloadConfigKeys are the FE configs that can be modified at runtime via
+// "ADMIN SET ALL FRONTENDS CONFIG", the change of them is applied without restarting FE pods.
+// Only the mutable configs that are not master-only should be listed here.
+var FeHotReloadConfigKeys = []string{
+	"qe_slow_log_ms",
+	"max_query_retry_time",
+	"stream_load_default_timeout_second",
+	"max_stream_load_timeout_second",
+	"max_load_timeout_second",
+	"min_load_timeout_second",
+	"max_bytes_per_broker_scanner",
+	"default_max_filter_ratio",
+	"enable_local_replica_selection",
+	"max_allowed_in_element_num_of_delete",
+	"expr_children_limit",
+	"expr_depth_limit",
+}
+
+// BeHotReloadConfigKeys are the BE configs that can be modified at runtime via the
+// "/api/update_config" http api of BE, the change of them is applied without restarting BE pods.
+// Only the mutable configs of BE should be listed here.
+var BeHotReloadConfigKeys = []string{
+	"streaming_load_max_mb",
+	"streaming_load_json_max_mb",
+	"disable_auto_compaction",
+	"max_tablet_version_num",
+	"write_buffer_size",
+	"trash_file_expire_time_sec",
+	"pending_data_expire_time_sec",
+	"storage_flood_stage_usage_percent",
+	"storage_flood_stage_left_capacity_bytes",
+	"doris_scanner_row_num",
+	"doris_max_scan_key_num",
+	"max_pushdown_conditions_per_column",
+}
+
+// ExtractHotReloadConfigs returns the hot-reloadable config items in the config file content.
+func ExtractHotReloadConfigs(content string, hotKeys []string) map[string]string {
+	hotConfigs := make(map[string]string)
+	configs := ParseComponentConf(content)
+	for _, key := range hotKeys {
+		if value, ok := configs[key]; ok {
+			hotConfigs[key] = value
+		}
+	}
+	return hotConfigs
+}
+
+// StripHotReloadConfigs returns a copy of the ConfigMap data in which the values of the
+// hot-reloadable config items are removed from the config file, it is used to compute the
+// config hash of pod template so that the change of hot-reloadable config values does not
+// restart the pods. The keys are retained, so adding or removing a hot-reloadable config
+// still restarts the pods, which resets the removed config to its default value.
+func StripHotReloadConfigs(data map[string]string, fileKey string, hotKeys []string) map[string]string {
+	content, ok := data[fileKey]
+	if !ok {
+		return data
+	}
+	hotKeySet := make(map[string]bool, len(hotKeys))
+	for _, key := range hotKeys {
+		hotKeySet[key] = true
+	}
+	var lines []string
+	stripped := false
+	for _, line := range strings.Split(content, "\n") {
+		key, _, found := strings.Cut(line, "=")
+		if found && hotKeySet[strings.TrimSpace(key)] {
+			stripped = true
+			lines = append(lines, strings.TrimSpace(key)+"=")
+			continue
+		}
+		lines = append(lines, line)
+	}
+	// keep the data untouched to retain the previous config hash
+	if !stripped {
+		return data
+	}
+	copied := make(map[string]string, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+	copied[fileKey] = strings.Join(lines, "\n")
+	return copied
+}
@@ -0,0 +1,49 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestHotReloadConfigs(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 1)
+	beConfHash := func(configs map[string]string) string {
+		cr.Spec.BE.Configs = configs
+		data := MakeBeConfigMap(cr, runtime.NewScheme(), nil).Data
+		return util.ConfigHash(StripHotReloadConfigs(data, BeConfFileKey, BeHotReloadConfigKeys))
+	}
+	// the config hash is retained when there are no hot-reloadable configs
+	data := MakeBeConfigMap(cr, runtime.NewScheme(), nil).Data
+	assert.Equal(t, util.ConfigHash(data), beConfHash(nil))
+
+	// the change of hot-reloadable config values does not change the config hash
+	base := beConfHash(map[string]string{"be_port": "9060", "streaming_load_max_mb": "10240"})
+	assert.Equal(t, base, beConfHash(map[string]string{"be_port": "9060", "streaming_load_max_mb": "20480"}))
+	// while removing a hot-reloadable config does, so that it is reset by restarting
+	assert.NotEqual(t, base, beConfHash(map[string]string{"be_port": "9060"}))
+	// while the change of other configs does
+	assert.NotEqual(t, base, beConfHash(map[string]string{"be_port": "9061", "streaming_load_max_mb": "10240"}))
+
+	assert.Equal(t, map[string]string{"qe_slow_log_ms": "3000"},
+		ExtractHotReloadConfigs("http_port = 8030\nqe_slow_log_ms = 3000\n# max_query_retry_time = 3", FeHotReloadConfigKeys))
+}
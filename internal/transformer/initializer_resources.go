@@ -67,7 +67,7 @@ func GetInitializerJobKey(initKey types.NamespacedName) types.NamespacedName {
 }
 
 func GetInitializerImage(r *dapi.DorisInitializer) string {
-	return util.StringFallback(r.Spec.Image, DefaultMysqlclientImage)
+	return util.Coalesce(r.Spec.Image, DefaultMysqlclientImage)
 }
 
 func MakeInitializerSecret(cr *dapi.DorisInitializer, scheme *runtime.Scheme) *corev1.Secret {
@@ -113,19 +113,16 @@ func MakeInitializerConfigMap(cr *dapi.DorisInitializer, scheme *runtime.Scheme)
 
 }
 
-func MakeInitializerJob(cr *dapi.DorisInitializer, feSvcQueryPort int32, scheme *runtime.Scheme) *batchv1.Job {
+// MakeInitializerJob makes the job to initialize the Doris cluster, feSvcHost is
+// the FE service DNS of the target DorisCluster.
+func MakeInitializerJob(cr *dapi.DorisInitializer, feSvcHost string, feSvcQueryPort int32,
+	accountSecretRef types.NamespacedName, scheme *runtime.Scheme) *batchv1.Job {
 	if cr.Spec.Cluster == "" {
 		return nil
 	}
-	clusterRef := types.NamespacedName{
-		Namespace: cr.Namespace,
-		Name:      cr.Spec.Cluster,
-	}
 	jobRef := GetInitializerJobKey(cr.ObjKey())
 	secretRef := GetInitializerSecretKey(cr.ObjKey())
 	configMapRef := GetInitializerConfigMapKey(cr.ObjKey())
-	feSvcRef := GetFeServiceKey(clusterRef)
-	accountSecretRef := GetOprSqlAccountSecretKey(clusterRef)
 
 	initLabels := GetInitializerLabels(cr.Spec.Cluster)
 	image := GetInitializerImage(cr)
@@ -174,7 +171,7 @@ func MakeInitializerJob(cr *dapi.DorisInitializer, feSvcQueryPort int32, scheme
 		Env: []corev1.EnvVar{
 			{
 				Name:  "FE_SVC",
-				Value: feSvcRef.Name,
+				Value: feSvcHost,
 			}, {
 				Name:  "FE_QUERY_PORT",
 				Value: strconv.Itoa(int(feSvcQueryPort)),
@@ -207,7 +204,7 @@ func MakeInitializerJob(cr *dapi.DorisInitializer, feSvcQueryPort int32, scheme
 		Env: []corev1.EnvVar{
 			{
 				Name:  "FE_SVC",
-				Value: feSvcRef.Name,
+				Value: feSvcHost,
 			}, {
 				Name:  "FE_QUERY_PORT",
 				Value: strconv.Itoa(int(feSvcQueryPort)),
@@ -156,7 +156,7 @@ func MakeLokiStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv1.S
 		Spec: corev1.PodSpec{
 			ServiceAccountName: cr.Spec.ServiceAccount,
 			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			NodeSelector:       util.MapFallback(cr.Spec.Loki.NodeSelector, cr.Spec.NodeSelector),
+			NodeSelector:       util.Fallback(cr.Spec.Loki.NodeSelector, cr.Spec.NodeSelector),
 			Volumes: []corev1.Volume{{
 				Name: "config",
 				VolumeSource: util.NewConfigMapItemsVolumeSource(
@@ -164,7 +164,7 @@ func MakeLokiStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv1.S
 			}},
 			Containers: []corev1.Container{{
 				Name:            "loki",
-				Image:           util.StringFallback(cr.Spec.Loki.Image, DefaultLokiImage),
+				Image:           util.Coalesce(cr.Spec.Loki.Image, DefaultLokiImage),
 				ImagePullPolicy: cr.Spec.ImagePullPolicy,
 				Resources:       formatContainerResourcesRequirement(cr.Spec.Loki.ResourceRequirements),
 				Args:            []string{"-config.file=/etc/loki/loki.yml"},
@@ -208,7 +208,7 @@ func MakeLokiStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv1.S
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			StorageClassName: util.PointerFallback(cr.Spec.Loki.StorageClassName, cr.Spec.StorageClassName),
+			StorageClassName: util.Coalesce(cr.Spec.Loki.StorageClassName, cr.Spec.StorageClassName),
 		},
 	}
 	if storageRequest := cr.Spec.Loki.Requests.Storage(); storageRequest != nil {
@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The recommended PriorityClasses of FE and BE, the FE pods hold the metadata of the whole cluster
+// and should be the last to be preempted, while the CN and Broker pods keep the default priority
+// since they are stateless and disposable.
+const (
+	DefaultFePriorityClassName  = "doris-fe-critical"
+	DefaultFePriorityClassValue = int32(1000000)
+	DefaultBePriorityClassName  = "doris-be-high"
+	DefaultBePriorityClassValue = int32(100000)
+)
+
+// EnableDefaultPriorityClasses makes the FE and BE pods use the recommended PriorityClasses when
+// the priorityClassName is not specified, and the operator creates these PriorityClasses when they
+// do not exist. It can be overridden by the flags of operator.
+var EnableDefaultPriorityClasses = false
+
+// GetFePriorityClassName returns the PriorityClass name of FE pods, which falls back to the
+// cluster one, and then the recommended PriorityClass when it is enabled.
+func GetFePriorityClassName(cr *dapi.DorisCluster) string {
+	return util.Coalesce(cr.Spec.FE.PriorityClassName, cr.Spec.PriorityClassName,
+		util.Elvis(EnableDefaultPriorityClasses, DefaultFePriorityClassName, ""))
+}
+
+// GetBePriorityClassName returns the PriorityClass name of BE pods, which falls back to the
+// cluster one, and then the recommended PriorityClass when it is enabled.
+func GetBePriorityClassName(cr *dapi.DorisCluster) string {
+	return util.Coalesce(cr.Spec.BE.PriorityClassName, cr.Spec.PriorityClassName,
+		util.Elvis(EnableDefaultPriorityClasses, DefaultBePriorityClassName, ""))
+}
+
+// MakeDefaultPriorityClasses makes the recommended PriorityClasses referenced by the FE and BE
+// pods of the DorisCluster. They are cluster-scoped and shared by all DorisClusters, so that no
+// owner reference is set on them.
+func MakeDefaultPriorityClasses(cr *dapi.DorisCluster) []*schedulingv1.PriorityClass {
+	if !EnableDefaultPriorityClasses {
+		return nil
+	}
+	var classes []*schedulingv1.PriorityClass
+	if cr.Spec.FE != nil && GetFePriorityClassName(cr) == DefaultFePriorityClassName {
+		classes = append(classes, makePriorityClass(DefaultFePriorityClassName, DefaultFePriorityClassValue,
+			"The priority of Doris FE pods managed by doris-operator."))
+	}
+	if cr.Spec.BE != nil && GetBePriorityClassName(cr) == DefaultBePriorityClassName {
+		classes = append(classes, makePriorityClass(DefaultBePriorityClassName, DefaultBePriorityClassValue,
+			"The priority of Doris BE pods managed by doris-operator."))
+	}
+	return classes
+}
+
+func makePriorityClass(name string, value int32, description string) *schedulingv1.PriorityClass {
+	preemptionPolicy := corev1.PreemptLowerPriority
+	return &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				K8sNameLabelKey:      DorisK8sNameLabelValue,
+				K8sManagedByLabelKey: DorisK8sManagedByLabelValue,
+			},
+		},
+		Value:            value,
+		PreemptionPolicy: &preemptionPolicy,
+		Description:      description,
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestPriorityClassAndPreemptionPolicyPropagation(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.CN = &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-cn", Replicas: 1}}
+	cr.Spec.PriorityClassName = "doris"
+	cr.Spec.PreemptionPolicy = util.Pointer(corev1.PreemptLowerPriority)
+	cr.Spec.FE.PriorityClassName = "doris-fe"
+	cr.Spec.CN.PreemptionPolicy = util.Pointer(corev1.PreemptNever)
+
+	fePod := MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, "doris-fe", fePod.PriorityClassName)
+	assert.Equal(t, corev1.PreemptLowerPriority, *fePod.PreemptionPolicy)
+
+	cnPod := MakeCnStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, "doris", cnPod.PriorityClassName)
+	assert.Equal(t, corev1.PreemptNever, *cnPod.PreemptionPolicy)
+
+	cr.Spec.PreemptionPolicy = nil
+	bePod := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, "doris", bePod.PriorityClassName)
+	assert.Nil(t, bePod.PreemptionPolicy)
+}
+
+func TestMakeDefaultPriorityClasses(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	assert.Empty(t, MakeDefaultPriorityClasses(cr))
+	assert.Equal(t, "", GetFePriorityClassName(cr))
+
+	EnableDefaultPriorityClasses = true
+	defer func() { EnableDefaultPriorityClasses = false }()
+	assert.Equal(t, DefaultFePriorityClassName, GetFePriorityClassName(cr))
+	assert.Equal(t, DefaultBePriorityClassName, GetBePriorityClassName(cr))
+	classes := MakeDefaultPriorityClasses(cr)
+	assert.Len(t, classes, 2)
+	assert.Equal(t, DefaultFePriorityClassName, classes[0].Name)
+	assert.Equal(t, DefaultFePriorityClassValue, classes[0].Value)
+	assert.Equal(t, DefaultBePriorityClassName, classes[1].Name)
+
+	// the specified PriorityClass takes precedence over the recommended one
+	cr.Spec.BE.PriorityClassName = "custom"
+	assert.Equal(t, "custom", GetBePriorityClassName(cr))
+	classes = MakeDefaultPriorityClasses(cr)
+	assert.Len(t, classes, 1)
+	assert.Equal(t, DefaultFePriorityClassName, classes[0].Name)
+}
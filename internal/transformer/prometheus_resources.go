@@ -39,6 +39,13 @@ var (
 	PrometheusConfTmpl = template.NewTemplateOrPanic("prometheus-conf", "monitor/prometheus.yml")
 )
 
+// PrometheusConfTmplData is the data of the prometheus.yml template.
+type PrometheusConfTmplData struct {
+	Namespace string
+	Name      string
+	RuleFiles []string
+}
+
 func GetMonitorPrometheusLabels(dorisClusterKey types.NamespacedName) map[string]string {
 	return MakeResourceLabels(dorisClusterKey.Name, "prometheus")
 }
@@ -74,7 +81,18 @@ func MakePrometheusConfigMap(cr *dapi.DorisMonitor, scheme *runtime.Scheme) (*co
 	}
 	configMapRef := GetPrometheusConfigMapKey(cr.ObjKey())
 	labels := GetMonitorPrometheusLabels(clusterRef)
-	promConfContent, err := template.ExecTemplate(PrometheusConfTmpl, clusterRef)
+	alertRulesContent, err := MakePrometheusAlertRulesFile(cr)
+	if err != nil {
+		return nil, err
+	}
+	tmplData := PrometheusConfTmplData{
+		Namespace: clusterRef.Namespace,
+		Name:      clusterRef.Name,
+	}
+	if alertRulesContent != "" {
+		tmplData.RuleFiles = []string{"/etc/prometheus/" + PrometheusAlertRulesFileKey}
+	}
+	promConfContent, err := template.ExecTemplate(PrometheusConfTmpl, tmplData)
 	if err != nil {
 		return nil, util.MergeErrors(fmt.Errorf("fail to parse prometheus.conf template"), err)
 	}
@@ -89,6 +107,9 @@ func MakePrometheusConfigMap(cr *dapi.DorisMonitor, scheme *runtime.Scheme) (*co
 			"prometheus.yml": promConfContent,
 		},
 	}
+	if alertRulesContent != "" {
+		configMap.Data[PrometheusAlertRulesFileKey] = alertRulesContent
+	}
 	_ = controllerutil.SetOwnerReference(cr, configMap, scheme)
 	return configMap, nil
 }
@@ -149,6 +170,10 @@ func MakePrometheusStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *a
 	labels := GetMonitorPrometheusLabels(clusterRef)
 
 	replicas := int32(1)
+	configItems := map[string]string{"prometheus.yml": "prometheus.yml"}
+	if cr.Spec.AlertRules != nil {
+		configItems[PrometheusAlertRulesFileKey] = PrometheusAlertRulesFileKey
+	}
 	// prometheus args
 	promArgs := []string{
 		"--config.file=/etc/prometheus/prometheus.yml",
@@ -170,15 +195,15 @@ func MakePrometheusStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *a
 		Spec: corev1.PodSpec{
 			ServiceAccountName: MonitorNamespacedAccountName,
 			ImagePullSecrets:   cr.Spec.ImagePullSecrets,
-			NodeSelector:       util.MapFallback(cr.Spec.Prometheus.NodeSelector, cr.Spec.NodeSelector),
+			NodeSelector:       util.Fallback(cr.Spec.Prometheus.NodeSelector, cr.Spec.NodeSelector),
 			Volumes: []corev1.Volume{{
 				Name: "prometheus-config",
 				VolumeSource: util.NewConfigMapItemsVolumeSource(
-					configMapRef.Name, map[string]string{"prometheus.yml": "prometheus.yml"}),
+					configMapRef.Name, configItems),
 			}},
 			Containers: []corev1.Container{{
 				Name:            "prometheus",
-				Image:           util.StringFallback(cr.Spec.Prometheus.Image, DefaultPrometheusImage),
+				Image:           util.Coalesce(cr.Spec.Prometheus.Image, DefaultPrometheusImage),
 				ImagePullPolicy: cr.Spec.ImagePullPolicy,
 				Resources:       formatContainerResourcesRequirement(cr.Spec.Prometheus.ResourceRequirements),
 				Args:            promArgs,
@@ -215,7 +240,7 @@ func MakePrometheusStatefulset(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *a
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			StorageClassName: util.PointerFallback(cr.Spec.Prometheus.StorageClassName, cr.Spec.StorageClassName),
+			StorageClassName: util.Coalesce(cr.Spec.Prometheus.StorageClassName, cr.Spec.StorageClassName),
 		},
 	}
 	if storageRequest := cr.Spec.Prometheus.Requests.Storage(); storageRequest != nil {
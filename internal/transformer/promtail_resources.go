@@ -140,7 +140,7 @@ func MakePromtailDaemonSet(cr *dapi.DorisMonitor, scheme *runtime.Scheme) *appv1
 			},
 			Containers: []corev1.Container{{
 				Name:            "promtail",
-				Image:           util.StringFallback(cr.Spec.Promtail.Image, DefaultPromtailImage),
+				Image:           util.Coalesce(cr.Spec.Promtail.Image, DefaultPromtailImage),
 				ImagePullPolicy: cr.Spec.ImagePullPolicy,
 				Resources:       cr.Spec.Promtail.ResourceRequirements,
 				Args:            []string{"-config.file=/etc/promtail/promtail.yaml"},
@@ -24,8 +24,14 @@ import (
 	dapi "github.com/al-assad/doris-operator/api/v1beta1"
 	"github.com/al-assad/doris-operator/internal/util"
 	u "github.com/rjNemo/underscore"
+	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"math/big"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"strconv"
 	"strings"
 )
@@ -44,10 +50,130 @@ const (
 	PrometheusScrapeAnnoKey = "prometheus.io/scrape"
 
 	DefaultBusyBoxImage = "busybox:1.36"
+
+	DefaultClusterDomain = "cluster.local"
+
+	// HadoopCredentialMountPath is where the keys of the Hadoop credential secret are mounted.
+	HadoopCredentialMountPath  = "/etc/apache-doris/hadoop-credentials/"
+	HadoopCredentialVolumeName = "hadoop-credentials"
+	Krb5ConfPath               = "/etc/krb5.conf"
+)
+
+var (
+	// ClusterOwnerNamespaceLabelKey and ClusterOwnerNameLabelKey record the owner DorisCluster of
+	// the sub resources placed in another namespace, where owner references are not allowed.
+	ClusterOwnerNamespaceLabelKey = fmt.Sprintf("%s/owner-namespace", dapi.GroupVersion.Group)
+	ClusterOwnerNameLabelKey      = fmt.Sprintf("%s/owner-name", dapi.GroupVersion.Group)
+)
+
+// Operator-wide default base images of Doris components, which are used when the baseImage of
+// the component is not specified, and can be overridden by the flags of operator.
+var (
+	DefaultFeBaseImage     = "ghcr.io/linsoss/doris-fe"
+	DefaultBeBaseImage     = "ghcr.io/linsoss/doris-be"
+	DefaultCnBaseImage     = "ghcr.io/linsoss/doris-cn"
+	DefaultBrokerBaseImage = "ghcr.io/linsoss/doris-broker"
 )
 
 func GetBusyBoxImage(cr *dapi.DorisCluster) string {
-	return util.PointerDeRefer(cr.Spec.BusyBoxImage, DefaultBusyBoxImage)
+	return withImageRegistry(cr, util.PointerDeRefer(cr.Spec.BusyBoxImage, DefaultBusyBoxImage))
+}
+
+// GetHelperImage returns the image of the operator-injected init containers,
+// which defaults to the image of the component.
+func GetHelperImage(cr *dapi.DorisCluster, componentImage string) string {
+	if image := util.PointerDeRefer(cr.Spec.HelperImage, ""); image != "" {
+		return withImageRegistry(cr, image)
+	}
+	return componentImage
+}
+
+// withImageRegistry prefixes the spec.imageRegistry to the image that does not specify a registry host,
+// the bare library image of Docker Hub is placed under the "library" repository of the registry.
+func withImageRegistry(cr *dapi.DorisCluster, image string) string {
+	registry := strings.TrimSuffix(cr.Spec.ImageRegistry, "/")
+	if registry == "" || image == "" || hasImageRegistryHost(image) {
+		return image
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	return registry + "/" + image
+}
+
+// hasImageRegistryHost checks whether the image reference specifies a registry host, following the
+// rule of docker that the first path component is a host when it contains "." or ":", or is "localhost".
+func hasImageRegistryHost(image string) bool {
+	first, _, found := strings.Cut(image, "/")
+	if !found {
+		return false
+	}
+	return strings.ContainsAny(first, ".:") || first == "localhost"
+}
+
+// GetImagePullPolicy returns the image pull policy of the component container.
+// When spec.imagePullPolicy is unset, it is derived from the image reference:
+// Always for the "latest" or missing tag, IfNotPresent for a pinned tag or digest.
+func GetImagePullPolicy(cr *dapi.DorisCluster, image string) corev1.PullPolicy {
+	if cr.Spec.ImagePullPolicy != "" {
+		return cr.Spec.ImagePullPolicy
+	}
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+	var tag string
+	name := image[strings.LastIndex(image, "/")+1:]
+	if idx := strings.LastIndex(name, ":"); idx >= 0 {
+		tag = name[idx+1:]
+	}
+	if tag == "" || tag == "latest" {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+// GetClusterDomain returns the domain of kubernetes cluster, defaults to cluster.local.
+func GetClusterDomain(cr *dapi.DorisCluster) string {
+	return util.Coalesce(cr.Spec.ClusterDomain, DefaultClusterDomain)
+}
+
+// Make the FQDN of the pod that is resolved via the headless peer service.
+func makePodFQDN(cr *dapi.DorisCluster, peerSvcName string, podName string) string {
+	return fmt.Sprintf("%s.%s.%s.svc.%s", podName, peerSvcName, cr.ResourceKey().Namespace, GetClusterDomain(cr))
+}
+
+// Make the environment variables of the pod FQDN, the POD_FQDN is used as the host of
+// the component to register into Doris cluster.
+func makePodFQDNEnvs(cr *dapi.DorisCluster, peerSvcName string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "POD_NAME", ValueFrom: util.NewEnvVarFieldSource("metadata.name")},
+		{Name: "POD_FQDN", Value: makePodFQDN(cr, peerSvcName, "$(POD_NAME)")},
+	}
+}
+
+// IsWaitForFe returns whether BE/CN pods should wait for the FE query port before starting,
+// defaults to true.
+func IsWaitForFe(cr *dapi.DorisCluster) bool {
+	return cr.Spec.FE != nil && util.PointerDeRefer(cr.Spec.WaitForFE, true)
+}
+
+// Make the init container that blocks until the FE query port is serving, it uses the
+// helper image or the component image by default to avoid pulling an extra image.
+func makeWaitForFeInitContainer(cr *dapi.DorisCluster, componentImage string) corev1.Container {
+	script := `until bash -c "echo > /dev/tcp/$FE_SVC/$FE_QUERY_PORT" 2>/dev/null; do
+  echo "info: waiting for FE $FE_SVC:$FE_QUERY_PORT to be serving"
+  sleep 2
+done`
+	return corev1.Container{
+		Name:            "wait-for-fe",
+		Image:           GetHelperImage(cr, componentImage),
+		ImagePullPolicy: cr.Spec.ImagePullPolicy,
+		Command:         []string{"/bin/bash", "-c", script},
+		Env: []corev1.EnvVar{
+			{Name: "FE_SVC", Value: GetFeServiceDNS(cr)},
+			{Name: "FE_QUERY_PORT", Value: strconv.Itoa(int(GetFeQueryPort(cr)))},
+		},
+	}
 }
 
 // MakeResourceLabels make the k8s label meta for the managed resource
@@ -61,6 +187,247 @@ func MakeResourceLabels(dorisName string, component string) map[string]string {
 	return labels
 }
 
+// IsCrossNamespace checks whether the sub resources of DorisCluster are placed in a namespace
+// other than the DorisCluster itself.
+func IsCrossNamespace(cr *dapi.DorisCluster) bool {
+	return cr.ResourceKey().Namespace != cr.Namespace
+}
+
+// GetClusterOwnerLabels returns the labels that record the owner DorisCluster of the sub resources.
+func GetClusterOwnerLabels(cr *dapi.DorisCluster) map[string]string {
+	return map[string]string{
+		ClusterOwnerNamespaceLabelKey: cr.Namespace,
+		ClusterOwnerNameLabelKey:      cr.Name,
+	}
+}
+
+// Set the DorisCluster as the owner of the sub resource. The owner is recorded via labels instead
+// of owner references when the sub resource is placed in another namespace, and it would be
+// garbage collected by operator rather than the cascading deletion of kubernetes.
+func setClusterOwner(cr *dapi.DorisCluster, obj metav1.Object, scheme *runtime.Scheme, controller bool) {
+	if IsCrossNamespace(cr) {
+		obj.SetLabels(util.MergeMaps(obj.GetLabels(), GetClusterOwnerLabels(cr)))
+		return
+	}
+	_ = controllerutil.SetOwnerReference(cr, obj, scheme)
+	if controller {
+		_ = controllerutil.SetControllerReference(cr, obj, scheme)
+	}
+}
+
+// Get the topology spread constraints of the component pods, the component-level settings take
+// precedence over cluster-level, and defaults to spread pods across nodes when replicas > 1.
+func getTopologySpreadConstraints(
+	cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec, labels map[string]string) []corev1.TopologySpreadConstraint {
+	constraints := util.Fallback(spec.TopologySpreadConstraints, cr.Spec.TopologySpreadConstraints)
+	if len(constraints) > 0 || spec.Replicas <= 1 {
+		return constraints
+	}
+	return []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+	}}
+}
+
+// DefaultDroppedCapabilities are the capabilities dropped from the Doris containers by
+// default, which are granted by the container runtime but not required by Doris.
+var DefaultDroppedCapabilities = []corev1.Capability{"NET_RAW", "MKNOD", "SYS_CHROOT", "AUDIT_WRITE", "SETFCAP"}
+
+// DorisCapabilities are the capabilities used by Doris to adjust the thread priorities
+// and lock the memory, which are added when spec.addDorisCapabilities is enabled.
+var DorisCapabilities = []corev1.Capability{"SYS_NICE", "IPC_LOCK"}
+
+// Get the pod security context of the component, the component-level settings take
+// precedence over cluster-level. The fsGroup is applied when it is not set in the pod
+// security context, which defaults to the non-root user that the container runs as,
+// and its change policy defaults to OnRootMismatch to avoid the slow recursive chown
+// of the large volumes on every mount.
+func getPodSecurityContext(cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec) *corev1.PodSecurityContext {
+	podSecCtx := util.Coalesce(spec.PodSecurityContext, cr.Spec.PodSecurityContext)
+	fsGroup := util.Coalesce(spec.FSGroup, cr.Spec.FSGroup)
+	if fsGroup == nil {
+		runAsUser := getContainerSecurityContext(cr, spec).RunAsUser
+		if runAsUser == nil && podSecCtx != nil {
+			runAsUser = podSecCtx.RunAsUser
+		}
+		if runAsUser != nil && *runAsUser != 0 {
+			fsGroup = runAsUser
+		}
+	}
+	changePolicy := util.Coalesce(spec.FSGroupChangePolicy, cr.Spec.FSGroupChangePolicy)
+	if fsGroup == nil && changePolicy == nil {
+		return podSecCtx
+	}
+	if podSecCtx == nil {
+		podSecCtx = &corev1.PodSecurityContext{}
+	} else {
+		podSecCtx = podSecCtx.DeepCopy()
+	}
+	if podSecCtx.FSGroup == nil && fsGroup != nil {
+		podSecCtx.FSGroup = util.Pointer(*fsGroup)
+		changePolicy = util.Coalesce(changePolicy, util.Pointer(corev1.FSGroupChangeOnRootMismatch))
+	}
+	if podSecCtx.FSGroup != nil && podSecCtx.FSGroupChangePolicy == nil {
+		podSecCtx.FSGroupChangePolicy = changePolicy
+	}
+	return podSecCtx
+}
+
+// Get the DNS config of the component pod, the component-level settings take precedence over cluster-level.
+func getPodDNSConfig(cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec) *corev1.PodDNSConfig {
+	return util.Coalesce(spec.DNSConfig, cr.Spec.DNSConfig)
+}
+
+// Get the DNS policy of the component pod, the component-level settings take precedence over cluster-level,
+// an empty policy means the default "ClusterFirst" of kubernetes.
+func getPodDNSPolicy(cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec) corev1.DNSPolicy {
+	return util.PointerDeRefer(util.Coalesce(spec.DNSPolicy, cr.Spec.DNSPolicy), "")
+}
+
+// Get the runtime class of the component pod, the component-level settings take precedence over cluster-level.
+func getPodRuntimeClassName(cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec) *string {
+	return util.Coalesce(spec.RuntimeClassName, cr.Spec.RuntimeClassName)
+}
+
+// Inject the Hadoop credentials of the secret into the component container, all the keys of
+// the secret are mounted under HadoopCredentialMountPath, the krb5.conf is mounted to /etc/krb5.conf,
+// and the env keys are injected as the environment variables. Returns the volumes of pod with the
+// credential volume appended.
+func injectHadoopCredentials(cr *dapi.DorisCluster, container *corev1.Container, volumes []corev1.Volume) []corev1.Volume {
+	if cr.Spec.HadoopConf == nil || cr.Spec.HadoopConf.CredentialSecretRef == nil {
+		return volumes
+	}
+	ref := cr.Spec.HadoopConf.CredentialSecretRef
+	volumes = append(volumes, corev1.Volume{Name: HadoopCredentialVolumeName, VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: ref.Name},
+	}})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name: HadoopCredentialVolumeName, MountPath: HadoopCredentialMountPath, ReadOnly: true,
+	})
+	if ref.Krb5ConfKey != "" {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: HadoopCredentialVolumeName, MountPath: Krb5ConfPath, SubPath: ref.Krb5ConfKey, ReadOnly: true,
+		})
+	}
+	for _, key := range ref.EnvKeys {
+		container.Env = append(container.Env, corev1.EnvVar{Name: key, ValueFrom: util.NewEnvVarSecretSource(ref.Name, key)})
+	}
+	return volumes
+}
+
+// Append the extra ports of the component to the container ports managed by operator.
+func withExtraContainerPorts(ports []corev1.ContainerPort, spec *dapi.DorisComponentSpec) []corev1.ContainerPort {
+	return append(ports, spec.ExtraPorts...)
+}
+
+// Convert the extra ports of the component to the ports of the component service.
+func makeExtraServicePorts(spec *dapi.DorisComponentSpec) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	for _, port := range spec.ExtraPorts {
+		ports = append(ports, corev1.ServicePort{Name: port.Name, Protocol: port.Protocol, Port: port.ContainerPort})
+	}
+	return ports
+}
+
+// Get the security context of the component container, the component-level settings take
+// precedence over cluster-level, and defaults to drop the capabilities not required by Doris.
+func getContainerSecurityContext(cr *dapi.DorisCluster, spec *dapi.DorisComponentSpec) *corev1.SecurityContext {
+	if ctx := util.Coalesce(spec.ContainerSecurityContext, cr.Spec.ContainerSecurityContext); ctx != nil {
+		return ctx
+	}
+	capabilities := &corev1.Capabilities{Drop: DefaultDroppedCapabilities}
+	if cr.Spec.AddDorisCapabilities {
+		capabilities.Add = DorisCapabilities
+	}
+	return &corev1.SecurityContext{Capabilities: capabilities}
+}
+
+// PeerServiceLabels are the extra labels of the headless peer services of components,
+// which distinguish them from the access services sharing the same component labels.
+var PeerServiceLabels = map[string]string{
+	fmt.Sprintf("%s/peer-service", dapi.GroupVersion.Group): "true",
+}
+
+// Merge the labels or annotations of pod into a new map,
+// the latter map takes precedence on key conflict.
+func mergePodMeta(metas ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, meta := range metas {
+		for k, v := range meta {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Mount the log volume of the component into the additional containers as read-only,
+// the containers that already mount the same volume or path are left untouched.
+func shareLogVolumeMount(containers []corev1.Container, logMount corev1.VolumeMount) []corev1.Container {
+	logMount.ReadOnly = true
+	shared := make([]corev1.Container, 0, len(containers))
+	for _, container := range containers {
+		c := container.DeepCopy()
+		mounted := len(u.Filter(c.VolumeMounts, func(m corev1.VolumeMount) bool {
+			return m.Name == logMount.Name || m.MountPath == logMount.MountPath
+		})) > 0
+		if !mounted {
+			c.VolumeMounts = append(c.VolumeMounts, logMount)
+		}
+		shared = append(shared, *c)
+	}
+	return shared
+}
+
+// Make the StatefulSet update strategy of the component, the partition only takes
+// effect on the RollingUpdate strategy.
+func makeStatefulSetUpdateStrategy(
+	compStg *appv1.StatefulSetUpdateStrategyType,
+	clusterStg *appv1.StatefulSetUpdateStrategyType,
+	partition *int32) appv1.StatefulSetUpdateStrategy {
+
+	updateStg := appv1.StatefulSetUpdateStrategy{
+		Type: util.PointerDeRefer(util.Coalesce(compStg, clusterStg), appv1.RollingUpdateStatefulSetStrategyType),
+	}
+	if updateStg.Type == appv1.RollingUpdateStatefulSetStrategyType && partition != nil {
+		updateStg.RollingUpdate = &appv1.RollingUpdateStatefulSetStrategy{Partition: partition}
+	}
+	return updateStg
+}
+
+// Make the PodDisruptionBudget of the component pods, the minAvailable or maxUnavailable
+// defined by user takes precedence over the default one.
+func makePodDisruptionBudget(
+	cr *dapi.DorisCluster,
+	scheme *runtime.Scheme,
+	pdbKey types.NamespacedName,
+	compLabels map[string]string,
+	userSpec *dapi.PodDisruptionBudgetSpec,
+	defaultSpec dapi.PodDisruptionBudgetSpec) *policyv1.PodDisruptionBudget {
+
+	pdbSpec := defaultSpec
+	if userSpec != nil && userSpec.MaxUnavailable != nil {
+		pdbSpec = dapi.PodDisruptionBudgetSpec{MaxUnavailable: userSpec.MaxUnavailable}
+	} else if userSpec != nil && userSpec.MinAvailable != nil {
+		pdbSpec = dapi.PodDisruptionBudgetSpec{MinAvailable: userSpec.MinAvailable}
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pdbKey.Name,
+			Namespace: pdbKey.Namespace,
+			Labels:    compLabels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: compLabels},
+			MinAvailable:   pdbSpec.MinAvailable,
+			MaxUnavailable: pdbSpec.MaxUnavailable,
+		},
+	}
+	setClusterOwner(cr, pdb, scheme, false)
+	return pdb
+}
+
 // MakePrometheusAnnotations make the prometheus discovery annotations
 func MakePrometheusAnnotations(path string, port int32) map[string]string {
 	return map[string]string{
@@ -70,6 +437,36 @@ func MakePrometheusAnnotations(path string, port int32) map[string]string {
 	}
 }
 
+const DefaultMetricsPath = "/metrics"
+
+// GetMetricsPath returns the path of the metrics endpoint of Doris components.
+func GetMetricsPath(cr *dapi.DorisCluster) string {
+	if cr.Spec.Monitoring == nil {
+		return DefaultMetricsPath
+	}
+	return util.Coalesce(cr.Spec.Monitoring.MetricsPath, DefaultMetricsPath)
+}
+
+// makePodPrometheusAnnotations makes the prometheus discovery annotations of the component pods
+// according to spec.monitoring, the port is picked from the metrics ports overrides and falls back
+// to the default port. It returns nil when the annotations are disabled or there is no metrics port.
+func makePodPrometheusAnnotations(
+	cr *dapi.DorisCluster, pickPort func(*dapi.MetricsPortsSpec) *int32, defaultPort int32) map[string]string {
+	port := defaultPort
+	if mon := cr.Spec.Monitoring; mon != nil {
+		if !util.PointerDeRefer(mon.PrometheusAnnotations, true) {
+			return nil
+		}
+		if mon.MetricsPorts != nil {
+			port = util.PointerDeRefer(pickPort(mon.MetricsPorts), defaultPort)
+		}
+	}
+	if port <= 0 {
+		return nil
+	}
+	return MakePrometheusAnnotations(GetMetricsPath(cr), port)
+}
+
 const DorisPasswordChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()-=_+[]{}"
 
 func GenerateRandomDorisPassword(length int) string {
@@ -83,17 +480,46 @@ func GenerateRandomDorisPassword(length int) string {
 	return string(password)
 }
 
+// config file keys of Doris components in the configmap
+const (
+	FeConfFileKey     = "fe.conf"
+	BeConfFileKey     = "be.conf"
+	BrokerConfFileKey = "apache_hdfs_broker.conf"
+)
+
 const (
 	JvmOptKey        = "JAVA_OPTS"
 	JvmOpt9Key       = "JAVA_OPTS_FOR_JDK_9"
 	JvmRamPercentage = 75
 )
 
-// Dump the doris component(FE, Broker) KV configs into plain text
-func dumpJavaBasedComponentConf(config map[string]string) string {
-	containerJvmRamOpt := fmt.Sprintf(
-		"-XX:MaxRAMPercentage=%d -XX:InitialRAMPercentage=%d -XX:MinRAMPercentage=%d",
-		JvmRamPercentage, JvmRamPercentage, JvmRamPercentage)
+// Make the JVM heap options of the Java based component, the max heap is computed as a
+// percentage of the container memory limit or request, and falls back to the RAM percentage
+// options of JVM when the memory is not specified.
+// Returns empty string when the auto-tuning is disabled.
+func makeJvmHeapOpt(heap *dapi.JvmHeapSpec, res corev1.ResourceRequirements) string {
+	percentage := int64(JvmRamPercentage)
+	if heap != nil {
+		if heap.DisableAutoTuning {
+			return ""
+		}
+		percentage = int64(util.PointerDeRefer(heap.Percentage, JvmRamPercentage))
+	}
+	memory, found := res.Limits[corev1.ResourceMemory]
+	if !found {
+		memory, found = res.Requests[corev1.ResourceMemory]
+	}
+	if found && memory.Value() > 0 {
+		return fmt.Sprintf("-Xmx%dm", memory.Value()*percentage/100/(1024*1024))
+	}
+	return fmt.Sprintf("-XX:MaxRAMPercentage=%d -XX:InitialRAMPercentage=%d -XX:MinRAMPercentage=%d",
+		percentage, percentage, percentage)
+}
+
+// Dump the doris component(FE, Broker) KV configs into plain text, the jvmHeapOpt is appended
+// to the JVM options in place of the heap options defined by user, the JVM options are
+// retained as is when jvmHeapOpt is empty.
+func dumpJavaBasedComponentConf(config map[string]string, jvmHeapOpt string) string {
 	// order by key
 	keys := util.MapSortedKeys(config)
 	hasJvmOpt := false
@@ -106,19 +532,21 @@ func dumpJavaBasedComponentConf(config map[string]string) string {
 		if key == JvmOptKey {
 			hasJvmOpt = true
 		}
-		if key == JvmOptKey || key == JvmOpt9Key {
+		if (key == JvmOptKey || key == JvmOpt9Key) && jvmHeapOpt != "" {
 			splits := strings.Split(value, " ")
 			noHandledOpts := u.Filter(splits, func(part string) bool {
 				return !strings.HasPrefix(part, "-Xss") && !strings.HasPrefix(part, "-Xmx")
 			})
-			noHandledOpts = append(noHandledOpts, containerJvmRamOpt)
+			noHandledOpts = append(noHandledOpts, jvmHeapOpt)
 			value = fmt.Sprintf(`"%s"`, strings.Join(noHandledOpts, " "))
+		} else if key == JvmOptKey || key == JvmOpt9Key {
+			value = fmt.Sprintf(`"%s"`, value)
 		}
 		line := fmt.Sprintf("%s=%s", key, value)
 		return line
 	})
-	if !hasJvmOpt {
-		lines = append(lines, fmt.Sprintf("%s=%s", JvmOptKey, fmt.Sprintf(`"%s"`, containerJvmRamOpt)))
+	if !hasJvmOpt && jvmHeapOpt != "" {
+		lines = append(lines, fmt.Sprintf("%s=%s", JvmOptKey, fmt.Sprintf(`"%s"`, jvmHeapOpt)))
 	}
 	return strings.Join(lines, "\n")
 }
@@ -141,6 +569,63 @@ func dumpCppBasedComponentConf(config map[string]string) string {
 	return strings.Join(lines, "\n")
 }
 
+// ParseComponentConf parses the content of the Doris component config file into a map.
+func ParseComponentConf(content string) map[string]string {
+	configs := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		// the JVM opt configs would be quoted again when dumping
+		if key == JvmOptKey || key == JvmOpt9Key {
+			value = strings.Trim(value, `"`)
+		}
+		configs[key] = value
+	}
+	return configs
+}
+
+// Get the KV configs of the component that take effect in spec, the configs parsed
+// from ConfigFileContent are overridden by Configs.
+func getSpecComponentConfigs(spec *dapi.DorisComponentSpec) map[string]string {
+	if strings.TrimSpace(spec.ConfigFileContent) == "" {
+		return spec.Configs
+	}
+	return util.MergeMaps(ParseComponentConf(spec.ConfigFileContent), spec.Configs)
+}
+
+// Prepend the raw config file content to the dumped configs, the dumped configs
+// are placed after the raw content so that they override it on key conflict.
+func withRawComponentConf(rawContent string, dumpedConf string) string {
+	rawContent = strings.TrimSpace(rawContent)
+	if rawContent == "" {
+		return dumpedConf
+	}
+	if dumpedConf == "" {
+		return rawContent
+	}
+	return rawContent + "\n\n" + dumpedConf
+}
+
+// Get the JVM opt configs from the raw config file content, they need to be
+// dumped again with the JVM heap opt.
+func getRawJvmOptConfigs(rawContent string) map[string]string {
+	configs := make(map[string]string)
+	for k, v := range ParseComponentConf(rawContent) {
+		if k == JvmOptKey || k == JvmOpt9Key {
+			configs[k] = v
+		}
+	}
+	return configs
+}
+
 // Get the port value from the kv config map
 func getPortValueFromRawConf(config map[string]string, key string, defaultValue int32) int32 {
 	strValue := config[key]
@@ -193,12 +678,114 @@ func mergeHostAlias(items []dapi.HostnameIpItem, hostAliasList []corev1.HostAlia
 	return result
 }
 
-// Format the resource requirement for Pod container
+// makeDefaultStartupProbe makes the default startup probe of the component main container,
+// which tolerates a slow startup of up to 10 minutes, e.g. replaying the large FE metadata
+// or loading a large number of BE tablets, so that the liveness probe needs no long initial delay.
+func makeDefaultStartupProbe(port int32) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler:     util.NewTcpSocketProbeHandler(port),
+		TimeoutSeconds:   1,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 60,
+	}
+}
+
+// DorisHealthApiPath is the health api served by the http port of FE and the webserver port of BE and CN.
+const DorisHealthApiPath = "/api/health"
+
+// makeReadinessProbe makes the default readiness probe of the component main container according to
+// the probe type, which checks the tcp port, or requests the health api of the http port, or runs the
+// exec command.
+func makeReadinessProbe(probeType dapi.ProbeType, tcpPort int32, httpPort int32, execCommand []string) *corev1.Probe {
+	probe := &corev1.Probe{
+		ProbeHandler:     util.NewTcpSocketProbeHandler(tcpPort),
+		TimeoutSeconds:   1,
+		PeriodSeconds:    5,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	switch probeType {
+	case dapi.ProbeHTTP:
+		probe.ProbeHandler = util.NewHttpGetProbeHandler(DorisHealthApiPath, httpPort)
+		probe.TimeoutSeconds = 3
+	case dapi.ProbeExec:
+		probe.ProbeHandler = util.NewExecProbeHandler(execCommand...)
+		probe.TimeoutSeconds = 5
+	}
+	return probe
+}
+
+// GetComponentResources returns the resource requirements of the component, the requests
+// and limits fall back to the ones of spec.defaultResources respectively when the component
+// does not specify any of them other than the storage, without merging per resource. The
+// storage of the component is retained since it is the request of the PVC.
+func GetComponentResources(cr *dapi.DorisCluster, comp *dapi.DorisComponentSpec) corev1.ResourceRequirements {
+	res := *comp.ResourceRequirements.DeepCopy()
+	if cr.Spec.DefaultResources == nil {
+		return res
+	}
+	defaults := formatContainerResourcesRequirement(*cr.Spec.DefaultResources)
+	res.Requests = inheritResourceList(res.Requests, defaults.Requests)
+	res.Limits = inheritResourceList(res.Limits, defaults.Limits)
+	return res
+}
+
+// the resource list is replaced by the defaults when it contains nothing but the storage.
+func inheritResourceList(own corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	storage, hasStorage := own[corev1.ResourceStorage]
+	if len(own) > util.Elvis(hasStorage, 1, 0) || len(defaults) == 0 {
+		return own
+	}
+	inherited := defaults.DeepCopy()
+	if hasStorage {
+		inherited[corev1.ResourceStorage] = storage
+	}
+	return inherited
+}
+
+// Format the resource requirement for Pod container, the storage is only used by
+// the PVC while the ephemeral-storage is kept for the container.
 func formatContainerResourcesRequirement(req corev1.ResourceRequirements) corev1.ResourceRequirements {
 	reqCopy := req.DeepCopy()
-	delete(reqCopy.Limits, corev1.ResourceEphemeralStorage)
 	delete(reqCopy.Limits, corev1.ResourceStorage)
-	delete(reqCopy.Requests, corev1.ResourceEphemeralStorage)
 	delete(reqCopy.Requests, corev1.ResourceStorage)
 	return *reqCopy
 }
+
+// Generate the PVC templates, pod volumes and volume mounts for the storage volumes, the PVC templates
+// are generated for the persistent ones with the annotations set, and the emptyDir pod volumes for
+// the ephemeral ones with the size limit of the storage request.
+func genStorageVolumes(volumes []dapi.StorageVolume, defaultStorageClassName *string, annotations map[string]string) (
+	[]corev1.PersistentVolumeClaim, []corev1.Volume, []corev1.VolumeMount) {
+	var pvcTemplates []corev1.PersistentVolumeClaim
+	var podVolumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	for _, volume := range volumes {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: volume.MountPath})
+		if volume.Type == dapi.StorageVolumeEphemeral {
+			podVolumes = append(podVolumes, corev1.Volume{Name: volume.Name, VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: volume.Request},
+			}})
+			continue
+		}
+		storageClassName := util.Coalesce(volume.StorageClassName, defaultStorageClassName)
+		pvcTemplate := util.NewReadWriteOncePVC(volume.Name, storageClassName, volume.Request)
+		pvcTemplate.Annotations = annotations
+		pvcTemplates = append(pvcTemplates, pvcTemplate)
+	}
+	return pvcTemplates, podVolumes, volumeMounts
+}
+
+// Merge the volume mounts of storage volumes into the built-in volume mounts,
+// the built-in volume mount would be replaced when it has the same mount path.
+func mergeStorageVolumeMounts(builtIn []corev1.VolumeMount, storageMounts []corev1.VolumeMount) []corev1.VolumeMount {
+	overridden := make(map[string]bool)
+	for _, vm := range storageMounts {
+		overridden[strings.TrimSuffix(vm.MountPath, "/")] = true
+	}
+	merged := u.Filter(builtIn, func(vm corev1.VolumeMount) bool {
+		return !overridden[strings.TrimSuffix(vm.MountPath, "/")]
+	})
+	return append(merged, storageMounts...)
+}
@@ -18,11 +18,20 @@
 
 package transformer
 
-import "testing"
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
 
 func TestDumpJavaBasedComponentConf(t *testing.T) {
 	test := func(configs map[string]string, expected string) {
-		result := dumpJavaBasedComponentConf(configs)
+		result := dumpJavaBasedComponentConf(configs, makeJvmHeapOpt(nil, corev1.ResourceRequirements{}))
 		if result != expected {
 			t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, result)
 		}
@@ -58,6 +67,38 @@ http_port=8030`)
 
 }
 
+func TestMakeJvmHeapOpt(t *testing.T) {
+	memRes := func(limit, request string) corev1.ResourceRequirements {
+		res := corev1.ResourceRequirements{Limits: corev1.ResourceList{}, Requests: corev1.ResourceList{}}
+		if limit != "" {
+			res.Limits[corev1.ResourceMemory] = resource.MustParse(limit)
+		}
+		if request != "" {
+			res.Requests[corev1.ResourceMemory] = resource.MustParse(request)
+		}
+		return res
+	}
+	// unit parsing
+	assert.Equal(t, "-Xmx6144m", makeJvmHeapOpt(nil, memRes("8Gi", "")))
+	assert.Equal(t, "-Xmx750m", makeJvmHeapOpt(nil, memRes("", "1000Mi")))
+	assert.Equal(t, "-Xmx1430m", makeJvmHeapOpt(nil, memRes("", "2G")))
+	// limit takes precedence over request
+	assert.Equal(t, "-Xmx3072m", makeJvmHeapOpt(nil, memRes("4Gi", "2Gi")))
+	// custom percentage
+	assert.Equal(t, "-Xmx4096m", makeJvmHeapOpt(&dapi.JvmHeapSpec{Percentage: util.Pointer(int32(50))}, memRes("8Gi", "")))
+	// fallback to RAM percentage when memory is not specified
+	assert.Equal(t, "-XX:MaxRAMPercentage=60 -XX:InitialRAMPercentage=60 -XX:MinRAMPercentage=60",
+		makeJvmHeapOpt(&dapi.JvmHeapSpec{Percentage: util.Pointer(int32(60))}, corev1.ResourceRequirements{}))
+	// auto-tuning disabled
+	assert.Equal(t, "", makeJvmHeapOpt(&dapi.JvmHeapSpec{DisableAutoTuning: true}, memRes("8Gi", "")))
+
+	// user defined heap options are retained when auto-tuning is disabled
+	assert.Equal(t, `JAVA_OPTS="-Xss4m -Xmx8192m"`,
+		dumpJavaBasedComponentConf(map[string]string{"JAVA_OPTS": "-Xss4m -Xmx8192m"}, ""))
+	assert.Equal(t, `JAVA_OPTS="-XX:+UseMembar -Xmx6144m"`,
+		dumpJavaBasedComponentConf(map[string]string{"JAVA_OPTS": "-Xss4m -Xmx8192m -XX:+UseMembar"}, "-Xmx6144m"))
+}
+
 func TestDumpCppBasedComponentConf(t *testing.T) {
 	eval := func(configs map[string]string, expected string) {
 		result := dumpCppBasedComponentConf(configs)
@@ -88,3 +129,402 @@ JAVA_OPTS_FOR_JDK_9="-Djavax.security.auth.useSubjectCredsOnly=false -Xss4m -Xmx
 be_port=9060`)
 
 }
+
+func TestGetImagePullPolicy(t *testing.T) {
+	cr := &dapi.DorisCluster{}
+	assert.Equal(t, corev1.PullAlways, GetImagePullPolicy(cr, "apache/doris:latest"))
+	assert.Equal(t, corev1.PullAlways, GetImagePullPolicy(cr, "apache/doris"))
+	assert.Equal(t, corev1.PullAlways, GetImagePullPolicy(cr, "registry.local:5000/apache/doris"))
+	assert.Equal(t, corev1.PullIfNotPresent, GetImagePullPolicy(cr, "registry.local:5000/apache/doris:2.0.0"))
+	assert.Equal(t, corev1.PullIfNotPresent, GetImagePullPolicy(cr,
+		"apache/doris@sha256:4b2fa3c2fb7b0b3c0a8a1b8b9d4f34d6b9a1f2e0d4c5b6a7988776655443322a"))
+
+	// user defined policy takes precedence
+	cr.Spec.ImagePullPolicy = corev1.PullNever
+	assert.Equal(t, corev1.PullNever, GetImagePullPolicy(cr, "apache/doris:latest"))
+}
+
+func TestCustomClusterDomain(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 1)
+	assert.Equal(t, "test-fe.default.svc.cluster.local", GetFeServiceDNS(cr))
+
+	cr.Spec.ClusterDomain = "doris.example"
+	assert.Equal(t, "test-fe.default.svc.doris.example", GetFeServiceDNS(cr))
+	assert.Equal(t, "test-fe-0.test-fe-peer.default.svc.doris.example", GetFePodFQDN(cr, "test-fe-0"))
+	assert.Equal(t, "test-be-0.test-be-peer.default.svc.doris.example", GetBePodFQDN(cr, "test-be-0"))
+
+	envs := func(container corev1.Container) map[string]string {
+		values := make(map[string]string)
+		for _, env := range container.Env {
+			values[env.Name] = env.Value
+		}
+		return values
+	}
+	feEnvs := envs(MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "test-fe.default.svc.doris.example", feEnvs["FE_SVC"])
+	assert.Equal(t, "$(POD_NAME).test-fe-peer.default.svc.doris.example", feEnvs["POD_FQDN"])
+	beEnvs := envs(MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0])
+	assert.Equal(t, "test-fe.default.svc.doris.example", beEnvs["FE_SVC"])
+	assert.Equal(t, "$(POD_NAME).test-be-peer.default.svc.doris.example", beEnvs["POD_FQDN"])
+}
+
+func TestGetComponentResources(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.DefaultResources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:     resource.MustParse("2"),
+			corev1.ResourceMemory:  resource.MustParse("4Gi"),
+			corev1.ResourceStorage: resource.MustParse("1Gi"),
+		},
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+	}
+	// inherit the defaults and retain the storage of the component
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	assert.Equal(t, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:     resource.MustParse("2"),
+			corev1.ResourceMemory:  resource.MustParse("4Gi"),
+			corev1.ResourceStorage: resource.MustParse("10Gi"),
+		},
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+	}, GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec))
+	sts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, resource.MustParse("2"), sts.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("10Gi"), sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+
+	// the requests of the component fully override the defaults, while the limits are still inherited
+	cr.Spec.FE.Requests[corev1.ResourceCPU] = resource.MustParse("1")
+	assert.Equal(t, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:     resource.MustParse("1"),
+			corev1.ResourceStorage: resource.MustParse("10Gi"),
+		},
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+	}, GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec))
+
+	// no defaults
+	cr.Spec.DefaultResources = nil
+	assert.Equal(t, cr.Spec.FE.ResourceRequirements, GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec))
+}
+
+func TestPodFsGroup(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 1)
+	// no fsGroup for the root user
+	assert.Nil(t, getPodSecurityContext(cr, &cr.Spec.FE.DorisComponentSpec))
+
+	// defaults to the non-root user that the container runs as
+	cr.Spec.ContainerSecurityContext = &corev1.SecurityContext{RunAsUser: util.Pointer(int64(1000))}
+	fePod := MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, int64(1000), *fePod.SecurityContext.FSGroup)
+	assert.Equal(t, corev1.FSGroupChangeOnRootMismatch, *fePod.SecurityContext.FSGroupChangePolicy)
+
+	// the dedicated settings of component take precedence over cluster-level
+	cr.Spec.FSGroup = util.Pointer(int64(2000))
+	cr.Spec.BE.FSGroup = util.Pointer(int64(3000))
+	cr.Spec.BE.FSGroupChangePolicy = util.Pointer(corev1.FSGroupChangeAlways)
+	assert.Equal(t, int64(2000), *getPodSecurityContext(cr, &cr.Spec.FE.DorisComponentSpec).FSGroup)
+	bePod := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, int64(3000), *bePod.SecurityContext.FSGroup)
+	assert.Equal(t, corev1.FSGroupChangeAlways, *bePod.SecurityContext.FSGroupChangePolicy)
+
+	// the fsGroup of pod security context takes precedence and is left untouched
+	cr.Spec.PodSecurityContext = &corev1.PodSecurityContext{FSGroup: util.Pointer(int64(4000))}
+	feSecCtx := getPodSecurityContext(cr, &cr.Spec.FE.DorisComponentSpec)
+	assert.Equal(t, cr.Spec.PodSecurityContext, feSecCtx)
+	assert.Nil(t, cr.Spec.PodSecurityContext.FSGroupChangePolicy)
+	// the change policy of component is still applied
+	beSecCtx := getPodSecurityContext(cr, &cr.Spec.BE.DorisComponentSpec)
+	assert.Equal(t, int64(4000), *beSecCtx.FSGroup)
+	assert.Equal(t, corev1.FSGroupChangeAlways, *beSecCtx.FSGroupChangePolicy)
+}
+
+func TestAdditionalEnvFrom(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 1)
+	feEnvFrom := []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "fe-tuning"}},
+	}}
+	beEnvFrom := []corev1.EnvFromSource{{
+		Prefix:    "DORIS_",
+		SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "be-secrets"}},
+	}}
+	cr.Spec.FE.AdditionalEnvFrom = feEnvFrom
+	cr.Spec.BE.AdditionalEnvFrom = beEnvFrom
+
+	envNames := func(container corev1.Container) []string {
+		var names []string
+		for _, env := range container.Env {
+			names = append(names, env.Name)
+		}
+		return names
+	}
+	feContainer := MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, feEnvFrom, feContainer.EnvFrom)
+	assert.Subset(t, envNames(feContainer), []string{"FE_SVC", "ACC_USER", "ACC_PWD"})
+	beContainer := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, beEnvFrom, beContainer.EnvFrom)
+	assert.Subset(t, envNames(beContainer), []string{"FE_SVC", "ACC_USER", "ACC_PWD"})
+}
+
+func TestSecurityContextPrecedence(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 1)
+
+	// defaults to drop the capabilities not required by Doris
+	fePod := MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Nil(t, fePod.SecurityContext)
+	assert.Equal(t, DefaultDroppedCapabilities, fePod.Containers[0].SecurityContext.Capabilities.Drop)
+	assert.Empty(t, fePod.Containers[0].SecurityContext.Capabilities.Add)
+
+	// the Doris capabilities are added when opted in
+	cr.Spec.AddDorisCapabilities = true
+	fePod = MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, DorisCapabilities, fePod.Containers[0].SecurityContext.Capabilities.Add)
+
+	// cluster-level settings are applied to all components
+	cr.Spec.PodSecurityContext = &corev1.PodSecurityContext{FSGroup: util.Pointer(int64(1000))}
+	cr.Spec.ContainerSecurityContext = &corev1.SecurityContext{RunAsUser: util.Pointer(int64(1000))}
+	// component-level settings take precedence
+	cr.Spec.BE.PodSecurityContext = &corev1.PodSecurityContext{FSGroup: util.Pointer(int64(2000))}
+	cr.Spec.BE.ContainerSecurityContext = &corev1.SecurityContext{RunAsUser: util.Pointer(int64(2000))}
+
+	fePod = MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, int64(1000), *fePod.SecurityContext.FSGroup)
+	assert.Equal(t, int64(1000), *fePod.Containers[0].SecurityContext.RunAsUser)
+	assert.Nil(t, fePod.Containers[0].SecurityContext.Capabilities)
+	bePod := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec
+	assert.Equal(t, int64(2000), *bePod.SecurityContext.FSGroup)
+	assert.Equal(t, int64(2000), *bePod.Containers[0].SecurityContext.RunAsUser)
+	// the privileged sysctl init container is left untouched
+	assert.True(t, *bePod.InitContainers[0].SecurityContext.Privileged)
+}
+
+func TestCrossNamespaceResourceOwner(t *testing.T) {
+	cr := newTestDorisCluster()
+	scheme := runtime.NewScheme()
+	_ = dapi.AddToScheme(scheme)
+
+	// owner references are set when the resources live along with the DorisCluster
+	sts := MakeFeStatefulSet(cr, scheme)
+	assert.Equal(t, "default", sts.Namespace)
+	assert.Len(t, sts.OwnerReferences, 1)
+	assert.NotContains(t, sts.Labels, ClusterOwnerNameLabelKey)
+
+	// owner labels are used instead when the resources are placed in another namespace
+	cr.Spec.ResourceNamespace = "doris"
+	sts = MakeFeStatefulSet(cr, scheme)
+	assert.Equal(t, "doris", sts.Namespace)
+	assert.Empty(t, sts.OwnerReferences)
+	assert.Equal(t, "default", sts.Labels[ClusterOwnerNamespaceLabelKey])
+	assert.Equal(t, "test", sts.Labels[ClusterOwnerNameLabelKey])
+	// the selector is left untouched
+	assert.NotContains(t, sts.Spec.Selector.MatchLabels, ClusterOwnerNameLabelKey)
+	assert.Equal(t, "test-fe-0.test-fe-peer.doris.svc.cluster.local", GetFePodFQDN(cr, "test-fe-0"))
+	assert.Equal(t, "doris", MakeOprSqlAccountSecret(cr).Namespace)
+}
+
+func TestMakeStatefulSetStartupProbe(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.CN = &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-cn", Replicas: 1}}
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-broker", Replicas: 1}}
+	startupProbes := func() map[string]*corev1.Probe {
+		return map[string]*corev1.Probe{
+			"fe":     MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].StartupProbe,
+			"be":     MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].StartupProbe,
+			"cn":     MakeCnStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].StartupProbe,
+			"broker": MakeBrokerStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].StartupProbe,
+		}
+	}
+	// default startup probe allows 10 minutes for the startup
+	expectedPorts := map[string]int32{
+		"fe":     GetFeHttpPort(cr),
+		"be":     GetBeHeartbeatServicePort(cr),
+		"cn":     GetCnHeartbeatServicePort(cr),
+		"broker": GetBrokerIpcPort(cr),
+	}
+	for comp, probe := range startupProbes() {
+		assert.NotNil(t, probe.TCPSocket, comp)
+		assert.Equal(t, expectedPorts[comp], probe.TCPSocket.Port.IntVal, comp)
+		assert.Equal(t, int32(600), probe.PeriodSeconds*probe.FailureThreshold, comp)
+	}
+
+	// overridden by user
+	userProbe := &corev1.Probe{
+		ProbeHandler:     corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{}},
+		PeriodSeconds:    30,
+		FailureThreshold: 60,
+	}
+	cr.Spec.FE.StartupProbe = userProbe
+	cr.Spec.BE.StartupProbe = userProbe
+	cr.Spec.CN.StartupProbe = userProbe
+	cr.Spec.Broker.StartupProbe = userProbe
+	for comp, probe := range startupProbes() {
+		assert.Equal(t, userProbe, probe, comp)
+	}
+}
+
+func TestGetPodDNSConfigAndPolicy(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	feSts := MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Nil(t, feSts.Spec.Template.Spec.DNSConfig)
+	assert.Empty(t, feSts.Spec.Template.Spec.DNSPolicy)
+
+	// cluster-level settings
+	ndots := "2"
+	clusterDNSConfig := &corev1.PodDNSConfig{
+		Options:  []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+		Searches: []string{"hadoop.example.com"},
+	}
+	cr.Spec.DNSConfig = clusterDNSConfig
+	cr.Spec.DNSPolicy = util.Pointer(corev1.DNSDefault)
+	feSts = MakeFeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, clusterDNSConfig, feSts.Spec.Template.Spec.DNSConfig)
+	assert.Equal(t, corev1.DNSDefault, feSts.Spec.Template.Spec.DNSPolicy)
+
+	// component-level settings take precedence
+	beDNSConfig := &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}}
+	cr.Spec.BE.DNSConfig = beDNSConfig
+	cr.Spec.BE.DNSPolicy = util.Pointer(corev1.DNSNone)
+	beSts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, beDNSConfig, beSts.Spec.Template.Spec.DNSConfig)
+	assert.Equal(t, corev1.DNSNone, beSts.Spec.Template.Spec.DNSPolicy)
+	assert.Equal(t, clusterDNSConfig, MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.DNSConfig)
+
+	// the BE pods in host network default to ClusterFirstWithHostNet
+	cr.Spec.DNSPolicy = nil
+	cr.Spec.BE.DNSPolicy = nil
+	cr.Spec.BE.HostNetwork = true
+	beSts = MakeBeStatefulSet(cr, runtime.NewScheme())
+	assert.Equal(t, corev1.DNSClusterFirstWithHostNet, beSts.Spec.Template.Spec.DNSPolicy)
+}
+
+func TestGetPodRuntimeClassName(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.CN = &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-cn", Replicas: 1}}
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-broker", Replicas: 1}}
+	podSpecs := func() map[string]corev1.PodSpec {
+		return map[string]corev1.PodSpec{
+			"fe":     MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec,
+			"be":     MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec,
+			"cn":     MakeCnStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec,
+			"broker": MakeBrokerStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec,
+		}
+	}
+	for name, spec := range podSpecs() {
+		assert.Nil(t, spec.RuntimeClassName, name)
+	}
+
+	// cluster-level settings are applied to all components
+	cr.Spec.RuntimeClassName = util.Pointer("gvisor")
+	for name, spec := range podSpecs() {
+		assert.Equal(t, "gvisor", *spec.RuntimeClassName, name)
+	}
+
+	// component-level settings take precedence
+	cr.Spec.BE.RuntimeClassName = util.Pointer("kata")
+	cr.Spec.Broker.RuntimeClassName = util.Pointer("kata")
+	specs := podSpecs()
+	assert.Equal(t, "gvisor", *specs["fe"].RuntimeClassName)
+	assert.Equal(t, "kata", *specs["be"].RuntimeClassName)
+	assert.Equal(t, "gvisor", *specs["cn"].RuntimeClassName)
+	assert.Equal(t, "kata", *specs["broker"].RuntimeClassName)
+}
+
+func TestWithImageRegistry(t *testing.T) {
+	cr := newTestDorisCluster()
+	// no registry override
+	assert.Equal(t, "busybox:1.36", withImageRegistry(cr, "busybox:1.36"))
+
+	cr.Spec.ImageRegistry = "registry.example.com/mirror/"
+	cases := map[string]string{
+		// bare library image
+		"busybox:1.36": "registry.example.com/mirror/library/busybox:1.36",
+		"busybox":      "registry.example.com/mirror/library/busybox",
+		// Docker Hub image with organization
+		"apache/doris:2.0.3": "registry.example.com/mirror/apache/doris:2.0.3",
+		// the image digest
+		"apache/doris@sha256:abc": "registry.example.com/mirror/apache/doris@sha256:abc",
+		// images that already specify a registry host
+		"ghcr.io/linsoss/doris-fe:2.0.3": "ghcr.io/linsoss/doris-fe:2.0.3",
+		"my.registry/doris-be:2.0.3":     "my.registry/doris-be:2.0.3",
+		"registry:5000/doris-be":         "registry:5000/doris-be",
+		"localhost/doris-be":             "localhost/doris-be",
+	}
+	for image, expected := range cases {
+		assert.Equal(t, expected, withImageRegistry(cr, image), image)
+	}
+
+	// applies to the component images
+	cr.Spec.FE.BaseImage = "linsoss/doris-fe"
+	cr.Spec.BusyBoxImage = util.Pointer("busybox:1.36")
+	assert.Equal(t, "registry.example.com/mirror/linsoss/doris-fe:2.0.3", GetFeImage(cr))
+	assert.Equal(t, "registry.example.com/mirror/library/busybox:1.36", GetBusyBoxImage(cr))
+}
+
+func TestMakePodPrometheusAnnotations(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.CN = &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-cn", Replicas: 1}}
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "doris-broker", Replicas: 1}}
+	podAnnotations := func() map[string]map[string]string {
+		return map[string]map[string]string{
+			"fe":     MakeFeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Annotations,
+			"be":     MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Annotations,
+			"cn":     MakeCnStatefulSet(cr, runtime.NewScheme()).Spec.Template.Annotations,
+			"broker": MakeBrokerStatefulSet(cr, runtime.NewScheme()).Spec.Template.Annotations,
+		}
+	}
+	// default annotations
+	annos := podAnnotations()
+	assert.Equal(t, MakePrometheusAnnotations("/metrics", GetFeHttpPort(cr)), annos["fe"])
+	assert.Equal(t, MakePrometheusAnnotations("/metrics", GetBeWebserverPort(cr)), annos["be"])
+	assert.Equal(t, MakePrometheusAnnotations("/metrics", GetCnWebserverPort(cr)), annos["cn"])
+	assert.NotContains(t, annos["broker"], PrometheusScrapeAnnoKey)
+
+	// custom path and ports
+	cr.Spec.Monitoring = &dapi.MonitoringSpec{
+		MetricsPath:  "/custom/metrics",
+		MetricsPorts: &dapi.MetricsPortsSpec{BE: util.Pointer[int32](18040), Broker: util.Pointer[int32](8000)},
+	}
+	annos = podAnnotations()
+	assert.Equal(t, MakePrometheusAnnotations("/custom/metrics", GetFeHttpPort(cr)), annos["fe"])
+	assert.Equal(t, MakePrometheusAnnotations("/custom/metrics", 18040), annos["be"])
+	assert.Equal(t, MakePrometheusAnnotations("/custom/metrics", 8000), annos["broker"])
+
+	// disabled
+	cr.Spec.Monitoring.PrometheusAnnotations = util.Pointer(false)
+	for comp, anno := range podAnnotations() {
+		assert.NotContains(t, anno, PrometheusScrapeAnnoKey, comp)
+		assert.NotContains(t, anno, PrometheusPathAnnoKey, comp)
+	}
+}
+
+func TestInjectHadoopCredentials(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	sts := MakeBeStatefulSet(cr, runtime.NewScheme())
+	for _, volume := range sts.Spec.Template.Spec.Volumes {
+		assert.NotEqual(t, HadoopCredentialVolumeName, volume.Name)
+	}
+
+	cr.Spec.HadoopConf = &dapi.HadoopConfSpec{CredentialSecretRef: &dapi.HadoopCredentialSecretRef{
+		Name:        "hadoop-cred",
+		EnvKeys:     []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+		Krb5ConfKey: "krb5.conf",
+	}}
+	for _, sts := range []*appv1.StatefulSet{MakeFeStatefulSet(cr, runtime.NewScheme()), MakeBeStatefulSet(cr, runtime.NewScheme())} {
+		podSpec := sts.Spec.Template.Spec
+		assert.Contains(t, podSpec.Volumes, corev1.Volume{Name: HadoopCredentialVolumeName, VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "hadoop-cred"},
+		}})
+		container := podSpec.Containers[0]
+		assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
+			Name: HadoopCredentialVolumeName, MountPath: HadoopCredentialMountPath, ReadOnly: true,
+		})
+		assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
+			Name: HadoopCredentialVolumeName, MountPath: Krb5ConfPath, SubPath: "krb5.conf", ReadOnly: true,
+		})
+		assert.Contains(t, container.Env, corev1.EnvVar{
+			Name: "AWS_ACCESS_KEY_ID", ValueFrom: util.NewEnvVarSecretSource("hadoop-cred", "AWS_ACCESS_KEY_ID"),
+		})
+		assert.Contains(t, container.Env, corev1.EnvVar{
+			Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: util.NewEnvVarSecretSource("hadoop-cred", "AWS_SECRET_ACCESS_KEY"),
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ServiceMonitorGVK is the GroupVersionKind of Prometheus Operator ServiceMonitor.
+var ServiceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// NewServiceMonitorObject returns an empty ServiceMonitor object.
+func NewServiceMonitorObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ServiceMonitorGVK)
+	return obj
+}
+
+func GetFeServiceMonitorKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-fe", dorisClusterKey.Name),
+	}
+}
+
+func GetBeServiceMonitorKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-be", dorisClusterKey.Name),
+	}
+}
+
+func GetCnServiceMonitorKey(dorisClusterKey types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: dorisClusterKey.Namespace,
+		Name:      fmt.Sprintf("%s-cn", dorisClusterKey.Name),
+	}
+}
+
+// MakeFeServiceMonitor makes the ServiceMonitor that scrapes the FE http port.
+func MakeFeServiceMonitor(cr *dapi.DorisCluster, scheme *runtime.Scheme) *unstructured.Unstructured {
+	if !cr.Spec.EnableServiceMonitor || cr.Spec.FE == nil {
+		return nil
+	}
+	return makeServiceMonitor(cr, scheme, GetFeServiceMonitorKey(cr.ResourceKey()), GetFeComponentLabels(cr.ResourceKey()), "http-port")
+}
+
+// MakeBeServiceMonitor makes the ServiceMonitor that scrapes the BE webserver port.
+func MakeBeServiceMonitor(cr *dapi.DorisCluster, scheme *runtime.Scheme) *unstructured.Unstructured {
+	if !cr.Spec.EnableServiceMonitor || cr.Spec.BE == nil {
+		return nil
+	}
+	return makeServiceMonitor(cr, scheme, GetBeServiceMonitorKey(cr.ResourceKey()), GetBeComponentLabels(cr.ResourceKey()), "webserver-port")
+}
+
+// MakeCnServiceMonitor makes the ServiceMonitor that scrapes the CN webserver port.
+// There is no ServiceMonitor for Broker since it does not expose the metrics endpoint.
+func MakeCnServiceMonitor(cr *dapi.DorisCluster, scheme *runtime.Scheme) *unstructured.Unstructured {
+	if !cr.Spec.EnableServiceMonitor || cr.Spec.CN == nil {
+		return nil
+	}
+	return makeServiceMonitor(cr, scheme, GetCnServiceMonitorKey(cr.ResourceKey()), GetCnComponentLabels(cr.ResourceKey()), "webserver-port")
+}
+
+// Make the ServiceMonitor that selects the access service of the component, the
+// headless peer service is excluded to avoid scraping the same pods twice.
+func makeServiceMonitor(
+	cr *dapi.DorisCluster,
+	scheme *runtime.Scheme,
+	key types.NamespacedName,
+	compLabels map[string]string,
+	portName string) *unstructured.Unstructured {
+
+	var peerLabelKeys []any
+	for k := range PeerServiceLabels {
+		peerLabelKeys = append(peerLabelKeys, map[string]any{"key": k, "operator": string(metav1.LabelSelectorOpDoesNotExist)})
+	}
+	matchLabels := make(map[string]any)
+	for k, v := range compLabels {
+		matchLabels[k] = v
+	}
+	obj := NewServiceMonitorObject()
+	obj.SetName(key.Name)
+	obj.SetNamespace(key.Namespace)
+	obj.SetLabels(compLabels)
+	obj.Object["spec"] = map[string]any{
+		"selector": map[string]any{
+			"matchLabels":      matchLabels,
+			"matchExpressions": peerLabelKeys,
+		},
+		"namespaceSelector": map[string]any{
+			"matchNames": []any{key.Namespace},
+		},
+		"endpoints": []any{
+			map[string]any{"port": portName, "path": GetMetricsPath(cr)},
+		},
+	}
+	setClusterOwner(cr, obj, scheme, false)
+	return obj
+}
@@ -0,0 +1,590 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	u "github.com/rjNemo/underscore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var oprSqlAccountUserPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// ValidateDorisCluster checks the DorisCluster spec for the misconfigurations that
+// would produce broken resources, including missing FE required by the other components,
+// no FE replicas, port conflicts of FE/BE/CN, negative replicas, resource limits less
+// than requests, missing storage of FE/BE, data paths of FE/BE inconsistent with the
+// mount paths, empty operator SQL account secret reference, invalid runtime class names,
+// extra ports conflicting with the managed ones, invalid Hadoop credential secret
+// references, cordoned BE ordinals out of the replicas, an empty helper image of the
+// enabled init containers, readiness probe types not supported by the component and
+// PodDisruptionBudgets with both minAvailable and maxUnavailable.
+// It is checked by the validating webhook on admission, and by the reconciler before
+// reconciling the sub resources since the webhook is optional.
+func ValidateDorisCluster(cr *dapi.DorisCluster) error {
+	var errs []error
+	if ref := cr.Spec.OprSqlAccountSecretRef; ref != nil && ref.Name == "" {
+		errs = append(errs, fmt.Errorf("spec.oprSqlAccountSecretRef.name: secret name must not be empty"))
+	}
+	errs = append(errs, validateComponentDependencies(cr)...)
+	errs = append(errs, validateOprSqlAccount(cr)...)
+	errs = append(errs, validateRuntimeClassName("spec", cr.Spec.RuntimeClassName)...)
+	if cr.Spec.DefaultResources != nil {
+		errs = append(errs, validateResourceLimits("spec.defaultResources", *cr.Spec.DefaultResources)...)
+	}
+	errs = append(errs, validateHelperImage(cr)...)
+	errs = append(errs, validateRestoreFrom(cr)...)
+	errs = append(errs, validateBackup(cr)...)
+	if cr.Spec.HadoopConf != nil {
+		errs = append(errs, validateHadoopCredentialSecretRef("spec.hadoopConf.credentialSecretRef",
+			cr.Spec.HadoopConf.CredentialSecretRef)...)
+	}
+	if cr.Spec.FE != nil {
+		if cr.Spec.FE.Replicas == 0 {
+			errs = append(errs, fmt.Errorf("spec.fe.replicas: at least 1 FE replica is required for the quorum of FE"))
+		}
+		errs = append(errs, validateReplicas("spec.fe", cr.Spec.FE.Replicas)...)
+		errs = append(errs, validateResourceLimits("spec.fe", GetComponentResources(cr, &cr.Spec.FE.DorisComponentSpec))...)
+		errs = append(errs, validateRuntimeClassName("spec.fe", cr.Spec.FE.RuntimeClassName)...)
+		if followers := cr.Spec.FE.Followers; followers != nil && (*followers < 1 || *followers > cr.Spec.FE.Replicas) {
+			errs = append(errs, fmt.Errorf("spec.fe.followers: followers %d must be between 1 and replicas %d",
+				*followers, cr.Spec.FE.Replicas))
+		}
+		fePorts := map[string]int32{
+			"http_port":     GetFeHttpPort(cr),
+			"query_port":    GetFeQueryPort(cr),
+			"rpc_port":      GetFeRpcPort(cr),
+			"edit_log_port": GetFeEditLogPort(cr),
+		}
+		if arrowFlightPort := GetFeArrowFlightPort(cr); arrowFlightPort > 0 {
+			fePorts["arrow_flight_sql_port"] = arrowFlightPort
+		}
+		errs = append(errs, validatePortConflicts("spec.fe.config", fePorts)...)
+		errs = append(errs, validateExtraPorts("spec.fe.extraPorts", makeFeContainerPorts(cr), cr.Spec.FE.ExtraPorts)...)
+		if tls := cr.Spec.FE.TLS; tls != nil && tls.SecretName == "" {
+			errs = append(errs, fmt.Errorf("spec.fe.tls.secretName: secret name must not be empty"))
+		}
+		errs = append(errs, validateStorageRequest("spec.fe.requests.storage", cr.Spec.FE.Requests.Storage())...)
+		errs = append(errs, validateStorageVolumes("spec.fe.storageVolumes", cr.Spec.FE.StorageVolumes, feBuiltInVolumeNames)...)
+		errs = append(errs, validateFeMetaDir(cr)...)
+		errs = append(errs, validatePodDisruptionBudget("spec.fe.podDisruptionBudget", cr.Spec.FE.PodDisruptionBudget)...)
+		if err := ValidateFeServiceNodePorts(cr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cr.Spec.BE != nil {
+		errs = append(errs, validateReplicas("spec.be", cr.Spec.BE.Replicas)...)
+		errs = append(errs, validateResourceLimits("spec.be", GetComponentResources(cr, &cr.Spec.BE.DorisComponentSpec))...)
+		errs = append(errs, validateRuntimeClassName("spec.be", cr.Spec.BE.RuntimeClassName)...)
+		errs = append(errs, validatePortConflicts("spec.be.config", map[string]int32{
+			"be_port":                GetBePort(cr),
+			"webserver_port":         GetBeWebserverPort(cr),
+			"heartbeat_service_port": GetBeHeartbeatServicePort(cr),
+			"brpc_port":              GetBeBrpcPort(cr),
+		})...)
+		errs = append(errs, validateExtraPorts("spec.be.extraPorts", makeBeContainerPorts(cr), cr.Spec.BE.ExtraPorts)...)
+		if len(cr.Spec.BE.Storage) == 0 || cr.Spec.BE.RetainDefaultStorage {
+			errs = append(errs, validateStorageRequest("spec.be.requests.storage", cr.Spec.BE.Requests.Storage())...)
+		}
+		for i, storage := range cr.Spec.BE.Storage {
+			errs = append(errs, validateStorageRequest(fmt.Sprintf("spec.be.storage[%d].request", i), storage.Request)...)
+		}
+		errs = append(errs, validateStorageVolumes("spec.be.storageVolumes", cr.Spec.BE.StorageVolumes, beBuiltInVolumeNames,
+			getBeDataStorageVolumes(cr.Spec.BE)...)...)
+		errs = append(errs, validateBeStorageRootPath(cr)...)
+		errs = append(errs, validateCordonedOrdinals("spec.be.cordonedOrdinals", cr.Spec.BE.CordonedOrdinals, cr.Spec.BE.Replicas)...)
+		errs = append(errs, validatePodDisruptionBudget("spec.be.podDisruptionBudget", cr.Spec.BE.PodDisruptionBudget)...)
+		errs = append(errs, validateReadinessProbeType("spec.be", cr.Spec.BE.ReadinessProbeType, dapi.ProbeTCP, dapi.ProbeHTTP)...)
+	}
+	if cr.Spec.CN != nil {
+		errs = append(errs, validateReplicas("spec.cn", cr.Spec.CN.Replicas)...)
+		errs = append(errs, validateResourceLimits("spec.cn", GetComponentResources(cr, &cr.Spec.CN.DorisComponentSpec))...)
+		errs = append(errs, validateRuntimeClassName("spec.cn", cr.Spec.CN.RuntimeClassName)...)
+		errs = append(errs, validatePortConflicts("spec.cn.config", map[string]int32{
+			"be_port":                GetCnPort(cr),
+			"webserver_port":         GetCnWebserverPort(cr),
+			"heartbeat_service_port": GetCnHeartbeatServicePort(cr),
+			"brpc_port":              GetCnBrpcPort(cr),
+		})...)
+		errs = append(errs, validateExtraPorts("spec.cn.extraPorts", makeCnContainerPorts(cr), cr.Spec.CN.ExtraPorts)...)
+		errs = append(errs, validateStorageVolumes("spec.cn.storageVolumes", cr.Spec.CN.StorageVolumes, cnBuiltInVolumeNames)...)
+		errs = append(errs, validateReadinessProbeType("spec.cn", cr.Spec.CN.ReadinessProbeType, dapi.ProbeTCP, dapi.ProbeHTTP)...)
+	}
+	if cr.Spec.Broker != nil {
+		errs = append(errs, validateReplicas("spec.broker", cr.Spec.Broker.Replicas)...)
+		errs = append(errs, validateResourceLimits("spec.broker", GetComponentResources(cr, &cr.Spec.Broker.DorisComponentSpec))...)
+		errs = append(errs, validateRuntimeClassName("spec.broker", cr.Spec.Broker.RuntimeClassName)...)
+		errs = append(errs, validateExtraPorts("spec.broker.extraPorts", makeBrokerContainerPorts(cr), cr.Spec.Broker.ExtraPorts)...)
+		errs = append(errs, validateStorageVolumes("spec.broker.storageVolumes", cr.Spec.Broker.StorageVolumes, brokerBuiltInVolumeNames)...)
+		errs = append(errs, validateReadinessProbeType("spec.broker", cr.Spec.Broker.ReadinessProbeType, dapi.ProbeTCP)...)
+		errs = append(errs, validateBrokerKerberos("spec.broker.kerberos", cr.Spec.Broker.Kerberos)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrors(errs...)
+}
+
+// BE, CN and Broker join the Doris cluster via FE, so that they can never become ready without FE.
+func validateComponentDependencies(cr *dapi.DorisCluster) []error {
+	if cr.Spec.FE != nil {
+		return nil
+	}
+	if cr.Spec.BE == nil && cr.Spec.CN == nil && cr.Spec.Broker == nil {
+		return []error{fmt.Errorf("spec: at least one of fe, be, cn and broker must be specified")}
+	}
+	var errs []error
+	if cr.Spec.BE != nil {
+		errs = append(errs, fmt.Errorf("spec.fe: FE is required by BE"))
+	}
+	if cr.Spec.CN != nil {
+		errs = append(errs, fmt.Errorf("spec.fe: FE is required by CN"))
+	}
+	if cr.Spec.Broker != nil {
+		errs = append(errs, fmt.Errorf("spec.fe: FE is required by Broker"))
+	}
+	return errs
+}
+
+// the username only applies to the account generated by operator.
+func validateOprSqlAccount(cr *dapi.DorisCluster) []error {
+	if cr.Spec.OprSqlAccount == nil || cr.Spec.OprSqlAccount.Username == "" {
+		return nil
+	}
+	if IsOprSqlAccountSecretReferenced(cr) {
+		return []error{fmt.Errorf("spec.oprSqlAccount.username: it can not be used together with spec.oprSqlAccountSecretRef")}
+	}
+	if !oprSqlAccountUserPattern.MatchString(cr.Spec.OprSqlAccount.Username) {
+		return []error{fmt.Errorf("spec.oprSqlAccount.username: invalid username %q", cr.Spec.OprSqlAccount.Username)}
+	}
+	return nil
+}
+
+// the exec readiness probe only applies to FE, and Broker serves no http endpoint.
+func validateReadinessProbeType(path string, probeType dapi.ProbeType, supported ...dapi.ProbeType) []error {
+	if probeType == "" {
+		return nil
+	}
+	for _, t := range supported {
+		if probeType == t {
+			return nil
+		}
+	}
+	return []error{fmt.Errorf("%s.readinessProbeType: probe type %q is not supported, supported types: %v",
+		path, probeType, supported)}
+}
+
+// the helper image is required to be a valid image reference when it is set and the
+// wait-for-fe init containers of BE or CN are enabled.
+func validateHelperImage(cr *dapi.DorisCluster) []error {
+	if cr.Spec.HelperImage == nil || !IsWaitForFe(cr) || (cr.Spec.BE == nil && cr.Spec.CN == nil) {
+		return nil
+	}
+	image := *cr.Spec.HelperImage
+	if image == "" {
+		return []error{fmt.Errorf("spec.helperImage: image must not be empty when the wait-for-fe init containers are enabled")}
+	}
+	if strings.ContainsAny(image, " \t\n") {
+		return []error{fmt.Errorf("spec.helperImage: invalid image reference %q", image)}
+	}
+	return nil
+}
+
+// the snapshots are restored into the BE, and the restore jobs are identified by the database
+// and snapshot name, so that the same snapshot of a database can not be restored twice.
+func validateRestoreFrom(cr *dapi.DorisCluster) []error {
+	restore := cr.Spec.RestoreFrom
+	if restore == nil {
+		return nil
+	}
+	var errs []error
+	if cr.Spec.BE == nil {
+		errs = append(errs, fmt.Errorf("spec.restoreFrom: BE is required to restore the snapshots"))
+	}
+	errs = append(errs, validateBackupRepository("spec.restoreFrom", restore.BackupRepositorySpec)...)
+	if len(restore.Snapshots) == 0 {
+		errs = append(errs, fmt.Errorf("spec.restoreFrom.snapshots: at least one snapshot is required"))
+	}
+	seen := make(map[string]bool)
+	for i, snapshot := range restore.Snapshots {
+		path := fmt.Sprintf("spec.restoreFrom.snapshots[%d]", i)
+		if snapshot.Database == "" || snapshot.Snapshot == "" || snapshot.BackupTimestamp == "" {
+			errs = append(errs, fmt.Errorf("%s: database, snapshot and backupTimestamp must not be empty", path))
+			continue
+		}
+		if strings.Contains(snapshot.Database, "`") || strings.Contains(snapshot.Snapshot, "`") {
+			errs = append(errs, fmt.Errorf("%s: database and snapshot must not contain backticks", path))
+			continue
+		}
+		key := snapshot.Database + "." + snapshot.Snapshot
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: duplicate snapshot %s", path, key))
+			continue
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// the backups are identified by the database and snapshot name, so that the databases must be distinct.
+func validateBackup(cr *dapi.DorisCluster) []error {
+	backup := cr.Spec.Backup
+	if backup == nil {
+		return nil
+	}
+	errs := validateBackupRepository("spec.backup", backup.BackupRepositorySpec)
+	if schedule, err := util.ParseCronSchedule(backup.Schedule); err != nil {
+		errs = append(errs, fmt.Errorf("spec.backup.schedule: %w", err))
+	} else if schedule.Next(time.Now()).IsZero() {
+		errs = append(errs, fmt.Errorf("spec.backup.schedule: %q would never be triggered", backup.Schedule))
+	}
+	if len(backup.Databases) == 0 {
+		errs = append(errs, fmt.Errorf("spec.backup.databases: at least one database is required"))
+	}
+	seen := make(map[string]bool)
+	for i, database := range backup.Databases {
+		path := fmt.Sprintf("spec.backup.databases[%d]", i)
+		switch {
+		case database == "" || strings.Contains(database, "`"):
+			errs = append(errs, fmt.Errorf("%s: invalid database name %q", path, database))
+		case seen[database]:
+			errs = append(errs, fmt.Errorf("%s: duplicate database %s", path, database))
+		}
+		seen[database] = true
+	}
+	if backup.Retention != nil && *backup.Retention < 1 {
+		errs = append(errs, fmt.Errorf("spec.backup.retention: retention %d must be at least 1", *backup.Retention))
+	}
+	return errs
+}
+
+func validateBackupRepository(path string, repo dapi.BackupRepositorySpec) []error {
+	if GetBackupRepositoryType(repo.Location) == "" {
+		return []error{fmt.Errorf("%s.location: unsupported location %q, only s3:// and hdfs:// are supported",
+			path, repo.Location)}
+	}
+	return nil
+}
+
+// ValidateDorisAutoscaler checks the replicas range and the scaling rules of DorisAutoscaler,
+// the min threshold of each rule must be less than the max one, otherwise the scale up and
+// scale down HPAs would keep fighting with each other.
+func ValidateDorisAutoscaler(cr *dapi.DorisAutoscaler) error {
+	if cr.Spec.CN == nil {
+		return nil
+	}
+	errs := validateReplicasRange("spec.cn.replicas", cr.Spec.CN.Replicas)
+	rules := cr.Spec.CN.Rules
+	for i, rule := range []*dapi.UtilizationThresholdRange{rules.Cpu, rules.Memory} {
+		path := []string{"cpu", "memory"}[i]
+		if rule != nil && rule.Max != nil && rule.Min != nil && *rule.Min >= *rule.Max {
+			errs = append(errs, fmt.Errorf("spec.cn.rules.%s: min %d must be less than max %d", path, *rule.Min, *rule.Max))
+		}
+	}
+	for i, rule := range rules.External {
+		if rule.Max != nil && rule.Min != nil && rule.Min.Cmp(*rule.Max) >= 0 {
+			errs = append(errs, fmt.Errorf("spec.cn.rules.external[%d]: min %s must be less than max %s",
+				i, rule.Min.String(), rule.Max.String()))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrors(errs...)
+}
+
+// check that the replicas range is accepted by the HPA, which requires max >= 1 and 1 <= min <= max.
+func validateReplicasRange(path string, replicas dapi.ReplicasRange) []error {
+	var errs []error
+	if replicas.Max < 1 {
+		errs = append(errs, fmt.Errorf("%s.max: max %d must be greater than 0", path, replicas.Max))
+	}
+	if replicas.Min != nil {
+		if *replicas.Min < 1 {
+			errs = append(errs, fmt.Errorf("%s.min: min %d must be greater than 0", path, *replicas.Min))
+		} else if replicas.Max >= 1 && *replicas.Min > replicas.Max {
+			errs = append(errs, fmt.Errorf("%s.min: min %d must be less than or equal to max %d", path, *replicas.Min, replicas.Max))
+		}
+	}
+	return errs
+}
+
+func validateReplicas(path string, replicas int32) []error {
+	if replicas < 0 {
+		return []error{fmt.Errorf("%s.replicas: replicas %d must not be negative", path, replicas)}
+	}
+	return nil
+}
+
+// check that the runtime class name is a valid RuntimeClass name when it is set,
+// otherwise the statefulset of the component would be rejected by kubernetes.
+func validateRuntimeClassName(path string, name *string) []error {
+	if name == nil {
+		return nil
+	}
+	if *name == "" {
+		return []error{fmt.Errorf("%s.runtimeClassName: runtime class name must not be empty", path)}
+	}
+	if msgs := validation.IsDNS1123Subdomain(*name); len(msgs) > 0 {
+		return []error{fmt.Errorf("%s.runtimeClassName: invalid runtime class name %q: %s",
+			path, *name, strings.Join(msgs, "; "))}
+	}
+	return nil
+}
+
+// check that the cpu, memory and ephemeral-storage limits of the component are not less than the requests.
+func validateResourceLimits(path string, req corev1.ResourceRequirements) []error {
+	var errs []error
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		limit, limitFound := req.Limits[name]
+		request, requestFound := req.Requests[name]
+		if limitFound && requestFound && limit.Cmp(request) < 0 {
+			errs = append(errs, fmt.Errorf("%s.limits.%s: limit %s must be greater than or equal to request %s",
+				path, name, limit.String(), request.String()))
+		}
+	}
+	return errs
+}
+
+// check that each port of the component is not used by other ports.
+func validatePortConflicts(path string, ports map[string]int32) []error {
+	var errs []error
+	usedBy := make(map[int32]string)
+	for _, name := range util.MapSortedKeys(ports) {
+		port := ports[name]
+		if other, found := usedBy[port]; found {
+			errs = append(errs, fmt.Errorf("%s.%s: port %d conflicts with %s", path, name, port, other))
+			continue
+		}
+		usedBy[port] = name
+	}
+	return errs
+}
+
+// check that each extra port of the component is a valid named port, and neither its name
+// nor its number and protocol conflict with the ports managed by operator or other extra ports.
+func validateExtraPorts(path string, managed []corev1.ContainerPort, extra []corev1.ContainerPort) []error {
+	type portKey struct {
+		port     int32
+		protocol corev1.Protocol
+	}
+	keyOf := func(port corev1.ContainerPort) portKey {
+		protocol := util.Coalesce(port.Protocol, corev1.ProtocolTCP)
+		return portKey{port: port.ContainerPort, protocol: protocol}
+	}
+	nameUsedBy := make(map[string]string)
+	portUsedBy := make(map[portKey]string)
+	for _, port := range managed {
+		nameUsedBy[port.Name] = port.Name
+		portUsedBy[keyOf(port)] = port.Name
+	}
+	var errs []error
+	for i, port := range extra {
+		portPath := fmt.Sprintf("%s[%d]", path, i)
+		if port.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: port name must not be empty", portPath))
+		} else if msgs := validation.IsValidPortName(port.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s.name: invalid port name %q: %s", portPath, port.Name, strings.Join(msgs, "; ")))
+		} else if other, found := nameUsedBy[port.Name]; found {
+			errs = append(errs, fmt.Errorf("%s.name: port name %q conflicts with %s", portPath, port.Name, other))
+		} else {
+			nameUsedBy[port.Name] = portPath
+		}
+		if msgs := validation.IsValidPortNum(int(port.ContainerPort)); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s.containerPort: invalid port %d: %s", portPath, port.ContainerPort, strings.Join(msgs, "; ")))
+			continue
+		}
+		key := keyOf(port)
+		if other, found := portUsedBy[key]; found {
+			errs = append(errs, fmt.Errorf("%s.containerPort: port %d/%s conflicts with %s", portPath, key.port, key.protocol, other))
+			continue
+		}
+		portUsedBy[key] = portPath
+	}
+	return errs
+}
+
+// check that the Hadoop credential secret reference has a name and its env keys are
+// valid and distinct environment variable names.
+func validateHadoopCredentialSecretRef(path string, ref *dapi.HadoopCredentialSecretRef) []error {
+	if ref == nil {
+		return nil
+	}
+	var errs []error
+	if ref.Name == "" {
+		errs = append(errs, fmt.Errorf("%s.name: secret name must not be empty", path))
+	}
+	seen := make(map[string]bool)
+	for i, key := range ref.EnvKeys {
+		if msgs := validation.IsEnvVarName(key); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s.envKeys[%d]: invalid env name %q: %s", path, i, key, strings.Join(msgs, "; ")))
+			continue
+		}
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s.envKeys[%d]: duplicate env name %q", path, i, key))
+			continue
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// check that the cordoned ordinals refer to distinct pods of the statefulset.
+func validateCordonedOrdinals(path string, ordinals []int32, replicas int32) []error {
+	var errs []error
+	seen := make(map[int32]bool)
+	for i, ordinal := range ordinals {
+		if ordinal < 0 || ordinal >= replicas {
+			errs = append(errs, fmt.Errorf("%s[%d]: ordinal %d must be between 0 and replicas-1 (%d)", path, i, ordinal, replicas-1))
+			continue
+		}
+		if seen[ordinal] {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate ordinal %d", path, i, ordinal))
+			continue
+		}
+		seen[ordinal] = true
+	}
+	return errs
+}
+
+// check that only one of minAvailable and maxUnavailable is set, the PodDisruptionBudget
+// of kubernetes does not allow both of them.
+func validatePodDisruptionBudget(path string, spec *dapi.PodDisruptionBudgetSpec) []error {
+	if spec != nil && spec.MinAvailable != nil && spec.MaxUnavailable != nil {
+		return []error{fmt.Errorf("%s: only one of minAvailable and maxUnavailable can be set", path)}
+	}
+	return nil
+}
+
+func validateStorageRequest(path string, request *resource.Quantity) []error {
+	if request == nil || request.Sign() <= 0 {
+		return []error{fmt.Errorf("%s: storage request must be greater than zero", path)}
+	}
+	return nil
+}
+
+// the names of the built-in pod volumes of each component, which can not be used by the storage
+// volumes, the built-in volume is replaced by the storage volume with the same mount path instead.
+var (
+	feBuiltInVolumeNames     = []string{"conf", "fe-meta", "fe-log", "fe-recovery", "fe-tls", HadoopCredentialVolumeName}
+	beBuiltInVolumeNames     = []string{"conf", "be-log", "cordon", "be-pod-info", HadoopCredentialVolumeName}
+	cnBuiltInVolumeNames     = []string{"conf", "cn-log", HadoopCredentialVolumeName}
+	brokerBuiltInVolumeNames = []string{"conf", "broker-log", BrokerKerberosVolumeName, HadoopCredentialVolumeName}
+)
+
+// check the storage volumes of component, the persistent ones require the storage request,
+// the names must be unique and must not be the reserved names of built-in pod volumes, and
+// the mount paths must not overlap, including the built-in data storage volumes of component.
+func validateStorageVolumes(path string, volumes []dapi.StorageVolume, reservedNames []string,
+	builtIn ...dapi.StorageVolume) []error {
+	var errs []error
+	names := make(map[string]bool)
+	var mountPaths []dapi.StorageVolume
+	for _, volume := range builtIn {
+		names[volume.Name] = true
+		mountPaths = append(mountPaths, volume)
+	}
+	for i, volume := range volumes {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if volume.Type != dapi.StorageVolumeEphemeral || volume.Request != nil {
+			errs = append(errs, validateStorageRequest(itemPath+".request", volume.Request)...)
+		}
+		if u.Contains(reservedNames, volume.Name) {
+			errs = append(errs, fmt.Errorf("%s.name: %q is reserved by the built-in volume, which could be "+
+				"replaced by a storage volume of another name with the same mount path", itemPath, volume.Name))
+		} else if names[volume.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: duplicate storage volume name %q", itemPath, volume.Name))
+		}
+		names[volume.Name] = true
+		for _, other := range mountPaths {
+			if isMountPathOverlapped(volume.MountPath, other.MountPath) {
+				errs = append(errs, fmt.Errorf("%s.mountPath: %s overlaps with the mount path %s of storage volume %q",
+					itemPath, volume.MountPath, other.MountPath, other.Name))
+			}
+		}
+		mountPaths = append(mountPaths, volume)
+	}
+	return errs
+}
+
+// check whether one of the mount paths is the same as or nested in the other one.
+func isMountPathOverlapped(path1 string, path2 string) bool {
+	path1, path2 = strings.TrimSuffix(path1, "/")+"/", strings.TrimSuffix(path2, "/")+"/"
+	return strings.HasPrefix(path1, path2) || strings.HasPrefix(path2, path1)
+}
+
+// check that the "meta_dir" defined by user matches the mount path of the FE metadata volume.
+func validateFeMetaDir(cr *dapi.DorisCluster) []error {
+	metaDir, found := getSpecComponentConfigs(&cr.Spec.FE.DorisComponentSpec)["meta_dir"]
+	if !found {
+		return nil
+	}
+	if mountPath := GetFeMetaMountPath(cr); strings.TrimSuffix(strings.TrimSpace(metaDir), "/") != mountPath {
+		return []error{fmt.Errorf("spec.fe.config.meta_dir: %s must match the metadata mount path %s", metaDir, mountPath)}
+	}
+	return nil
+}
+
+// check that each path of the "storage_root_path" defined by user matches one of the mount paths
+// of the BE data storage, the path is followed by the optional properties like ",medium:SSD".
+func validateBeStorageRootPath(cr *dapi.DorisCluster) []error {
+	rootPath, found := getSpecComponentConfigs(&cr.Spec.BE.DorisComponentSpec)["storage_root_path"]
+	if !found {
+		return nil
+	}
+	mountPaths := make(map[string]bool)
+	for _, volume := range getBeStorageVolumes(cr.Spec.BE) {
+		mountPaths[strings.TrimSuffix(volume.MountPath, "/")] = true
+	}
+	var errs []error
+	for _, part := range strings.Split(rootPath, ";") {
+		path := strings.TrimSuffix(strings.TrimSpace(strings.Split(part, ",")[0]), "/")
+		if path == "" {
+			continue
+		}
+		if !mountPaths[path] {
+			errs = append(errs, fmt.Errorf("spec.be.config.storage_root_path: %s does not match any mount path of BE data storage %v",
+				path, util.MapSortedKeys(mountPaths)))
+		}
+	}
+	return errs
+}
+
+// check that the Kerberos of Broker has a secret name, and the keytab is not mounted
+// over the krb5.conf.
+func validateBrokerKerberos(path string, spec *dapi.BrokerKerberosSpec) []error {
+	if spec == nil {
+		return nil
+	}
+	var errs []error
+	if spec.SecretName == "" {
+		errs = append(errs, fmt.Errorf("%s.secretName: secret name must not be empty", path))
+	}
+	if spec.Krb5ConfKey != "" && spec.Krb5ConfKey == GetBrokerKeytabKey(spec) {
+		errs = append(errs, fmt.Errorf("%s.krb5ConfKey: key %q is already used by the keytab", path, spec.Krb5ConfKey))
+	}
+	return errs
+}
@@ -0,0 +1,394 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package transformer
+
+import (
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+func TestValidateDorisCluster(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	// port conflicts with the default query_port
+	cr.Spec.FE.Configs = map[string]string{"http_port": "9030"}
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.fe.config.query_port: port 9030 conflicts with http_port")
+
+	// negative replicas and missing storage
+	cr.Spec.FE.Configs = nil
+	cr.Spec.BE = &dapi.BESpec{}
+	cr.Spec.BE.Replicas = -1
+	err = ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.be.replicas")
+	assert.Contains(t, err.Error(), "spec.be.requests.storage")
+
+	// limits less than requests
+	cr.Spec.BE = nil
+	cr.Spec.FE.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	cr.Spec.FE.Requests[corev1.ResourceCPU] = resource.MustParse("1")
+	err = ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.fe.limits.cpu: limit 500m must be greater than or equal to request 1")
+	assert.NotContains(t, err.Error(), "spec.fe.limits.memory")
+	cr.Spec.FE.Limits = nil
+
+	// empty operator sql account secret reference
+	cr.Spec.BE = nil
+	cr.Spec.OprSqlAccountSecretRef = &corev1.LocalObjectReference{}
+	err = ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.oprSqlAccountSecretRef.name")
+}
+
+func TestValidateRuntimeClassName(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	assert.Nil(t, ValidateDorisCluster(cr))
+	cr.Spec.RuntimeClassName = util.Pointer("gvisor")
+	cr.Spec.FE.RuntimeClassName = util.Pointer("kata-qemu")
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	// set but empty or invalid
+	cr.Spec.RuntimeClassName = util.Pointer("")
+	cr.Spec.FE.RuntimeClassName = util.Pointer("Kata_QEMU")
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.runtimeClassName: runtime class name must not be empty")
+	assert.Contains(t, err.Error(), `spec.fe.runtimeClassName: invalid runtime class name "Kata_QEMU"`)
+}
+
+func TestValidateExtraPorts(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.FE.ExtraPorts = []corev1.ContainerPort{
+		{Name: "jvm-debug", ContainerPort: 5005},
+		{Name: "rpc-udp", ContainerPort: GetFeRpcPort(cr), Protocol: corev1.ProtocolUDP},
+	}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	// conflicts with the managed ports and each other
+	cr.Spec.FE.ExtraPorts = []corev1.ContainerPort{
+		{Name: "query-port", ContainerPort: 5005},
+		{Name: "debug", ContainerPort: GetFeHttpPort(cr)},
+		{Name: "debug", ContainerPort: 5005},
+		{ContainerPort: 70000},
+	}
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `spec.fe.extraPorts[0].name: port name "query-port" conflicts with query-port`)
+	assert.Contains(t, err.Error(), "spec.fe.extraPorts[1].containerPort: port 8030/TCP conflicts with http-port")
+	assert.Contains(t, err.Error(), `spec.fe.extraPorts[2].name: port name "debug" conflicts with spec.fe.extraPorts[1]`)
+	assert.Contains(t, err.Error(), "spec.fe.extraPorts[2].containerPort: port 5005/TCP conflicts with spec.fe.extraPorts[0]")
+	assert.Contains(t, err.Error(), "spec.fe.extraPorts[3].name: port name must not be empty")
+	assert.Contains(t, err.Error(), "spec.fe.extraPorts[3].containerPort: invalid port 70000")
+}
+
+func TestValidateHadoopCredentialSecretRef(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.HadoopConf = &dapi.HadoopConfSpec{CredentialSecretRef: &dapi.HadoopCredentialSecretRef{
+		Name:    "hadoop-cred",
+		EnvKeys: []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+	}}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	cr.Spec.HadoopConf.CredentialSecretRef = &dapi.HadoopCredentialSecretRef{
+		EnvKeys: []string{"AWS_ACCESS_KEY_ID", "1_KEY", "AWS_ACCESS_KEY_ID"},
+	}
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.hadoopConf.credentialSecretRef.name: secret name must not be empty")
+	assert.Contains(t, err.Error(), `spec.hadoopConf.credentialSecretRef.envKeys[1]: invalid env name "1_KEY"`)
+	assert.Contains(t, err.Error(), `spec.hadoopConf.credentialSecretRef.envKeys[2]: duplicate env name "AWS_ACCESS_KEY_ID"`)
+}
+
+func TestValidateBrokerKerberos(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.Broker = &dapi.BrokerSpec{
+		DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1},
+		Kerberos:           &dapi.BrokerKerberosSpec{SecretName: "broker-krb"},
+	}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	cr.Spec.Broker.Kerberos = &dapi.BrokerKerberosSpec{Krb5ConfKey: "keytab"}
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.broker.kerberos.secretName: secret name must not be empty")
+	assert.Contains(t, err.Error(), `spec.broker.kerberos.krb5ConfKey: key "keytab" is already used by the keytab`)
+}
+
+func TestValidateCordonedOrdinals(t *testing.T) {
+	assert.Empty(t, validateCordonedOrdinals("spec.be.cordonedOrdinals", []int32{0, 2}, 3))
+	errs := validateCordonedOrdinals("spec.be.cordonedOrdinals", []int32{1, 3, 1, -1}, 3)
+	assert.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "spec.be.cordonedOrdinals[1]: ordinal 3 must be between 0 and replicas-1 (2)")
+	assert.EqualError(t, errs[1], "spec.be.cordonedOrdinals[2]: duplicate ordinal 1")
+	assert.EqualError(t, errs[2], "spec.be.cordonedOrdinals[3]: ordinal -1 must be between 0 and replicas-1 (2)")
+}
+
+func TestValidatePodDisruptionBudget(t *testing.T) {
+	minAvailable, maxUnavailable := intstr.FromInt(2), intstr.FromInt(1)
+	assert.Empty(t, validatePodDisruptionBudget("spec.fe.podDisruptionBudget", nil))
+	assert.Empty(t, validatePodDisruptionBudget("spec.fe.podDisruptionBudget",
+		&dapi.PodDisruptionBudgetSpec{MinAvailable: &minAvailable}))
+	errs := validatePodDisruptionBudget("spec.fe.podDisruptionBudget",
+		&dapi.PodDisruptionBudgetSpec{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable})
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "spec.fe.podDisruptionBudget: only one of minAvailable and maxUnavailable can be set")
+}
+
+func TestValidateHelperImage(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	assert.Empty(t, validateHelperImage(cr))
+
+	cr.Spec.HelperImage = util.Pointer("")
+	errs := validateHelperImage(cr)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "spec.helperImage: image must not be empty when the wait-for-fe init containers are enabled")
+
+	cr.Spec.HelperImage = util.Pointer("bash :5.2")
+	errs = validateHelperImage(cr)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `spec.helperImage: invalid image reference "bash :5.2"`)
+
+	cr.Spec.WaitForFE = util.Pointer(false)
+	assert.Empty(t, validateHelperImage(cr))
+}
+
+func TestValidateOprSqlAccount(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.OprSqlAccount = &dapi.OprSqlAccountSpec{Username: "svc_doris"}
+	assert.Empty(t, validateOprSqlAccount(cr))
+	assert.Equal(t, "svc_doris", MakeOprSqlAccountSecret(cr).StringData[OprSqlAccountUserKey])
+
+	cr.Spec.OprSqlAccount.Username = "svc-doris"
+	errs := validateOprSqlAccount(cr)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `spec.oprSqlAccount.username: invalid username "svc-doris"`)
+
+	cr.Spec.OprSqlAccountSecretRef = &corev1.LocalObjectReference{Name: "account"}
+	errs = validateOprSqlAccount(cr)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "spec.oprSqlAccount.username: it can not be used together with spec.oprSqlAccountSecretRef")
+}
+
+func TestValidateComponentDependencies(t *testing.T) {
+	cr := newTestDorisCluster()
+	assert.Empty(t, validateComponentDependencies(cr))
+
+	cr.Spec.FE = nil
+	errs := validateComponentDependencies(cr)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "spec: at least one of fe, be, cn and broker must be specified")
+
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}}
+	cr.Spec.Broker = &dapi.BrokerSpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}}
+	errs = validateComponentDependencies(cr)
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "spec.fe: FE is required by BE")
+	assert.EqualError(t, errs[1], "spec.fe: FE is required by Broker")
+
+	// no FE replicas
+	cr = newTestDorisCluster()
+	cr.Spec.FE.Replicas = 0
+	assert.ErrorContains(t, ValidateDorisCluster(cr), "spec.fe.replicas: at least 1 FE replica is required")
+}
+
+func TestValidateReadinessProbeType(t *testing.T) {
+	assert.Empty(t, validateReadinessProbeType("spec.be", "", dapi.ProbeTCP, dapi.ProbeHTTP))
+	assert.Empty(t, validateReadinessProbeType("spec.be", dapi.ProbeHTTP, dapi.ProbeTCP, dapi.ProbeHTTP))
+	errs := validateReadinessProbeType("spec.be", dapi.ProbeExec, dapi.ProbeTCP, dapi.ProbeHTTP)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `spec.be.readinessProbeType: probe type "Exec" is not supported, supported types: [TCP HTTP]`)
+}
+
+func TestValidateRestoreFrom(t *testing.T) {
+	cr := withBE(newTestDorisCluster(), 3)
+	cr.Spec.RestoreFrom = &dapi.RestoreFromSpec{
+		BackupRepositorySpec: dapi.BackupRepositorySpec{Location: "hdfs://namenode:8020/backup"},
+		Snapshots:            []dapi.RestoreSnapshotSpec{{Database: "db1", Snapshot: "snap1", BackupTimestamp: "2024-01-01-12-00-00"}},
+	}
+	assert.Empty(t, validateRestoreFrom(cr))
+
+	cr.Spec.RestoreFrom.Location = "oss://bucket/backup"
+	cr.Spec.RestoreFrom.Snapshots = append(cr.Spec.RestoreFrom.Snapshots,
+		dapi.RestoreSnapshotSpec{Database: "db1", Snapshot: "snap1", BackupTimestamp: "2024-01-02-12-00-00"},
+		dapi.RestoreSnapshotSpec{Database: "db2", Snapshot: "snap2"})
+	errs := validateRestoreFrom(cr)
+	assert.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], `spec.restoreFrom.location: unsupported location "oss://bucket/backup", only s3:// and hdfs:// are supported`)
+	assert.EqualError(t, errs[1], "spec.restoreFrom.snapshots[1]: duplicate snapshot db1.snap1")
+	assert.EqualError(t, errs[2], "spec.restoreFrom.snapshots[2]: database, snapshot and backupTimestamp must not be empty")
+}
+
+func TestValidateBackup(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.Backup = &dapi.BackupSpec{
+		BackupRepositorySpec: dapi.BackupRepositorySpec{Location: "s3://bucket/backup"},
+		Schedule:             "@daily",
+		Databases:            []string{"db1", "db2"},
+	}
+	assert.Empty(t, validateBackup(cr))
+
+	cr.Spec.Backup.Schedule = "0 2 * *"
+	cr.Spec.Backup.Databases = []string{"db1", "db1", ""}
+	cr.Spec.Backup.Retention = util.Pointer(int32(0))
+	errs := validateBackup(cr)
+	assert.Len(t, errs, 4)
+	assert.EqualError(t, errs[0], `spec.backup.schedule: invalid cron schedule "0 2 * *": expected 5 fields but got 4`)
+	assert.EqualError(t, errs[1], "spec.backup.databases[1]: duplicate database db1")
+	assert.EqualError(t, errs[2], `spec.backup.databases[2]: invalid database name ""`)
+	assert.EqualError(t, errs[3], "spec.backup.retention: retention 0 must be at least 1")
+
+	cr.Spec.Backup = &dapi.BackupSpec{Schedule: "0 0 30 2 *", Databases: []string{"db1"}}
+	errs = validateBackup(cr)
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[1], `spec.backup.schedule: "0 0 30 2 *" would never be triggered`)
+}
+
+func TestValidateDorisAutoscaler(t *testing.T) {
+	cr := newTestDorisAutoscaler()
+	maxValue, minValue := resource.MustParse("10"), resource.MustParse("2")
+	cr.Spec.CN.Rules.Cpu = &dapi.UtilizationThresholdRange{Max: util.Pointer(int32(80)), Min: util.Pointer(int32(20))}
+	cr.Spec.CN.Rules.External = []dapi.ExternalMetricRule{{Name: "doris_fe_running_query", Max: &maxValue, Min: &minValue}}
+	assert.Nil(t, ValidateDorisAutoscaler(cr))
+
+	// inverted thresholds
+	cr.Spec.CN.Rules.Cpu.Min = util.Pointer(int32(80))
+	cr.Spec.CN.Rules.External[0].Min = &maxValue
+	err := ValidateDorisAutoscaler(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.cn.rules.cpu")
+	assert.Contains(t, err.Error(), "spec.cn.rules.external[0]")
+	assert.NotContains(t, err.Error(), "spec.cn.replicas")
+}
+
+func TestValidateDorisAutoscalerReplicas(t *testing.T) {
+	cr := newTestDorisAutoscaler()
+	assert.Nil(t, ValidateDorisAutoscaler(cr))
+	cr.Spec.CN.Replicas = dapi.ReplicasRange{Max: 3, Min: util.Pointer(int32(3))}
+	assert.Nil(t, ValidateDorisAutoscaler(cr))
+	cr.Spec.CN.Replicas.Min = nil
+	assert.Nil(t, ValidateDorisAutoscaler(cr))
+
+	// min greater than max
+	cr.Spec.CN.Replicas.Min = util.Pointer(int32(4))
+	err := ValidateDorisAutoscaler(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.cn.replicas.min: min 4 must be less than or equal to max 3")
+
+	// non-positive min and max
+	cr.Spec.CN.Replicas = dapi.ReplicasRange{Max: 0, Min: util.Pointer(int32(0))}
+	err = ValidateDorisAutoscaler(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.cn.replicas.max: max 0 must be greater than 0")
+	assert.Contains(t, err.Error(), "spec.cn.replicas.min: min 0 must be greater than 0")
+}
+
+func TestValidateStorageVolumes(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}}
+	cr.Spec.BE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.BE.StorageVolumes = []dapi.StorageVolume{
+		{Name: "be-log-storage", MountPath: "/opt/apache-doris/be/log", Request: util.Pointer(resource.MustParse("50Gi"))},
+		{Name: "be-spill", MountPath: "/opt/apache-doris/be/spill", Type: dapi.StorageVolumeEphemeral},
+	}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	cr.Spec.BE.StorageVolumes = []dapi.StorageVolume{
+		{Name: "be-spill", MountPath: "/opt/apache-doris/be/spill/", Type: dapi.StorageVolumeEphemeral},
+		{Name: "be-spill", MountPath: "/opt/apache-doris/be/spill/tmp", Type: dapi.StorageVolumeEphemeral},
+		{Name: "be-storage", MountPath: "/opt/apache-doris/be/storage/cache"},
+	}
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `spec.be.storageVolumes[1].name: duplicate storage volume name "be-spill"`)
+	assert.Contains(t, err.Error(), `spec.be.storageVolumes[1].mountPath: /opt/apache-doris/be/spill/tmp overlaps with the mount path /opt/apache-doris/be/spill/ of storage volume "be-spill"`)
+	assert.Contains(t, err.Error(), `spec.be.storageVolumes[2].name: duplicate storage volume name "be-storage"`)
+	assert.Contains(t, err.Error(), `spec.be.storageVolumes[2].mountPath: /opt/apache-doris/be/storage/cache overlaps with the mount path /opt/apache-doris/be/storage of storage volume "be-storage"`)
+	// the persistent storage volume requires the storage request
+	assert.Contains(t, err.Error(), "spec.be.storageVolumes[2].request: storage request must be greater than zero")
+	assert.NotContains(t, err.Error(), "spec.be.storageVolumes[0].request")
+
+	// the names of built-in pod volumes are reserved
+	cr.Spec.BE.StorageVolumes = []dapi.StorageVolume{
+		{Name: "be-log", MountPath: "/opt/apache-doris/be/log", Type: dapi.StorageVolumeEphemeral},
+	}
+	cr.Spec.FE.StorageVolumes = []dapi.StorageVolume{
+		{Name: "conf", MountPath: "/opt/apache-doris/fe/plugins", Type: dapi.StorageVolumeEphemeral},
+	}
+	err = ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `spec.be.storageVolumes[0].name: "be-log" is reserved by the built-in volume`)
+	assert.Contains(t, err.Error(), `spec.fe.storageVolumes[0].name: "conf" is reserved by the built-in volume`)
+}
+
+func TestValidateDataPaths(t *testing.T) {
+	cr := newTestDorisCluster()
+	cr.Spec.FE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1}}
+	cr.Spec.BE.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+
+	// the data paths match the default mount paths
+	cr.Spec.FE.Configs = map[string]string{"meta_dir": "/opt/apache-doris/fe/doris-meta/"}
+	cr.Spec.BE.Configs = map[string]string{"storage_root_path": "/opt/apache-doris/be/storage,medium:SSD"}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	// the data paths mismatch the custom mount paths
+	cr.Spec.FE.MetaMountPath = "/data/doris-meta"
+	cr.Spec.BE.StorageMountPath = "/data/storage"
+	err := ValidateDorisCluster(cr)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.fe.config.meta_dir: /opt/apache-doris/fe/doris-meta/ must match the metadata mount path /data/doris-meta")
+	assert.Contains(t, err.Error(), "spec.be.config.storage_root_path: /opt/apache-doris/be/storage does not match")
+
+	cr.Spec.FE.Configs = map[string]string{"meta_dir": "/data/doris-meta"}
+	cr.Spec.BE.Configs = map[string]string{"storage_root_path": "/data/storage"}
+	assert.Nil(t, ValidateDorisCluster(cr))
+
+	// the configs follow the custom mount paths
+	cr.Spec.FE.Configs = nil
+	cr.Spec.BE.Configs = nil
+	cr.Spec.BE.LogMountPath = "/data/log"
+	feConf := MakeFeConfigMap(cr, runtime.NewScheme(), nil).Data[FeConfFileKey]
+	assert.Contains(t, feConf, "meta_dir=/data/doris-meta")
+	assert.NotContains(t, feConf, "LOG_DIR")
+	beConf := MakeBeConfigMap(cr, runtime.NewScheme(), nil).Data[BeConfFileKey]
+	assert.Contains(t, beConf, "storage_root_path=/data/storage,medium:HDD")
+	assert.Contains(t, beConf, "LOG_DIR=/data/log")
+	beMounts := MakeBeStatefulSet(cr, runtime.NewScheme()).Spec.Template.Spec.Containers[0].VolumeMounts
+	assert.Contains(t, beMounts, corev1.VolumeMount{Name: "be-log", MountPath: "/data/log"})
+	assert.Contains(t, beMounts, corev1.VolumeMount{Name: "be-storage", MountPath: "/data/storage"})
+}
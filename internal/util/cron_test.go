@@ -0,0 +1,54 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, spec := range []string{"0 2 * * *", "*/15 * * * *", "0 0-12/3 1,15 * 1-5", "@daily", "0 0 * * 7"} {
+		_, err := ParseCronSchedule(spec)
+		assert.NoError(t, err, spec)
+	}
+	for _, spec := range []string{"", "0 2 * *", "60 * * * *", "0 0 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseCronSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 20, 30, 0, time.UTC)
+	next := func(spec string) time.Time {
+		schedule, err := ParseCronSchedule(spec)
+		assert.NoError(t, err)
+		return schedule.Next(base)
+	}
+	assert.Equal(t, time.Date(2024, 2, 1, 2, 0, 0, 0, time.UTC), next("0 2 * * *"))
+	assert.Equal(t, time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC), next("*/15 * * * *"))
+	assert.Equal(t, time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC), next("@hourly"))
+	// Sunday as 7
+	assert.Equal(t, time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC), next("0 0 * * 7"))
+	// either the day of month or the day of week
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), next("0 0 1 * 6"))
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), next("0 0 29 2 *"))
+	assert.True(t, next("0 0 30 2 *").IsZero())
+}
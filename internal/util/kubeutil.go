@@ -19,14 +19,18 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
 	acv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"reflect"
+	"sort"
 )
 
 func K8sObjKeyStr(key types.NamespacedName) string {
@@ -78,6 +82,12 @@ func NewEnvVarSecretSource(secretName string, key string) *corev1.EnvVarSource {
 	}
 }
 
+func NewEnvVarFieldSource(fieldPath string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: fieldPath},
+	}
+}
+
 func NewTcpSocketProbeHandler(tcpPort int32) corev1.ProbeHandler {
 	return corev1.ProbeHandler{
 		TCPSocket: &corev1.TCPSocketAction{
@@ -96,6 +106,12 @@ func NewHttpGetProbeHandler(path string, httpPort int32) corev1.ProbeHandler {
 	}
 }
 
+func NewExecProbeHandler(command ...string) corev1.ProbeHandler {
+	return corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: command},
+	}
+}
+
 func NewExecLifecycleHandler(command ...string) *corev1.LifecycleHandler {
 	return &corev1.LifecycleHandler{
 		Exec: &corev1.ExecAction{Command: command},
@@ -115,6 +131,22 @@ func NewResourceAvgUtilizationMetricSpec(name corev1.ResourceName, avgUnit *int3
 	}
 }
 
+func NewExternalAvgValueMetricSpec(name string, selector *metav1.LabelSelector, avgValue *resource.Quantity) acv2.MetricSpec {
+	return acv2.MetricSpec{
+		Type: acv2.ExternalMetricSourceType,
+		External: &acv2.ExternalMetricSource{
+			Metric: acv2.MetricIdentifier{
+				Name:     name,
+				Selector: selector,
+			},
+			Target: acv2.MetricTarget{
+				Type:         acv2.AverageValueMetricType,
+				AverageValue: avgValue,
+			},
+		},
+	}
+}
+
 func NewReadWriteOncePVC(name string, storageClassName *string, storageRequest *resource.Quantity) corev1.PersistentVolumeClaim {
 	pvc := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -157,3 +189,87 @@ func IsJobFailed(job batchv1.Job) bool {
 	}
 	return false
 }
+
+// DiffObjectFields compares the fields defined in the desired object with the existing one,
+// and returns the differences in "path: existing -> desired" format. The metadata except the
+// labels and annotations and the status are ignored, and the fields that are only defined in
+// the existing object, e.g. the defaulted fields, are ignored as well.
+// The values would be redacted when redactValue is true.
+func DiffObjectFields(desired, existing runtime.Object, redactValue bool) ([]string, error) {
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+	existingMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return nil, err
+	}
+	delete(desiredMap, "status")
+	delete(desiredMap, "apiVersion")
+	delete(desiredMap, "kind")
+	if meta, ok := desiredMap["metadata"].(map[string]interface{}); ok {
+		desiredMap["metadata"] = map[string]interface{}{"labels": meta["labels"], "annotations": meta["annotations"]}
+	}
+	var diffs []string
+	record := func(path string, existingVal, desiredVal interface{}) {
+		if redactValue {
+			diffs = append(diffs, path)
+			return
+		}
+		diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", path, fieldValueStr(existingVal), fieldValueStr(desiredVal)))
+	}
+	var diff func(path string, desiredVal, existingVal interface{})
+	diff = func(path string, desiredVal, existingVal interface{}) {
+		switch d := desiredVal.(type) {
+		case nil:
+			return
+		case map[string]interface{}:
+			e, ok := existingVal.(map[string]interface{})
+			if !ok {
+				if len(d) > 0 {
+					record(path, existingVal, desiredVal)
+				}
+				return
+			}
+			keys := make([]string, 0, len(d))
+			for k := range d {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				diff(path+"."+k, d[k], e[k])
+			}
+		case []interface{}:
+			e, ok := existingVal.([]interface{})
+			if !ok || len(e) != len(d) {
+				if len(d) > 0 || len(e) > 0 {
+					record(path, existingVal, desiredVal)
+				}
+				return
+			}
+			for i := range d {
+				diff(fmt.Sprintf("%s[%d]", path, i), d[i], e[i])
+			}
+		default:
+			if !reflect.DeepEqual(desiredVal, existingVal) {
+				record(path, existingVal, desiredVal)
+			}
+		}
+	}
+	diff("", desiredMap, existingMap)
+	for i := range diffs {
+		diffs[i] = diffs[i][1:]
+	}
+	return diffs, nil
+}
+
+func fieldValueStr(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
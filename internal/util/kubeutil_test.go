@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2023 @ Linying Assad <linying@apache.org>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestDiffObjectFields(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test", ResourceVersion: "10",
+			Labels: map[string]string{"app": "doris"},
+		},
+		Data: map[string]string{"fe.conf": "a=1", "log4j": "x"},
+	}
+	// no difference, the fields only defined in existing object are ignored
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"app": "doris"}},
+		Data:       map[string]string{"fe.conf": "a=1"},
+	}
+	diffs, err := DiffObjectFields(desired, existing, false)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	// changed and added fields
+	desired.Data = map[string]string{"fe.conf": "a=2", "hdfs-site.xml": "y"}
+	desired.Annotations = map[string]string{"foo": "bar"}
+	diffs, err = DiffObjectFields(desired, existing, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`data.fe.conf: "a=1" -> "a=2"`,
+		`data.hdfs-site.xml: <none> -> "y"`,
+		`metadata.annotations: <none> -> {"foo":"bar"}`,
+	}, diffs)
+
+	// redacted values
+	diffs, err = DiffObjectFields(desired, existing, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data.fe.conf", "data.hdfs-site.xml", "metadata.annotations"}, diffs)
+
+	// list with different length
+	existingSvc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "a", Port: 80}}}}
+	desiredSvc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "a", Port: 80}, {Name: "b", Port: 81}}}}
+	diffs, err = DiffObjectFields(desiredSvc, existingSvc, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(diffs))
+	assert.Contains(t, diffs[0], "spec.ports: ")
+	desiredSvc.Spec.Ports = []corev1.ServicePort{{Name: "a", Port: 8080}}
+	diffs, err = DiffObjectFields(desiredSvc, existingSvc, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec.ports[0].port: 80 -> 8080"}, diffs)
+}
@@ -94,8 +94,8 @@ type MultiTaggedError struct {
 
 func (e *MultiTaggedError) Error() string {
 	errStrs := make([]string, 0, len(e.Errors))
-	for tag, err := range e.Errors {
-		errStrs = append(errStrs, fmt.Sprintf("[%s] %s", tag, err.Error()))
+	for _, tag := range MapSortedKeys(e.Errors) {
+		errStrs = append(errStrs, fmt.Sprintf("[%s] %s", tag, e.Errors[tag].Error()))
 	}
 	return strings.Join(errStrs, "; ")
 }
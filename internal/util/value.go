@@ -22,52 +22,72 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"reflect"
 )
 
-// StringFallback returns the first string if it is not empty, otherwise the second string.
-func StringFallback(str string, fallback string) string {
-	if str != "" {
-		return str
-	} else {
+// Coalesce returns the first value that is not the zero value of its type, e.g. the non-empty
+// string or the non-nil pointer, otherwise the zero value.
+func Coalesce[T comparable](values ...T) T {
+	var zero T
+	for _, value := range values {
+		if value != zero {
+			return value
+		}
+	}
+	return zero
+}
+
+// Fallback returns the value if it is not empty, otherwise the fallback, the value is empty
+// when it is the zero value of its type or an empty slice or map.
+func Fallback[T any](value T, fallback T) T {
+	if IsEmpty(value) {
 		return fallback
 	}
+	return value
+}
+
+// IsEmpty checks whether the value is the zero value of its type or an empty slice or map,
+// the value held by an interface is checked by its dynamic type.
+func IsEmpty[T any](value T) bool {
+	v := reflect.ValueOf(&value).Elem()
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// StringFallback returns the first string if it is not empty, otherwise the second string.
+func StringFallback(str string, fallback string) string {
+	return Coalesce(str, fallback)
 }
 
 // ArrayFallback returns the first array if it is not empty, otherwise the second array.
 func ArrayFallback[T any](array []T, fallback []T) []T {
-	if len(array) != 0 {
-		return array
-	} else {
-		return fallback
-	}
+	return Fallback(array, fallback)
 }
 
+// MapFallback returns the first map if it is not empty, otherwise the second map.
 func MapFallback[K comparable, V any](mapValue map[K]V, fallback map[K]V) map[K]V {
-	if mapValue == nil || len(mapValue) == 0 {
-		return fallback
-	} else {
-		return mapValue
-	}
+	return Fallback(mapValue, fallback)
 }
 
 // PointerFallback returns the first pointer if it is not nil, otherwise the second pointer.
 func PointerFallback[T any](pointer *T, fallback *T) *T {
-	if pointer != nil {
-		return pointer
-	} else {
-		return fallback
-	}
+	return Coalesce(pointer, fallback)
 }
 
 // PointerFallbackAndDeRefer returns the first pointer if it is not nil, otherwise the second pointer,
 // then dereference the pointer fallback with the defaultValue.
 func PointerFallbackAndDeRefer[T any](pointer *T, fallback *T, defaultValue T) T {
-	result := PointerFallback(pointer, fallback)
-	if result != nil {
-		return *result
-	} else {
-		return defaultValue
-	}
+	return PointerDeRefer(Coalesce(pointer, fallback), defaultValue)
 }
 
 func PointerDeRefer[T any](pointer *T, defaultValue T) T {
@@ -78,6 +98,11 @@ func PointerDeRefer[T any](pointer *T, defaultValue T) T {
 	}
 }
 
+// Pointer returns the pointer of the given value.
+func Pointer[T any](value T) *T {
+	return &value
+}
+
 // Elvis is a Groovy-like elvis expression.
 func Elvis[T any](condition bool, leftValue T, rightValue T) T {
 	if condition {
@@ -109,3 +134,9 @@ func Md5HashOr(obj any, fallback string) string {
 	}
 	return hash
 }
+
+// ConfigHash returns the md5 hash of the configuration data, the json marshal of map
+// is sorted by key, so the same content always yields the same hash.
+func ConfigHash(data map[string]string) string {
+	return Md5HashOr(data, "")
+}
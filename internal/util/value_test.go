@@ -23,6 +23,68 @@ import (
 	"testing"
 )
 
+func TestCoalesce(t *testing.T) {
+	foo, bar := "foo", "bar"
+	strCases := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"no value", nil, ""},
+		{"all zero", []string{"", ""}, ""},
+		{"first non-zero", []string{"foo", "bar"}, "foo"},
+		{"fallback", []string{"", "bar"}, "bar"},
+		{"last fallback", []string{"", "", "baz"}, "baz"},
+	}
+	for _, c := range strCases {
+		assert.Equal(t, c.expected, Coalesce(c.values...), c.name)
+	}
+	ptrCases := []struct {
+		name     string
+		values   []*string
+		expected *string
+	}{
+		{"all nil", []*string{nil, nil}, nil},
+		{"first non-nil", []*string{&foo, &bar}, &foo},
+		{"fallback", []*string{nil, &bar}, &bar},
+	}
+	for _, c := range ptrCases {
+		assert.Same(t, c.expected, Coalesce(c.values...), c.name)
+	}
+	// the pointer to zero value is not regarded as zero
+	zero := int32(0)
+	assert.Same(t, &zero, Coalesce(&zero, Pointer(int32(1))))
+	assert.Equal(t, int32(2), Coalesce(int32(0), int32(2)))
+}
+
+func TestFallback(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    any
+		fallback any
+		expected any
+	}{
+		{"nil slice", []string(nil), []string{"bar"}, []string{"bar"}},
+		{"empty slice", []string{}, []string{"bar"}, []string{"bar"}},
+		{"non-empty slice", []string{"foo"}, []string{"bar"}, []string{"foo"}},
+		{"nil map", map[string]string(nil), map[string]string{"k": "bar"}, map[string]string{"k": "bar"}},
+		{"empty map", map[string]string{}, map[string]string{"k": "bar"}, map[string]string{"k": "bar"}},
+		{"non-empty map", map[string]string{"k": "foo"}, map[string]string{"k": "bar"}, map[string]string{"k": "foo"}},
+		{"zero string", "", "bar", "bar"},
+		{"non-zero string", "foo", "bar", "foo"},
+		{"zero int", 0, 1, 1},
+		{"non-zero int", 2, 1, 2},
+		{"zero struct", struct{ S string }{}, struct{ S string }{"bar"}, struct{ S string }{"bar"}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Fallback(c.value, c.fallback), c.name)
+	}
+	assert.Equal(t, []int{1}, ArrayFallback(nil, []int{1}))
+	assert.Equal(t, map[string]int{"a": 1}, MapFallback(map[string]int{}, map[string]int{"a": 1}))
+	assert.True(t, IsEmpty[*string](nil))
+	assert.False(t, IsEmpty(Pointer("")))
+}
+
 func TestStringFallback(t *testing.T) {
 	assert.Equal(t, "foo", StringFallback("foo", "bar"))
 	assert.Equal(t, "bar", StringFallback("", "bar"))
@@ -67,3 +129,23 @@ func TestMd5Hash(t *testing.T) {
 	}
 	assert.Equal(t, "9e0bf104708effc55357dc36f9426ce7", Md5HashOr(m, ""))
 }
+
+func TestConfigHashStable(t *testing.T) {
+	data := map[string]string{
+		"fe.conf":       "http_port=8030",
+		"hdfs-site.xml": "<configuration></configuration>",
+		"core-site.xml": "<configuration></configuration>",
+		"hive-site.xml": "<configuration></configuration>",
+	}
+	expected := ConfigHash(data)
+	assert.NotEmpty(t, expected)
+	for i := 0; i < 1000; i++ {
+		copied := make(map[string]string)
+		for k, v := range data {
+			copied[k] = v
+		}
+		assert.Equal(t, expected, ConfigHash(copied))
+	}
+	data["hdfs-site.xml"] = "<configuration><property/></configuration>"
+	assert.NotEqual(t, expected, ConfigHash(data))
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 @ Linying Assad <linying@apache.org>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	admissionv1 "k8s.io/api/admission/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-al-assad-github-io-v1beta1-doriscluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=al-assad.github.io,resources=dorisclusters,verbs=create;update,versions=v1beta1,name=mdoriscluster.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+// DefaultStorageClassAnnoKey is the annotation that marks the default StorageClass of kubernetes cluster.
+const DefaultStorageClassAnnoKey = "storageclass.kubernetes.io/is-default-class"
+
+// DorisClusterDefaulter defaults the DorisCluster on creation and update.
+type DorisClusterDefaulter struct {
+	Client client.Reader
+}
+
+var _ admission.CustomDefaulter = &DorisClusterDefaulter{}
+
+// SetupWithManager sets up the mutating webhook of DorisCluster with the Manager.
+func (d *DorisClusterDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&dapi.DorisCluster{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// Default fills the base images of components with the operator-wide defaults, and on creation,
+// rounds the even FE replicas up to an odd number for the quorum of FE followers and pins the
+// storageClassName of FE and BE to the default StorageClass. The replicas and storageClassName
+// of the existing DorisCluster are left untouched, since the latter is immutable in statefulset.
+func (d *DorisClusterDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	cr, ok := obj.(*dapi.DorisCluster)
+	if !ok {
+		return fmt.Errorf("expected a DorisCluster but got a %T", obj)
+	}
+	defaultBaseImages(cr)
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation != admissionv1.Create {
+		return nil
+	}
+	if cr.Spec.FE != nil && cr.Spec.FE.Followers == nil {
+		cr.Spec.FE.Replicas = roundUpToOdd(cr.Spec.FE.Replicas)
+	}
+	needStorageClass := (cr.Spec.FE != nil && cr.Spec.FE.StorageClassName == nil) ||
+		(cr.Spec.BE != nil && cr.Spec.BE.StorageClassName == nil)
+	if !needStorageClass {
+		return nil
+	}
+	storageClass, err := d.getDefaultStorageClass(ctx)
+	if err != nil || storageClass == "" {
+		return err
+	}
+	if cr.Spec.FE != nil && cr.Spec.FE.StorageClassName == nil {
+		cr.Spec.FE.StorageClassName = &storageClass
+	}
+	if cr.Spec.BE != nil && cr.Spec.BE.StorageClassName == nil {
+		cr.Spec.BE.StorageClassName = &storageClass
+	}
+	return nil
+}
+
+// fill the empty base images of components with the operator-wide defaults.
+func defaultBaseImages(cr *dapi.DorisCluster) {
+	if cr.Spec.FE != nil {
+		cr.Spec.FE.BaseImage = tran.GetFeBaseImage(cr)
+	}
+	if cr.Spec.BE != nil {
+		cr.Spec.BE.BaseImage = tran.GetBeBaseImage(cr)
+	}
+	if cr.Spec.CN != nil {
+		cr.Spec.CN.BaseImage = tran.GetCnBaseImage(cr)
+	}
+	if cr.Spec.Broker != nil {
+		cr.Spec.Broker.BaseImage = tran.GetBrokerBaseImage(cr)
+	}
+}
+
+// round the positive even number up to the next odd number, e.g. 2 -> 3, 4 -> 5.
+func roundUpToOdd(n int32) int32 {
+	if n > 0 && n%2 == 0 {
+		return n + 1
+	}
+	return n
+}
+
+// get the name of the default StorageClass, returns empty when there is no default StorageClass.
+func (d *DorisClusterDefaulter) getDefaultStorageClass(ctx context.Context) (string, error) {
+	if d.Client == nil {
+		return "", nil
+	}
+	storageClasses := &storagev1.StorageClassList{}
+	if err := d.Client.List(ctx, storageClasses); err != nil {
+		return "", err
+	}
+	for _, item := range storageClasses.Items {
+		if item.Annotations[DefaultStorageClassAnnoKey] == "true" {
+			return item.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 @ Linying Assad <linying@apache.org>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"testing"
+)
+
+func TestDorisClusterDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+			Name:        "ssd",
+			Annotations: map[string]string{DefaultStorageClassAnnoKey: "true"},
+		}},
+	).Build()
+	defaulter := &DorisClusterDefaulter{Client: cli}
+	newCr := func() *dapi.DorisCluster {
+		return &dapi.DorisCluster{Spec: dapi.DorisClusterSpec{
+			FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 2}},
+			BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{BaseImage: "custom/doris-be", Replicas: 2},
+				StorageClassName: util.Pointer("hdd")},
+		}}
+	}
+	withOperation := func(op admissionv1.Operation) context.Context {
+		return admission.NewContextWithRequest(context.Background(),
+			admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: op}})
+	}
+
+	// defaults on creation
+	cr := newCr()
+	assert.NoError(t, defaulter.Default(withOperation(admissionv1.Create), cr))
+	assert.Equal(t, tran.DefaultFeBaseImage, cr.Spec.FE.BaseImage)
+	assert.Equal(t, "custom/doris-be", cr.Spec.BE.BaseImage)
+	assert.Equal(t, int32(3), cr.Spec.FE.Replicas)
+	assert.Equal(t, int32(2), cr.Spec.BE.Replicas)
+	assert.Equal(t, "ssd", *cr.Spec.FE.StorageClassName)
+	assert.Equal(t, "hdd", *cr.Spec.BE.StorageClassName)
+
+	// the FE replicas are left untouched when the followers are specified
+	cr = newCr()
+	cr.Spec.FE.Followers = util.Pointer(int32(1))
+	assert.NoError(t, defaulter.Default(withOperation(admissionv1.Create), cr))
+	assert.Equal(t, int32(2), cr.Spec.FE.Replicas)
+
+	// only the base images are defaulted on update
+	cr = newCr()
+	assert.NoError(t, defaulter.Default(withOperation(admissionv1.Update), cr))
+	assert.Equal(t, tran.DefaultFeBaseImage, cr.Spec.FE.BaseImage)
+	assert.Equal(t, int32(2), cr.Spec.FE.Replicas)
+	assert.Nil(t, cr.Spec.FE.StorageClassName)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2023 @ Linying Assad <linying@apache.org>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	tran "github.com/al-assad/doris-operator/internal/transformer"
+	"github.com/al-assad/doris-operator/internal/util"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+)
+
+//+kubebuilder:webhook:path=/validate-al-assad-github-io-v1beta1-doriscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=al-assad.github.io,resources=dorisclusters,verbs=create;update,versions=v1beta1,name=vdoriscluster.kb.io,admissionReviewVersions=v1
+
+// DorisClusterValidator validates the DorisCluster on creation and update.
+type DorisClusterValidator struct {
+	Client client.Reader
+}
+
+var _ admission.CustomValidator = &DorisClusterValidator{}
+
+// SetupWithManager sets up the validating webhook of DorisCluster with the Manager.
+func (v *DorisClusterValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&dapi.DorisCluster{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *DorisClusterValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	warnings, err := v.validate(ctx, obj)
+	if err != nil {
+		return warnings, err
+	}
+	if err := v.validateResourceKeyUnique(ctx, obj.(*dapi.DorisCluster)); err != nil {
+		return warnings, err
+	}
+	return warnings, nil
+}
+
+func (v *DorisClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	warnings, err := v.validate(ctx, newObj)
+	if err != nil {
+		return warnings, err
+	}
+	oldCr, oldOk := oldObj.(*dapi.DorisCluster)
+	newCr := newObj.(*dapi.DorisCluster)
+	// the existing sub resources would be left behind in the previous namespace
+	if oldOk && oldCr.ResourceKey().Namespace != newCr.ResourceKey().Namespace {
+		return warnings, fmt.Errorf("spec.resourceNamespace is immutable")
+	}
+	// the restoring only applies to the newly created cluster, while it can be removed afterward
+	if oldOk && newCr.Spec.RestoreFrom != nil && !reflect.DeepEqual(oldCr.Spec.RestoreFrom, newCr.Spec.RestoreFrom) {
+		return warnings, fmt.Errorf("spec.restoreFrom can only be set on the creation of DorisCluster")
+	}
+	if oldOk {
+		if err := validateStorageAnnotationsUnchanged(oldCr, newCr); err != nil {
+			return warnings, err
+		}
+		if err := validateStorageVolumeTypesUnchanged(oldCr, newCr); err != nil {
+			return warnings, err
+		}
+	}
+	if oldOk && tran.GetOprSqlAccountSecretRef(oldCr) != tran.GetOprSqlAccountSecretRef(newCr) {
+		warnings = append(warnings, "changing spec.oprSqlAccountSecretRef does not change the account in Doris, "+
+			"the account of the new secret should have been created in Doris")
+	}
+	return warnings, nil
+}
+
+func (v *DorisClusterValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *DorisClusterValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*dapi.DorisCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a DorisCluster but got a %T", obj)
+	}
+	if err := tran.ValidateDorisCluster(cr); err != nil {
+		return nil, err
+	}
+	var warnings admission.Warnings
+	if cr.Spec.ConfigKeyValidation != dapi.ConfigKeyValidationDisabled {
+		// Doris adds config keys over time, the unknown keys are only rejected in the strict mode
+		unknownKeys := tran.FindUnknownConfigKeys(cr)
+		if len(unknownKeys) > 0 && cr.Spec.ConfigKeyValidation == dapi.ConfigKeyValidationStrict {
+			return nil, fmt.Errorf("%s", strings.Join(unknownKeys, "; "))
+		}
+		warnings = append(warnings, unknownKeys...)
+	}
+	// the FE replicas are all followers unless the followers are specified
+	if cr.Spec.FE != nil && cr.Spec.FE.Replicas > 0 && cr.Spec.FE.Replicas%2 == 0 {
+		warnings = append(warnings, fmt.Sprintf("the number of FE replicas %d is even, "+
+			"an odd number is recommended for the quorum of FE", cr.Spec.FE.Replicas))
+	}
+	if followers := tran.GetFeFollowerNum(cr); cr.Spec.FE != nil && cr.Spec.FE.Followers != nil &&
+		followers != cr.Spec.FE.Replicas && followers%2 == 0 {
+		warnings = append(warnings, fmt.Sprintf("the number of FE followers %d is even, "+
+			"an odd number is recommended for the quorum of FE", followers))
+	}
+	if cr.Spec.BE != nil && cr.Spec.BE.HostNetwork {
+		warnings = append(warnings, fmt.Sprintf("hostNetwork is enabled for BE, at most one BE pod can be run "+
+			"on each node, the %d BE replicas require at least %d schedulable nodes", cr.Spec.BE.Replicas, cr.Spec.BE.Replicas))
+	}
+	if cr.Spec.CN != nil {
+		autoscaler, err := v.findRefDorisAutoscaler(ctx, cr)
+		if err != nil {
+			return warnings, err
+		}
+		// the replicas of CN statefulset are left to the HPAs of DorisAutoscaler
+		if autoscaler != nil && autoscaler.Spec.CN != nil {
+			warnings = append(warnings, fmt.Sprintf("spec.cn.replicas %d is ignored since the CN is scaled by "+
+				"DorisAutoscaler %s within the replicas range [%d, %d]", cr.Spec.CN.Replicas, autoscaler.Name,
+				util.PointerDeRefer(autoscaler.Spec.CN.Replicas.Min, 1), autoscaler.Spec.CN.Replicas.Max))
+		}
+	}
+	return warnings, nil
+}
+
+// the volumeClaimTemplates of statefulset are immutable, so that the storage annotations
+// of the existing components can not be changed.
+func validateStorageAnnotationsUnchanged(oldCr, newCr *dapi.DorisCluster) error {
+	oldAnnotations, newAnnotations := getStorageAnnotations(oldCr), getStorageAnnotations(newCr)
+	var errs []error
+	for _, component := range util.MapSortedKeys(newAnnotations) {
+		oldValue, found := oldAnnotations[component]
+		newValue := newAnnotations[component]
+		if !found || len(oldValue) == 0 && len(newValue) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			errs = append(errs, fmt.Errorf("spec.%s.storageAnnotations is immutable", component))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrors(errs...)
+}
+
+// the persistent storage volumes are generated as the volumeClaimTemplates of statefulset,
+// which are immutable, so that the type of the existing storage volumes can not be changed.
+func validateStorageVolumeTypesUnchanged(oldCr, newCr *dapi.DorisCluster) error {
+	oldVolumes, newVolumes := getStorageVolumes(oldCr), getStorageVolumes(newCr)
+	var errs []error
+	for _, component := range util.MapSortedKeys(newVolumes) {
+		oldTypes := make(map[string]dapi.StorageVolumeType)
+		for _, volume := range oldVolumes[component] {
+			oldTypes[volume.Name] = util.Coalesce(volume.Type, dapi.StorageVolumePersistent)
+		}
+		for i, volume := range newVolumes[component] {
+			oldType, found := oldTypes[volume.Name]
+			if found && oldType != util.Coalesce(volume.Type, dapi.StorageVolumePersistent) {
+				errs = append(errs, fmt.Errorf("spec.%s.storageVolumes[%d].type is immutable", component, i))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.MergeErrors(errs...)
+}
+
+// get the storage volumes of each component in the DorisCluster.
+func getStorageVolumes(cr *dapi.DorisCluster) map[string][]dapi.StorageVolume {
+	volumes := make(map[string][]dapi.StorageVolume)
+	if cr.Spec.FE != nil {
+		volumes["fe"] = cr.Spec.FE.StorageVolumes
+	}
+	if cr.Spec.BE != nil {
+		volumes["be"] = cr.Spec.BE.StorageVolumes
+	}
+	if cr.Spec.CN != nil {
+		volumes["cn"] = cr.Spec.CN.StorageVolumes
+	}
+	if cr.Spec.Broker != nil {
+		volumes["broker"] = cr.Spec.Broker.StorageVolumes
+	}
+	return volumes
+}
+
+// get the storage annotations of each component in the DorisCluster.
+func getStorageAnnotations(cr *dapi.DorisCluster) map[string]map[string]string {
+	annotations := make(map[string]map[string]string)
+	if cr.Spec.FE != nil {
+		annotations["fe"] = cr.Spec.FE.StorageAnnotations
+	}
+	if cr.Spec.BE != nil {
+		annotations["be"] = cr.Spec.BE.StorageAnnotations
+	}
+	if cr.Spec.CN != nil {
+		annotations["cn"] = cr.Spec.CN.StorageAnnotations
+	}
+	if cr.Spec.Broker != nil {
+		annotations["broker"] = cr.Spec.Broker.StorageAnnotations
+	}
+	return annotations
+}
+
+// the names of sub resources are derived from the name of DorisCluster only, two DorisClusters
+// with the same name can not place their sub resources in the same namespace.
+func (v *DorisClusterValidator) validateResourceKeyUnique(ctx context.Context, cr *dapi.DorisCluster) error {
+	if v.Client == nil {
+		return nil
+	}
+	crList := &dapi.DorisClusterList{}
+	if err := v.Client.List(ctx, crList); err != nil {
+		return err
+	}
+	for _, item := range crList.Items {
+		if item.Namespace != cr.Namespace && item.ResourceKey() == cr.ResourceKey() {
+			return fmt.Errorf("DorisCluster %s has placed its sub resources in namespace %s, "+
+				"spec.resourceNamespace conflicts with it", util.K8sObjKeyStr(item.ObjKey()), cr.ResourceKey().Namespace)
+		}
+	}
+	return nil
+}
+
+// find the DorisAutoscaler that refers to the DorisCluster in the same way as
+// ReconcileContext.FindRefDorisAutoScaler, returns nil when there is none.
+func (v *DorisClusterValidator) findRefDorisAutoscaler(ctx context.Context, cr *dapi.DorisCluster) (*dapi.DorisAutoscaler, error) {
+	if v.Client == nil {
+		return nil, nil
+	}
+	crList := &dapi.DorisAutoscalerList{}
+	if err := v.Client.List(ctx, crList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
+		return nil, err
+	}
+	for _, item := range crList.Items {
+		if item.Spec.Cluster == cr.Name {
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
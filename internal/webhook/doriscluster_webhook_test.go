@@ -0,0 +1,262 @@
+/*
+Copyright 2023 @ Linying Assad <linying@apache.org>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	dapi "github.com/al-assad/doris-operator/api/v1beta1"
+	"github.com/al-assad/doris-operator/internal/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestDorisClusterValidatorAutoscaledCn(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = dapi.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&dapi.DorisAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-autoscaler", Namespace: "default"},
+			Spec: dapi.DorisAutoscalerSpec{Cluster: "test", CN: &dapi.CNAutoscalerSpec{
+				Replicas: dapi.ReplicasRange{Max: 5, Min: util.Pointer(int32(2))},
+			}},
+		},
+	).Build()
+	validator := &DorisClusterValidator{Client: cli}
+	newCr := func(name string) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: dapi.DorisClusterSpec{
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				}}},
+				CN: &dapi.CNSpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3}},
+			},
+		}
+	}
+
+	// the cn replicas of the autoscaled cluster are ignored
+	warnings, err := validator.ValidateCreate(context.Background(), newCr("test"))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "spec.cn.replicas 3 is ignored")
+	assert.Contains(t, warnings[0], "DorisAutoscaler test-autoscaler within the replicas range [2, 5]")
+
+	// the cluster without autoscaler
+	warnings, err = validator.ValidateCreate(context.Background(), newCr("other"))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// the cluster without cn
+	cr := newCr("test")
+	cr.Spec.CN = nil
+	warnings, err = validator.ValidateUpdate(context.Background(), newCr("test"), cr)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestDorisClusterValidatorEvenFeReplicas(t *testing.T) {
+	validator := &DorisClusterValidator{}
+	cr := &dapi.DorisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: dapi.DorisClusterSpec{
+			FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3, ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			}}},
+		},
+	}
+	warnings, err := validator.ValidateCreate(context.Background(), cr)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// the even replicas without the followers specified
+	cr.Spec.FE.Replicas = 4
+	warnings, err = validator.ValidateCreate(context.Background(), cr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"the number of FE replicas 4 is even, an odd number is recommended for the quorum of FE"}, []string(warnings))
+
+	// the odd followers along with the observers
+	cr.Spec.FE.Replicas = 5
+	cr.Spec.FE.Followers = util.Pointer(int32(3))
+	warnings, err = validator.ValidateCreate(context.Background(), cr)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// the even followers
+	cr.Spec.FE.Followers = util.Pointer(int32(2))
+	warnings, err = validator.ValidateCreate(context.Background(), cr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"the number of FE followers 2 is even, an odd number is recommended for the quorum of FE"}, []string(warnings))
+}
+
+func TestDorisClusterValidatorStorageAnnotations(t *testing.T) {
+	validator := &DorisClusterValidator{}
+	newCr := func(annotations map[string]string) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: dapi.DorisClusterSpec{
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, StorageAnnotations: annotations,
+					ResourceRequirements: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					}}},
+			},
+		}
+	}
+	csiAnnotations := map[string]string{"csi.example.com/encryption-key": "doris"}
+	_, err := validator.ValidateUpdate(context.Background(), newCr(csiAnnotations), newCr(csiAnnotations))
+	assert.NoError(t, err)
+	_, err = validator.ValidateUpdate(context.Background(), newCr(nil), newCr(map[string]string{}))
+	assert.NoError(t, err)
+
+	_, err = validator.ValidateUpdate(context.Background(), newCr(nil), newCr(csiAnnotations))
+	assert.ErrorContains(t, err, "spec.fe.storageAnnotations is immutable")
+	_, err = validator.ValidateUpdate(context.Background(), newCr(csiAnnotations),
+		newCr(map[string]string{"csi.example.com/encryption-key": "other"}))
+	assert.ErrorContains(t, err, "spec.fe.storageAnnotations is immutable")
+
+	// the storage annotations of the newly added component
+	oldCr, cr := newCr(nil), newCr(nil)
+	cr.Spec.BE = &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, StorageAnnotations: csiAnnotations,
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		}}}
+	_, err = validator.ValidateUpdate(context.Background(), oldCr, cr)
+	assert.NoError(t, err)
+}
+
+func TestDorisClusterValidatorStorageVolumeTypes(t *testing.T) {
+	validator := &DorisClusterValidator{}
+	newCr := func(volumeType dapi.StorageVolumeType) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: dapi.DorisClusterSpec{
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1,
+					ResourceRequirements: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+					StorageVolumes: []dapi.StorageVolume{{Name: "fe-plugins", MountPath: "/opt/apache-doris/fe/plugins",
+						Type: volumeType, Request: util.Pointer(resource.MustParse("1Gi"))}},
+				}},
+			},
+		}
+	}
+	_, err := validator.ValidateUpdate(context.Background(), newCr(""), newCr(dapi.StorageVolumePersistent))
+	assert.NoError(t, err)
+	_, err = validator.ValidateUpdate(context.Background(), newCr(""), newCr(dapi.StorageVolumeEphemeral))
+	assert.ErrorContains(t, err, "spec.fe.storageVolumes[0].type is immutable")
+	_, err = validator.ValidateUpdate(context.Background(), newCr(dapi.StorageVolumeEphemeral), newCr(dapi.StorageVolumePersistent))
+	assert.ErrorContains(t, err, "spec.fe.storageVolumes[0].type is immutable")
+}
+
+func TestDorisClusterValidatorConfigKeys(t *testing.T) {
+	validator := &DorisClusterValidator{}
+	newCr := func(mode dapi.ConfigKeyValidationMode) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: dapi.DorisClusterSpec{
+				Version:             "2.0.3",
+				ConfigKeyValidation: mode,
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				}, Configs: map[string]string{"http_prot": "8030", "qe_slow_log_ms": "5000"}}},
+			},
+		}
+	}
+
+	// the unknown keys are warned by default
+	warnings, err := validator.ValidateCreate(context.Background(), newCr(""))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `spec.fe.config: unknown config key "http_prot" for Doris 2.0, did you mean "http_port"?`)
+
+	// the unknown keys are rejected in the strict mode
+	_, err = validator.ValidateCreate(context.Background(), newCr(dapi.ConfigKeyValidationStrict))
+	assert.ErrorContains(t, err, `unknown config key "http_prot"`)
+
+	// the check is skipped when disabled
+	warnings, err = validator.ValidateCreate(context.Background(), newCr(dapi.ConfigKeyValidationDisabled))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestDorisClusterValidatorRestoreFrom(t *testing.T) {
+	validator := &DorisClusterValidator{}
+	newCr := func(restore *dapi.RestoreFromSpec) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: dapi.DorisClusterSpec{
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				}}},
+				BE: &dapi.BESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 3, ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+				}}},
+				RestoreFrom: restore,
+			},
+		}
+	}
+	restore := &dapi.RestoreFromSpec{
+		BackupRepositorySpec: dapi.BackupRepositorySpec{Location: "s3://bucket/backup"},
+		Snapshots:            []dapi.RestoreSnapshotSpec{{Database: "db1", Snapshot: "snap1", BackupTimestamp: "2024-01-01-12-00-00"}},
+	}
+
+	// the restoreFrom can not be added to an existing cluster
+	_, err := validator.ValidateUpdate(context.Background(), newCr(nil), newCr(restore))
+	assert.ErrorContains(t, err, "spec.restoreFrom can only be set on the creation of DorisCluster")
+
+	// nor be changed after the creation
+	changed := restore.DeepCopy()
+	changed.Location = "s3://bucket/other"
+	_, err = validator.ValidateUpdate(context.Background(), newCr(restore), newCr(changed))
+	assert.Error(t, err)
+
+	// while it can be removed
+	_, err = validator.ValidateUpdate(context.Background(), newCr(restore), newCr(nil))
+	assert.NoError(t, err)
+}
+
+func TestDorisClusterValidatorResourceKeyUnique(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = dapi.AddToScheme(scheme)
+	newCr := func(namespace, resourceNamespace string) *dapi.DorisCluster {
+		return &dapi.DorisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace},
+			Spec: dapi.DorisClusterSpec{
+				ResourceNamespace: resourceNamespace,
+				FE: &dapi.FESpec{DorisComponentSpec: dapi.DorisComponentSpec{Replicas: 1, ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				}}},
+			},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newCr("ns-a", "doris")).Build()
+	validator := &DorisClusterValidator{Client: cli}
+
+	_, err := validator.ValidateCreate(context.Background(), newCr("ns-b", "doris"))
+	assert.ErrorContains(t, err, "DorisCluster test.ns-a has placed its sub resources in namespace doris")
+	_, err = validator.ValidateCreate(context.Background(), newCr("doris", ""))
+	assert.Error(t, err)
+	_, err = validator.ValidateCreate(context.Background(), newCr("ns-b", "other"))
+	assert.NoError(t, err)
+}